@@ -0,0 +1,283 @@
+// Package adversary simulates a malicious server. It wraps the evaluators of REP (vche_1) and PE (vche_2) so that they
+// deviate from the computation with a chosen cheating strategy, in order to check that the client detects the deviation
+// when it verifies the results.
+//
+// The wrapped evaluators compute the tags of the results honestly, as a cheating server would to pass the checks on the
+// tags, and only tamper with the encrypted values.
+package adversary
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+	"sync"
+
+	"github.com/ldsec/lattigo/v2/bfv"
+	"github.com/ldsec/lattigo/v2/ring"
+	"github.com/ldsec/lattigo/v2/rlwe"
+	"github.com/ldsec/lattigo/v2/utils"
+	"veritas/vche/vche"
+)
+
+// Strategy is a way for the server to deviate from the computation.
+type Strategy int
+
+const (
+	Honest         Strategy = iota // evaluates the operations correctly
+	Skip                           // returns the first operand instead of the result of the operation
+	Substitute                     // evaluates the operation with the first operand replaced by Config.Substitute
+	Replay                         // returns the result of the previous operation, or the first operand if there is none
+	Offset                         // adds Config.Offset to all the slots of the result
+	CorruptReplica                 // adds Config.Offset to the slot Config.Slot of the result, that is, to a single replica in REP
+	GuessDummies                   // adds Config.Offset to the replicas of a random guess of the non-dummy replications in REP
+	GuessAlpha                     // alters the result of PE so that it is consistent only for a random guess of the secret alpha
+)
+
+// Strategies lists the cheating strategies. GuessDummies and GuessAlpha are the best strategies against REP and PE: they
+// succeed with the probability of the theoretical soundness bound.
+var Strategies = []Strategy{Skip, Substitute, Replay, Offset, CorruptReplica, GuessDummies, GuessAlpha}
+
+// ParseStrategy returns the strategy with the given name.
+func ParseStrategy(name string) (Strategy, error) {
+	for _, s := range append([]Strategy{Honest}, Strategies...) {
+		if s.String() == name {
+			return s, nil
+		}
+	}
+	return Honest, fmt.Errorf("unknown strategy %q", name)
+}
+
+func (s Strategy) String() string {
+	switch s {
+	case Honest:
+		return "honest"
+	case Skip:
+		return "skip"
+	case Substitute:
+		return "substitute"
+	case Replay:
+		return "replay"
+	case Offset:
+		return "offset"
+	case CorruptReplica:
+		return "corrupt-replica"
+	case GuessDummies:
+		return "guess-dummies"
+	case GuessAlpha:
+		return "guess-alpha"
+	default:
+		return fmt.Sprintf("Strategy(%d)", int(s))
+	}
+}
+
+// Config configures the deviation of a malicious evaluator.
+type Config struct {
+	Strategy Strategy
+	// Target is the index of the operation to tamper with, counting the operations from 0 in the order in which they are
+	// called. All the operations are tampered with if Target is negative.
+	Target int
+	// Substitute is the ciphertext used by the Substitute strategy, for instance the encryption of another input of the
+	// client. It has the type of the ciphertexts of the encoding.
+	Substitute interface{}
+	Offset     uint64 // constant added by the Offset and CorruptReplica strategies, defaults to 1
+	Slot       int    // slot corrupted by the CorruptReplica strategy
+}
+
+// adversary holds the state of a malicious evaluator, which is shared by its shallow copies.
+type adversary struct {
+	mu         sync.Mutex
+	cfg        Config
+	params     vche.Parameters
+	evaluator  bfv.Evaluator
+	offsets    []*bfv.Plaintext // plaintexts added to the components of the result
+	count      int
+	deviations int
+	last       []*rlwe.Ciphertext // result of the previous operation, for the Replay strategy
+}
+
+func newAdversary(params vche.Parameters, cfg Config) *adversary {
+	if cfg.Offset == 0 {
+		cfg.Offset = 1
+	}
+	a := &adversary{cfg: cfg, params: params, evaluator: bfv.NewEvaluator(params.Parameters, rlwe.EvaluationKey{})}
+	switch cfg.Strategy {
+	case Honest, Skip, Replay:
+	case Substitute:
+		if cfg.Substitute == nil {
+			panic(fmt.Errorf("the %s strategy requires a substitute ciphertext", cfg.Strategy))
+		}
+	case Offset:
+		a.offsets = []*bfv.Plaintext{encodeConstant(params, cfg.Offset%params.T())}
+	case CorruptReplica:
+		coeffs := make([]uint64, params.N())
+		if cfg.Slot < 0 || cfg.Slot >= len(coeffs) {
+			panic(fmt.Errorf("slot %d out of range [0, %d)", cfg.Slot, len(coeffs)))
+		}
+		coeffs[cfg.Slot] = cfg.Offset % params.T()
+		a.offsets = []*bfv.Plaintext{encode(params, coeffs)}
+	case GuessDummies:
+		// The replicas of each value remain consistent if the guess is the complement of the dummy set
+		coeffs := make([]uint64, params.N())
+		guess := randomSubset(params.NumReplications, params.NumReplications-params.NumDummies)
+		for i := 0; i < params.NSlots; i++ {
+			for _, j := range guess {
+				coeffs[i*params.NumReplications+j] = cfg.Offset % params.T()
+			}
+		}
+		a.offsets = []*bfv.Plaintext{encode(params, coeffs)}
+	case GuessAlpha:
+		// (c0 + offset) + (c1 - offset/guess) * alpha = c0 + c1 * alpha if guess = alpha
+		T := new(big.Int).SetUint64(params.T())
+		guess := new(big.Int).SetUint64(1 + randomUint64(params.T()-1))
+		correction := new(big.Int).ModInverse(guess, T)
+		correction.Mul(correction, new(big.Int).SetUint64(cfg.Offset))
+		correction.Neg(correction).Mod(correction, T)
+		a.offsets = []*bfv.Plaintext{encodeConstant(params, cfg.Offset%params.T()), encodeConstant(params, correction.Uint64())}
+	default:
+		panic(fmt.Errorf("unknown strategy %s", cfg.Strategy))
+	}
+	return a
+}
+
+// deviation is the tampering of one operation. src holds the components that replace those of the honest result, or is
+// nil if the honest result is kept.
+type deviation struct {
+	src []*rlwe.Ciphertext
+}
+
+// begin is called before an operation whose first operand has the components first. substitute evaluates the operation
+// with the substitute ciphertext as first operand. It returns nil if the operation is evaluated honestly.
+func (a *adversary) begin(first []*rlwe.Ciphertext, substitute func() []*rlwe.Ciphertext) *deviation {
+	a.mu.Lock()
+	index := a.count
+	a.count++
+	last := a.last
+	a.mu.Unlock()
+
+	if a.cfg.Strategy == Honest || (a.cfg.Target >= 0 && a.cfg.Target != index) {
+		return nil
+	}
+	switch a.cfg.Strategy {
+	case Skip:
+		return &deviation{copyComponents(first)}
+	case Substitute:
+		return &deviation{substitute()}
+	case Replay:
+		if last == nil {
+			return &deviation{copyComponents(first)}
+		}
+		return &deviation{last}
+	default:
+		return &deviation{}
+	}
+}
+
+// end tampers with the components out of the honest result of an operation, according to the deviation d, and returns
+// the components of the result. The components of out are modified in place, and components are appended if the result
+// has more components than out.
+func (a *adversary) end(d *deviation, out []*rlwe.Ciphertext) []*rlwe.Ciphertext {
+	if d != nil {
+		if d.src != nil {
+			out = overwrite(out, d.src)
+		}
+		if len(a.offsets) > len(out) {
+			panic(fmt.Errorf("the %s strategy requires a result with at least %d components, got %d", a.cfg.Strategy, len(a.offsets), len(out)))
+		}
+		for i, offset := range a.offsets {
+			a.evaluator.Add(&bfv.Ciphertext{Ciphertext: out[i]}, offset, &bfv.Ciphertext{Ciphertext: out[i]})
+		}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if d != nil {
+		a.deviations++
+	}
+	if a.cfg.Strategy == Replay {
+		a.last = copyComponents(out)
+	}
+	return out
+}
+
+// deviationCount returns the number of operations tampered with so far.
+func (a *adversary) deviationCount() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.deviations
+}
+
+// overwrite replaces the components dst by copies of src. The components of dst are modified in place, padded with
+// zeros to keep at least their degree, and the extra components of dst are set to zero.
+func overwrite(dst, src []*rlwe.Ciphertext) []*rlwe.Ciphertext {
+	for i := range src {
+		if i == len(dst) {
+			dst = append(dst, src[i].CopyNew())
+			continue
+		}
+		degree := dst[i].Degree()
+		if src[i].Degree() > degree {
+			degree = src[i].Degree()
+		}
+		value := make([]*ring.Poly, degree+1)
+		for j := range value {
+			if j <= src[i].Degree() {
+				value[j] = src[i].Value[j].CopyNew()
+			} else {
+				value[j] = src[i].Value[0].CopyNew()
+				value[j].Zero()
+			}
+		}
+		dst[i].Value = value
+	}
+	for i := len(src); i < len(dst); i++ {
+		for _, poly := range dst[i].Value {
+			poly.Zero()
+		}
+	}
+	return dst
+}
+
+func copyComponents(cts []*rlwe.Ciphertext) []*rlwe.Ciphertext {
+	res := make([]*rlwe.Ciphertext, len(cts))
+	for i := range cts {
+		res[i] = cts[i].CopyNew()
+	}
+	return res
+}
+
+func encode(params vche.Parameters, coeffs []uint64) *bfv.Plaintext {
+	pt := bfv.NewPlaintext(params.Parameters)
+	bfv.NewEncoder(params.Parameters).EncodeUint(coeffs, pt)
+	return pt
+}
+
+// encodeConstant returns the plaintext with the value c in all the slots.
+func encodeConstant(params vche.Parameters, c uint64) *bfv.Plaintext {
+	coeffs := make([]uint64, params.N())
+	for i := range coeffs {
+		coeffs[i] = c
+	}
+	return encode(params, coeffs)
+}
+
+// randomUint64 returns a uniformly random integer in [0, n).
+func randomUint64(n uint64) uint64 {
+	prng, err := utils.NewPRNG()
+	if err != nil {
+		panic(err)
+	}
+	return ring.RandUniform(prng, n, uint64(1<<uint64(bits.Len64(n)))-1)
+}
+
+// randomSubset returns k distinct indices sampled uniformly in [0, n).
+func randomSubset(n, k int) []int {
+	perm := make([]int, n)
+	for i := range perm {
+		perm[i] = i
+	}
+	for i := 0; i < k; i++ {
+		j := i + int(randomUint64(uint64(n-i)))
+		perm[i], perm[j] = perm[j], perm[i]
+	}
+	return perm[:k]
+}
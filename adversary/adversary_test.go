@@ -0,0 +1,150 @@
+package adversary
+
+import (
+	"testing"
+
+	"github.com/ldsec/lattigo/v2/rlwe"
+	"github.com/stretchr/testify/require"
+	"veritas/vche/vche"
+	"veritas/vche/vche_1"
+	"veritas/vche/vche_2"
+)
+
+// target is the index of the operation tampered with in the circuit x*y + x of the trials: the addition, which comes
+// after the multiplication and the relinearization.
+const target = 2
+
+// trial evaluates x*y + x on fresh inputs with a malicious evaluator and reports whether the client detected the
+// deviation, and whether the decoded result is correct.
+type trial func(strategy Strategy) (detected, correct bool)
+
+func TestAdversary(t *testing.T) {
+	trials := 10
+	if testing.Short() {
+		trials = 3
+	}
+	for _, encoding := range []struct {
+		name     string
+		newTrial func(t *testing.T) trial
+	}{
+		{"REP", newREPTrial},
+		{"PE", newPETrial},
+	} {
+		run := encoding.newTrial(t)
+		t.Run(encoding.name+"/"+Honest.String(), func(t *testing.T) {
+			for i := 0; i < trials; i++ {
+				detected, correct := run(Honest)
+				require.False(t, detected)
+				require.True(t, correct)
+			}
+		})
+		for _, strategy := range Strategies {
+			if encoding.name == "REP" && strategy == GuessAlpha {
+				continue
+			}
+			t.Run(encoding.name+"/"+strategy.String(), func(t *testing.T) {
+				numDetected := 0
+				for i := 0; i < trials; i++ {
+					if detected, _ := run(strategy); detected {
+						numDetected++
+					}
+				}
+				t.Logf("detection rate %d/%d", numDetected, trials)
+				require.Equal(t, trials, numDetected)
+			})
+		}
+	}
+}
+
+func TestAdversaryTarget(t *testing.T) {
+	params, err := vche_1.NewParametersFromLiteral(vche_1.DefaultParams[0])
+	require.NoError(t, err)
+	kgen := vche_1.NewKeyGenerator(params)
+	sk := kgen.GenSecretKey()
+	ct := vche_1.NewEncryptor(params, sk).EncryptNew(vche_1.NewPlaintext(params))
+	evaluator := vche_1.NewEvaluator(params, &vche_1.EvaluationKey{H: sk.H})
+
+	eval := NewREPEvaluator(evaluator, params, Config{Strategy: Offset, Target: 1})
+	for i := 0; i < 3; i++ {
+		eval.AddNew(ct, ct)
+	}
+	require.Equal(t, 1, eval.Deviations())
+
+	eval = NewREPEvaluator(evaluator, params, Config{Strategy: Offset, Target: -1})
+	copied := eval.ShallowCopy()
+	eval.AddNew(ct, ct)
+	copied.AddNew(ct, ct)
+	require.Equal(t, 2, eval.Deviations())
+
+	require.Panics(t, func() { NewREPEvaluator(evaluator, params, Config{Strategy: Substitute}) })
+	require.Panics(t, func() { NewREPEvaluator(evaluator, params, Config{Strategy: CorruptReplica, Slot: params.N()}) })
+	require.Panics(t, func() { NewREPEvaluator(evaluator, params, Config{Strategy: GuessAlpha}) })
+}
+
+func newREPTrial(t *testing.T) trial {
+	params, err := vche_1.NewParametersFromLiteral(vche_1.DefaultParams[0])
+	require.NoError(t, err)
+	kgen := vche_1.NewKeyGenerator(params)
+	sk := kgen.GenSecretKey()
+	rlk := kgen.GenRelinearizationKey(sk, 1)
+	encoder := vche_1.NewEncoder(params, sk.K, sk.S, false)
+	encryptor := vche_1.NewEncryptor(params, sk)
+	decryptor := vche_1.NewDecryptor(params, sk)
+	evaluator := vche_1.NewEvaluator(params, &vche_1.EvaluationKey{EvaluationKey: rlwe.EvaluationKey{Rlk: rlk.RelinearizationKey}, H: rlk.H})
+	encoderPlaintext := vche_1.NewEncoderPlaintext(params, sk.K)
+	evaluatorPlaintext := vche_1.NewEvaluatorPlaintext(params, sk.H)
+
+	input := func(datasetTag string) ([]uint64, *vche_1.Ciphertext, *vche_1.TaggedPoly) {
+		tags := vche.GetIndexTags([]byte(datasetTag), params.NSlots)
+		coeffs := vche.GetRandomCoeffs(params.NSlots, params.T())
+		return coeffs, encryptor.EncryptNew(encoder.EncodeUintNew(coeffs, tags)), encoderPlaintext.EncodeNew(tags)
+	}
+
+	return func(strategy Strategy) (bool, bool) {
+		x, ctX, verifX := input("x")
+		y, ctY, verifY := input("y")
+		_, ctZ, _ := input("z")
+		eval := NewREPEvaluator(evaluator, params, Config{Strategy: strategy, Target: target, Substitute: ctZ})
+		out := eval.AddNew(eval.RelinearizeNew(eval.MulNew(ctX, ctY)), ctX)
+		verif := evaluatorPlaintext.AddNew(evaluatorPlaintext.RelinearizeNew(evaluatorPlaintext.MulNew(verifX, verifY)), verifX)
+		return verify(func() []uint64 { return encoder.DecodeUintNew(decryptor.DecryptNew(out), verif) }, expected(x, y, params.T()))
+	}
+}
+
+func newPETrial(t *testing.T) trial {
+	params, err := vche_2.NewParametersFromLiteral(vche_2.DefaultParams[0])
+	require.NoError(t, err)
+	kgen := vche_2.NewKeyGenerator(params)
+	sk := kgen.GenSecretKey()
+	encoder := vche_2.NewEncoder(params, sk.K, sk.Alpha, false)
+	encryptor := vche_2.NewEncryptor(params, sk)
+	decryptor := vche_2.NewDecryptor(params, sk)
+	evaluator := vche_2.NewEvaluator(params, &vche_2.EvaluationKey{Rlk: kgen.GenRelinearizationKey(sk, 1)})
+	encoderPlaintext := vche_2.NewEncoderPlaintext(params, sk.K)
+	evaluatorPlaintext := vche_2.NewEvaluatorPlaintext(params)
+
+	input := func(datasetTag string) ([]uint64, *vche_2.Ciphertext, *vche_2.Poly) {
+		tags := vche.GetIndexTags([]byte(datasetTag), params.NSlots)
+		coeffs := vche.GetRandomCoeffs(params.NSlots, params.T())
+		return coeffs, encryptor.EncryptNew(encoder.EncodeUintNew(coeffs, tags)), encoderPlaintext.EncodeNew(tags)
+	}
+
+	return func(strategy Strategy) (bool, bool) {
+		x, ctX, verifX := input("x")
+		y, ctY, verifY := input("y")
+		_, ctZ, _ := input("z")
+		eval := NewPEEvaluator(evaluator, params, Config{Strategy: strategy, Target: target, Substitute: ctZ})
+		out := eval.AddNew(eval.RelinearizeNew(eval.MulNew(ctX, ctY)), ctX)
+		verif := evaluatorPlaintext.AddNew(evaluatorPlaintext.RelinearizeNew(evaluatorPlaintext.MulNew(verifX, verifY)), verifX)
+		return verify(func() []uint64 { return encoder.DecodeUintNew(decryptor.DecryptNew(out), verif) }, expected(x, y, params.T()))
+	}
+}
+
+// expected returns x*y + x mod t.
+func expected(x, y []uint64, t uint64) []uint64 {
+	res := make([]uint64, len(x))
+	for i := range res {
+		res[i] = (x[i]*y[i]%t + x[i]) % t
+	}
+	return res
+}
@@ -0,0 +1,216 @@
+package adversary
+
+import (
+	"fmt"
+
+	"github.com/ldsec/lattigo/v2/bfv"
+	"github.com/ldsec/lattigo/v2/rlwe"
+	"veritas/vche/vche_2"
+)
+
+// PEEvaluator is a PE evaluator that deviates from the computation.
+type PEEvaluator interface {
+	vche_2.Evaluator
+	// Deviations returns the number of operations tampered with so far.
+	Deviations() int
+}
+
+type peEvaluator struct {
+	vche_2.Evaluator
+	adversary  *adversary
+	params     vche_2.Parameters
+	substitute *vche_2.Ciphertext
+}
+
+// NewPEEvaluator returns an evaluator that evaluates the operations with eval, and tampers with their results as
+// configured by cfg. The substitute ciphertext of cfg, if any, must be a *vche_2.Ciphertext.
+func NewPEEvaluator(eval vche_2.Evaluator, params vche_2.Parameters, cfg Config) PEEvaluator {
+	var substitute *vche_2.Ciphertext
+	if cfg.Substitute != nil {
+		ct, ok := cfg.Substitute.(*vche_2.Ciphertext)
+		if !ok {
+			panic(fmt.Errorf("expected a *vche_2.Ciphertext as substitute, got %T", cfg.Substitute))
+		}
+		substitute = ct
+	}
+	return &peEvaluator{eval, newAdversary(params, cfg), params, substitute}
+}
+
+func (eval *peEvaluator) Deviations() int {
+	return eval.adversary.deviationCount()
+}
+
+// run evaluates in place an operation whose first operand is op0 with op, and tampers with its result ctOut. opNew
+// evaluates the operation on another first operand.
+func (eval *peEvaluator) run(op0 vche_2.Operand, opNew func(op0 vche_2.Operand) *vche_2.Ciphertext, op func(), ctOut *vche_2.Ciphertext) {
+	d := eval.begin(op0, opNew)
+	op()
+	eval.end(d, ctOut)
+}
+
+// runNew evaluates an operation whose first operand is op0 with opNew, and tampers with its result.
+func (eval *peEvaluator) runNew(op0 vche_2.Operand, opNew func(op0 vche_2.Operand) *vche_2.Ciphertext) (ctOut *vche_2.Ciphertext) {
+	d := eval.begin(op0, opNew)
+	ctOut = opNew(op0)
+	eval.end(d, ctOut)
+	return ctOut
+}
+
+func (eval *peEvaluator) begin(op0 vche_2.Operand, opNew func(op0 vche_2.Operand) *vche_2.Ciphertext) *deviation {
+	return eval.adversary.begin(components(op0), func() []*rlwe.Ciphertext {
+		return components(opNew(eval.substitute))
+	})
+}
+
+// end tampers with the result ctOut, to which it appends components if the tampered result has more components.
+func (eval *peEvaluator) end(d *deviation, ctOut *vche_2.Ciphertext) {
+	out := eval.adversary.end(d, components(ctOut))
+	for i := len(ctOut.Ciphertexts); i < len(out); i++ {
+		ctOut.Ciphertexts = append(ctOut.Ciphertexts, &bfv.Ciphertext{Ciphertext: out[i]})
+	}
+}
+
+// components returns the BFV ciphertexts or plaintexts of a PE operand.
+func components(op vche_2.Operand) []*rlwe.Ciphertext {
+	operands := op.Operands()
+	res := make([]*rlwe.Ciphertext, len(operands))
+	for i := range operands {
+		res[i] = operands[i].El()
+	}
+	return res
+}
+
+func (eval *peEvaluator) Add(op0, op1 vche_2.Operand, ctOut *vche_2.Ciphertext) {
+	eval.run(op0, func(op0 vche_2.Operand) *vche_2.Ciphertext { return eval.Evaluator.AddNew(op0, op1) }, func() { eval.Evaluator.Add(op0, op1, ctOut) }, ctOut)
+}
+
+func (eval *peEvaluator) AddNew(op0, op1 vche_2.Operand) (ctOut *vche_2.Ciphertext) {
+	return eval.runNew(op0, func(op0 vche_2.Operand) *vche_2.Ciphertext { return eval.Evaluator.AddNew(op0, op1) })
+}
+
+func (eval *peEvaluator) AddNoMod(op0, op1 vche_2.Operand, ctOut *vche_2.Ciphertext) {
+	eval.run(op0, func(op0 vche_2.Operand) *vche_2.Ciphertext { return eval.Evaluator.AddNoModNew(op0, op1) }, func() { eval.Evaluator.AddNoMod(op0, op1, ctOut) }, ctOut)
+}
+
+func (eval *peEvaluator) AddNoModNew(op0, op1 vche_2.Operand) (ctOut *vche_2.Ciphertext) {
+	return eval.runNew(op0, func(op0 vche_2.Operand) *vche_2.Ciphertext { return eval.Evaluator.AddNoModNew(op0, op1) })
+}
+
+func (eval *peEvaluator) Sub(op0, op1 vche_2.Operand, ctOut *vche_2.Ciphertext) {
+	eval.run(op0, func(op0 vche_2.Operand) *vche_2.Ciphertext { return eval.Evaluator.SubNew(op0, op1) }, func() { eval.Evaluator.Sub(op0, op1, ctOut) }, ctOut)
+}
+
+func (eval *peEvaluator) SubNew(op0, op1 vche_2.Operand) (ctOut *vche_2.Ciphertext) {
+	return eval.runNew(op0, func(op0 vche_2.Operand) *vche_2.Ciphertext { return eval.Evaluator.SubNew(op0, op1) })
+}
+
+func (eval *peEvaluator) SubNoMod(op0, op1 vche_2.Operand, ctOut *vche_2.Ciphertext) {
+	eval.run(op0, func(op0 vche_2.Operand) *vche_2.Ciphertext { return eval.Evaluator.SubNoModNew(op0, op1) }, func() { eval.Evaluator.SubNoMod(op0, op1, ctOut) }, ctOut)
+}
+
+func (eval *peEvaluator) SubNoModNew(op0, op1 vche_2.Operand) (ctOut *vche_2.Ciphertext) {
+	return eval.runNew(op0, func(op0 vche_2.Operand) *vche_2.Ciphertext { return eval.Evaluator.SubNoModNew(op0, op1) })
+}
+
+func (eval *peEvaluator) Neg(op vche_2.Operand, ctOut *vche_2.Ciphertext) {
+	eval.run(op, eval.Evaluator.NegNew, func() { eval.Evaluator.Neg(op, ctOut) }, ctOut)
+}
+
+func (eval *peEvaluator) NegNew(op vche_2.Operand) (ctOut *vche_2.Ciphertext) {
+	return eval.runNew(op, eval.Evaluator.NegNew)
+}
+
+func (eval *peEvaluator) Reduce(op vche_2.Operand, ctOut *vche_2.Ciphertext) {
+	eval.run(op, eval.Evaluator.ReduceNew, func() { eval.Evaluator.Reduce(op, ctOut) }, ctOut)
+}
+
+func (eval *peEvaluator) ReduceNew(op vche_2.Operand) (ctOut *vche_2.Ciphertext) {
+	return eval.runNew(op, eval.Evaluator.ReduceNew)
+}
+
+func (eval *peEvaluator) MulScalar(op vche_2.Operand, scalar uint64, ctOut *vche_2.Ciphertext) {
+	eval.run(op, func(op vche_2.Operand) *vche_2.Ciphertext { return eval.Evaluator.MulScalarNew(op, scalar) }, func() { eval.Evaluator.MulScalar(op, scalar, ctOut) }, ctOut)
+}
+
+func (eval *peEvaluator) MulScalarNew(op vche_2.Operand, scalar uint64) (ctOut *vche_2.Ciphertext) {
+	return eval.runNew(op, func(op vche_2.Operand) *vche_2.Ciphertext { return eval.Evaluator.MulScalarNew(op, scalar) })
+}
+
+func (eval *peEvaluator) Mul(op0 *vche_2.Ciphertext, op1 vche_2.Operand, ctOut *vche_2.Ciphertext) {
+	eval.run(op0, func(op0 vche_2.Operand) *vche_2.Ciphertext {
+		return eval.Evaluator.MulNew(op0.(*vche_2.Ciphertext), op1)
+	}, func() { eval.Evaluator.Mul(op0, op1, ctOut) }, ctOut)
+}
+
+func (eval *peEvaluator) MulNew(op0 *vche_2.Ciphertext, op1 vche_2.Operand) (ctOut *vche_2.Ciphertext) {
+	return eval.runNew(op0, func(op0 vche_2.Operand) *vche_2.Ciphertext {
+		return eval.Evaluator.MulNew(op0.(*vche_2.Ciphertext), op1)
+	})
+}
+
+func (eval *peEvaluator) Relinearize(ct0 *vche_2.Ciphertext, ctOut *vche_2.Ciphertext) {
+	eval.run(ct0, func(ct0 vche_2.Operand) *vche_2.Ciphertext {
+		return eval.Evaluator.RelinearizeNew(ct0.(*vche_2.Ciphertext))
+	}, func() { eval.Evaluator.Relinearize(ct0, ctOut) }, ctOut)
+}
+
+func (eval *peEvaluator) RelinearizeNew(ct0 *vche_2.Ciphertext) (ctOut *vche_2.Ciphertext) {
+	return eval.runNew(ct0, func(ct0 vche_2.Operand) *vche_2.Ciphertext {
+		return eval.Evaluator.RelinearizeNew(ct0.(*vche_2.Ciphertext))
+	})
+}
+
+func (eval *peEvaluator) SwitchKeys(ct0 *vche_2.Ciphertext, switchKey *vche_2.SwitchingKey, ctOut *vche_2.Ciphertext) {
+	eval.run(ct0, func(ct0 vche_2.Operand) *vche_2.Ciphertext {
+		return eval.Evaluator.SwitchKeysNew(ct0.(*vche_2.Ciphertext), switchKey)
+	}, func() { eval.Evaluator.SwitchKeys(ct0, switchKey, ctOut) }, ctOut)
+}
+
+func (eval *peEvaluator) SwitchKeysNew(ct0 *vche_2.Ciphertext, switchKey *vche_2.SwitchingKey) (ctOut *vche_2.Ciphertext) {
+	return eval.runNew(ct0, func(ct0 vche_2.Operand) *vche_2.Ciphertext {
+		return eval.Evaluator.SwitchKeysNew(ct0.(*vche_2.Ciphertext), switchKey)
+	})
+}
+
+func (eval *peEvaluator) RotateColumns(ct0 *vche_2.Ciphertext, k int, ctOut *vche_2.Ciphertext) {
+	eval.run(ct0, func(ct0 vche_2.Operand) *vche_2.Ciphertext {
+		return eval.Evaluator.RotateColumnsNew(ct0.(*vche_2.Ciphertext), k)
+	}, func() { eval.Evaluator.RotateColumns(ct0, k, ctOut) }, ctOut)
+}
+
+func (eval *peEvaluator) RotateColumnsNew(ct0 *vche_2.Ciphertext, k int) (ctOut *vche_2.Ciphertext) {
+	return eval.runNew(ct0, func(ct0 vche_2.Operand) *vche_2.Ciphertext {
+		return eval.Evaluator.RotateColumnsNew(ct0.(*vche_2.Ciphertext), k)
+	})
+}
+
+func (eval *peEvaluator) RotateRows(ct0 *vche_2.Ciphertext, ctOut *vche_2.Ciphertext) {
+	eval.run(ct0, func(ct0 vche_2.Operand) *vche_2.Ciphertext {
+		return eval.Evaluator.RotateRowsNew(ct0.(*vche_2.Ciphertext))
+	}, func() { eval.Evaluator.RotateRows(ct0, ctOut) }, ctOut)
+}
+
+func (eval *peEvaluator) RotateRowsNew(ct0 *vche_2.Ciphertext) (ctOut *vche_2.Ciphertext) {
+	return eval.runNew(ct0, func(ct0 vche_2.Operand) *vche_2.Ciphertext {
+		return eval.Evaluator.RotateRowsNew(ct0.(*vche_2.Ciphertext))
+	})
+}
+
+func (eval *peEvaluator) InnerSum(ct0 *vche_2.Ciphertext, ctOut *vche_2.Ciphertext) {
+	innerSumNew := func(ct0 vche_2.Operand) *vche_2.Ciphertext {
+		res := vche_2.NewCiphertext(eval.params, ct0.BfvDegree())
+		eval.Evaluator.InnerSum(ct0.(*vche_2.Ciphertext), res)
+		return res
+	}
+	eval.run(ct0, innerSumNew, func() { eval.Evaluator.InnerSum(ct0, ctOut) }, ctOut)
+}
+
+// ShallowCopy returns a copy of the evaluator that shares the state of the adversary, so that the operations of all the
+// copies are counted together.
+func (eval *peEvaluator) ShallowCopy() vche_2.Evaluator {
+	return &peEvaluator{eval.Evaluator.ShallowCopy(), eval.adversary, eval.params, eval.substitute}
+}
+
+func (eval *peEvaluator) WithKey(evk vche_2.EvaluationKey) vche_2.Evaluator {
+	return &peEvaluator{eval.Evaluator.WithKey(evk), eval.adversary, eval.params, eval.substitute}
+}
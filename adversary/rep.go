@@ -0,0 +1,204 @@
+package adversary
+
+import (
+	"fmt"
+
+	"github.com/ldsec/lattigo/v2/rlwe"
+	"veritas/vche/vche_1"
+)
+
+// REPEvaluator is a REP evaluator that deviates from the computation.
+type REPEvaluator interface {
+	vche_1.Evaluator
+	// Deviations returns the number of operations tampered with so far.
+	Deviations() int
+}
+
+type repEvaluator struct {
+	vche_1.Evaluator
+	adversary  *adversary
+	params     vche_1.Parameters
+	substitute *vche_1.Ciphertext
+}
+
+// NewREPEvaluator returns an evaluator that evaluates the operations with eval, and tampers with their results as
+// configured by cfg. The substitute ciphertext of cfg, if any, must be a *vche_1.Ciphertext.
+func NewREPEvaluator(eval vche_1.Evaluator, params vche_1.Parameters, cfg Config) REPEvaluator {
+	if cfg.Strategy == GuessAlpha {
+		panic(fmt.Errorf("the %s strategy only applies to PE", cfg.Strategy))
+	}
+	var substitute *vche_1.Ciphertext
+	if cfg.Substitute != nil {
+		ct, ok := cfg.Substitute.(*vche_1.Ciphertext)
+		if !ok {
+			panic(fmt.Errorf("expected a *vche_1.Ciphertext as substitute, got %T", cfg.Substitute))
+		}
+		substitute = ct
+	}
+	return &repEvaluator{eval, newAdversary(params, cfg), params, substitute}
+}
+
+func (eval *repEvaluator) Deviations() int {
+	return eval.adversary.deviationCount()
+}
+
+// run evaluates in place an operation whose first operand is op0 with op, and tampers with its result ctOut. opNew
+// evaluates the operation on another first operand.
+func (eval *repEvaluator) run(op0 vche_1.Operand, opNew func(op0 vche_1.Operand) *vche_1.Ciphertext, op func(), ctOut *vche_1.Ciphertext) {
+	d := eval.begin(op0, opNew)
+	op()
+	eval.end(d, ctOut)
+}
+
+// runNew evaluates an operation whose first operand is op0 with opNew, and tampers with its result.
+func (eval *repEvaluator) runNew(op0 vche_1.Operand, opNew func(op0 vche_1.Operand) *vche_1.Ciphertext) (ctOut *vche_1.Ciphertext) {
+	d := eval.begin(op0, opNew)
+	ctOut = opNew(op0)
+	eval.end(d, ctOut)
+	return ctOut
+}
+
+func (eval *repEvaluator) begin(op0 vche_1.Operand, opNew func(op0 vche_1.Operand) *vche_1.Ciphertext) *deviation {
+	return eval.adversary.begin([]*rlwe.Ciphertext{op0.El()}, func() []*rlwe.Ciphertext {
+		return []*rlwe.Ciphertext{opNew(eval.substitute).El()}
+	})
+}
+
+func (eval *repEvaluator) end(d *deviation, ctOut *vche_1.Ciphertext) {
+	eval.adversary.end(d, []*rlwe.Ciphertext{ctOut.El()})
+}
+
+func (eval *repEvaluator) Add(op0, op1 vche_1.Operand, ctOut *vche_1.Ciphertext) {
+	eval.run(op0, func(op0 vche_1.Operand) *vche_1.Ciphertext { return eval.Evaluator.AddNew(op0, op1) }, func() { eval.Evaluator.Add(op0, op1, ctOut) }, ctOut)
+}
+
+func (eval *repEvaluator) AddNew(op0, op1 vche_1.Operand) (ctOut *vche_1.Ciphertext) {
+	return eval.runNew(op0, func(op0 vche_1.Operand) *vche_1.Ciphertext { return eval.Evaluator.AddNew(op0, op1) })
+}
+
+func (eval *repEvaluator) AddNoMod(op0, op1 vche_1.Operand, ctOut *vche_1.Ciphertext) {
+	eval.run(op0, func(op0 vche_1.Operand) *vche_1.Ciphertext { return eval.Evaluator.AddNoModNew(op0, op1) }, func() { eval.Evaluator.AddNoMod(op0, op1, ctOut) }, ctOut)
+}
+
+func (eval *repEvaluator) AddNoModNew(op0, op1 vche_1.Operand) (ctOut *vche_1.Ciphertext) {
+	return eval.runNew(op0, func(op0 vche_1.Operand) *vche_1.Ciphertext { return eval.Evaluator.AddNoModNew(op0, op1) })
+}
+
+func (eval *repEvaluator) Sub(op0, op1 vche_1.Operand, ctOut *vche_1.Ciphertext) {
+	eval.run(op0, func(op0 vche_1.Operand) *vche_1.Ciphertext { return eval.Evaluator.SubNew(op0, op1) }, func() { eval.Evaluator.Sub(op0, op1, ctOut) }, ctOut)
+}
+
+func (eval *repEvaluator) SubNew(op0, op1 vche_1.Operand) (ctOut *vche_1.Ciphertext) {
+	return eval.runNew(op0, func(op0 vche_1.Operand) *vche_1.Ciphertext { return eval.Evaluator.SubNew(op0, op1) })
+}
+
+func (eval *repEvaluator) SubNoMod(op0, op1 vche_1.Operand, ctOut *vche_1.Ciphertext) {
+	eval.run(op0, func(op0 vche_1.Operand) *vche_1.Ciphertext { return eval.Evaluator.SubNoModNew(op0, op1) }, func() { eval.Evaluator.SubNoMod(op0, op1, ctOut) }, ctOut)
+}
+
+func (eval *repEvaluator) SubNoModNew(op0, op1 vche_1.Operand) (ctOut *vche_1.Ciphertext) {
+	return eval.runNew(op0, func(op0 vche_1.Operand) *vche_1.Ciphertext { return eval.Evaluator.SubNoModNew(op0, op1) })
+}
+
+func (eval *repEvaluator) Neg(op vche_1.Operand, ctOut *vche_1.Ciphertext) {
+	eval.run(op, eval.Evaluator.NegNew, func() { eval.Evaluator.Neg(op, ctOut) }, ctOut)
+}
+
+func (eval *repEvaluator) NegNew(op vche_1.Operand) (ctOut *vche_1.Ciphertext) {
+	return eval.runNew(op, eval.Evaluator.NegNew)
+}
+
+func (eval *repEvaluator) Reduce(op vche_1.Operand, ctOut *vche_1.Ciphertext) {
+	eval.run(op, eval.Evaluator.ReduceNew, func() { eval.Evaluator.Reduce(op, ctOut) }, ctOut)
+}
+
+func (eval *repEvaluator) ReduceNew(op vche_1.Operand) (ctOut *vche_1.Ciphertext) {
+	return eval.runNew(op, eval.Evaluator.ReduceNew)
+}
+
+func (eval *repEvaluator) MulScalar(op vche_1.Operand, scalar uint64, ctOut *vche_1.Ciphertext) {
+	eval.run(op, func(op vche_1.Operand) *vche_1.Ciphertext { return eval.Evaluator.MulScalarNew(op, scalar) }, func() { eval.Evaluator.MulScalar(op, scalar, ctOut) }, ctOut)
+}
+
+func (eval *repEvaluator) MulScalarNew(op vche_1.Operand, scalar uint64) (ctOut *vche_1.Ciphertext) {
+	return eval.runNew(op, func(op vche_1.Operand) *vche_1.Ciphertext { return eval.Evaluator.MulScalarNew(op, scalar) })
+}
+
+func (eval *repEvaluator) Mul(op0 *vche_1.Ciphertext, op1 vche_1.Operand, ctOut *vche_1.Ciphertext) {
+	eval.run(op0, func(op0 vche_1.Operand) *vche_1.Ciphertext {
+		return eval.Evaluator.MulNew(op0.(*vche_1.Ciphertext), op1)
+	}, func() { eval.Evaluator.Mul(op0, op1, ctOut) }, ctOut)
+}
+
+func (eval *repEvaluator) MulNew(op0 *vche_1.Ciphertext, op1 vche_1.Operand) (ctOut *vche_1.Ciphertext) {
+	return eval.runNew(op0, func(op0 vche_1.Operand) *vche_1.Ciphertext {
+		return eval.Evaluator.MulNew(op0.(*vche_1.Ciphertext), op1)
+	})
+}
+
+func (eval *repEvaluator) Relinearize(ct0 *vche_1.Ciphertext, ctOut *vche_1.Ciphertext) {
+	eval.run(ct0, func(ct0 vche_1.Operand) *vche_1.Ciphertext {
+		return eval.Evaluator.RelinearizeNew(ct0.(*vche_1.Ciphertext))
+	}, func() { eval.Evaluator.Relinearize(ct0, ctOut) }, ctOut)
+}
+
+func (eval *repEvaluator) RelinearizeNew(ct0 *vche_1.Ciphertext) (ctOut *vche_1.Ciphertext) {
+	return eval.runNew(ct0, func(ct0 vche_1.Operand) *vche_1.Ciphertext {
+		return eval.Evaluator.RelinearizeNew(ct0.(*vche_1.Ciphertext))
+	})
+}
+
+func (eval *repEvaluator) SwitchKeys(ct0 *vche_1.Ciphertext, switchKey *vche_1.SwitchingKey, ctOut *vche_1.Ciphertext) {
+	eval.run(ct0, func(ct0 vche_1.Operand) *vche_1.Ciphertext {
+		return eval.Evaluator.SwitchKeysNew(ct0.(*vche_1.Ciphertext), switchKey)
+	}, func() { eval.Evaluator.SwitchKeys(ct0, switchKey, ctOut) }, ctOut)
+}
+
+func (eval *repEvaluator) SwitchKeysNew(ct0 *vche_1.Ciphertext, switchKey *vche_1.SwitchingKey) (ctOut *vche_1.Ciphertext) {
+	return eval.runNew(ct0, func(ct0 vche_1.Operand) *vche_1.Ciphertext {
+		return eval.Evaluator.SwitchKeysNew(ct0.(*vche_1.Ciphertext), switchKey)
+	})
+}
+
+func (eval *repEvaluator) RotateColumns(ct0 *vche_1.Ciphertext, k int, ctOut *vche_1.Ciphertext) {
+	eval.run(ct0, func(ct0 vche_1.Operand) *vche_1.Ciphertext {
+		return eval.Evaluator.RotateColumnsNew(ct0.(*vche_1.Ciphertext), k)
+	}, func() { eval.Evaluator.RotateColumns(ct0, k, ctOut) }, ctOut)
+}
+
+func (eval *repEvaluator) RotateColumnsNew(ct0 *vche_1.Ciphertext, k int) (ctOut *vche_1.Ciphertext) {
+	return eval.runNew(ct0, func(ct0 vche_1.Operand) *vche_1.Ciphertext {
+		return eval.Evaluator.RotateColumnsNew(ct0.(*vche_1.Ciphertext), k)
+	})
+}
+
+func (eval *repEvaluator) RotateRows(ct0 *vche_1.Ciphertext, ctOut *vche_1.Ciphertext) {
+	eval.run(ct0, func(ct0 vche_1.Operand) *vche_1.Ciphertext {
+		return eval.Evaluator.RotateRowsNew(ct0.(*vche_1.Ciphertext))
+	}, func() { eval.Evaluator.RotateRows(ct0, ctOut) }, ctOut)
+}
+
+func (eval *repEvaluator) RotateRowsNew(ct0 *vche_1.Ciphertext) (ctOut *vche_1.Ciphertext) {
+	return eval.runNew(ct0, func(ct0 vche_1.Operand) *vche_1.Ciphertext {
+		return eval.Evaluator.RotateRowsNew(ct0.(*vche_1.Ciphertext))
+	})
+}
+
+func (eval *repEvaluator) InnerSum(ct0 *vche_1.Ciphertext, ctOut *vche_1.Ciphertext) {
+	innerSumNew := func(ct0 vche_1.Operand) *vche_1.Ciphertext {
+		res := vche_1.NewCiphertext(eval.params, ct0.Degree())
+		eval.Evaluator.InnerSum(ct0.(*vche_1.Ciphertext), res)
+		return res
+	}
+	eval.run(ct0, innerSumNew, func() { eval.Evaluator.InnerSum(ct0, ctOut) }, ctOut)
+}
+
+// ShallowCopy returns a copy of the evaluator that shares the state of the adversary, so that the operations of all the
+// copies are counted together.
+func (eval *repEvaluator) ShallowCopy() vche_1.Evaluator {
+	return &repEvaluator{eval.Evaluator.ShallowCopy(), eval.adversary, eval.params, eval.substitute}
+}
+
+func (eval *repEvaluator) WithKey(evk vche_1.EvaluationKey) vche_1.Evaluator {
+	return &repEvaluator{eval.Evaluator.WithKey(evk), eval.adversary, eval.params, eval.substitute}
+}
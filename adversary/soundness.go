@@ -0,0 +1,188 @@
+package adversary
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/ldsec/lattigo/v2/utils"
+	"veritas/vche/vche"
+	"veritas/vche/vche_1"
+	"veritas/vche/vche_2"
+)
+
+// Experiment configures the empirical estimation of the soundness of an encoding against a cheating strategy. Each trial
+// samples fresh secret keys and inputs x and y, evaluates 3*(x+y) with a malicious evaluator that tampers with the
+// multiplication by the scalar, and verifies the result. The encryption of a third input z is not part of the circuit:
+// it is the ciphertext that the Substitute strategy puts in place of x+y.
+type Experiment struct {
+	Strategy   Strategy
+	Trials     int     // number of trials, defaults to 100
+	Confidence float64 // confidence level of the interval of the detection probability, defaults to 0.95
+	Offset     uint64  // constant of the strategies that add one, defaults to 1
+}
+
+// experimentTarget is the index of the operation tampered with in the circuit of the trials.
+const experimentTarget = 1
+
+// Estimate is the outcome of an experiment.
+type Estimate struct {
+	Experiment
+	Detected   int     // trials in which the client rejected the result
+	Undetected int     // trials in which the client accepted an incorrect result
+	Harmless   int     // trials in which the client accepted a correct result, as the deviation had no effect
+	Rate       float64 // observed detection probability, over the trials in which the deviation had an effect
+	Lower      float64 // lower end of the confidence interval of the detection probability
+	Upper      float64 // upper end of the confidence interval of the detection probability
+	Bound      float64 // theoretical lower bound on the detection probability, one minus the cheating probability
+}
+
+// Consistent reports whether the experiment agrees with the theoretical bound, that is, whether the bound does not lie
+// above the confidence interval of the detection probability.
+func (e Estimate) Consistent() bool {
+	return e.Bound <= e.Upper
+}
+
+func (e Estimate) String() string {
+	verdict := "consistent with the bound"
+	if !e.Consistent() {
+		verdict = "below the bound"
+	}
+	return fmt.Sprintf("strategy %s: %d/%d detected (%d harmless), detection probability %.4f in [%.4f, %.4f] at %g%% confidence, theoretical bound %.4f (cheating probability %.3g): %s",
+		e.Strategy, e.Detected, e.Detected+e.Undetected, e.Harmless, e.Rate, e.Lower, e.Upper, 100*e.Confidence, e.Bound, 1-e.Bound, verdict)
+}
+
+func (exp Experiment) withDefaults() Experiment {
+	if exp.Trials <= 0 {
+		exp.Trials = 100
+	}
+	if exp.Confidence <= 0 || exp.Confidence >= 1 {
+		exp.Confidence = 0.95
+	}
+	if exp.Offset == 0 {
+		exp.Offset = 1
+	}
+	return exp
+}
+
+func (e *Estimate) record(detected, correct bool) {
+	switch {
+	case detected:
+		e.Detected++
+	case correct:
+		e.Harmless++
+	default:
+		e.Undetected++
+	}
+}
+
+func (e *Estimate) finish() {
+	n := e.Detected + e.Undetected
+	if n > 0 {
+		e.Rate = float64(e.Detected) / float64(n)
+	}
+	e.Lower, e.Upper = wilsonInterval(e.Detected, n, e.Confidence)
+}
+
+// EstimateREP estimates the probability that the client detects the strategy of exp with the REP encoding. The
+// theoretical bound follows from the probability 1/binom(NumReplications, NumDummies) of guessing the dummy set.
+func EstimateREP(params vche_1.Parameters, exp Experiment) Estimate {
+	exp = exp.withDefaults()
+	est := Estimate{Experiment: exp, Bound: 1 - vche_1.CheatingProbability(params.NumReplications, params.NumDummies)}
+	kgen := vche_1.NewKeyGenerator(params)
+	for i := 0; i < exp.Trials; i++ {
+		sk := kgen.GenSecretKey()
+		encoder := vche_1.NewEncoder(params, sk.K, sk.S, false)
+		encryptor := vche_1.NewEncryptor(params, sk)
+		encoderPlaintext := vche_1.NewEncoderPlaintext(params, sk.K)
+		evaluatorPlaintext := vche_1.NewEvaluatorPlaintext(params, sk.H)
+		input := func(datasetTag string) ([]uint64, *vche_1.Ciphertext, *vche_1.TaggedPoly) {
+			tags := vche.GetIndexTags([]byte(datasetTag), params.NSlots)
+			coeffs := vche.GetRandomCoeffs(params.NSlots, params.T())
+			return coeffs, encryptor.EncryptNew(encoder.EncodeUintNew(coeffs, tags)), encoderPlaintext.EncodeNew(tags)
+		}
+
+		x, ctX, verifX := input("x")
+		y, ctY, verifY := input("y")
+		_, ctZ, _ := input("z")
+		eval := NewREPEvaluator(vche_1.NewEvaluator(params, &vche_1.EvaluationKey{H: sk.H}), params, Config{Strategy: exp.Strategy, Target: experimentTarget, Substitute: ctZ, Offset: exp.Offset})
+		out := eval.MulScalarNew(eval.AddNew(ctX, ctY), 3)
+		verif := evaluatorPlaintext.MulScalarNew(evaluatorPlaintext.AddNew(verifX, verifY), 3)
+		est.record(verify(func() []uint64 {
+			return encoder.DecodeUintNew(vche_1.NewDecryptor(params, sk).DecryptNew(out), verif)
+		}, experimentResult(x, y, params.T())))
+	}
+	est.finish()
+	return est
+}
+
+// EstimatePE estimates the probability that the client detects the strategy of exp with the PE encoding. The
+// theoretical bound follows from the probability 1/(T-1) of guessing the secret alpha, as the circuit of the trials is
+// linear.
+func EstimatePE(params vche_2.Parameters, exp Experiment) Estimate {
+	exp = exp.withDefaults()
+	est := Estimate{Experiment: exp, Bound: 1 - 1/float64(params.T()-1)}
+	kgen := vche_2.NewKeyGenerator(params)
+	for i := 0; i < exp.Trials; i++ {
+		sk := kgen.GenSecretKey()
+		encoder := vche_2.NewEncoder(params, sk.K, sk.Alpha, false)
+		encryptor := vche_2.NewEncryptor(params, sk)
+		encoderPlaintext := vche_2.NewEncoderPlaintext(params, sk.K)
+		evaluatorPlaintext := vche_2.NewEvaluatorPlaintext(params)
+		input := func(datasetTag string) ([]uint64, *vche_2.Ciphertext, *vche_2.Poly) {
+			tags := vche.GetIndexTags([]byte(datasetTag), params.NSlots)
+			coeffs := vche.GetRandomCoeffs(params.NSlots, params.T())
+			return coeffs, encryptor.EncryptNew(encoder.EncodeUintNew(coeffs, tags)), encoderPlaintext.EncodeNew(tags)
+		}
+
+		x, ctX, verifX := input("x")
+		y, ctY, verifY := input("y")
+		_, ctZ, _ := input("z")
+		eval := NewPEEvaluator(vche_2.NewEvaluator(params, &vche_2.EvaluationKey{}), params, Config{Strategy: exp.Strategy, Target: experimentTarget, Substitute: ctZ, Offset: exp.Offset})
+		out := eval.MulScalarNew(eval.AddNew(ctX, ctY), 3)
+		verif := evaluatorPlaintext.MulScalarNew(evaluatorPlaintext.AddNew(verifX, verifY), 3)
+		est.record(verify(func() []uint64 {
+			return encoder.DecodeUintNew(vche_2.NewDecryptor(params, sk).DecryptNew(out), verif)
+		}, experimentResult(x, y, params.T())))
+	}
+	est.finish()
+	return est
+}
+
+// experimentResult returns 3*(x+y) mod t.
+func experimentResult(x, y []uint64, t uint64) []uint64 {
+	res := make([]uint64, len(x))
+	for i := range res {
+		res[i] = 3 * ((x[i] + y[i]) % t) % t
+	}
+	return res
+}
+
+// verify decodes a result, and reports whether the decoding detected a deviation or whether the result is correct. Only
+// verification failures count as detections: any other panic, e.g., a runtime error, is a crash and is propagated.
+func verify(decode func() []uint64, expected []uint64) (detected, correct bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			if err, ok := r.(error); !ok || !errors.Is(err, vche.ErrVerification) {
+				panic(r)
+			}
+			detected = true
+		}
+	}()
+	res := decode()
+	return false, len(res) >= len(expected) && utils.EqualSliceUint64(res[:len(expected)], expected)
+}
+
+// wilsonInterval returns the Wilson score interval of a binomial proportion with the given number of successes out of n
+// trials. Unlike the normal approximation, it remains meaningful when all or none of the trials succeed.
+func wilsonInterval(successes, n int, confidence float64) (lower, upper float64) {
+	if n == 0 {
+		return 0, 1
+	}
+	z := math.Sqrt2 * math.Erfinv(confidence)
+	p := float64(successes) / float64(n)
+	z2n := z * z / float64(n)
+	center := (p + z2n/2) / (1 + z2n)
+	halfWidth := z * math.Sqrt(p*(1-p)/float64(n)+z2n/float64(4*n)) / (1 + z2n)
+	return math.Max(0, center-halfWidth), math.Min(1, center+halfWidth)
+}
@@ -0,0 +1,66 @@
+package adversary
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"veritas/vche/vche"
+	"veritas/vche/vche_1"
+	"veritas/vche/vche_2"
+)
+
+func TestEstimate(t *testing.T) {
+	trials := 60
+	if testing.Short() {
+		trials = 20
+	}
+
+	t.Run("REP/guess-dummies", func(t *testing.T) {
+		// Guessing the dummy set is the best strategy, so the detection probability matches the bound of 1/2
+		literal := vche_1.DefaultParams[0]
+		literal.NumReplications, literal.NumDummies = 2, 1
+		params, err := vche_1.NewParametersFromLiteral(literal)
+		require.NoError(t, err)
+		est := EstimateREP(params, Experiment{Strategy: GuessDummies, Trials: trials, Confidence: 0.999})
+		t.Log(est)
+		require.Equal(t, 0.5, est.Bound)
+		require.Equal(t, trials, est.Detected+est.Undetected)
+		require.True(t, est.Consistent())
+		require.LessOrEqual(t, est.Lower, est.Bound)
+	})
+
+	t.Run("PE/offset", func(t *testing.T) {
+		params, err := vche_2.NewParametersFromLiteral(vche_2.DefaultParams[0])
+		require.NoError(t, err)
+		est := EstimatePE(params, Experiment{Strategy: Offset, Trials: 5})
+		t.Log(est)
+		require.Equal(t, 5, est.Detected)
+		require.Equal(t, 1.0, est.Rate)
+		require.True(t, est.Consistent())
+	})
+
+	t.Run("Verify", func(t *testing.T) {
+		expected := []uint64{1, 2}
+		detected, correct := verify(func() []uint64 { return []uint64{1, 2, 3} }, expected)
+		require.False(t, detected)
+		require.True(t, correct)
+		detected, _ = verify(func() []uint64 { panic(fmt.Errorf("%w due to mismatch", vche.ErrVerification)) }, expected)
+		require.True(t, detected)
+
+		// Crashes are not detections
+		require.Panics(t, func() { verify(func() []uint64 { return expected[:len(expected)+1] }, expected) })
+		require.Panics(t, func() { verify(func() []uint64 { panic("unsupported operand") }, expected) })
+	})
+
+	t.Run("Wilson", func(t *testing.T) {
+		lower, upper := wilsonInterval(10, 10, 0.95)
+		require.InDelta(t, 0.7225, lower, 1e-4)
+		require.Equal(t, 1.0, upper)
+		lower, upper = wilsonInterval(5, 10, 0.95)
+		require.InDelta(t, 0.5-lower, upper-0.5, 1e-9)
+		lower, upper = wilsonInterval(0, 0, 0.95)
+		require.Equal(t, 0.0, lower)
+		require.Equal(t, 1.0, upper)
+	})
+}
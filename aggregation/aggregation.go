@@ -0,0 +1,385 @@
+// Package aggregation provides verified secure aggregation, as in the FedAvg example: clients submit encrypted vectors
+// of arbitrary length, the server sums them, possibly weighted, and the decryptor verifies the result. It works with any
+// encoding through the generic interfaces of vche.
+//
+// The server sums the submissions along a fixed binary tree over the client identifiers, in which the leaves of the
+// clients that dropped out are skipped. The verifier precomputes the same tree on the verification state of every
+// client, and only recomputes the paths of the dropped clients to verify a partial aggregate. Since the encodings
+// authenticate the order of the operations (e.g., the tags of REP), both trees must have the same shape.
+//
+// The verification authenticates the set of clients that an aggregate claims to sum, but not why the other clients are
+// missing: by default, the server decides which clients dropped out, and could discard honest submissions. The verifier
+// bounds this with Config.MinClients, or rules it out with Verifier.WithClients when it learns the live clients from
+// another channel, e.g., the acknowledgments of the clients.
+package aggregation
+
+import (
+	"fmt"
+	"sort"
+
+	"veritas/vche/vche"
+)
+
+// Config is the configuration of an aggregation shared by the clients, the server and the verifier.
+type Config struct {
+	Round      []byte   // identifier of the aggregation, so that the tags of distinct rounds do not collide
+	NumClients int      // clients are identified by 0, ..., NumClients-1
+	Weights    []uint64 // weights of the clients, or nil for a plain sum
+	MinClients int      // minimum number of clients of an aggregate accepted by the verifier, or 0 for any
+}
+
+func (cfg Config) check() {
+	if cfg.NumClients <= 0 {
+		panic(fmt.Errorf("the number of clients should be positive, got %d", cfg.NumClients))
+	}
+	if cfg.Weights != nil && len(cfg.Weights) != cfg.NumClients {
+		panic(fmt.Errorf("got %d weights for %d clients", len(cfg.Weights), cfg.NumClients))
+	}
+	if cfg.MinClients < 0 || cfg.MinClients > cfg.NumClients {
+		panic(fmt.Errorf("invalid minimum number of clients %d, should be in [0, %d]", cfg.MinClients, cfg.NumClients))
+	}
+}
+
+func (cfg Config) checkClient(client int) {
+	if client < 0 || client >= cfg.NumClients {
+		panic(fmt.Errorf("invalid client %d, should be in [0, %d)", client, cfg.NumClients))
+	}
+}
+
+// DatasetTag returns the dataset tag of the vector of the given client.
+func (cfg Config) DatasetTag(client int) []byte {
+	return []byte(fmt.Sprintf("%s/client%d", cfg.Round, client))
+}
+
+// TotalWeight returns the sum of the weights of the given clients, or their number for a plain sum.
+func (cfg Config) TotalWeight(clients []int) uint64 {
+	if cfg.Weights == nil {
+		return uint64(len(clients))
+	}
+	total := uint64(0)
+	for _, client := range clients {
+		total += cfg.Weights[client]
+	}
+	return total
+}
+
+// Submission is the encrypted vector of a client, in chunks of at most NSlots values.
+type Submission struct {
+	Client int
+	Len    int
+	Chunks []interface{}
+}
+
+// Aggregate is the (weighted) sum of the submissions of Clients, in increasing order.
+type Aggregate struct {
+	Clients []int
+	Len     int
+	Chunks  []interface{}
+}
+
+// Client encrypts the vector of a client.
+type Client interface {
+	SubmitUint(values []uint64) *Submission
+	SubmitInt(values []int64) *Submission
+}
+
+type client struct {
+	params    vche.Parameters
+	cfg       Config
+	id        int
+	encoder   vche.GenericEncoder
+	encryptor vche.GenericEncryptor
+}
+
+func NewClient(params vche.Parameters, cfg Config, id int, encoder vche.GenericEncoder, encryptor vche.GenericEncryptor) Client {
+	cfg.check()
+	cfg.checkClient(id)
+	return &client{params, cfg, id, encoder, encryptor}
+}
+
+func (c *client) SubmitUint(values []uint64) *Submission {
+	tags := vche.GetChunkedIndexTags(c.cfg.DatasetTag(c.id), len(values), c.params.NSlots)
+	sub := &Submission{c.id, len(values), make([]interface{}, len(tags))}
+	for i, chunk := range vche.ChunkUint(values, c.params.NSlots) {
+		sub.Chunks[i] = c.encryptor.EncryptNew(c.encoder.EncodeUintNew(chunk, tags[i]))
+	}
+	return sub
+}
+
+func (c *client) SubmitInt(values []int64) *Submission {
+	tags := vche.GetChunkedIndexTags(c.cfg.DatasetTag(c.id), len(values), c.params.NSlots)
+	sub := &Submission{c.id, len(values), make([]interface{}, len(tags))}
+	for i, chunk := range vche.ChunkInt(values, c.params.NSlots) {
+		sub.Chunks[i] = c.encryptor.EncryptNew(c.encoder.EncodeIntNew(chunk, tags[i]))
+	}
+	return sub
+}
+
+// Aggregator sums the submissions of the clients on the server.
+type Aggregator interface {
+	Sum(subs []*Submission) *Aggregate
+}
+
+type aggregator struct {
+	cfg  Config
+	eval vche.GenericEvaluator
+}
+
+func NewAggregator(cfg Config, eval vche.GenericEvaluator) Aggregator {
+	cfg.check()
+	return &aggregator{cfg, eval}
+}
+
+// Sum returns the (weighted) sum of the submissions, in any order. The clients without a submission are considered
+// dropped out.
+func (agg *aggregator) Sum(subs []*Submission) *Aggregate {
+	if len(subs) == 0 {
+		panic(fmt.Errorf("no submission to aggregate"))
+	}
+	leaves := make([][]interface{}, agg.cfg.NumClients)
+	for _, sub := range subs {
+		agg.cfg.checkClient(sub.Client)
+		if leaves[sub.Client] != nil {
+			panic(fmt.Errorf("duplicate submission of client %d", sub.Client))
+		}
+		if sub.Len != subs[0].Len || len(sub.Chunks) != len(subs[0].Chunks) {
+			panic(fmt.Errorf("submissions should have the same length, got %d and %d", subs[0].Len, sub.Len))
+		}
+		leaves[sub.Client] = weigh(agg.eval, sub.Chunks, agg.cfg.Weights, sub.Client)
+	}
+	return &Aggregate{participants(leaves), subs[0].Len, newTree(agg.eval, leaves).root()}
+}
+
+// Verifier verifies and decodes the aggregates of a round on the decryptor. The decoders panic with an error wrapping
+// vche.ErrVerification if the aggregate does not sum the submissions of the clients it claims, if it has fewer than
+// Config.MinClients clients, or if its clients are not the ones expected by WithClients.
+type Verifier interface {
+	// Verification returns the verification state of the aggregate of the given clients.
+	Verification(clients []int) []interface{}
+	// WithClients returns a verifier that shares the precomputations of v, and only accepts the aggregates of exactly the
+	// given clients, or of any clients if nil.
+	WithClients(clients []int) Verifier
+	DecodeUintNew(agg *Aggregate) []uint64
+	DecodeIntNew(agg *Aggregate) []int64
+	// DecodeAverageNew returns the weighted average of the vectors submitted with SubmitInt by the clients of agg. The
+	// weighted sums are decoded as signed integers, so they must lie in (-T/2, T/2].
+	DecodeAverageNew(agg *Aggregate) []float64
+	// DecodeAverageUintNew returns the weighted average of the vectors submitted with SubmitUint by the clients of agg.
+	// The weighted sums are decoded as unsigned integers, so they must lie in [0, T).
+	DecodeAverageUintNew(agg *Aggregate) []float64
+}
+
+type verifier struct {
+	params    vche.Parameters
+	cfg       Config
+	length    int
+	eval      vche.GenericEvaluator
+	encoder   vche.GenericEncoder
+	decryptor vche.GenericDecryptor
+	full      *tree
+	expected  []int
+}
+
+// NewVerifier returns the verifier of the aggregates of vectors of the given length, which precomputes the verification
+// state of the aggregate of all the clients.
+func NewVerifier(params vche.Parameters, cfg Config, length int, encoderPlaintext vche.GenericEncoderPlaintext, evaluatorPlaintext vche.GenericEvaluator,
+	encoder vche.GenericEncoder, decryptor vche.GenericDecryptor) Verifier {
+	cfg.check()
+	leaves := make([][]interface{}, cfg.NumClients)
+	for i := range leaves {
+		tags := vche.GetChunkedIndexTags(cfg.DatasetTag(i), length, params.NSlots)
+		leaves[i] = make([]interface{}, len(tags))
+		for j := range tags {
+			leaves[i][j] = encoderPlaintext.EncodeNew(tags[j])
+		}
+		leaves[i] = weigh(evaluatorPlaintext, leaves[i], cfg.Weights, i)
+	}
+	return &verifier{params, cfg, length, evaluatorPlaintext, encoder, decryptor, newTree(evaluatorPlaintext, leaves), nil}
+}
+
+func (v *verifier) Verification(clients []int) []interface{} {
+	present := make([]bool, v.cfg.NumClients)
+	for _, client := range clients {
+		v.cfg.checkClient(client)
+		if present[client] {
+			panic(fmt.Errorf("duplicate client %d", client))
+		}
+		present[client] = true
+	}
+	var dropped []int
+	for client, ok := range present {
+		if !ok {
+			dropped = append(dropped, client)
+		}
+	}
+	if len(dropped) == v.cfg.NumClients {
+		panic(fmt.Errorf("no client to aggregate"))
+	}
+	return v.full.without(v.eval, dropped).root()
+}
+
+func (v *verifier) WithClients(clients []int) Verifier {
+	res := *v
+	res.expected = nil
+	if clients != nil {
+		res.expected = append([]int{}, clients...)
+		sort.Ints(res.expected)
+	}
+	return &res
+}
+
+// checkClients checks the clients of an aggregate against the minimum number and the expected clients.
+func (v *verifier) checkClients(clients []int) {
+	if len(clients) < v.cfg.MinClients {
+		panic(fmt.Errorf("%w due to too many dropped clients: got %d clients, expected at least %d", vche.ErrVerification, len(clients), v.cfg.MinClients))
+	}
+	if v.expected == nil {
+		return
+	}
+	sorted := append([]int{}, clients...)
+	sort.Ints(sorted)
+	if len(sorted) != len(v.expected) {
+		panic(fmt.Errorf("%w due to mismatched clients: got %v, expected %v", vche.ErrVerification, sorted, v.expected))
+	}
+	for i := range sorted {
+		if sorted[i] != v.expected[i] {
+			panic(fmt.Errorf("%w due to mismatched clients: got %v, expected %v", vche.ErrVerification, sorted, v.expected))
+		}
+	}
+}
+
+func (v *verifier) decrypt(agg *Aggregate) (pts, verifs []interface{}) {
+	if agg.Len != v.length || len(agg.Chunks) != vche.NumChunks(v.length, v.params.NSlots) {
+		panic(fmt.Errorf("aggregate should have length %d, got %d", v.length, agg.Len))
+	}
+	v.checkClients(agg.Clients)
+	verifs = v.Verification(agg.Clients)
+	pts = make([]interface{}, len(agg.Chunks))
+	for i := range agg.Chunks {
+		pts[i] = v.decryptor.DecryptNew(agg.Chunks[i])
+	}
+	return pts, verifs
+}
+
+func (v *verifier) DecodeUintNew(agg *Aggregate) []uint64 {
+	pts, verifs := v.decrypt(agg)
+	values := make([]uint64, v.length)
+	for i, chunk := range vche.ChunkUint(values, v.params.NSlots) {
+		v.encoder.DecodeUint(pts[i], verifs[i], chunk)
+	}
+	return values
+}
+
+func (v *verifier) DecodeIntNew(agg *Aggregate) []int64 {
+	pts, verifs := v.decrypt(agg)
+	values := make([]int64, v.length)
+	for i, chunk := range vche.ChunkInt(values, v.params.NSlots) {
+		v.encoder.DecodeInt(pts[i], verifs[i], chunk)
+	}
+	return values
+}
+
+func (v *verifier) DecodeAverageNew(agg *Aggregate) []float64 {
+	sums := v.DecodeIntNew(agg)
+	total := float64(v.cfg.TotalWeight(agg.Clients))
+	res := make([]float64, len(sums))
+	for i := range sums {
+		res[i] = float64(sums[i]) / total
+	}
+	return res
+}
+
+func (v *verifier) DecodeAverageUintNew(agg *Aggregate) []float64 {
+	sums := v.DecodeUintNew(agg)
+	total := float64(v.cfg.TotalWeight(agg.Clients))
+	res := make([]float64, len(sums))
+	for i := range sums {
+		res[i] = float64(sums[i]) / total
+	}
+	return res
+}
+
+// weigh multiplies the chunks of a client by its weight, if any.
+func weigh(eval vche.GenericEvaluator, chunks []interface{}, weights []uint64, client int) []interface{} {
+	if weights == nil {
+		return chunks
+	}
+	res := make([]interface{}, len(chunks))
+	for i := range chunks {
+		res[i] = eval.MulScalarNew(chunks[i], weights[client])
+	}
+	return res
+}
+
+// participants returns the indices of the non-nil leaves.
+func participants(leaves [][]interface{}) []int {
+	var clients []int
+	for i, leaf := range leaves {
+		if leaf != nil {
+			clients = append(clients, i)
+		}
+	}
+	return clients
+}
+
+// tree is a binary tree of sums of chunked vectors: levels[0] holds the leaves and levels[k+1][i] the sum of
+// levels[k][2i] and levels[k][2i+1]. A nil node is the sum of no leaf.
+type tree struct {
+	levels [][][]interface{}
+}
+
+func newTree(eval vche.GenericEvaluator, leaves [][]interface{}) *tree {
+	t := &tree{[][][]interface{}{leaves}}
+	for len(t.levels[len(t.levels)-1]) > 1 {
+		prev := t.levels[len(t.levels)-1]
+		level := make([][]interface{}, (len(prev)+1)/2)
+		for i := range level {
+			level[i] = t.node(eval, len(t.levels)-1, i)
+		}
+		t.levels = append(t.levels, level)
+	}
+	return t
+}
+
+// node returns the sum of the children of the i-th node of the level above level.
+func (t *tree) node(eval vche.GenericEvaluator, level, i int) []interface{} {
+	left := t.levels[level][2*i]
+	if 2*i+1 == len(t.levels[level]) || t.levels[level][2*i+1] == nil {
+		return left
+	}
+	right := t.levels[level][2*i+1]
+	if left == nil {
+		return right
+	}
+	sum := make([]interface{}, len(left))
+	for j := range left {
+		sum[j] = eval.AddNew(left[j], right[j])
+	}
+	return sum
+}
+
+// without returns the tree without the given leaves, which shares the nodes of t that do not depend on them.
+func (t *tree) without(eval vche.GenericEvaluator, leaves []int) *tree {
+	res := &tree{make([][][]interface{}, len(t.levels))}
+	for k := range t.levels {
+		res.levels[k] = append([][]interface{}{}, t.levels[k]...)
+	}
+	dirty := map[int]bool{}
+	for _, i := range leaves {
+		res.levels[0][i] = nil
+		dirty[i/2] = true
+	}
+	for k := 1; k < len(res.levels); k++ {
+		parents := map[int]bool{}
+		for i := range dirty {
+			res.levels[k][i] = res.node(eval, k-1, i)
+			parents[i/2] = true
+		}
+		dirty = parents
+	}
+	return res
+}
+
+func (t *tree) root() []interface{} {
+	return t.levels[len(t.levels)-1][0]
+}
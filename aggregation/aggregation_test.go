@@ -0,0 +1,181 @@
+package aggregation
+
+import (
+	"testing"
+
+	"github.com/ldsec/lattigo/v2/ring"
+	"github.com/ldsec/lattigo/v2/rlwe"
+	"github.com/stretchr/testify/require"
+	"veritas/vche/vche"
+	"veritas/vche/vche_1"
+	"veritas/vche/vche_2"
+)
+
+// testScheme holds the generic components of an encoding.
+type testScheme struct {
+	params             vche.Parameters
+	encoder            vche.GenericEncoder
+	encryptor          vche.GenericEncryptor
+	decryptor          vche.GenericDecryptor
+	evaluator          vche.GenericEvaluator
+	encoderPlaintext   vche.GenericEncoderPlaintext
+	evaluatorPlaintext vche.GenericEvaluator
+}
+
+func newREPScheme(t *testing.T) testScheme {
+	params, err := vche_1.NewParametersFromLiteral(vche_1.DefaultParams[0])
+	require.NoError(t, err)
+	kgen := vche_1.NewKeyGenerator(params)
+	sk, pk := kgen.GenKeyPair()
+	return testScheme{
+		params,
+		vche_1.NewGenericEncoder(params, sk.K, sk.S, false),
+		vche_1.NewGenericEncryptor(params, pk),
+		vche_1.NewGenericDecryptor(params, sk),
+		vche_1.NewGenericEvaluator(params, &vche_1.EvaluationKey{EvaluationKey: rlwe.EvaluationKey{}, H: sk.H}),
+		vche_1.NewGenericEncoderPlaintext(params, sk.K),
+		vche_1.NewGenericEvaluatorPlaintext(params, sk.H),
+	}
+}
+
+func newPEScheme(t *testing.T) testScheme {
+	params, err := vche_2.NewParametersFromLiteral(vche_2.DefaultParams[0])
+	require.NoError(t, err)
+	kgen := vche_2.NewKeyGenerator(params)
+	sk, pk := kgen.GenKeyPair()
+	return testScheme{
+		params,
+		vche_2.NewGenericEncoder(params, sk.K, sk.Alpha, false),
+		vche_2.NewGenericEncryptor(params, pk),
+		vche_2.NewGenericDecryptor(params, sk),
+		vche_2.NewGenericEvaluator(params, &vche_2.EvaluationKey{}),
+		vche_2.NewGenericEncoderPlaintext(params, sk.K),
+		vche_2.NewGenericEvaluatorPlaintext(params),
+	}
+}
+
+func TestAggregation(t *testing.T) {
+	for _, encoding := range []struct {
+		name      string
+		newScheme func(t *testing.T) testScheme
+	}{
+		{"REP", newREPScheme},
+		{"PE", newPEScheme},
+	} {
+		s := encoding.newScheme(t)
+		// Vectors span two chunks, the last of which is partial
+		length := s.params.NSlots + 3
+
+		for _, weights := range [][]uint64{nil, {1, 2, 3, 4, 5}} {
+			cfg := Config{Round: []byte("round1"), NumClients: 5, Weights: weights}
+			values := make([][]int64, cfg.NumClients)
+			subs := make([]*Submission, cfg.NumClients)
+			for i := range values {
+				values[i] = make([]int64, length)
+				for j := range values[i] {
+					values[i][j] = int64((i+1)*(j%7)) - 10
+				}
+				subs[i] = NewClient(s.params, cfg, i, s.encoder, s.encryptor).SubmitInt(values[i])
+			}
+			aggregator := NewAggregator(cfg, s.evaluator)
+			verifier := NewVerifier(s.params, cfg, length, s.encoderPlaintext, s.evaluatorPlaintext, s.encoder, s.decryptor)
+
+			for _, clients := range [][]int{{0, 1, 2, 3, 4}, {0, 2, 3}, {4}, {1, 2, 3, 4}} {
+				t.Run(encoding.name, func(t *testing.T) {
+					var present []*Submission
+					want := make([]int64, length)
+					for _, i := range clients {
+						present = append(present, subs[i])
+						w := int64(1)
+						if weights != nil {
+							w = int64(weights[i])
+						}
+						for j := range want {
+							want[j] += w * values[i][j]
+						}
+					}
+					agg := aggregator.Sum(present)
+					require.Equal(t, clients, agg.Clients)
+					require.Equal(t, want, verifier.DecodeIntNew(agg))
+
+					avg := verifier.DecodeAverageNew(agg)
+					require.InDelta(t, float64(want[1])/float64(cfg.TotalWeight(clients)), avg[1], 1e-9)
+
+					// Claiming a dropped client, or dropping a present one, is detected
+					if len(clients) < cfg.NumClients {
+						forged := *agg
+						forged.Clients = []int{0, 1, 2, 3, 4}
+						require.True(t, vche.Rejects(func() { verifier.DecodeIntNew(&forged) }))
+					}
+					if len(clients) > 1 {
+						forged := *agg
+						forged.Clients = clients[1:]
+						require.True(t, vche.Rejects(func() { verifier.DecodeIntNew(&forged) }))
+					}
+
+					// A verifier that knows the live clients rejects an aggregate that drops one of them
+					require.Equal(t, want, verifier.WithClients(clients).DecodeIntNew(agg))
+					if len(clients) > 1 {
+						require.True(t, vche.Rejects(func() { verifier.WithClients(clients).DecodeIntNew(aggregator.Sum(present[1:])) }))
+					}
+				})
+			}
+
+			require.Panics(t, func() { aggregator.Sum([]*Submission{subs[0], subs[0]}) })
+			require.Panics(t, func() { aggregator.Sum(nil) })
+		}
+
+		t.Run(encoding.name+"/MinClients", func(t *testing.T) {
+			cfg := Config{Round: []byte("round2"), NumClients: 3, MinClients: 2}
+			subs := make([]*Submission, cfg.NumClients)
+			for i := range subs {
+				subs[i] = NewClient(s.params, cfg, i, s.encoder, s.encryptor).SubmitUint([]uint64{uint64(i)})
+			}
+			aggregator := NewAggregator(cfg, s.evaluator)
+			verifier := NewVerifier(s.params, cfg, 1, s.encoderPlaintext, s.evaluatorPlaintext, s.encoder, s.decryptor)
+			require.Equal(t, []uint64{3}, verifier.DecodeUintNew(aggregator.Sum(subs[1:])))
+			require.True(t, vche.Rejects(func() { verifier.DecodeUintNew(aggregator.Sum(subs[2:])) }))
+			require.Panics(t, func() { NewAggregator(Config{NumClients: 3, MinClients: 4}, s.evaluator) })
+		})
+	}
+}
+
+func TestAverageUint(t *testing.T) {
+	s := newREPScheme(t)
+	cfg := Config{Round: []byte("round1"), NumClients: 2}
+	// The sum of the values exceeds T/2, so it is only decoded correctly as an unsigned integer
+	v := s.params.T() / 3
+	values := []uint64{v, v + 2}
+	subs := make([]*Submission, cfg.NumClients)
+	for i := range subs {
+		subs[i] = NewClient(s.params, cfg, i, s.encoder, s.encryptor).SubmitUint(values)
+	}
+	agg := NewAggregator(cfg, s.evaluator).Sum(subs)
+	verifier := NewVerifier(s.params, cfg, len(values), s.encoderPlaintext, s.evaluatorPlaintext, s.encoder, s.decryptor)
+	require.Equal(t, []float64{float64(v), float64(v + 2)}, verifier.DecodeAverageUintNew(agg))
+	require.Negative(t, verifier.DecodeAverageNew(agg)[0])
+}
+
+func TestTreeWithout(t *testing.T) {
+	params, err := vche.NewParametersFromLiteral(vche.ParametersLiteral{ParametersLiteral: vche_1.DefaultParams[0].ParametersLiteral, NumReplications: 1, NumDistinctPRFKeys: 1})
+	require.NoError(t, err)
+	eval := vche.NewGenericEvaluatorPlaintext(params)
+	leaves := make([][]interface{}, 7)
+	for i := range leaves {
+		p := params.RingT().NewPoly()
+		p.Coeffs[0][0] = uint64(1) << uint(i)
+		leaves[i] = []interface{}{p}
+	}
+	full := newTree(eval, leaves)
+
+	for _, dropped := range [][]int{{}, {6}, {0, 3}, {1, 2, 4, 5, 6}} {
+		partial := append([][]interface{}{}, leaves...)
+		want := uint64(1)<<7 - 1
+		for _, i := range dropped {
+			partial[i] = nil
+			want -= uint64(1) << uint(i)
+		}
+		require.Equal(t, newTree(eval, partial).root(), full.without(eval, dropped).root())
+		require.Equal(t, want, full.without(eval, dropped).root()[0].(*ring.Poly).Coeffs[0][0])
+	}
+}
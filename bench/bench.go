@@ -0,0 +1,320 @@
+// Package bench runs the encoder, evaluator and protocol benchmarks of plain BFV, REP, PE and their closed-form PRF
+// variants for selected parameter
+// sets, and records their timings, allocations, ciphertext sizes and communication volumes. It replaces running
+// `go test -bench` and post-processing its output with benchstat: the results are written directly in CSV, with the
+// columns that the plotting notebooks read, or in JSON.
+package bench
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"runtime"
+	"time"
+
+	"veritas/vche/vche"
+	"veritas/vche/vche_2"
+)
+
+// Config selects the benchmarks to run.
+type Config struct {
+	Encodings []Encoding     // encodings to benchmark, defaults to Encodings
+	Params    []int          // indices of the default parameters of each encoding to benchmark, defaults to {0}
+	Runs      int            // number of measurements of each benchmark, defaults to 1
+	MinTime   time.Duration  // minimum duration of a measurement, defaults to 1s
+	Filter    *regexp.Regexp // if not nil, only the benchmarks whose name matches Filter are run
+	Log       io.Writer      // if not nil, each result is written to Log in the format of go test as soon as it is measured
+}
+
+// Result is the outcome of a benchmark, averaged over all its runs.
+type Result struct {
+	Name            string   `json:"name"`
+	Encoding        Encoding `json:"encoding"`
+	Op              string   `json:"op"`
+	LogN            int      `json:"logN"`
+	LogQP           int      `json:"logQP"`
+	Slots           int      `json:"slots"`
+	Runs            int      `json:"runs"`
+	Iterations      int      `json:"iterations"` // iterations of the last run
+	NsPerOp         float64  `json:"nsPerOp"`
+	Spread          float64  `json:"spread"` // largest relative deviation of a run from NsPerOp, as the ± of benchstat
+	AllocsPerOp     int64    `json:"allocsPerOp"`
+	BytesPerOp      int64    `json:"bytesPerOp"`
+	CiphertextBytes int      `json:"ciphertextBytes"` // size of the serialized ciphertext produced by the operation, if any
+	CommBytes       int      `json:"commBytes"`       // bytes sent between the client and the server by the operation
+}
+
+// benchmark is an operation to measure. setup prepares its inputs, and returns the operation with the sizes of its
+// output ciphertext and of the data it sends over the network, or a nil operation if the encoding does not support it.
+type benchmark struct {
+	op         string
+	verifiable bool // only run for encodings that verify the results
+	setup      func(s *suite) (run func(), ciphertextBytes, commBytes int)
+}
+
+var benchmarks = []benchmark{
+	{"KeyGen", false, func(s *suite) (func(), int, int) {
+		// The evaluation key is sent to the server once
+		return func() { newSuite(s.encoding, s.params) }, 0, vche.Size(s.evk)
+	}},
+	{"Encoder/EncodeUint", false, func(s *suite) (func(), int, int) {
+		coeffs := vche.GetRandomCoeffs(s.params.NSlots, s.params.T())
+		tags := vche.GetIndexTags([]byte("x"), s.params.NSlots)
+		pt := s.encoder.EncodeUintNew(coeffs, tags)
+		return func() { s.encoder.EncodeUint(coeffs, tags, pt) }, 0, 0
+	}},
+	{"Encoder/DecodeUint", false, func(s *suite) (func(), int, int) {
+		_, ct, verif := s.input("x")
+		pt := s.decryptor.DecryptNew(ct)
+		verif = s.finalize(verif)
+		coeffs := make([]uint64, s.params.NSlots)
+		return func() { s.encoder.DecodeUint(pt, verif, coeffs) }, 0, 0
+	}},
+	{"EncoderPlaintext/Encode", true, func(s *suite) (func(), int, int) {
+		tags := vche.GetIndexTags([]byte("x"), s.params.NSlots)
+		return func() { s.encoderPlaintext.EncodeNew(tags) }, 0, 0
+	}},
+	{"Encrypt", false, func(s *suite) (func(), int, int) {
+		pt, ct, _ := s.input("x")
+		// The client uploads the ciphertext
+		return func() { s.encryptor.Encrypt(pt, ct) }, vche.Size(ct), vche.Size(ct)
+	}},
+	{"Decrypt", false, func(s *suite) (func(), int, int) {
+		_, ct, _ := s.input("x")
+		pt := s.decryptor.DecryptNew(ct)
+		return func() { s.decryptor.Decrypt(ct, pt) }, 0, 0
+	}},
+	{"Evaluator/Add", false, evaluatorBenchmark(func(eval vche.GenericEvaluator, x, y interface{}) interface{} {
+		return eval.AddNew(x, y)
+	})},
+	{"Evaluator/MulScalar", false, evaluatorBenchmark(func(eval vche.GenericEvaluator, x, _ interface{}) interface{} {
+		return eval.MulScalarNew(x, 5)
+	})},
+	{"Evaluator/Mul", false, evaluatorBenchmark(func(eval vche.GenericEvaluator, x, y interface{}) interface{} {
+		return eval.MulNew(x, y)
+	})},
+	{"Evaluator/Relinearize", false, func(s *suite) (func(), int, int) {
+		_, x, _ := s.input("x")
+		_, y, _ := s.input("y")
+		xy := s.evaluator.MulNew(x, y)
+		out := s.evaluator.RelinearizeNew(xy)
+		return func() { s.evaluator.Relinearize(xy, out) }, vche.Size(out), 0
+	}},
+	{"Evaluator/RotateColumns", false, evaluatorBenchmark(func(eval vche.GenericEvaluator, x, _ interface{}) interface{} {
+		return eval.RotateColumnsNew(x, rotation)
+	})},
+	{"Evaluator/RotateRows", false, evaluatorBenchmark(func(eval vche.GenericEvaluator, x, _ interface{}) interface{} {
+		return eval.RotateRowsNew(x)
+	})},
+	{"EvaluatorPlaintext/Add", true, evaluatorPlaintextBenchmark(func(eval vche.GenericEvaluator, x, y interface{}) interface{} {
+		return eval.AddNew(x, y)
+	})},
+	{"EvaluatorPlaintext/Mul", true, evaluatorPlaintextBenchmark(func(eval vche.GenericEvaluator, x, y interface{}) interface{} {
+		return eval.MulNew(x, y)
+	})},
+	{"EvaluatorPlaintext/RotateColumns", true, evaluatorPlaintextBenchmark(func(eval vche.GenericEvaluator, x, _ interface{}) interface{} {
+		return eval.RotateColumnsNew(x, rotation)
+	})},
+	{"Protocol/Circuit", false, func(s *suite) (func(), int, int) {
+		// The server computes x*y + rot(x) and the client verifies and decodes the result. The inputs are encrypted, and
+		// their verification states encoded, beforehand, as measured by Encrypt and EncoderPlaintext/Encode
+		c := vche.NewCircuit()
+		x, y := c.Input(), c.Input()
+		c.Output(c.Add(c.Relinearize(c.Mul(x, y)), c.RotateColumns(x, rotation)))
+		_, ctX, verifX := s.input("x")
+		_, ctY, verifY := s.input("y")
+		var out interface{}
+		run := func() {
+			out = c.Eval(s.evaluator, ctX, ctY)[0]
+			var verif interface{}
+			if s.verifiable() {
+				verif = s.finalize(c.Eval(s.evaluatorPlaintext, verifX, verifY)[0])
+			}
+			s.encoder.DecodeUintNew(s.decryptor.DecryptNew(out), verif)
+		}
+		run()
+		return run, vche.Size(out), vche.Size(ctX) + vche.Size(ctY) + vche.Size(out)
+	}},
+	{"Protocol/Polynomial", true, func(s *suite) (func(), int, int) {
+		// The client verifies x*y with the polynomial protocol of PE, instead of decrypting all its components
+		if s.skPE == nil || s.params.LogN() < minProtocolLogN {
+			return nil, 0, 0
+		}
+		_, ctX, verifX := s.input("x")
+		_, ctY, verifY := s.input("y")
+		ct := s.evaluator.RelinearizeNew(s.evaluator.MulNew(ctX, ctY)).(*vche_2.Ciphertext)
+		verif := s.finalize(s.evaluatorPlaintext.MulNew(verifX, verifY)).(*vche_2.Poly)
+		// The messages are recorded by an untimed run, so that their accounting is not measured as protocol work
+		prover, verifier, session := s.proverVerifier()
+		vche_2.RunPolynomialProtocolUint(prover, verifier, ct, verif)
+		verifier = verifier.WithSession(nil)
+		run := func() { vche_2.RunPolynomialProtocolUint(prover, verifier, ct, verif) }
+		return run, 0, commBytes(session)
+	}},
+	{"Protocol/Requadratization", true, func(s *suite) (func(), int, int) {
+		// The client brings x^2*y^2, of degree 4, back to degree 2 with the requadratization protocol of PE
+		if s.skPE == nil || s.params.LogN() < minProtocolLogN {
+			return nil, 0, 0
+		}
+		_, ctX, _ := s.input("x")
+		_, ctY, _ := s.input("y")
+		ct2 := s.evaluator.MulNew(ctX, ctY)
+		ct4 := s.evaluator.MulNew(ct2, ct2).(*vche_2.Ciphertext)
+		tagsX, tagsY := vche.GetIndexTags([]byte("x"), s.params.NSlots), vche.GetIndexTags([]byte("y"), s.params.NSlots)
+		prover, verifier, session := s.proverVerifier()
+		var requad func(v vche_2.Verifier)
+		if s.encoding.cfprf() {
+			enc, eval := vche_2.NewEncoderPlaintextCFPRFRequad(s.params, s.skPE.K), vche_2.NewEvaluatorPlaintextCFPRFRequad(s.params)
+			verif2 := eval.MulNew(enc.EncodeNew(tagsX), enc.EncodeNew(tagsY))
+			verif4 := eval.MulNew(verif2, verif2)
+			requad = func(v vche_2.Verifier) { vche_2.RunRequadratizationProtocolCFPRF(prover, v, ct4, verif4) }
+		} else {
+			enc, eval := vche_2.NewEncoderPlaintextRequad(s.params, s.skPE.K), vche_2.NewEvaluatorPlaintextRequad(s.params)
+			verif2 := eval.MulNew(enc.EncodeNew(tagsX), enc.EncodeNew(tagsY))
+			verif4 := eval.MulNew(verif2, verif2)
+			requad = func(v vche_2.Verifier) { vche_2.RunRequadratizationProtocol(prover, v, ct4, verif4) }
+		}
+		// As for the polynomial protocol, the messages are recorded by an untimed run
+		requad(verifier)
+		verifier = verifier.WithSession(nil)
+		run := func() { requad(verifier) }
+		return run, 0, commBytes(session)
+	}},
+}
+
+// minProtocolLogN is the smallest ring degree whose default parameters leave enough noise budget for the protocols of PE,
+// which are not benchmarked with smaller parameters.
+const minProtocolLogN = 13
+
+// commBytes returns the bytes exchanged in both directions in the session.
+func commBytes(session *vche.Session) int {
+	report := session.Report()
+	return report.Total(vche.ClientToServer).Bytes + report.Total(vche.ServerToClient).Bytes
+}
+
+// evaluatorBenchmark measures an operation of the evaluator of the server on fresh ciphertexts.
+func evaluatorBenchmark(op func(eval vche.GenericEvaluator, x, y interface{}) interface{}) func(s *suite) (func(), int, int) {
+	return func(s *suite) (func(), int, int) {
+		_, x, _ := s.input("x")
+		_, y, _ := s.input("y")
+		return func() { op(s.evaluator, x, y) }, vche.Size(op(s.evaluator, x, y)), 0
+	}
+}
+
+// evaluatorPlaintextBenchmark measures an operation of the evaluator of the client on fresh verification states.
+func evaluatorPlaintextBenchmark(op func(eval vche.GenericEvaluator, x, y interface{}) interface{}) func(s *suite) (func(), int, int) {
+	return func(s *suite) (func(), int, int) {
+		_, _, x := s.input("x")
+		_, _, y := s.input("y")
+		return func() { op(s.evaluatorPlaintext, x, y) }, 0, 0
+	}
+}
+
+// Name returns the name of the benchmark of op with the given encoding and parameters, in the format of the go test
+// benchmarks.
+func Name(encoding Encoding, op string, params vche.Parameters) string {
+	return fmt.Sprintf("%s/%s/LogN=%d&logQ=%d&alpha=%d&beta=%d", encoding.prefix(), op, params.LogN(), params.LogQP(), params.PCount(), params.Beta())
+}
+
+// Run runs the benchmarks selected by cfg.
+func Run(cfg Config) ([]Result, error) {
+	if len(cfg.Encodings) == 0 {
+		cfg.Encodings = Encodings
+	}
+	if len(cfg.Params) == 0 {
+		cfg.Params = []int{0}
+	}
+	if cfg.Runs <= 0 {
+		cfg.Runs = 1
+	}
+	if cfg.MinTime <= 0 {
+		cfg.MinTime = time.Second
+	}
+
+	var results []Result
+	for _, encoding := range cfg.Encodings {
+		for _, i := range cfg.Params {
+			literal, err := encoding.ParametersLiteral(i)
+			if err != nil {
+				return nil, err
+			}
+			params, err := vche.NewParametersFromLiteral(literal)
+			if err != nil {
+				return nil, err
+			}
+
+			var s *suite
+			for _, bm := range benchmarks {
+				name := Name(encoding, bm.op, params)
+				if cfg.Filter != nil && !cfg.Filter.MatchString(name) {
+					continue
+				}
+				if s == nil {
+					s = newSuite(encoding, params)
+				}
+				if bm.verifiable && !s.verifiable() {
+					continue
+				}
+				res := Result{Name: name, Encoding: encoding, Op: bm.op, LogN: params.LogN(), LogQP: params.LogQP(), Slots: params.NSlots, Runs: cfg.Runs}
+				run, ciphertextBytes, commBytes := bm.setup(s)
+				if run == nil {
+					continue
+				}
+				res.CiphertextBytes, res.CommBytes = ciphertextBytes, commBytes
+				nsPerOp := make([]float64, cfg.Runs)
+				for r := range nsPerOp {
+					nsPerOp[r], res.Iterations, res.AllocsPerOp, res.BytesPerOp = measure(run, cfg.MinTime)
+				}
+				res.NsPerOp, res.Spread = meanAndSpread(nsPerOp)
+				if cfg.Log != nil {
+					fmt.Fprintf(cfg.Log, "Benchmark%s\t%d\t%.0f ns/op\t%d B/op\t%d allocs/op\n", res.Name, res.Iterations, res.NsPerOp, res.BytesPerOp, res.AllocsPerOp)
+				}
+				results = append(results, res)
+			}
+		}
+	}
+	return results, nil
+}
+
+// maxIterations bounds the number of iterations of a measurement, as in the testing package.
+const maxIterations = 1e9
+
+// measure runs op for at least minTime and returns the time and allocations per iteration. As the testing package, it
+// first runs op once, then grows the number of iterations from the estimated time per iteration.
+func measure(op func(), minTime time.Duration) (nsPerOp float64, iterations int, allocsPerOp, bytesPerOp int64) {
+	var before, after runtime.MemStats
+	for n := 1; ; {
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		start := time.Now()
+		for i := 0; i < n; i++ {
+			op()
+		}
+		elapsed := time.Since(start)
+		runtime.ReadMemStats(&after)
+		if elapsed >= minTime || n >= maxIterations {
+			return float64(elapsed.Nanoseconds()) / float64(n), n, int64(after.Mallocs-before.Mallocs) / int64(n), int64(after.TotalAlloc-before.TotalAlloc) / int64(n)
+		}
+		// Aim 20% above minTime, growing at most 100x per round
+		next := int(1.2 * float64(minTime.Nanoseconds()) * float64(n) / math.Max(float64(elapsed.Nanoseconds()), 1))
+		if next > 100*n {
+			next = 100 * n
+		}
+		if next <= n {
+			next = n + 1
+		}
+		n = next
+	}
+}
+
+func meanAndSpread(xs []float64) (mean, spread float64) {
+	for _, x := range xs {
+		mean += x
+	}
+	mean /= float64(len(xs))
+	for _, x := range xs {
+		spread = math.Max(spread, math.Abs(x-mean)/mean)
+	}
+	return mean, spread
+}
@@ -0,0 +1,111 @@
+package bench
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBench(t *testing.T) {
+	var log bytes.Buffer
+	results, err := Run(Config{
+		Encodings: Encodings,
+		Params:    []int{0},
+		MinTime:   time.Millisecond,
+		Filter:    regexp.MustCompile("Encrypt|/Mul/|Protocol"),
+		Log:       &log,
+	})
+	require.NoError(t, err)
+
+	ops := make(map[Encoding][]string)
+	for _, res := range results {
+		ops[res.Encoding] = append(ops[res.Encoding], res.Op)
+		require.True(t, strings.HasPrefix(res.Name, res.Encoding.prefix()+"/"+res.Op+"/LogN=12"), res.Name)
+		require.Positive(t, res.NsPerOp)
+		require.Positive(t, res.Iterations)
+		if res.Op != "EvaluatorPlaintext/Mul" {
+			require.Positive(t, res.CiphertextBytes, res.Name)
+		}
+	}
+	require.Equal(t, []string{"Encrypt", "Evaluator/Mul", "Protocol/Circuit"}, ops[BFV])
+	require.Equal(t, []string{"Encrypt", "Evaluator/Mul", "EvaluatorPlaintext/Mul", "Protocol/Circuit"}, ops[REP])
+	// The protocols of PE are not run with PN12
+	require.Equal(t, ops[REP], ops[PE])
+	require.Equal(t, ops[REP], ops[REPCFPRF])
+	require.Equal(t, ops[REP], ops[PECFPRF])
+	require.Equal(t, len(results), strings.Count(log.String(), "\n"))
+
+	byName := make(map[string]Result)
+	for _, res := range results {
+		byName[res.Name] = res
+	}
+	bfv, rep, pe := byName["VCHEBFV/Protocol/Circuit/LogN=12&logQ=109&alpha=1&beta=2"], byName["VCHE1/Protocol/Circuit/LogN=12&logQ=109&alpha=1&beta=2"], byName["VCHE2/Protocol/Circuit/LogN=12&logQ=109&alpha=1&beta=2"]
+	require.Equal(t, 3*bfv.CiphertextBytes, bfv.CommBytes)
+	require.Greater(t, rep.CiphertextBytes, bfv.CiphertextBytes, "a REP ciphertext is a BFV ciphertext with tags")
+	require.Less(t, rep.Slots, bfv.Slots)
+	require.Greater(t, pe.CiphertextBytes, bfv.CiphertextBytes, "a PE ciphertext has several BFV components")
+
+	t.Run("CSV", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, WriteCSV(&buf, results))
+		records, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, len(results)+1)
+		require.Equal(t, []string{"name", "time/op (ns/op)"}, records[0][:2])
+		require.Equal(t, results[0].Name, records[1][0])
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, WriteJSON(&buf, results))
+		var decoded []Result
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+		require.Equal(t, results, decoded)
+	})
+
+	t.Run("Protocols", func(t *testing.T) {
+		results, err := Run(Config{
+			Encodings: []Encoding{REP, PE, PECFPRF},
+			Params:    []int{1},
+			MinTime:   time.Millisecond,
+			Filter:    regexp.MustCompile("Protocol/(Polynomial|Requadratization)"),
+		})
+		require.NoError(t, err)
+		var names []string
+		for _, res := range results {
+			names = append(names, res.Encoding.prefix()+"/"+res.Op)
+			require.Positive(t, res.CommBytes, res.Name)
+		}
+		require.Equal(t, []string{"VCHE2/Protocol/Polynomial", "VCHE2/Protocol/Requadratization", "VCHE2CFPRF/Protocol/Polynomial", "VCHE2CFPRF/Protocol/Requadratization"}, names)
+	})
+
+	_, err = Run(Config{Encodings: []Encoding{"foo"}})
+	require.Error(t, err)
+	_, err = Run(Config{Encodings: []Encoding{REP}, Params: []int{42}})
+	require.Error(t, err)
+}
+
+func TestParseGoTest(t *testing.T) {
+	out := `goos: linux
+BenchmarkObliviousRiding/Client/Encrypt-8         	      10	   2000000 ns/op	   4096 B/op	      12 allocs/op
+BenchmarkObliviousRiding/Server/Eval-8            	       5	   9000000 ns/op
+BenchmarkObliviousRiding/Client/Encrypt-8         	      12	   1800000 ns/op	   4096 B/op	      12 allocs/op
+PASS
+ok  	veritas/vche/examples/ObliviousRiding/vche_1	12.345s
+`
+	results, err := ParseGoTest(strings.NewReader(out))
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.Equal(t, Result{Name: "ObliviousRiding/Client/Encrypt", Runs: 2, Iterations: 12, NsPerOp: 1900000, Spread: 100000.0 / 1900000, AllocsPerOp: 12, BytesPerOp: 4096}, results[0])
+	require.Equal(t, "ObliviousRiding/Server/Eval", results[1].Name)
+	require.Equal(t, 9000000.0, results[1].NsPerOp)
+
+	_, err = ParseGoTest(strings.NewReader("BenchmarkFoo-8 10 x ns/op\n"))
+	require.Error(t, err)
+}
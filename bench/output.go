@@ -0,0 +1,44 @@
+package bench
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// csvHeader starts with the columns of benchstat -format csv, which the plotting notebooks read.
+var csvHeader = []string{"name", "time/op (ns/op)", "±", "allocs/op", "alloc/op (B/op)", "ciphertext (B)", "communication (B)", "slots"}
+
+// WriteCSV writes the results in CSV, one line per benchmark.
+func WriteCSV(w io.Writer, results []Result) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, res := range results {
+		record := []string{
+			res.Name,
+			strconv.FormatFloat(res.NsPerOp, 'E', 5, 64),
+			fmt.Sprintf("%.0f%%", 100*res.Spread),
+			strconv.FormatInt(res.AllocsPerOp, 10),
+			strconv.FormatInt(res.BytesPerOp, 10),
+			strconv.Itoa(res.CiphertextBytes),
+			strconv.Itoa(res.CommBytes),
+			strconv.Itoa(res.Slots),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes the results as a JSON array.
+func WriteJSON(w io.Writer, results []Result) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(results)
+}
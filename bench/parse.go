@@ -0,0 +1,66 @@
+package bench
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// procsSuffix is the GOMAXPROCS suffix that go test appends to the names of the benchmarks.
+var procsSuffix = regexp.MustCompile(`-\d+$`)
+
+// ParseGoTest reads the output of `go test -bench`, e.g., of the benchmarks of the examples, and returns one result per
+// benchmark, averaged over its runs (-count), as benchstat does. The lines that are not benchmark results are ignored.
+// Only the timings and allocations are filled in; the encoding, the operation and the sizes are unknown.
+func ParseGoTest(r io.Reader) ([]Result, error) {
+	var results []Result
+	nsPerOp := make(map[string][]float64)
+	index := make(map[string]int)
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") || len(fields)%2 != 0 {
+			continue
+		}
+		iterations, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+		name := procsSuffix.ReplaceAllString(strings.TrimPrefix(fields[0], "Benchmark"), "")
+		i, ok := index[name]
+		if !ok {
+			i = len(results)
+			index[name] = i
+			results = append(results, Result{Name: name})
+		}
+		res := &results[i]
+		res.Runs++
+		res.Iterations = iterations
+		for f := 2; f < len(fields); f += 2 {
+			v, err := strconv.ParseFloat(fields[f], 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid value %q", lineNum, fields[f])
+			}
+			switch fields[f+1] {
+			case "ns/op":
+				nsPerOp[name] = append(nsPerOp[name], v)
+			case "B/op":
+				res.BytesPerOp = int64(v)
+			case "allocs/op":
+				res.AllocsPerOp = int64(v)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	for i := range results {
+		if ns := nsPerOp[results[i].Name]; len(ns) > 0 {
+			results[i].NsPerOp, results[i].Spread = meanAndSpread(ns)
+		}
+	}
+	return results, nil
+}
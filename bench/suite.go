@@ -0,0 +1,174 @@
+package bench
+
+import (
+	"fmt"
+
+	"github.com/ldsec/lattigo/v2/bfv"
+	"github.com/ldsec/lattigo/v2/rlwe"
+	"veritas/vche/bfv_generic"
+	"veritas/vche/vche"
+	"veritas/vche/vche_1"
+	"veritas/vche/vche_2"
+)
+
+// Encoding is a scheme that can be benchmarked.
+type Encoding string
+
+const (
+	BFV      Encoding = "bfv"       // plain BFV, the baseline without verification
+	REP      Encoding = "rep"       // replication encoding (vche_1)
+	PE       Encoding = "pe"        // polynomial encoding (vche_2)
+	REPCFPRF Encoding = "rep-cfprf" // replication encoding with closed-form PRFs
+	PECFPRF  Encoding = "pe-cfprf"  // polynomial encoding with closed-form PRFs
+)
+
+// Encodings lists all the encodings, in the order in which they are benchmarked by default.
+var Encodings = []Encoding{BFV, REP, PE, REPCFPRF, PECFPRF}
+
+// prefix is the name of the benchmarks of the encoding in the go test benchmarks, which the plotting notebooks expect.
+func (e Encoding) prefix() string {
+	switch e {
+	case BFV:
+		return "VCHEBFV"
+	case REP:
+		return "VCHE1"
+	case PE:
+		return "VCHE2"
+	case REPCFPRF:
+		return "VCHE1CFPRF"
+	case PECFPRF:
+		return "VCHE2CFPRF"
+	default:
+		return string(e)
+	}
+}
+
+// ParametersLiteral returns the i-th default parameters of the encoding.
+func (e Encoding) ParametersLiteral(i int) (vche.ParametersLiteral, error) {
+	var defaults []vche.ParametersLiteral
+	switch e {
+	case BFV, PE, PECFPRF:
+		defaults = vche_2.DefaultParams
+	case REP, REPCFPRF:
+		defaults = vche_1.DefaultParams
+	default:
+		return vche.ParametersLiteral{}, fmt.Errorf("unknown encoding %q", e)
+	}
+	if i < 0 || i >= len(defaults) {
+		return vche.ParametersLiteral{}, fmt.Errorf("no default parameters at index %d, there are %d", i, len(defaults))
+	}
+	return defaults[i], nil
+}
+
+// cfprf reports whether the encoding uses closed-form PRFs.
+func (e Encoding) cfprf() bool {
+	return e == REPCFPRF || e == PECFPRF
+}
+
+// suite holds the keys and generic components of an encoding. The plaintext encoder and evaluator are nil for BFV, which
+// has no verification.
+type suite struct {
+	encoding           Encoding
+	params             vche.Parameters
+	evk                *rlwe.EvaluationKey
+	encoder            vche.GenericEncoder
+	encryptor          vche.GenericEncryptor
+	decryptor          vche.GenericDecryptor
+	evaluator          vche.GenericEvaluator
+	encoderPlaintext   vche.GenericEncoderPlaintext
+	evaluatorPlaintext vche.GenericEvaluator
+	// finalize turns the output of evaluatorPlaintext into the verification state expected by the decoder, i.e.,
+	// evaluates the closed-form PRFs
+	finalize func(verif interface{}) interface{}
+	// skPE is the secret key of PE, with which the protocols of the verifier are run
+	skPE *vche_2.SecretKey
+}
+
+// rotation is the column rotation that the rotation keys of a suite are generated for.
+const rotation = 1
+
+func newSuite(encoding Encoding, params vche.Parameters) *suite {
+	s := &suite{encoding: encoding, params: params, finalize: func(verif interface{}) interface{} { return verif }}
+	cfprf := encoding.cfprf()
+	// REP rotates the columns by rotation*NumReplications slots, to move past the replications of a value
+	galEls := []uint64{params.GaloisElementForColumnRotationBy(rotation * params.NumReplications), params.GaloisElementForRowRotation()}
+	switch encoding {
+	case BFV:
+		kgen := bfv.NewKeyGenerator(params.Parameters)
+		sk := kgen.GenSecretKey()
+		s.evk = &rlwe.EvaluationKey{Rlk: kgen.GenRelinearizationKey(sk, 1), Rtks: kgen.GenRotationKeys(galEls, sk)}
+		s.encoder = bfv_generic.NewGenericEncoder(params.Parameters)
+		s.encryptor = bfv_generic.NewGenericEncryptor(params.Parameters, sk)
+		s.decryptor = bfv_generic.NewGenericDecryptor(params.Parameters, sk)
+		s.evaluator = bfv_generic.NewGenericEvaluator(params.Parameters, *s.evk)
+	case REP, REPCFPRF:
+		kgen := vche_1.NewKeyGenerator(params)
+		sk := kgen.GenSecretKey()
+		rlk := kgen.GenRelinearizationKey(sk, 1)
+		s.evk = &rlwe.EvaluationKey{Rlk: rlk.RelinearizationKey, Rtks: kgen.GenRotationKeys(galEls, sk).RotationKeySet}
+		s.encoder = vche_1.NewGenericEncoder(params, sk.K, sk.S, cfprf)
+		s.encryptor = vche_1.NewGenericEncryptor(params, sk)
+		s.decryptor = vche_1.NewGenericDecryptor(params, sk)
+		s.evaluator = vche_1.NewGenericEvaluator(params, &vche_1.EvaluationKey{EvaluationKey: *s.evk, H: rlk.H})
+		if cfprf {
+			s.encoderPlaintext = vche_1.NewGenericEncoderPlaintextCFPRF(params, sk.K)
+			s.evaluatorPlaintext = vche_1.NewGenericEvaluatorPlaintextCFPRF(params, sk.H)
+			eval := vche_1.NewEvaluatorPlaintextCFPRF(params, sk.H)
+			s.finalize = func(verif interface{}) interface{} {
+				eval.ComputeMemo(verif.(*vche_1.VerifPlaintext))
+				return eval.Eval(verif.(*vche_1.VerifPlaintext))
+			}
+		} else {
+			s.encoderPlaintext = vche_1.NewGenericEncoderPlaintext(params, sk.K)
+			s.evaluatorPlaintext = vche_1.NewGenericEvaluatorPlaintext(params, sk.H)
+		}
+	case PE, PECFPRF:
+		kgen := vche_2.NewKeyGenerator(params)
+		sk := kgen.GenSecretKey()
+		s.evk = &rlwe.EvaluationKey{Rlk: kgen.GenRelinearizationKey(sk, 1), Rtks: kgen.GenRotationKeys(galEls, sk)}
+		s.skPE = sk
+		s.encoder = vche_2.NewGenericEncoder(params, sk.K, sk.Alpha, cfprf)
+		s.encryptor = vche_2.NewGenericEncryptor(params, sk)
+		s.decryptor = vche_2.NewGenericDecryptor(params, sk)
+		s.evaluator = vche_2.NewGenericEvaluator(params, s.evk)
+		if cfprf {
+			s.encoderPlaintext = vche_2.NewGenericEncoderPlaintextCFPRF(params, sk.K)
+			s.evaluatorPlaintext = vche_2.NewGenericEvaluatorPlaintextCFPRF(params)
+			eval := vche_2.NewEvaluatorPlaintextCFPRF(params)
+			s.finalize = func(verif interface{}) interface{} {
+				eval.ComputeMemo(verif.(*vche_2.VerifPlaintext))
+				return eval.Eval(verif.(*vche_2.VerifPlaintext))
+			}
+		} else {
+			s.encoderPlaintext = vche_2.NewGenericEncoderPlaintext(params, sk.K)
+			s.evaluatorPlaintext = vche_2.NewGenericEvaluatorPlaintext(params)
+		}
+	default:
+		panic(fmt.Errorf("unknown encoding %q", encoding))
+	}
+	return s
+}
+
+// verifiable reports whether the encoding verifies the results.
+func (s *suite) verifiable() bool {
+	return s.encoderPlaintext != nil
+}
+
+// input returns the encoding and the encryption of a random vector, with its verification state.
+func (s *suite) input(datasetTag string) (pt, ct, verif interface{}) {
+	tags := vche.GetIndexTags([]byte(datasetTag), s.params.NSlots)
+	pt = s.encoder.EncodeUintNew(vche.GetRandomCoeffs(s.params.NSlots, s.params.T()), tags)
+	ct = s.encryptor.EncryptNew(pt)
+	if s.verifiable() {
+		verif = s.encoderPlaintext.EncodeNew(tags)
+	}
+	return pt, ct, verif
+}
+
+// proverVerifier returns the prover and the verifier of the protocols of PE, and a session that records their messages.
+func (s *suite) proverVerifier() (vche_2.Prover, vche_2.Verifier, *vche.Session) {
+	kgen := vche_2.NewKeyGenerator(s.params)
+	prover, verifier := vche_2.NewProverVerifier(s.params, s.skPE, kgen.GenRotationKeysForInnerSum(s.skPE))
+	session := vche.NewSession()
+	return prover, verifier.WithSession(session), session
+}
@@ -6,20 +6,6 @@ import (
 	"veritas/vche/vche"
 )
 
-type decryptor struct {
-	bfv.Decryptor
-}
-
-var _ vche.GenericDecryptor = decryptor{}
-
 func NewGenericDecryptor(params bfv.Parameters, sk *rlwe.SecretKey) vche.GenericDecryptor {
-	return decryptor{bfv.NewDecryptor(params, sk)}
-}
-
-func (d decryptor) Decrypt(ciphertext interface{}, plaintext interface{}) {
-	d.Decryptor.Decrypt(ctxt(ciphertext), ptxt(plaintext))
-}
-
-func (d decryptor) DecryptNew(ciphertext interface{}) (plaintext interface{}) {
-	return d.Decryptor.DecryptNew(ctxt(ciphertext))
+	return vche.NewGenericDecryptorOf[*bfv.Ciphertext, *bfv.Plaintext](bfv.NewDecryptor(params, sk))
 }
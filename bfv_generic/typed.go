@@ -0,0 +1,84 @@
+package bfv_generic
+
+import (
+	"github.com/ldsec/lattigo/v2/bfv"
+	"github.com/ldsec/lattigo/v2/rlwe"
+	"veritas/vche/vche"
+)
+
+var _ vche.TypedEncryptor[*bfv.Plaintext, *bfv.Ciphertext] = bfv.Encryptor(nil)
+var _ vche.TypedDecryptor[*bfv.Ciphertext, *bfv.Plaintext] = bfv.Decryptor(nil)
+
+// Verif is the verification state of BFV, which has none.
+type Verif = interface{}
+
+type typedEncoder struct {
+	bfv.Encoder
+	params bfv.Parameters
+}
+
+// NewTypedEncoder returns an encoder that ignores the tags and the verification state, see vche.TypedEncoder.
+func NewTypedEncoder(params bfv.Parameters) vche.TypedEncoder[*bfv.Plaintext, *bfv.PlaintextMul, Verif] {
+	return typedEncoder{bfv.NewEncoder(params), params}
+}
+
+func (enc typedEncoder) EncodeUint(coeffs []uint64, _ []vche.Tag, pt *bfv.Plaintext) {
+	enc.Encoder.EncodeUint(coeffs, pt)
+}
+
+func (enc typedEncoder) EncodeUintNew(coeffs []uint64, _ []vche.Tag) (pt *bfv.Plaintext) {
+	pt = bfv.NewPlaintext(enc.params)
+	enc.Encoder.EncodeUint(coeffs, pt)
+	return pt
+}
+
+func (enc typedEncoder) EncodeInt(coeffs []int64, _ []vche.Tag, pt *bfv.Plaintext) {
+	enc.Encoder.EncodeInt(coeffs, pt)
+}
+
+func (enc typedEncoder) EncodeIntNew(coeffs []int64, _ []vche.Tag) (pt *bfv.Plaintext) {
+	pt = bfv.NewPlaintext(enc.params)
+	enc.Encoder.EncodeInt(coeffs, pt)
+	return pt
+}
+
+func (enc typedEncoder) EncodeUintMul(coeffs []uint64, _ []vche.Tag, pt *bfv.PlaintextMul) {
+	enc.Encoder.EncodeUintMul(coeffs, pt)
+}
+
+func (enc typedEncoder) EncodeUintMulNew(coeffs []uint64, _ []vche.Tag) (pt *bfv.PlaintextMul) {
+	pt = bfv.NewPlaintextMul(enc.params)
+	enc.Encoder.EncodeUintMul(coeffs, pt)
+	return pt
+}
+
+func (enc typedEncoder) EncodeIntMul(coeffs []int64, _ []vche.Tag, pt *bfv.PlaintextMul) {
+	enc.Encoder.EncodeIntMul(coeffs, pt)
+}
+
+func (enc typedEncoder) EncodeIntMulNew(coeffs []int64, _ []vche.Tag) (pt *bfv.PlaintextMul) {
+	pt = bfv.NewPlaintextMul(enc.params)
+	enc.Encoder.EncodeIntMul(coeffs, pt)
+	return pt
+}
+
+func (enc typedEncoder) DecodeUint(pt *bfv.Plaintext, _ Verif, coeffs []uint64) {
+	enc.Encoder.DecodeUint(pt, coeffs)
+}
+
+func (enc typedEncoder) DecodeUintNew(pt *bfv.Plaintext, _ Verif) (coeffs []uint64) {
+	return enc.Encoder.DecodeUintNew(pt)
+}
+
+func (enc typedEncoder) DecodeInt(pt *bfv.Plaintext, _ Verif, coeffs []int64) {
+	enc.Encoder.DecodeInt(pt, coeffs)
+}
+
+func (enc typedEncoder) DecodeIntNew(pt *bfv.Plaintext, _ Verif) (coeffs []int64) {
+	return enc.Encoder.DecodeIntNew(pt)
+}
+
+// NewTypedEvaluator returns the evaluator of ciphertexts as a vche.TypedEvaluator.
+func NewTypedEvaluator(params bfv.Parameters, evaluationKey rlwe.EvaluationKey) vche.TypedEvaluator[*bfv.Ciphertext] {
+	return vche.NewTypedEvaluator[bfv.Operand, *bfv.Ciphertext](operandEvaluator{bfv.NewEvaluator(params, evaluationKey), params})
+}
@@ -0,0 +1,46 @@
+package bfv_generic
+
+import (
+	"testing"
+
+	"github.com/ldsec/lattigo/v2/bfv"
+	"github.com/ldsec/lattigo/v2/rlwe"
+	"github.com/stretchr/testify/require"
+	"veritas/vche/vche"
+)
+
+func TestTyped(t *testing.T) {
+	params, err := bfv.NewParametersFromLiteral(bfv.PN12QP109)
+	require.NoError(t, err)
+	kgen := bfv.NewKeyGenerator(params)
+	sk, pk := kgen.GenKeyPair()
+	evk := rlwe.EvaluationKey{Rlk: kgen.GenRelinearizationKey(sk, 1)}
+
+	encoder := NewTypedEncoder(params)
+	encryptor := bfv.NewEncryptor(params, pk)
+	x, y := vche.GetRandomCoeffs(params.N(), 1<<8), vche.GetRandomCoeffs(params.N(), 1<<8)
+	ctX, ctY := encryptor.EncryptNew(encoder.EncodeUintNew(x, nil)), encryptor.EncryptNew(encoder.EncodeUintNew(y, nil))
+	want := make([]uint64, params.N())
+	for i := range want {
+		want[i] = (x[i]*y[i] + y[i]) % params.T()
+	}
+
+	// x * y + y, with y as a ciphertext or as a plaintext operand
+	eval := NewTypedEvaluator(params, evk)
+	res := eval.RelinearizeNew(eval.MulNew(ctX, ctY))
+	eval.Add(res, ctY, res)
+	require.Equal(t, want, encoder.DecodeUintNew(bfv.NewDecryptor(params, sk).DecryptNew(res), nil))
+
+	genericEval := NewGenericEvaluator(params, evk).ShallowCopy()
+	genericEncoder := NewGenericEncoder(params)
+	genericDecryptor := NewGenericDecryptor(params, sk)
+	genericRes := genericEval.RelinearizeNew(genericEval.MulNew(ctX, genericEval.CopyNew(ctY)))
+	genericEval.Add(genericRes, genericEncoder.EncodeUintNew(y, nil), genericRes)
+	require.Equal(t, want, genericEncoder.DecodeUintNew(genericDecryptor.DecryptNew(genericRes), nil))
+
+	// The Generic API checks the types at runtime
+	pt := genericEncoder.EncodeUintNew(y, nil)
+	require.PanicsWithError(t, "expected *bfv.Ciphertext, got *bfv.Plaintext", func() { genericEval.RelinearizeNew(pt) })
+	require.PanicsWithError(t, "expected *bfv.Plaintext, got *bfv.Ciphertext", func() { genericEncoder.DecodeUintNew(genericRes, nil) })
+	require.PanicsWithError(t, "expected *bfv.Plaintext, got *bfv.Ciphertext", func() { NewGenericEncryptor(params, pk).EncryptNew(ctX) })
+}
@@ -0,0 +1,100 @@
+// Command bench runs the benchmarks of the bench package and writes the results in CSV or JSON:
+//
+//	bench [-encodings bfv,rep,pe,rep-cfprf,pe-cfprf] [-params 2] [-runs 10] [-benchtime 1s] [-run regexp] [-format csv|json] [-out file]
+//	bench -parse bench.out [-format csv|json] [-out file]
+//
+// -params selects parameter sets by their index in the DefaultParams of each encoding. The progress is written to the
+// standard error in the format of go test. With -parse, the results are read from the output of `go test -bench`, e.g.,
+// of the benchmarks of the examples, instead of being measured.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"veritas/vche/bench"
+)
+
+func main() {
+	encodings := flag.String("encodings", "bfv,rep,pe,rep-cfprf,pe-cfprf", "comma-separated encodings to benchmark")
+	params := flag.String("params", "2", "comma-separated indices of the default parameters to benchmark")
+	runs := flag.Int("runs", 1, "number of measurements of each benchmark")
+	benchtime := flag.Duration("benchtime", 0, "minimum duration of a measurement (defaults to 1s)")
+	run := flag.String("run", "", "only run the benchmarks whose name matches this regular expression")
+	format := flag.String("format", "csv", "output format: csv or json")
+	out := flag.String("out", "", "file to write the results to (defaults to the standard output)")
+	parse := flag.String("parse", "", "file with the output of go test -bench to convert, instead of running the benchmarks")
+	flag.Parse()
+
+	if err := runBench(*encodings, *params, *runs, *benchtime, *run, *format, *out, *parse); err != nil {
+		fmt.Fprintf(os.Stderr, "bench: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runBench(encodings, params string, runs int, benchtime time.Duration, run, format, out, parse string) error {
+	cfg := bench.Config{Runs: runs, MinTime: benchtime, Log: os.Stderr}
+	for _, e := range strings.Split(encodings, ",") {
+		cfg.Encodings = append(cfg.Encodings, bench.Encoding(strings.TrimSpace(e)))
+	}
+	for _, p := range strings.Split(params, ",") {
+		i, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil {
+			return fmt.Errorf("invalid parameters index %q", p)
+		}
+		cfg.Params = append(cfg.Params, i)
+	}
+	if run != "" {
+		filter, err := regexp.Compile(run)
+		if err != nil {
+			return err
+		}
+		cfg.Filter = filter
+	}
+
+	var write func(io.Writer, []bench.Result) error
+	switch format {
+	case "csv":
+		write = bench.WriteCSV
+	case "json":
+		write = bench.WriteJSON
+	default:
+		return fmt.Errorf("unknown format %q, expected csv or json", format)
+	}
+
+	var results []bench.Result
+	if parse != "" {
+		f, err := os.Open(parse)
+		if err != nil {
+			return err
+		}
+		results, err = bench.ParseGoTest(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %v", parse, err)
+		}
+	} else {
+		var err error
+		if results, err = bench.Run(cfg); err != nil {
+			return err
+		}
+	}
+	if out == "" {
+		return write(os.Stdout, results)
+	}
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	if err := write(f, results); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
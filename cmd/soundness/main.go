@@ -0,0 +1,98 @@
+// Command soundness estimates empirically the probability that the client detects a cheating server, and compares it
+// with the theoretical soundness bound of the parameters:
+//
+//	soundness [-encoding rep|pe] [-params 0] [-replications 4] [-dummies 1] [-strategy guess-dummies] [-trials 100] [-confidence 0.95] [-offset 1]
+//
+// -params selects the parameters by their index in the DefaultParams of the encoding, and -replications and -dummies
+// override the number of replications and of dummies of REP. The strategy defaults to the best known strategy against
+// the encoding, guess-dummies for REP and guess-alpha for PE, which succeeds with the probability of the bound. The
+// command exits with status 2 if the observed detection probability is significantly below the bound.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"veritas/vche/adversary"
+	"veritas/vche/vche"
+	"veritas/vche/vche_1"
+	"veritas/vche/vche_2"
+)
+
+func main() {
+	encoding := flag.String("encoding", "rep", "encoding: rep (replication) or pe (polynomial)")
+	paramsIndex := flag.Int("params", 0, "index of the default parameters of the encoding")
+	replications := flag.Int("replications", 0, "number of replications of REP (defaults to that of the parameters)")
+	dummies := flag.Int("dummies", 0, "number of dummies of REP (defaults to half the replications)")
+	strategyName := flag.String("strategy", "", "cheating strategy (defaults to the best strategy against the encoding)")
+	trials := flag.Int("trials", 100, "number of trials")
+	confidence := flag.Float64("confidence", 0.95, "confidence level of the interval of the detection probability")
+	offset := flag.Uint64("offset", 1, "constant added by the strategies that add one")
+	flag.Parse()
+
+	est, err := estimate(*encoding, *paramsIndex, *replications, *dummies, *strategyName, adversary.Experiment{Trials: *trials, Confidence: *confidence, Offset: *offset})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "soundness: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(est)
+	if !est.Consistent() {
+		os.Exit(2)
+	}
+}
+
+func estimate(encoding string, paramsIndex, replications, dummies int, strategyName string, exp adversary.Experiment) (adversary.Estimate, error) {
+	var defaults []vche.ParametersLiteral
+	switch encoding {
+	case "rep":
+		defaults = vche_1.DefaultParams
+		if strategyName == "" {
+			strategyName = adversary.GuessDummies.String()
+		}
+	case "pe":
+		defaults = vche_2.DefaultParams
+		if strategyName == "" {
+			strategyName = adversary.GuessAlpha.String()
+		}
+	default:
+		return adversary.Estimate{}, fmt.Errorf("unknown encoding %q, expected rep or pe", encoding)
+	}
+	if paramsIndex < 0 || paramsIndex >= len(defaults) {
+		return adversary.Estimate{}, fmt.Errorf("no default parameters at index %d, there are %d", paramsIndex, len(defaults))
+	}
+	strategy, err := adversary.ParseStrategy(strategyName)
+	if err != nil {
+		return adversary.Estimate{}, err
+	}
+	exp.Strategy = strategy
+
+	literal := defaults[paramsIndex]
+	if encoding == "pe" {
+		if replications != 0 || dummies != 0 {
+			return adversary.Estimate{}, fmt.Errorf("PE does not use replications nor dummies")
+		}
+		params, err := vche_2.NewParametersFromLiteral(literal)
+		if err != nil {
+			return adversary.Estimate{}, err
+		}
+		fmt.Printf("PE, LogN=%d, T=%d\n", params.LogN(), params.T())
+		return adversary.EstimatePE(params, exp), nil
+	}
+
+	if replications != 0 {
+		literal.NumReplications, literal.NumDummies = replications, 0
+	}
+	if dummies != 0 {
+		literal.NumDummies = dummies
+	}
+	params, err := vche_1.NewParametersFromLiteral(literal)
+	if err != nil {
+		return adversary.Estimate{}, err
+	}
+	if strategy == adversary.GuessAlpha {
+		return adversary.Estimate{}, fmt.Errorf("the %s strategy only applies to PE", strategy)
+	}
+	fmt.Printf("REP, LogN=%d, T=%d, %d replications, %d dummies\n", params.LogN(), params.T(), params.NumReplications, params.NumDummies)
+	return adversary.EstimateREP(params, exp), nil
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ldsec/lattigo/v2/rlwe"
+	"veritas/vche/vche"
+)
+
+const (
+	configFile    = "params.json"
+	secretKeyFile = "secret.key"
+	evalKeyFile   = "eval.key"
+)
+
+// config is the public configuration of a key directory, shared by the client and the server.
+type config struct {
+	Encoding string                 `json:"encoding"`
+	CFPRF    bool                   `json:"cfprf"`
+	Params   vche.ParametersLiteral `json:"params"`
+}
+
+// evalKeyData is the serialized evaluation key of the server.
+type evalKeyData struct {
+	Rlk  []byte
+	Rtks []byte
+}
+
+// ciphertextData is a vector of values encrypted chunk by chunk. The dataset tag and the length determine the tags of
+// all the values (see vche.GetChunkedIndexTags), so that the client can re-derive them to verify the results.
+type ciphertextData struct {
+	DatasetTag []byte
+	Length     int
+	Chunks     [][]byte
+}
+
+// resultData holds the outputs of a circuit evaluated chunk by chunk on ciphertexts.
+type resultData struct {
+	Outputs []ciphertextData
+}
+
+func writeJSON(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+func readJSON(path string, v interface{}) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func writeGob(path string, v interface{}) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, buf.Bytes(), 0644)
+}
+
+func readGob(path string, v interface{}) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(v); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	return nil
+}
+
+func readConfig(dir string) (*config, error) {
+	cfg := new(config)
+	if err := readJSON(filepath.Join(dir, configFile), cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// readSecretKey loads the scheme of the key directory with the secret key of the client.
+func readSecretKey(dir string) (scheme, error) {
+	cfg, err := readConfig(dir)
+	if err != nil {
+		return nil, err
+	}
+	s, err := newScheme(cfg)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, secretKeyFile))
+	if err != nil {
+		return nil, err
+	}
+	seed, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", secretKeyFile, err)
+	}
+	s.setSecretKey(vche.NewKDF(seed, kdfContext(cfg)))
+	return s, nil
+}
+
+// readEvalKey loads the scheme of the key directory with the evaluator of the server.
+func readEvalKey(dir string) (scheme, vche.GenericEvaluator, error) {
+	cfg, err := readConfig(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	s, err := newScheme(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	var data evalKeyData
+	if err := readGob(filepath.Join(dir, evalKeyFile), &data); err != nil {
+		return nil, nil, err
+	}
+	evk := &rlwe.EvaluationKey{Rlk: new(rlwe.RelinearizationKey)}
+	if err := evk.Rlk.UnmarshalBinary(data.Rlk); err != nil {
+		return nil, nil, err
+	}
+	if data.Rtks != nil {
+		evk.Rtks = new(rlwe.RotationKeySet)
+		if err := evk.Rtks.UnmarshalBinary(data.Rtks); err != nil {
+			return nil, nil, err
+		}
+	}
+	return s, s.evaluator(evk), nil
+}
+
+// kdfContext binds the keys derived from a seed to the encoding, so that the same seed yields independent keys for REP
+// and PE.
+func kdfContext(cfg *config) string {
+	return "veritas-cli/" + cfg.Encoding
+}
+
+func readCircuit(path string) (*vche.Circuit, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c, err := vche.ParseCircuit(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return c, nil
+}
+
+// readValues reads the values to encrypt, either from a JSON file {"tag": ..., "values": [...]}, or from a CSV file of
+// integers separated by commas or newlines. It returns the tag of the JSON file, if any.
+func readValues(path string) ([]int64, string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	if strings.HasSuffix(path, ".json") {
+		var v struct {
+			Tag    string  `json:"tag"`
+			Values []int64 `json:"values"`
+		}
+		if err := json.Unmarshal(data, &v); err != nil {
+			return nil, "", fmt.Errorf("%s: %v", path, err)
+		}
+		return v.Values, v.Tag, nil
+	}
+	var values []int64
+	for _, f := range strings.FieldsFunc(string(data), func(r rune) bool { return r == ',' || r == '\n' || r == '\r' }) {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		v, err := strconv.ParseInt(f, 10, 64)
+		if err != nil {
+			return nil, "", fmt.Errorf("%s: %v", path, err)
+		}
+		values = append(values, v)
+	}
+	return values, "", nil
+}
@@ -0,0 +1,345 @@
+// Command veritas runs the client and server sides of VERITAS on files, so that the full flow can be scripted:
+//
+//	veritas keygen  -encoding rep|pe [-cfprf] [-params params.json] [-circuit circuit.txt] -keys dir
+//	veritas encrypt -keys dir -in values.csv|values.json [-tag tag] -out ct.bin
+//	veritas eval    -keys dir -circuit circuit.txt -out result.bin ct1.bin ct2.bin ...
+//	veritas decrypt -keys dir -circuit circuit.txt -result result.bin [-signed] [-out result.csv] ct1.bin ct2.bin ...
+//
+// keygen writes the public configuration (params.json), the secret key of the client (secret.key, a seed from which all
+// the secret material is derived) and the evaluation key of the server (eval.key) to the key directory. The server only
+// needs params.json and eval.key.
+//
+// Circuits are given in the textual format of vche.Circuit.String. Vectors longer than the number of slots are split
+// into several ciphertexts, and the circuit is evaluated on each chunk independently: rotations and inner sums apply
+// within each chunk. decrypt re-derives the verification state of the inputs from their dataset tag and length, so it
+// must be given the same input files as eval, and fails if the server did not evaluate the circuit faithfully.
+package main
+
+import (
+	"crypto/rand"
+	"encoding"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"veritas/vche/vche"
+	"veritas/vche/vche_1"
+	"veritas/vche/vche_2"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	commands := map[string]func([]string) error{
+		"keygen":  keygen,
+		"encrypt": encrypt,
+		"eval":    eval,
+		"decrypt": decrypt,
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+	}
+	if err := cmd(os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "veritas %s: %v\n", os.Args[1], err)
+		if errors.Is(err, vche.ErrVerification) {
+			os.Exit(2)
+		}
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: veritas keygen|encrypt|eval|decrypt [flags]")
+	os.Exit(1)
+}
+
+func keygen(args []string) error {
+	fs := flag.NewFlagSet("keygen", flag.ExitOnError)
+	encodingName := fs.String("encoding", "rep", "encoding: rep (replication) or pe (polynomial)")
+	cfprf := fs.Bool("cfprf", false, "use the closed-form PRF optimisation for the verification")
+	paramsPath := fs.String("params", "", "JSON file with the parameters literal (defaults to the first default parameters of the encoding)")
+	circuitPath := fs.String("circuit", "", "circuit to generate the rotation keys for")
+	dir := fs.String("keys", "keys", "directory to write the keys to")
+	fs.Parse(args)
+
+	cfg := &config{Encoding: *encodingName, CFPRF: *cfprf}
+	switch {
+	case *paramsPath != "":
+		if err := readJSON(*paramsPath, &cfg.Params); err != nil {
+			return err
+		}
+	case cfg.Encoding == "pe":
+		cfg.Params = vche_2.DefaultParams[0]
+	default:
+		cfg.Params = vche_1.DefaultParams[0]
+	}
+	s, err := newScheme(cfg)
+	if err != nil {
+		return err
+	}
+	params, err := vche.NewParametersFromLiteral(cfg.Params)
+	if err != nil {
+		return err
+	}
+
+	var galEls []uint64
+	if *circuitPath != "" {
+		c, err := readCircuit(*circuitPath)
+		if err != nil {
+			return err
+		}
+		seen := make(map[uint64]bool)
+		add := func(galEl uint64) {
+			if !seen[galEl] {
+				seen[galEl] = true
+				galEls = append(galEls, galEl)
+			}
+		}
+		// The replications of a value are in consecutive slots, so REP rotates by k*NumReplications slots
+		for _, k := range c.Rotations() {
+			add(params.GaloisElementForColumnRotationBy(k * params.NumReplications))
+		}
+		if c.NeedsRotateRowsKey() {
+			add(params.GaloisElementForRowRotation())
+		}
+		if c.NeedsInnerSumKeys() {
+			for _, galEl := range params.GaloisElementsForRowInnerSum() {
+				add(galEl)
+			}
+		}
+	}
+
+	seed := make([]byte, 32)
+	if _, err := rand.Read(seed); err != nil {
+		return err
+	}
+	s.setSecretKey(vche.NewKDF(seed, kdfContext(cfg)))
+	evk := s.genEvaluationKey(galEls)
+	var data evalKeyData
+	if data.Rlk, err = evk.Rlk.MarshalBinary(); err != nil {
+		return err
+	}
+	if evk.Rtks != nil {
+		if data.Rtks, err = evk.Rtks.MarshalBinary(); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(*dir, 0755); err != nil {
+		return err
+	}
+	if err := writeJSON(filepath.Join(*dir, configFile), cfg); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(*dir, secretKeyFile), []byte(hex.EncodeToString(seed)+"\n"), 0600); err != nil {
+		return err
+	}
+	return writeGob(filepath.Join(*dir, evalKeyFile), &data)
+}
+
+func encrypt(args []string) error {
+	fs := flag.NewFlagSet("encrypt", flag.ExitOnError)
+	dir := fs.String("keys", "keys", "key directory")
+	in := fs.String("in", "", "CSV or JSON file with the values to encrypt")
+	tag := fs.String("tag", "", "dataset tag of the values (overridden by the tag of a JSON file)")
+	out := fs.String("out", "", "file to write the ciphertext to")
+	fs.Parse(args)
+	if *in == "" || *out == "" {
+		return fmt.Errorf("-in and -out are required")
+	}
+
+	s, err := readSecretKey(*dir)
+	if err != nil {
+		return err
+	}
+	values, jsonTag, err := readValues(*in)
+	if err != nil {
+		return err
+	}
+	if len(values) == 0 {
+		return fmt.Errorf("%s: no values to encrypt", *in)
+	}
+	if jsonTag != "" {
+		*tag = jsonTag
+	}
+	if *tag == "" {
+		return fmt.Errorf("a dataset tag is required, with -tag or in the JSON file")
+	}
+
+	nSlots := s.nSlots()
+	data := ciphertextData{DatasetTag: []byte(*tag), Length: len(values)}
+	tags := vche.GetChunkedIndexTags(data.DatasetTag, data.Length, nSlots)
+	encoder, encryptor := s.encoder(), s.encryptor()
+	for c, chunk := range vche.ChunkInt(values, nSlots) {
+		ct := encryptor.EncryptNew(encoder.EncodeIntNew(chunk, tags[c]))
+		b, err := ct.(encoding.BinaryMarshaler).MarshalBinary()
+		if err != nil {
+			return err
+		}
+		data.Chunks = append(data.Chunks, b)
+	}
+	return writeGob(*out, &data)
+}
+
+func eval(args []string) error {
+	fs := flag.NewFlagSet("eval", flag.ExitOnError)
+	dir := fs.String("keys", "keys", "key directory (only params.json and eval.key are used)")
+	circuitPath := fs.String("circuit", "", "circuit to evaluate")
+	out := fs.String("out", "", "file to write the outputs of the circuit to")
+	fs.Parse(args)
+	if *circuitPath == "" || *out == "" {
+		return fmt.Errorf("-circuit and -out are required")
+	}
+
+	s, evaluator, err := readEvalKey(*dir)
+	if err != nil {
+		return err
+	}
+	c, err := readCircuit(*circuitPath)
+	if err != nil {
+		return err
+	}
+	inputs, err := readInputs(c, fs.Args())
+	if err != nil {
+		return err
+	}
+
+	result := resultData{Outputs: make([]ciphertextData, c.NumOutputs())}
+	for o := range result.Outputs {
+		result.Outputs[o] = ciphertextData{DatasetTag: inputs[0].DatasetTag, Length: inputs[0].Length}
+	}
+	for chunk := range inputs[0].Chunks {
+		cts := make([]interface{}, len(inputs))
+		for i, in := range inputs {
+			ct := s.newCiphertext()
+			if err := ct.UnmarshalBinary(in.Chunks[chunk]); err != nil {
+				return err
+			}
+			cts[i] = ct
+		}
+		for o, ct := range c.Eval(evaluator, cts...) {
+			b, err := ct.(encoding.BinaryMarshaler).MarshalBinary()
+			if err != nil {
+				return err
+			}
+			result.Outputs[o].Chunks = append(result.Outputs[o].Chunks, b)
+		}
+	}
+	return writeGob(*out, &result)
+}
+
+func decrypt(args []string) (err error) {
+	fs := flag.NewFlagSet("decrypt", flag.ExitOnError)
+	dir := fs.String("keys", "keys", "key directory")
+	circuitPath := fs.String("circuit", "", "circuit that the server evaluated")
+	resultPath := fs.String("result", "", "outputs of the circuit, as written by eval")
+	signed := fs.Bool("signed", false, "decode the results as signed integers")
+	out := fs.String("out", "", "CSV file to write the results to, one line per output (defaults to the standard output)")
+	fs.Parse(args)
+	if *circuitPath == "" || *resultPath == "" {
+		return fmt.Errorf("-circuit and -result are required")
+	}
+
+	s, err := readSecretKey(*dir)
+	if err != nil {
+		return err
+	}
+	c, err := readCircuit(*circuitPath)
+	if err != nil {
+		return err
+	}
+	inputs, err := readInputs(c, fs.Args())
+	if err != nil {
+		return err
+	}
+	var result resultData
+	if err := readGob(*resultPath, &result); err != nil {
+		return err
+	}
+	if len(result.Outputs) != c.NumOutputs() {
+		return fmt.Errorf("circuit has %d outputs, result has %d", c.NumOutputs(), len(result.Outputs))
+	}
+
+	nSlots := s.nSlots()
+	tags := make([][][]vche.Tag, len(inputs))
+	for i, in := range inputs {
+		tags[i] = vche.GetChunkedIndexTags(in.DatasetTag, in.Length, nSlots)
+	}
+
+	// The decoders panic with an error wrapping vche.ErrVerification when the results do not pass the verification;
+	// other panics are bugs, and are not reported as verification failures
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(error); ok && errors.Is(e, vche.ErrVerification) {
+				err = e
+				return
+			}
+			panic(r)
+		}
+	}()
+	encoder, decryptor := s.encoder(), s.decryptor()
+	encoderPlaintext, evaluatorPlaintext := s.encoderPlaintext(), s.evaluatorPlaintext()
+	lines := make([][]string, c.NumOutputs())
+	for chunk := range inputs[0].Chunks {
+		verifs := make([]interface{}, len(inputs))
+		for i := range inputs {
+			verifs[i] = encoderPlaintext.EncodeNew(tags[i][chunk])
+		}
+		verifs = c.Eval(evaluatorPlaintext, verifs...)
+		for o, output := range result.Outputs {
+			verif := s.finalize(verifs[o])
+			if len(output.Chunks) != len(inputs[0].Chunks) {
+				return fmt.Errorf("output %d has %d chunks, expected %d", o, len(output.Chunks), len(inputs[0].Chunks))
+			}
+			ct := s.newCiphertext()
+			if err := ct.UnmarshalBinary(output.Chunks[chunk]); err != nil {
+				return err
+			}
+			pt := decryptor.DecryptNew(ct)
+			n := len(tags[0][chunk])
+			if *signed {
+				for _, v := range encoder.DecodeIntNew(pt, verif)[:n] {
+					lines[o] = append(lines[o], fmt.Sprint(v))
+				}
+			} else {
+				for _, v := range encoder.DecodeUintNew(pt, verif)[:n] {
+					lines[o] = append(lines[o], fmt.Sprint(v))
+				}
+			}
+		}
+	}
+
+	var csv strings.Builder
+	for _, line := range lines {
+		csv.WriteString(strings.Join(line, ",") + "\n")
+	}
+	if *out == "" {
+		_, err = fmt.Print(csv.String())
+		return err
+	}
+	return ioutil.WriteFile(*out, []byte(csv.String()), 0644)
+}
+
+// readInputs reads the ciphertext files of the inputs of c, which must all have the same length.
+func readInputs(c *vche.Circuit, paths []string) ([]ciphertextData, error) {
+	if len(paths) != c.NumInputs() {
+		return nil, fmt.Errorf("circuit expects %d inputs, got %d", c.NumInputs(), len(paths))
+	}
+	inputs := make([]ciphertextData, len(paths))
+	for i, path := range paths {
+		if err := readGob(path, &inputs[i]); err != nil {
+			return nil, err
+		}
+		if inputs[i].Length != inputs[0].Length || len(inputs[i].Chunks) != len(inputs[0].Chunks) {
+			return nil, fmt.Errorf("%s: inputs should have the same length, got %d and %d", path, inputs[i].Length, inputs[0].Length)
+		}
+	}
+	return inputs, nil
+}
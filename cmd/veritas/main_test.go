@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"veritas/vche/vche"
+)
+
+func TestRoundTrip(t *testing.T) {
+	// 3 * x * y - y, and its inner sum
+	c := vche.NewCircuit()
+	x, y := c.Input(), c.Input()
+	w := c.Sub(c.MulScalar(c.Relinearize(c.Mul(x, y)), 3), y)
+	c.Output(w, c.InnerSum(w))
+
+	valuesX := []int64{1, 2, 3, 4, 5}
+	valuesY := []int64{7, 0, 11, 2, 9}
+	expected := make([]string, len(valuesX))
+	sum := int64(0)
+	for i := range valuesX {
+		v := 3*valuesX[i]*valuesY[i] - valuesY[i]
+		expected[i] = fmt.Sprint(v)
+		sum += v
+	}
+
+	for _, encoding := range []string{"rep", "pe"} {
+		for _, cfprf := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/CFPRF=%v", encoding, cfprf), func(t *testing.T) {
+				dir := t.TempDir()
+				path := func(name string) string { return filepath.Join(dir, name) }
+				require.NoError(t, ioutil.WriteFile(path("circuit.txt"), []byte(c.String()), 0644))
+				require.NoError(t, ioutil.WriteFile(path("x.csv"), []byte("1,2,3\n4,5\n"), 0644))
+				require.NoError(t, writeJSON(path("y.json"), map[string]interface{}{"tag": "y", "values": valuesY}))
+
+				args := []string{"-encoding", encoding, "-circuit", path("circuit.txt"), "-keys", path("keys")}
+				if cfprf {
+					args = append(args, "-cfprf")
+				}
+				require.NoError(t, keygen(args))
+				require.NoError(t, encrypt([]string{"-keys", path("keys"), "-in", path("x.csv"), "-tag", "x", "-out", path("x.bin")}))
+				require.NoError(t, encrypt([]string{"-keys", path("keys"), "-in", path("y.json"), "-out", path("y.bin")}))
+				require.NoError(t, eval([]string{"-keys", path("keys"), "-circuit", path("circuit.txt"), "-out", path("result.bin"), path("x.bin"), path("y.bin")}))
+
+				decryptArgs := func(result string) []string {
+					return []string{"-keys", path("keys"), "-circuit", path("circuit.txt"), "-result", path(result), "-signed", "-out", path("result.csv"), path("x.bin"), path("y.bin")}
+				}
+				require.NoError(t, decrypt(decryptArgs("result.bin")))
+				data, err := ioutil.ReadFile(path("result.csv"))
+				require.NoError(t, err)
+				lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+				require.Len(t, lines, 2)
+				require.Equal(t, strings.Join(expected, ","), lines[0])
+				require.Equal(t, fmt.Sprint(sum), strings.Split(lines[1], ",")[0])
+
+				// A result replaced by an input does not pass the verification
+				var result resultData
+				require.NoError(t, readGob(path("result.bin"), &result))
+				var input ciphertextData
+				require.NoError(t, readGob(path("y.bin"), &input))
+				result.Outputs[0].Chunks[0] = input.Chunks[0]
+				require.NoError(t, writeGob(path("forged.bin"), &result))
+				err = decrypt(decryptArgs("forged.bin"))
+				require.ErrorIs(t, err, vche.ErrVerification)
+
+				// A truncated result is rejected, but is not a verification failure
+				result.Outputs[0].Chunks[0] = input.Chunks[0][:len(input.Chunks[0])-1]
+				require.NoError(t, writeGob(path("truncated.bin"), &result))
+				err = decrypt(decryptArgs("truncated.bin"))
+				require.Error(t, err)
+				require.NotErrorIs(t, err, vche.ErrVerification)
+			})
+		}
+	}
+}
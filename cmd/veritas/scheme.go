@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding"
+	"fmt"
+
+	"github.com/ldsec/lattigo/v2/rlwe"
+	"golang.org/x/crypto/blake2b"
+	"veritas/vche/vche"
+	"veritas/vche/vche_1"
+	"veritas/vche/vche_2"
+)
+
+// scheme gives access to the generic components of an encoding, so that the subcommands do not depend on it.
+type scheme interface {
+	nSlots() int
+	// setSecretKey derives the secret key of the client from kdf.
+	setSecretKey(kdf vche.KDF)
+	// genEvaluationKey generates the evaluation key of the server, with the rotation keys for galEls.
+	genEvaluationKey(galEls []uint64) *rlwe.EvaluationKey
+	evaluator(evk *rlwe.EvaluationKey) vche.GenericEvaluator
+	encoder() vche.GenericEncoder
+	encryptor() vche.GenericEncryptor
+	decryptor() vche.GenericDecryptor
+	encoderPlaintext() vche.GenericEncoderPlaintext
+	evaluatorPlaintext() vche.GenericEvaluator
+	// finalize turns the output of evaluatorPlaintext into the verification state expected by the decoder.
+	finalize(verif interface{}) interface{}
+	newCiphertext() encoding.BinaryUnmarshaler
+}
+
+func newScheme(cfg *config) (scheme, error) {
+	params, err := vche.NewParametersFromLiteral(cfg.Params)
+	if err != nil {
+		return nil, err
+	}
+	switch cfg.Encoding {
+	case "rep":
+		return &rep{params: params, cfprf: cfg.CFPRF}, nil
+	case "pe":
+		return &pe{params: params, cfprf: cfg.CFPRF}, nil
+	default:
+		return nil, fmt.Errorf("unknown encoding %q, expected rep or pe", cfg.Encoding)
+	}
+}
+
+// rep is the replication encoding (vche_1).
+type rep struct {
+	params vche.Parameters
+	cfprf  bool
+	sk     *vche_1.SecretKey
+}
+
+func (s *rep) nSlots() int {
+	return s.params.NSlots
+}
+
+func (s *rep) setSecretKey(kdf vche.KDF) {
+	s.sk = vche_1.NewKeyGenerator(s.params).GenSecretKeyFromKDF(kdf)
+}
+
+func (s *rep) genEvaluationKey(galEls []uint64) *rlwe.EvaluationKey {
+	kgen := vche_1.NewKeyGenerator(s.params)
+	evk := &rlwe.EvaluationKey{Rlk: kgen.GenRelinearizationKey(s.sk, 1).RelinearizationKey}
+	if len(galEls) > 0 {
+		evk.Rtks = kgen.GenRotationKeys(galEls, s.sk).RotationKeySet
+	}
+	return evk
+}
+
+func (s *rep) evaluator(evk *rlwe.EvaluationKey) vche.GenericEvaluator {
+	H, err := blake2b.New256(nil)
+	if err != nil {
+		panic(err)
+	}
+	return vche_1.NewGenericEvaluator(s.params, &vche_1.EvaluationKey{EvaluationKey: *evk, H: H})
+}
+
+func (s *rep) encoder() vche.GenericEncoder {
+	return vche_1.NewGenericEncoder(s.params, s.sk.K, s.sk.S, s.cfprf)
+}
+
+func (s *rep) encryptor() vche.GenericEncryptor {
+	return vche_1.NewGenericEncryptor(s.params, s.sk)
+}
+
+func (s *rep) decryptor() vche.GenericDecryptor {
+	return vche_1.NewGenericDecryptor(s.params, s.sk)
+}
+
+func (s *rep) encoderPlaintext() vche.GenericEncoderPlaintext {
+	if s.cfprf {
+		return vche_1.NewGenericEncoderPlaintextCFPRF(s.params, s.sk.K)
+	}
+	return vche_1.NewGenericEncoderPlaintext(s.params, s.sk.K)
+}
+
+func (s *rep) evaluatorPlaintext() vche.GenericEvaluator {
+	if s.cfprf {
+		return vche_1.NewGenericEvaluatorPlaintextCFPRF(s.params, s.sk.H)
+	}
+	return vche_1.NewGenericEvaluatorPlaintext(s.params, s.sk.H)
+}
+
+func (s *rep) finalize(verif interface{}) interface{} {
+	if s.cfprf {
+		eval := vche_1.NewEvaluatorPlaintextCFPRF(s.params, s.sk.H)
+		eval.ComputeMemo(verif.(*vche_1.VerifPlaintext))
+		return eval.Eval(verif.(*vche_1.VerifPlaintext))
+	}
+	return verif
+}
+
+func (s *rep) newCiphertext() encoding.BinaryUnmarshaler {
+	return new(vche_1.Ciphertext)
+}
+
+// pe is the polynomial encoding (vche_2).
+type pe struct {
+	params vche.Parameters
+	cfprf  bool
+	sk     *vche_2.SecretKey
+}
+
+func (s *pe) nSlots() int {
+	return s.params.NSlots
+}
+
+func (s *pe) setSecretKey(kdf vche.KDF) {
+	s.sk = vche_2.NewKeyGenerator(s.params).GenSecretKeyFromKDF(kdf)
+}
+
+func (s *pe) genEvaluationKey(galEls []uint64) *rlwe.EvaluationKey {
+	kgen := vche_2.NewKeyGenerator(s.params)
+	evk := &rlwe.EvaluationKey{Rlk: kgen.GenRelinearizationKey(s.sk, 1)}
+	if len(galEls) > 0 {
+		evk.Rtks = kgen.GenRotationKeys(galEls, s.sk)
+	}
+	return evk
+}
+
+func (s *pe) evaluator(evk *rlwe.EvaluationKey) vche.GenericEvaluator {
+	return vche_2.NewGenericEvaluator(s.params, evk)
+}
+
+func (s *pe) encoder() vche.GenericEncoder {
+	return vche_2.NewGenericEncoder(s.params, s.sk.K, s.sk.Alpha, s.cfprf)
+}
+
+func (s *pe) encryptor() vche.GenericEncryptor {
+	return vche_2.NewGenericEncryptor(s.params, s.sk)
+}
+
+func (s *pe) decryptor() vche.GenericDecryptor {
+	return vche_2.NewGenericDecryptor(s.params, s.sk)
+}
+
+func (s *pe) encoderPlaintext() vche.GenericEncoderPlaintext {
+	if s.cfprf {
+		return vche_2.NewGenericEncoderPlaintextCFPRF(s.params, s.sk.K)
+	}
+	return vche_2.NewGenericEncoderPlaintext(s.params, s.sk.K)
+}
+
+func (s *pe) evaluatorPlaintext() vche.GenericEvaluator {
+	if s.cfprf {
+		return vche_2.NewGenericEvaluatorPlaintextCFPRF(s.params)
+	}
+	return vche_2.NewGenericEvaluatorPlaintext(s.params)
+}
+
+func (s *pe) finalize(verif interface{}) interface{} {
+	if s.cfprf {
+		eval := vche_2.NewEvaluatorPlaintextCFPRF(s.params)
+		eval.ComputeMemo(verif.(*vche_2.VerifPlaintext))
+		return eval.Eval(verif.(*vche_2.VerifPlaintext))
+	}
+	return verif
+}
+
+func (s *pe) newCiphertext() encoding.BinaryUnmarshaler {
+	return new(vche_2.Ciphertext)
+}
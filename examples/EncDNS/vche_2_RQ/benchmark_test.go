@@ -221,8 +221,8 @@ func benchEval(ctListKeys, ctListVals []*vche_2.Ciphertext, encryptedOne, encryp
 	numPacked int, b *testing.B) (*vche_2.Ciphertext, *vche_2.Poly) {
 	numVec := len(ctListKeys)
 
-	numCtxtsC_SP := 0
-	numCtxtsSP_C := 0
+	session := vche.NewSession()
+	verifier := verifier.WithSession(session)
 	numRuns := 0
 
 	// Perform the search
@@ -237,10 +237,6 @@ func benchEval(ctListKeys, ctListVals []*vche_2.Ciphertext, encryptedOne, encryp
 				b.StopTimer()
 				evaluatorPlaintext.Mul(verifListKeys[i], verifQuery, tempVerif2)
 				evaluatorPlaintext.Relinearize(tempVerif2, verifMask)
-				if len(ctMask.Ciphertexts) > 3 {
-					numCtxtsSP_C += len(ctMask.Ciphertexts) - 3
-					numCtxtsC_SP += 2
-				}
 				b.StartTimer()
 				ctMask, verifMask = vche_2.BenchmarkRequadratizationProtocolProver(prover, verifier, ctMask, verifMask, b)
 				b.StartTimer()
@@ -273,10 +269,6 @@ func benchEval(ctListKeys, ctListVals []*vche_2.Ciphertext, encryptedOne, encryp
 					evaluatorPlaintext.RotateColumns(verifMask, 1<<(j+1), tempVerif1)
 					evaluatorPlaintext.Mul(verifMask, tempVerif1, tempVerif2)
 					evaluatorPlaintext.Relinearize(tempVerif2, verifMask)
-					if len(ctMask.Ciphertexts) > 3 {
-						numCtxtsSP_C += len(ctMask.Ciphertexts) - 3
-						numCtxtsC_SP += 2
-					}
 					b.StartTimer()
 					ctMask, verifMask = vche_2.BenchmarkRequadratizationProtocolProver(prover, verifier, ctMask, verifMask, b)
 					b.StartTimer()
@@ -310,10 +302,6 @@ func benchEval(ctListKeys, ctListVals []*vche_2.Ciphertext, encryptedOne, encryp
 				b.StopTimer()
 				evaluatorPlaintext.Mul(verifMask, verifListVals[i], tempVerif2)
 				evaluatorPlaintext.Relinearize(tempVerif2, tempVerif1)
-				if len(tempCt1.Ciphertexts) > 3 {
-					numCtxtsSP_C += len(tempCt1.Ciphertexts) - 3
-					numCtxtsC_SP += 2
-				}
 				b.StartTimer()
 				tempCt1, tempVerif1 = vche_2.BenchmarkRequadratizationProtocolProver(prover, verifier, tempCt1, tempVerif1, b)
 				b.StartTimer()
@@ -357,16 +345,17 @@ func benchEval(ctListKeys, ctListVals []*vche_2.Ciphertext, encryptedOne, encryp
 			b.StartTimer()
 		}
 	})
+	report := session.Report()
 	b.Run(benchmarkString("Communication/Client->SP/Requad"), func(b *testing.B) {
-		numCtxts := float64(numCtxtsC_SP) / float64(numRuns)
-		b.ReportMetric(numCtxts, "BFV-ctxt")
-		// TODO: find a way to log the size in bytes
+		traffic := report.Get(vche.ClientToServer, vche.RequadratizationMessage)
+		b.ReportMetric(float64(traffic.Messages)/float64(numRuns), "BFV-ctxt")
+		b.ReportMetric(float64(traffic.Bytes)/float64(numRuns), "B")
 		b.ReportMetric(0.0, "ns/op")
 	})
 	b.Run(benchmarkString("Communication/SP->Client/Requad"), func(b *testing.B) {
-		numCtxts := float64(numCtxtsSP_C) / float64(numRuns)
-		b.ReportMetric(numCtxts, "BFV-ctxt")
-		// TODO: find a way to log the size in bytes
+		traffic := report.Get(vche.ServerToClient, vche.RequadratizationMessage)
+		b.ReportMetric(float64(traffic.Messages)/float64(numRuns), "BFV-ctxt")
+		b.ReportMetric(float64(traffic.Bytes)/float64(numRuns), "B")
 		b.ReportMetric(0.0, "ns/op")
 	})
 
@@ -378,8 +367,8 @@ func benchVerif(ctListKeys, ctListVals []*vche_2.Ciphertext, encryptedOne, encry
 	numPacked int, b *testing.B) {
 	numVec := len(ctListKeys)
 
-	numCtxtsC_SP := 0
-	numCtxtsSP_C := 0
+	session := vche.NewSession()
+	verifier := verifier.WithSession(session)
 	numRuns := 0
 
 	// Perform the search
@@ -390,10 +379,6 @@ func benchVerif(ctListKeys, ctListVals []*vche_2.Ciphertext, encryptedOne, encry
 				b.StopTimer()
 				evaluator.Mul(ctListKeys[i], encryptedQuery, tempCt2)
 				evaluator.Relinearize(tempCt2, ctMask)
-				if len(ctMask.Ciphertexts) > 3 {
-					numCtxtsSP_C += len(ctMask.Ciphertexts) - 3
-					numCtxtsC_SP += 2
-				}
 				b.StartTimer()
 				evaluatorPlaintext.Mul(verifListKeys[i], verifQuery, tempVerif2)
 				evaluatorPlaintext.Relinearize(tempVerif2, verifMask)
@@ -417,10 +402,6 @@ func benchVerif(ctListKeys, ctListVals []*vche_2.Ciphertext, encryptedOne, encry
 					evaluator.RotateColumns(ctMask, 1<<(j+1), tempCt1)
 					evaluator.Mul(ctMask, tempCt1, tempCt2)
 					evaluator.Relinearize(tempCt2, ctMask)
-					if len(ctMask.Ciphertexts) > 3 {
-						numCtxtsSP_C += len(ctMask.Ciphertexts) - 3
-						numCtxtsC_SP += 2
-					}
 					b.StartTimer()
 
 					evaluatorPlaintext.RotateColumns(verifMask, 1<<(j+1), tempVerif1)
@@ -451,10 +432,6 @@ func benchVerif(ctListKeys, ctListVals []*vche_2.Ciphertext, encryptedOne, encry
 				b.StopTimer()
 				evaluator.Mul(ctMask, ctListVals[i], tempCt2)
 				evaluator.Relinearize(tempCt2, tempCt1)
-				if len(tempCt1.Ciphertexts) > 3 {
-					numCtxtsSP_C += len(tempCt1.Ciphertexts) - 3
-					numCtxtsC_SP += 2
-				}
 				b.StartTimer()
 
 				evaluatorPlaintext.Mul(verifMask, verifListVals[i], tempVerif2)
@@ -7,6 +7,7 @@ import (
 	"veritas/vche/bfv_generic"
 	"veritas/vche/examples/NeuralNetworkInference"
 	"veritas/vche/examples/NeuralNetworkInference/neural_network"
+	"veritas/vche/nn"
 	"veritas/vche/vche"
 	"testing"
 )
@@ -23,11 +24,11 @@ func Benchmark(b *testing.B) {
 	relinKey := keygen.GenRelinearizationKey(sk, 1)
 	evk := rlwe.EvaluationKey{Rlk: relinKey, Rtks: rotKeys}
 
-	modelParams := neural_network.ModelParams{
+	backend := nn.Backend{
 		Encryptor:          bfv_generic.NewGenericEncryptor(params, sk),
 		Decryptor:          bfv_generic.NewGenericDecryptor(params, sk),
 		Encoder:            bfv_generic.NewGenericEncoder(params),
-		EncoderVerif:       nil,
+		EncoderPlaintext:   nil,
 		Evaluator:          bfv_generic.NewGenericEvaluator(params, evk),
 		EvaluatorPlaintext: nil,
 		Parameters: vche.Parameters{
@@ -38,12 +39,12 @@ func Benchmark(b *testing.B) {
 		},
 	}
 
-	model := neural_network.NewModelSmall(modelParams)
+	model := neural_network.NewModelSmall(backend)
 
 	img := NeuralNetworkInference.MNISTTestImages[0]
-	tags := NeuralNetworkInference.GenTags(0, NeuralNetworkInference.MNISTTestLabels[0], 25, params.N())
+	datasetTag := NeuralNetworkInference.DatasetTag(0, NeuralNetworkInference.MNISTTestLabels[0])
 
-	x := benchEnc(model, img, tags, b)
+	x := benchEnc(model, neural_network.Normalize(img), datasetTag, b)
 
 	res := benchEval(model, x, b)
 
@@ -52,23 +53,23 @@ func Benchmark(b *testing.B) {
 	NeuralNetworkInference.CheckResult(preds, NeuralNetworkInference.MNISTTestLabels[0])
 }
 
-func benchEnc(model neural_network.Model, img [][]uint64, tags [][]vche.Tag, b *testing.B) []interface{} {
+func benchEnc(model *nn.Model, img [][]float64, datasetTag []byte, b *testing.B) []interface{} {
 	var x []interface{}
 
 	b.Run(benchmarkString("Encode"), func(b *testing.B) {
 		for i := 0; i < b.N; i++ {
-			x = neural_network.Encode(model, img, tags)
+			x = neural_network.Encode(model, img, datasetTag)
 		}
 	})
 
 	ctxts := make([]interface{}, len(x))
 	for i := range ctxts {
-		ctxts[i] = bfv.NewCiphertext(model.Parameters().Parameters, 1)
+		ctxts[i] = bfv.NewCiphertext(model.Parameters.Parameters, 1)
 	}
 	b.Run(benchmarkString("Encrypt"), func(b *testing.B) {
 		for run := 0; run < b.N; run++ {
 			for i := range x {
-				model.Encryptor().Encrypt(x[i], ctxts[i])
+				model.Encryptor.Encrypt(x[i], ctxts[i])
 			}
 		}
 	})
@@ -82,18 +83,18 @@ func benchEnc(model neural_network.Model, img [][]uint64, tags [][]vche.Tag, b *
 	return ctxts
 }
 
-func benchEval(model neural_network.Model, x []interface{}, b *testing.B) []interface{} {
+func benchEval(model *nn.Model, x []interface{}, b *testing.B) []interface{} {
 	var res []interface{}
 	b.Run(benchmarkString("Eval"), func(b *testing.B) {
 		for i := 0; i < b.N; i++ {
-			res = neural_network.Eval(model, x)
+			res = model.Eval(x)
 
 		}
 	})
 	return res
 }
 
-func benchDec(model neural_network.Model, res []interface{}, verif []interface{}, b *testing.B) []float64 {
+func benchDec(model *nn.Model, res []interface{}, verif []interface{}, b *testing.B) []float64 {
 	b.Run(benchmarkString("Communication/SP->Client"), func(b *testing.B) {
 		b.ReportMetric(float64(len(res)), "BFV-ctxt")
 		b.ReportMetric(float64(size.Of(res)), "bytes")
@@ -102,41 +103,41 @@ func benchDec(model neural_network.Model, res []interface{}, verif []interface{}
 
 	var ptxts = make([]*bfv.Plaintext, len(res))
 	for i := range ptxts {
-		ptxts[i] = bfv.NewPlaintext(model.Parameters().Parameters)
+		ptxts[i] = bfv.NewPlaintext(model.Parameters.Parameters)
 	}
 	var values = make([][]int64, len(res))
 	for i := range values {
-		values[i] = make([]int64, model.Parameters().NSlots)
+		values[i] = make([]int64, model.Parameters.NSlots)
 	}
 
 	b.Run(benchmarkString("Decrypt"), func(b *testing.B) {
 		for run := 0; run < b.N; run++ {
 			for i := range res {
-				model.Decryptor().Decrypt(res[i], ptxts[i])
+				model.Decryptor.Decrypt(res[i], ptxts[i])
 			}
 		}
 	})
 
 	ptxtCopies := make([]*bfv.Plaintext, len(ptxts))
 	for i := range ptxts {
-		ptxtCopies[i] = bfv.NewPlaintext(model.Parameters().Parameters)
+		ptxtCopies[i] = bfv.NewPlaintext(model.Parameters.Parameters)
 		ptxtCopies[i].Plaintext.Copy(ptxts[i].Plaintext)
 	}
 
 	b.Run(benchmarkString("Decode"), func(b *testing.B) {
 		for run := 0; run < b.N; run++ {
 			for i := range res {
-				model.Encoder().DecodeInt(ptxts[i], nil, values[i])
+				model.Encoder.DecodeInt(ptxts[i], nil, values[i])
 			}
 		}
 	})
 
 	for i := range res {
-		model.Encoder().DecodeInt(ptxtCopies[i], nil, values[i])
+		model.Encoder.DecodeInt(ptxtCopies[i], nil, values[i])
 	}
 
 	preds := make([]float64, len(res))
-	outScale := model.EvalLayers()[len(model.EvalLayers())-1].OutputScale()
+	outScale := model.Scale()
 
 	for i := range values {
 		preds[i] = float64(values[i][0]) / float64(outScale)
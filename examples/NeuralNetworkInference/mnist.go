@@ -3,7 +3,6 @@ package NeuralNetworkInference
 import (
 	"fmt"
 	"github.com/ldsec/lattigo/v2/bfv"
-	"veritas/vche/vche"
 	"github.com/petar/GoMNIST"
 	"image/color"
 	"math"
@@ -44,13 +43,9 @@ func init() {
 	}
 }
 
-func GenTags(imgIdx int, label uint64, numConvs, NSlots int) [][]vche.Tag {
-	tags := make([][]vche.Tag, numConvs)
-	for i := range tags {
-		datasetTag := []byte(fmt.Sprintf("MNIST-idx=%d-lbl=%d-%d", imgIdx, label, i))
-		tags[i] = vche.GetIndexTags(datasetTag, NSlots)
-	}
-	return tags
+// DatasetTag returns the dataset tag of an image, see nn.Im2Col.Tags.
+func DatasetTag(imgIdx int, label uint64) []byte {
+	return []byte(fmt.Sprintf("MNIST-idx=%d-lbl=%d", imgIdx, label))
 }
 
 func CheckResult(preds []float64, trueLabel uint64) {
@@ -0,0 +1,107 @@
+package neural_network
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"veritas/vche/nn"
+	"veritas/vche/scheme"
+	"veritas/vche/vche_1"
+)
+
+// quantize returns the values of v multiplied by scale and rounded, as by nn.Rescale before the reduction modulo T.
+func quantize(v []float64, scale uint64) []int64 {
+	res := make([]int64, len(v))
+	for i := range v {
+		res[i] = int64(math.Round(v[i] * float64(scale)))
+	}
+	return res
+}
+
+// referenceSmall evaluates the small LoLa network on integers as in neural_network_python/model_small.py: a
+// convolution with a padding of one pixel, whose outputs are flattened by map and squared, and a dense layer. It
+// returns the predictions and the largest absolute value of the intermediate results.
+func referenceSmall(img [][]uint64) ([]float64, int64) {
+	var max int64
+	track := func(v int64) int64 {
+		if v > max {
+			max = v
+		} else if -v > max {
+			max = -v
+		}
+		return v
+	}
+
+	convScale := imgScaleSmall * weightsScaleSmall
+	w, b := quantize(weightsConvSmall.Data, weightsScaleSmall), quantize(biasConvSmall.Data, convScale)
+	hOut, wOut := InputSmall.OutputShape()
+	var x []int64
+	for m := range b {
+		for i := 0; i < hOut; i++ {
+			for j := 0; j < wOut; j++ {
+				v := b[m]
+				for ki := 0; ki < 5; ki++ {
+					for kj := 0; kj < 5; kj++ {
+						r, c := i*4+ki-1, j*4+kj-1
+						if r >= 0 && r < 28 && c >= 0 && c < 28 {
+							px := int64(math.Round(float64(img[r][c]) / 256 * float64(imgScaleSmall)))
+							v += w[m*25+ki*5+kj] * px
+						}
+					}
+				}
+				x = append(x, track(track(v)*v))
+			}
+		}
+	}
+
+	scale := convScale * convScale * weightsScaleSmall
+	W, B := quantize(weightLinSmall.Data, weightsScaleSmall), quantize(biasLinSmall.Data, convScale*convScale*weightsScaleSmall)
+	preds := make([]float64, len(B))
+	for r := range preds {
+		v := B[r]
+		for n := range x {
+			v += W[r*len(x)+n] * x[n]
+		}
+		preds[r] = float64(track(v)) / float64(scale)
+	}
+	return preds, max
+}
+
+func TestModelSmall(t *testing.T) {
+	want, max := referenceSmall(GetImage())
+
+	// A plaintext modulus of 32 bits holds the intermediate values, and two replications leave room for the 256 slots
+	// of the dense layer
+	literal := vche_1.DefaultParams[2]
+	literal.T = 4295294977
+	literal.NumReplications = 2
+	require.Less(t, float64(max), float64(literal.T/2))
+
+	s, err := scheme.New(scheme.Config{Encoding: scheme.REP, Params: literal, Rotations: RotsSmall})
+	require.NoError(t, err)
+	m := NewModelSmall(nn.NewBackend(s))
+	require.ElementsMatch(t, RotsSmall, m.Rotations())
+
+	datasetTag := []byte("img0")
+	res := m.Eval(m.EncryptImage(Normalize(GetImage()), datasetTag))
+	preds := m.Decode(res, m.Verif(m.VerifImage(datasetTag)))
+	require.InDeltaSlice(t, want, preds, 1e-9)
+
+	// The image is a zero
+	for i := range preds {
+		require.LessOrEqual(t, preds[i], preds[0])
+	}
+}
+
+func TestModel(t *testing.T) {
+	literal := vche_1.DefaultParams[1]
+	literal.NumReplications = 2
+	s, err := scheme.New(scheme.Config{Encoding: scheme.REP, Params: literal})
+	require.NoError(t, err)
+
+	// ((input * conv)^2 * fc1)^2 * fc2
+	m := NewModel(nn.NewBackend(s))
+	require.Equal(t, uint64(((2*2)*(2*2)*2)*((2*2)*(2*2)*2)*2), m.Scale())
+	require.Equal(t, 13*13, m.Input.NumOutputs())
+}
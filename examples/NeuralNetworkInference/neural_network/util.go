@@ -3,75 +3,46 @@ package neural_network
 import (
 	"encoding/csv"
 	"fmt"
-	"veritas/vche/vche"
+	"veritas/vche/nn"
 	"veritas/vche/vche_2"
-	"math"
 	"os"
 	"strconv"
 	"testing"
 )
 
-type Model interface {
-	// Hack to cope with Go's lack of generics
-	// Encryption / Decryption
-	Encryptor() vche.GenericEncryptor
-	Decryptor() vche.GenericDecryptor
-	// Encoding / Decoding
-	Encoder() vche.GenericEncoder
-	EncoderPlaintext() vche.GenericEncoderPlaintext
-	// Evaluation
-	Evaluator() vche.GenericEvaluator
-	EvaluatorPlaintext() vche.GenericEvaluator
-	// Other methods
-	Parameters() vche.Parameters
-	EncodeLayer() Layer
-	EvalLayers() []Layer
-}
-
-type ModelParams struct {
-	Encryptor          vche.GenericEncryptor
-	Decryptor          vche.GenericDecryptor
-	Encoder            vche.GenericEncoder
-	EncoderVerif       vche.GenericEncoderPlaintext
-	Evaluator          vche.GenericEvaluator
-	EvaluatorPlaintext vche.GenericEvaluator
-	Parameters         vche.Parameters
-}
-
-func Encode(model Model, img [][]uint64, tags [][]vche.Tag) []interface{} {
-	in := struct {
-		img [][]uint64
-		tag [][]vche.Tag
-	}{img, tags}
-	var x interface{} = in
-	x = model.EncodeLayer().Eval(x)
-	return x.([]interface{})
+// Normalize returns the pixels of an image divided by 256, as expected by the models.
+func Normalize(img [][]uint64) [][]float64 {
+	res := make([][]float64, len(img))
+	for i := range img {
+		res[i] = make([]float64, len(img[i]))
+		for j := range img[i] {
+			res[i][j] = float64(img[i][j]) / 256
+		}
+	}
+	return res
 }
 
-func Eval(model Model, x interface{}) []interface{} {
-	for _, l := range model.EvalLayers() {
-		x = l.Eval(x)
+// Encode packs and encodes an image with the given dataset tag, see nn.Model.EncryptImage.
+func Encode(model *nn.Model, img [][]float64, datasetTag []byte) []interface{} {
+	vecs, tags := model.Input.Pack(model.Parameters, img, model.InputScale), model.Input.Tags(datasetTag)
+	x := make([]interface{}, len(vecs))
+	for i := range vecs {
+		x[i] = model.Encoder.EncodeUintNew(vecs[i], tags[i])
 	}
-	return x.([]interface{})
+	return x
 }
 
-func BenchEvalRequadProver(model Model, prover vche_2.Prover, verifier vche_2.Verifier, ctxt interface{}, verif interface{}, b *testing.B) (ctxt2 interface{}, verif2 interface{}, numCtxtsC_SP, numCtxtsSP_C int) {
-	numCtxtsC_SP = 0
-	numCtxtsSP_C = 0
+// BenchEvalRequadProver evaluates the model, requadratizing the ciphertexts after each square. The messages of the
+// requadratization protocol are recorded in the session of the verifier, if any.
+func BenchEvalRequadProver(model *nn.Model, prover vche_2.Prover, verifier vche_2.Verifier, ctxt interface{}, verif interface{}, b *testing.B) (interface{}, interface{}) {
 	b.StartTimer()
-	for _, layer := range model.EvalLayers() {
+	for _, layer := range model.Layers {
 		switch l := layer.(type) {
-		case Square:
+		case *nn.Square:
 			ctxt = l.Eval(ctxt)
 
 			b.StopTimer()
 			verif = l.Verif(verif)
-
-			c := ctxt.(*vche_2.Ciphertext)
-			if len(c.Ciphertexts) > 3 {
-				numCtxtsSP_C += len(c.Ciphertexts) - 3
-				numCtxtsC_SP += 2
-			}
 			b.StartTimer()
 
 			ctxt, verif = vche_2.BenchmarkRequadratizationProtocolProver(prover, verifier, ctxt.(*vche_2.Ciphertext), verif.(*vche_2.Poly), b)
@@ -83,14 +54,14 @@ func BenchEvalRequadProver(model Model, prover vche_2.Prover, verifier vche_2.Ve
 			b.StartTimer()
 		}
 	}
-	return ctxt, verif, numCtxtsC_SP, numCtxtsSP_C
+	return ctxt, verif
 }
 
-func BenchEvalRequadVerifier(model Model, prover vche_2.Prover, verifier vche_2.Verifier, ctxt interface{}, verif interface{}, b *testing.B) (interface{}, interface{}) {
+func BenchEvalRequadVerifier(model *nn.Model, prover vche_2.Prover, verifier vche_2.Verifier, ctxt interface{}, verif interface{}, b *testing.B) (interface{}, interface{}) {
 	b.StartTimer()
-	for _, layer := range model.EvalLayers() {
+	for _, layer := range model.Layers {
 		switch l := layer.(type) {
-		case Square:
+		case *nn.Square:
 			b.StopTimer()
 			ctxt = l.Eval(ctxt)
 			b.StartTimer()
@@ -110,42 +81,9 @@ func BenchEvalRequadVerifier(model Model, prover vche_2.Prover, verifier vche_2.
 	return ctxt, verif
 }
 
-func EncVerif(model Model, tags [][]vche.Tag) []interface{} {
-	var x interface{} = tags
-	x = model.EncodeLayer().Verif(x)
-	return x.([]interface{})
-}
-
-func Verif(model Model, x interface{}) []interface{} {
-	for _, l := range model.EvalLayers() {
-		x = l.Verif(x)
-	}
-	return x.([]interface{})
-}
-
-func Dec(model Model, ctxts []interface{}, verif []interface{}) []float64 {
+func DecPolyProtocol(model *nn.Model, prover vche_2.Prover, verifier vche_2.Verifier, ctxts []interface{}, verif []interface{}) []float64 {
 	preds := make([]float64, len(ctxts))
 
-	outScale := model.EvalLayers()[len(model.EvalLayers())-1].OutputScale()
-	sum := 0.0
-	for i := range ctxts {
-		ptxt := model.Decryptor().DecryptNew(ctxts[i])
-		var currVerif interface{}
-		if verif != nil {
-			currVerif = verif[i]
-		}
-		vals := model.Encoder().DecodeIntNew(ptxt, currVerif)
-		preds[i] = float64(vals[0]) / float64(outScale)
-		sum += preds[i]
-	}
-	return preds
-}
-
-func DecPolyProtocol(model Model, prover vche_2.Prover, verifier vche_2.Verifier, ctxts []interface{}, verif []interface{}) []float64 {
-	preds := make([]float64, len(ctxts))
-
-	outScale := model.EvalLayers()[len(model.EvalLayers())-1].OutputScale()
-	sum := 0.0
 	for i := range ctxts {
 		var currVerif interface{}
 		if verif != nil {
@@ -154,59 +92,11 @@ func DecPolyProtocol(model Model, prover vche_2.Prover, verifier vche_2.Verifier
 
 		vals := vche_2.RunPolynomialProtocolUint(prover, verifier, ctxts[i].(*vche_2.Ciphertext), currVerif.(*vche_2.Poly))
 
-		preds[i] = float64(vals[0]) / float64(outScale)
-		sum += preds[i]
+		preds[i] = float64(vals[0]) / float64(model.Scale())
 	}
 	return preds
 }
 
-func Reshape2D(v []uint64, m, n int) [][]uint64 {
-	if len(v) != m*n {
-		panic("mismatch in lengths")
-	}
-	res := make([][]uint64, m)
-	for i := range res {
-		res[i] = make([]uint64, n)
-		copy(res[i], v[i*n:(i+1)*n])
-	}
-	return res
-}
-
-func Reshape3D(v []uint64, l, m, n int) [][][]uint64 {
-	if len(v) != l*m*n {
-		panic("mismatch in lengths")
-	}
-	res := make([][][]uint64, l)
-	for i := range res {
-		res[i] = make([][]uint64, m)
-		for j := range res[i] {
-			res[i][j] = make([]uint64, n)
-			copy(res[i][j], v[i*m*n+j*n:i*m*n+(j+1)*n])
-		}
-	}
-	return res
-}
-
-func Rescale(params vche.Parameters, v []float64, scale uint64) []uint64 {
-	res := make([]uint64, len(v))
-	modulusHalf := params.T() >> 1
-	for i := range v {
-		scaled := int64(float64(scale) * v[i]) // TODO: correct rounding
-		if scaled < 0 {
-			if scaled < -int64(modulusHalf) {
-				panic("encoding error")
-			}
-			res[i] = uint64(int64(params.T()) + scaled)
-		} else {
-			if scaled > int64(modulusHalf) {
-				panic("encoding error")
-			}
-			res[i] = uint64(scaled)
-		}
-	}
-	return res
-}
-
 func Transpose(v []float64, inSize, outMaps int) []float64 {
 	res := make([]float64, len(v))
 	for i := 0; i < inSize; i++ {
@@ -274,7 +164,3 @@ func readWeights(path string, res []float64) []float64 {
 	}
 	return res
 }
-
-func nextPow2(x int) int {
-	return 1 << uint(math.Ceil(math.Log2(float64(x))))
-}
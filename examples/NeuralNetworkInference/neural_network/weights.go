@@ -3,14 +3,16 @@ package neural_network
 import (
 	"os"
 	"path"
+
+	"veritas/vche/nn"
 )
 
-var weightsConv = make([]float64, 5*5*5)
-var biasConv = make([]float64, 5)
-var weightsLin1 = make([]float64, 845*100)
-var biasLin1 = make([]float64, 100)
-var weightsLin2 = make([]float64, 100*10)
-var biasLin2 = make([]float64, 10)
+var weightsConv = nn.Tensor{Shape: []int{5, 5, 5}, Data: make([]float64, 5*5*5)}
+var biasConv = nn.Tensor{Shape: []int{5}, Data: make([]float64, 5)}
+var weightsLin1 = nn.Tensor{Shape: []int{100, 845}, Data: make([]float64, 845*100)}
+var biasLin1 = nn.Tensor{Shape: []int{100}, Data: make([]float64, 100)}
+var weightsLin2 = nn.Tensor{Shape: []int{10, 100}, Data: make([]float64, 100*10)}
+var biasLin2 = nn.Tensor{Shape: []int{10}, Data: make([]float64, 10)}
 
 func init() {
 	p, err := os.Getwd()
@@ -20,9 +22,8 @@ func init() {
 	p = path.Clean(path.Join(p, "../models/LoLa_MNIST/"))
 
 	names := []string{"conv1.weight.csv", "conv1.bias.csv", "lin1.weight.csv", "lin1.bias.csv", "lin2.weight.csv", "lin2.bias.csv"}
-	arrs := [][]float64{weightsConv, biasConv, weightsLin1, biasLin1, weightsLin2, biasLin2}
+	arrs := []nn.Tensor{weightsConv, biasConv, weightsLin1, biasLin1, weightsLin2, biasLin2}
 	for i := range names {
-		_, _ = i, arrs
-		readWeights(path.Join(p, names[i]), arrs[i])
+		readWeights(path.Join(p, names[i]), arrs[i].Data)
 	}
 }
@@ -3,12 +3,14 @@ package neural_network
 import (
 	"os"
 	"path"
+
+	"veritas/vche/nn"
 )
 
-var weightsConvSmall = make([]float64, 5*5*5)
-var biasConvSmall = make([]float64, 5)
-var weightLinSmall = make([]float64, 10*245)
-var biasLinSmall = make([]float64, 10)
+var weightsConvSmall = nn.Tensor{Shape: []int{5, 5, 5}, Data: make([]float64, 5*5*5)}
+var biasConvSmall = nn.Tensor{Shape: []int{5}, Data: make([]float64, 5)}
+var weightLinSmall = nn.Tensor{Shape: []int{10, 245}, Data: make([]float64, 10*245)}
+var biasLinSmall = nn.Tensor{Shape: []int{10}, Data: make([]float64, 10)}
 
 func init() {
 	p, err := os.Getwd()
@@ -18,8 +20,8 @@ func init() {
 	p = path.Clean(path.Join(p, "../models/LoLa_MNIST_small/"))
 
 	names := []string{"conv1.weight.csv", "conv1.bias.csv", "lin1.weight.csv", "lin1.bias.csv"}
-	arrs := [][]float64{weightsConvSmall, biasConvSmall, weightLinSmall, biasLinSmall}
+	arrs := []nn.Tensor{weightsConvSmall, biasConvSmall, weightLinSmall, biasLinSmall}
 	for i := range names {
-		readWeights(path.Join(p, names[i]), arrs[i])
+		readWeights(path.Join(p, names[i]), arrs[i].Data)
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"github.com/ldsec/lattigo/v2/rlwe"
 	"veritas/vche/examples/NeuralNetworkInference"
 	"veritas/vche/examples/NeuralNetworkInference/neural_network"
+	"veritas/vche/nn"
 	"veritas/vche/vche"
 	"veritas/vche/vche_1"
 	"testing"
@@ -25,60 +26,60 @@ func Benchmark(b *testing.B) {
 	relinKey := keygen.GenRelinearizationKey(sk, 1)
 	evk := &vche_1.EvaluationKey{EvaluationKey: rlwe.EvaluationKey{Rlk: relinKey.RelinearizationKey, Rtks: rotKeys.RotationKeySet}, H: sk.H}
 
-	modelParams := neural_network.ModelParams{
+	backend := nn.Backend{
 		Encryptor:          vche_1.NewGenericEncryptor(params, sk),
 		Decryptor:          vche_1.NewGenericDecryptor(params, sk),
 		Encoder:            vche_1.NewGenericEncoder(params, sk.K, sk.S, false),
-		EncoderVerif:       vche_1.NewGenericEncoderPlaintext(params, sk.K),
+		EncoderPlaintext:   vche_1.NewGenericEncoderPlaintext(params, sk.K),
 		Evaluator:          vche_1.NewGenericEvaluator(params, evk),
 		EvaluatorPlaintext: vche_1.NewGenericEvaluatorPlaintext(params, evk.H),
 		Parameters:         params,
 	}
 
-	model := neural_network.NewModelSmall(modelParams)
+	model := neural_network.NewModelSmall(backend)
 
 	img := NeuralNetworkInference.MNISTTestImages[0]
-	tags := NeuralNetworkInference.GenTags(0, NeuralNetworkInference.MNISTTestLabels[0], 25, params.NSlots)
+	datasetTag := NeuralNetworkInference.DatasetTag(0, NeuralNetworkInference.MNISTTestLabels[0])
 
-	x := benchEnc(model, img, tags, b)
+	x := benchEnc(model, neural_network.Normalize(img), datasetTag, b)
 
 	res := benchEval(model, x, b)
-	verif := benchVerif(model, tags, b)
+	verif := benchVerif(model, datasetTag, b)
 
 	preds := benchDec(model, res, verif, b)
 
 	NeuralNetworkInference.CheckResult(preds, NeuralNetworkInference.MNISTTestLabels[0])
 }
 
-func benchVerif(model neural_network.Model, tags [][]vche.Tag, b *testing.B) []interface{} {
+func benchVerif(model *nn.Model, datasetTag []byte, b *testing.B) []interface{} {
 	var enc interface{}
 	var verif []interface{}
-	enc = neural_network.EncVerif(model, tags)
+	enc = model.VerifImage(datasetTag)
 
 	b.Run(benchmarkString("EvalVerif"), func(b *testing.B) {
 		for run := 0; run < b.N; run++ {
-			verif = neural_network.Verif(model, enc)
+			verif = model.Verif(enc)
 		}
 	})
 	return verif
 }
 
-func benchEnc(model neural_network.Model, img [][]uint64, tags [][]vche.Tag, b *testing.B) []interface{} {
+func benchEnc(model *nn.Model, img [][]float64, datasetTag []byte, b *testing.B) []interface{} {
 	var x []interface{}
 	b.Run(benchmarkString("Encode"), func(b *testing.B) {
 		for i := 0; i < b.N; i++ {
-			x = neural_network.Encode(model, img, tags)
+			x = neural_network.Encode(model, img, datasetTag)
 		}
 	})
 
 	ctxts := make([]interface{}, len(x))
 	for i := range ctxts {
-		ctxts[i] = vche_1.NewCiphertext(model.Parameters(), 1)
+		ctxts[i] = vche_1.NewCiphertext(model.Parameters, 1)
 	}
 	b.Run(benchmarkString("Encrypt"), func(b *testing.B) {
 		for run := 0; run < b.N; run++ {
 			for i := range x {
-				model.Encryptor().Encrypt(x[i], ctxts[i])
+				model.Encryptor.Encrypt(x[i], ctxts[i])
 			}
 		}
 	})
@@ -92,17 +93,17 @@ func benchEnc(model neural_network.Model, img [][]uint64, tags [][]vche.Tag, b *
 	return ctxts
 }
 
-func benchEval(model neural_network.Model, x []interface{}, b *testing.B) []interface{} {
+func benchEval(model *nn.Model, x []interface{}, b *testing.B) []interface{} {
 	var res []interface{}
 	b.Run(benchmarkString("Eval"), func(b *testing.B) {
 		for i := 0; i < b.N; i++ {
-			res = neural_network.Eval(model, x)
+			res = model.Eval(x)
 		}
 	})
 	return res
 }
 
-func benchDec(model neural_network.Model, res []interface{}, verif []interface{}, b *testing.B) []float64 {
+func benchDec(model *nn.Model, res []interface{}, verif []interface{}, b *testing.B) []float64 {
 	b.Run(benchmarkString("Communication/SP->Client"), func(b *testing.B) {
 		b.ReportMetric(float64(len(res)), "BFV-ctxt")
 		b.ReportMetric(float64(size.Of(res)), "bytes")
@@ -111,17 +112,17 @@ func benchDec(model neural_network.Model, res []interface{}, verif []interface{}
 
 	var ptxts = make([]*vche_1.Plaintext, len(res))
 	for i := range ptxts {
-		ptxts[i] = vche_1.NewPlaintext(model.Parameters())
+		ptxts[i] = vche_1.NewPlaintext(model.Parameters)
 	}
 	var values = make([][]int64, len(res))
 	for i := range values {
-		values[i] = make([]int64, model.Parameters().NSlots)
+		values[i] = make([]int64, model.Parameters.NSlots)
 	}
 
 	b.Run(benchmarkString("Decrypt"), func(b *testing.B) {
 		for run := 0; run < b.N; run++ {
 			for i := range res {
-				model.Decryptor().Decrypt(res[i], ptxts[i])
+				model.Decryptor.Decrypt(res[i], ptxts[i])
 			}
 		}
 	})
@@ -129,13 +130,13 @@ func benchDec(model neural_network.Model, res []interface{}, verif []interface{}
 	b.Run(benchmarkString("Decode"), func(b *testing.B) {
 		for run := 0; run < b.N; run++ {
 			for i := range res {
-				model.Encoder().DecodeInt(ptxts[i], verif[i], values[i])
+				model.Encoder.DecodeInt(ptxts[i], verif[i], values[i])
 			}
 		}
 	})
 
 	preds := make([]float64, len(res))
-	outScale := model.EvalLayers()[len(model.EvalLayers())-1].OutputScale()
+	outScale := model.Scale()
 
 	for i := range values {
 		preds[i] = float64(values[i][0]) / float64(outScale)
@@ -9,6 +9,6 @@ for SUBDIR in $SUBDIRS; do
   echo "${SUBDIR##*/}"
   echo "--------------------------------"
   cd "$SUBDIR"
-  go test -run=1000 -bench=. -timeout=60m | tee bench.out && benchstat -format csv bench.out >bench.csv
+  go test -run=1000 -bench=. -benchmem -timeout=60m | tee bench.out && go run veritas/vche/cmd/bench -parse bench.out -out bench.csv
   cd -
 done
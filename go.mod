@@ -1,6 +1,6 @@
 module veritas/vche
 
-go 1.15
+go 1.18
 
 require (
 	github.com/DmitriyVTitov/size v1.1.0
@@ -10,4 +10,11 @@ require (
 	golang.org/x/crypto v0.0.0-20210317152858-513c2a44f670
 )
 
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sys v0.0.0-20210317225723-c4fcb01b228e // indirect
+	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b // indirect
+)
+
 replace github.com/ldsec/lattigo/v2 v2.2.0 => github.com/ldsec/lattigo/v2 v2.2.1-0.20210923173451-d9eb44f2c43f
@@ -0,0 +1,210 @@
+// Package lookup provides verified equality tests and key-value lookups over bit-decomposed keys, as in the EncDNS
+// example. The computations are built as circuits (vche.Circuit), which the server evaluates on the ciphertexts of the
+// REP (vche_1) or PE (vche_2) encoding and the client on the verification state of the same inputs, so that the result
+// can be verified by the decoder of the encoding.
+//
+// A key of at most MaxInputLen symbols of NumBits bits is decomposed into its Width = MaxInputLen*NumBits bits, most
+// significant bit first, which fill a block of Width consecutive slots. A vector of NSlots slots packs NSlots/Width keys,
+// or the values associated with them.
+package lookup
+
+import (
+	"fmt"
+	"math/bits"
+
+	"veritas/vche/vche"
+)
+
+// Layout is the packing of bit-decomposed keys in the slots of a vector.
+type Layout struct {
+	MaxInputLen int // maximum number of symbols of a key, e.g., of characters of a domain name
+	NumBits     int // number of bits of a symbol
+	NSlots      int // number of slots of a vector
+}
+
+// NewLayout returns the layout of keys of maxInputLen symbols of numBits bits in vectors of nSlots slots. The width of
+// the keys must be a power of two, and the vector must hold a power of two of keys in each of its two rows, so that the
+// blocks can be combined by rotations.
+func NewLayout(maxInputLen, numBits, nSlots int) (Layout, error) {
+	l := Layout{maxInputLen, numBits, nSlots}
+	if maxInputLen <= 0 || numBits <= 0 || numBits > 64 {
+		return Layout{}, fmt.Errorf("invalid key of %d symbols of %d bits", maxInputLen, numBits)
+	}
+	if bits.OnesCount(uint(l.Width())) != 1 {
+		return Layout{}, fmt.Errorf("the width %d of the keys should be a power of two", l.Width())
+	}
+	if nSlots%(2*l.Width()) != 0 || bits.OnesCount(uint(nSlots/(2*l.Width()))) != 1 {
+		return Layout{}, fmt.Errorf("%d slots cannot hold a power of two of keys of width %d in each row", nSlots, l.Width())
+	}
+	return l, nil
+}
+
+// Width returns the number of slots of a key.
+func (l Layout) Width() int {
+	return l.MaxInputLen * l.NumBits
+}
+
+// NumBlocks returns the number of keys packed in a vector.
+func (l Layout) NumBlocks() int {
+	return l.NSlots / l.Width()
+}
+
+// EncodeKey returns the bits of the symbols of a key, most significant bit first, padded with zeros to the width of the
+// keys.
+func (l Layout) EncodeKey(symbols []uint64) []uint64 {
+	if len(symbols) > l.MaxInputLen {
+		panic(fmt.Errorf("key of %d symbols exceeds the maximum of %d", len(symbols), l.MaxInputLen))
+	}
+	res := make([]uint64, l.Width())
+	for i, s := range symbols {
+		if l.NumBits < 64 && s>>uint(l.NumBits) != 0 {
+			panic(fmt.Errorf("symbol %d does not fit in %d bits", s, l.NumBits))
+		}
+		for j := 0; j < l.NumBits; j++ {
+			res[i*l.NumBits+j] = (s >> uint(l.NumBits-1-j)) & 1
+		}
+	}
+	return res
+}
+
+// DecodeKey returns the symbols of the first key of a vector of bits.
+func (l Layout) DecodeKey(vec []uint64) []uint64 {
+	symbols := make([]uint64, l.MaxInputLen)
+	for i := range symbols {
+		for j := 0; j < l.NumBits; j++ {
+			symbols[i] = symbols[i]<<1 | vec[i*l.NumBits+j]&1
+		}
+	}
+	return symbols
+}
+
+// EncodeString encodes the bytes of s as the symbols of a key.
+func (l Layout) EncodeString(s string) []uint64 {
+	symbols := make([]uint64, len(s))
+	for i := range s {
+		symbols[i] = uint64(s[i])
+	}
+	return l.EncodeKey(symbols)
+}
+
+// DecodeString decodes the first key of a vector of bits as a string of bytes, without its trailing null bytes.
+func (l Layout) DecodeString(vec []uint64) string {
+	symbols := l.DecodeKey(vec)
+	s := make([]byte, len(symbols))
+	for i, symbol := range symbols {
+		s[i] = byte(symbol)
+	}
+	for len(s) > 0 && s[len(s)-1] == 0 {
+		s = s[:len(s)-1]
+	}
+	return string(s)
+}
+
+// Pack packs entries, e.g., encoded keys or values, in as many vectors as needed, one entry per block. The remaining
+// blocks are zero, which never matches a query if the keys of the database are not zero.
+func (l Layout) Pack(entries [][]uint64) [][]uint64 {
+	vecs := make([][]uint64, (len(entries)+l.NumBlocks()-1)/l.NumBlocks())
+	for i := range vecs {
+		vecs[i] = make([]uint64, l.NSlots)
+	}
+	for i, entry := range entries {
+		if len(entry) > l.Width() {
+			panic(fmt.Errorf("entry of %d slots exceeds the width %d of the keys", len(entry), l.Width()))
+		}
+		copy(vecs[i/l.NumBlocks()][(i%l.NumBlocks())*l.Width():], entry)
+	}
+	return vecs
+}
+
+// Replicate returns the vector that holds entry in every block, such as a query to compare with all the keys of a vector.
+func (l Layout) Replicate(entry []uint64) []uint64 {
+	entries := make([][]uint64, l.NumBlocks())
+	for i := range entries {
+		entries[i] = entry
+	}
+	return l.Pack(entries)[0]
+}
+
+// BlockStarts returns the vector that is 1 in the first slot of each block and 0 elsewhere.
+func (l Layout) BlockStarts() []uint64 {
+	res := make([]uint64, l.NSlots)
+	for i := 0; i < l.NSlots; i += l.Width() {
+		res[i] = 1
+	}
+	return res
+}
+
+// Ones returns the vector that is 1 in every slot.
+func (l Layout) Ones() []uint64 {
+	res := make([]uint64, l.NSlots)
+	for i := range res {
+		res[i] = 1
+	}
+	return res
+}
+
+// EqualBits adds to c the gates that compare the keys of the bit vectors x and y block by block, given the input one
+// that is 1 in every slot. The first slot of each block of the result is 1 if the keys are equal and 0 otherwise; the
+// other slots compare the keys only partially.
+func (l Layout) EqualBits(c *vche.Circuit, x, y, one vche.Wire) vche.Wire {
+	// 2xy - x - y + 1 is 1 if the bits are equal and 0 otherwise
+	eq := c.Add(one, c.Sub(c.Sub(c.MulScalar(c.Relinearize(c.Mul(x, y)), 2), x), y))
+	// Multiply the comparisons of the bits into the first slot of each block, in log(Width) steps
+	for k := 1; k < l.Width(); k <<= 1 {
+		eq = c.Relinearize(c.Mul(eq, c.RotateColumns(eq, k)))
+	}
+	return eq
+}
+
+// Select adds to c the gates that keep the blocks of values whose mask is 1 and zero the others. The mask of each block
+// is read from its first slot, as returned by EqualBits, and first is the plaintext of BlockStarts.
+func (l Layout) Select(c *vche.Circuit, mask, first, values vche.Wire) vche.Wire {
+	m := c.Mul(mask, first)
+	// Spread the mask from the first slot of each block to the whole block
+	for k := 1; k < l.Width(); k <<= 1 {
+		m = c.Add(m, c.RotateColumns(m, l.NSlots-k))
+	}
+	return c.Relinearize(c.Mul(m, values))
+}
+
+// KeyValueLookup returns the circuit that looks up a query in a database of numVectors vectors of keys and as many
+// vectors of values, packed with Pack. Its inputs are listed by LookupInputs. Its output holds in every block the sum of
+// the values whose key equals the query, that is, the value of the query if the keys are distinct, or zero if the query
+// is not in the database.
+func (l Layout) KeyValueLookup(numVectors int) *vche.Circuit {
+	if numVectors <= 0 {
+		panic(fmt.Errorf("the database should have at least one vector, got %d", numVectors))
+	}
+	c := vche.NewCircuit()
+	one, first, query := c.Input(), c.Input(), c.Input()
+	keys, values := c.Inputs(numVectors), c.Inputs(numVectors)
+
+	var res vche.Wire
+	for i := range keys {
+		selected := l.Select(c, l.EqualBits(c, keys[i], query, one), first, values[i])
+		if i == 0 {
+			res = selected
+		} else {
+			res = c.Add(res, selected)
+		}
+	}
+
+	// Sum the blocks of each row, then the two rows
+	for k := l.Width(); k < l.NSlots/2; k <<= 1 {
+		res = c.Add(res, c.RotateColumns(res, k))
+	}
+	res = c.Add(res, c.RotateRows(res))
+	c.Output(res)
+	return c
+}
+
+// LookupInputs returns the inputs of the circuit of KeyValueLookup, in order: the encodings of Ones, of BlockStarts, of
+// the query replicated with Replicate, and of the vectors of keys and of values. The server passes its ciphertexts, and
+// the plaintext of BlockStarts, and the client the verification state of the same inputs.
+func LookupInputs(one, first, query interface{}, keys, values []interface{}) []interface{} {
+	if len(keys) != len(values) {
+		panic(fmt.Errorf("got %d vectors of keys but %d vectors of values", len(keys), len(values)))
+	}
+	inputs := append([]interface{}{one, first, query}, keys...)
+	return append(inputs, values...)
+}
@@ -0,0 +1,183 @@
+package lookup
+
+import (
+	"testing"
+
+	"github.com/ldsec/lattigo/v2/bfv"
+	"github.com/ldsec/lattigo/v2/ring"
+	"github.com/ldsec/lattigo/v2/rlwe"
+	"github.com/stretchr/testify/require"
+	"veritas/vche/vche"
+	"veritas/vche/vche_1"
+	"veritas/vche/vche_2"
+)
+
+// testDatabase maps keys of two symbols of two bits to values.
+var testDatabase = map[[2]uint64]uint64{{1, 2}: 7, {3, 0}: 11, {2, 2}: 13, {0, 1}: 17}
+
+// testEntries returns the encoded keys and values of testDatabase, and the values expected for some queries.
+func testEntries(l Layout) (keys, values [][]uint64, want map[[2]uint64]uint64) {
+	want = map[[2]uint64]uint64{{1, 1}: 0, {0, 0}: 0}
+	for k, v := range testDatabase {
+		keys = append(keys, l.EncodeKey(k[:]))
+		values = append(values, []uint64{v})
+		want[k] = v
+	}
+	return keys, values, want
+}
+
+func TestLayout(t *testing.T) {
+	for _, bad := range [][3]int{{0, 8, 64}, {3, 8, 256}, {2, 4, 8}, {2, 4, 48}, {1, 65, 128}} {
+		_, err := NewLayout(bad[0], bad[1], bad[2])
+		require.Error(t, err, bad)
+	}
+
+	l, err := NewLayout(4, 8, 256)
+	require.NoError(t, err)
+	require.Equal(t, 32, l.Width())
+	require.Equal(t, 8, l.NumBlocks())
+	require.Equal(t, "epfl", l.DecodeString(l.EncodeString("epfl")))
+	require.Equal(t, "ab", l.DecodeString(l.EncodeString("ab")))
+	require.Equal(t, []uint64{0, 1, 0, 0, 0, 0, 0, 1}, l.EncodeKey([]uint64{0x41})[:8])
+	require.Panics(t, func() { l.EncodeString("too long") })
+	require.Panics(t, func() { l.EncodeKey([]uint64{256}) })
+
+	vecs := l.Pack(make([][]uint64, l.NumBlocks()+1))
+	require.Len(t, vecs, 2)
+	query := l.Replicate(l.EncodeString("ab"))
+	for i := 0; i < l.NSlots; i += l.Width() {
+		require.Equal(t, "ab", l.DecodeString(query[i:]))
+	}
+	require.Equal(t, uint64(l.NumBlocks()), sum(l.BlockStarts()))
+	require.Equal(t, uint64(l.NSlots), sum(l.Ones()))
+}
+
+func TestKeyValueLookupPlaintext(t *testing.T) {
+	params, err := vche.NewParametersFromLiteral(vche.ParametersLiteral{ParametersLiteral: bfv.PN12QP109, NumReplications: 1, NumDistinctPRFKeys: 1})
+	require.NoError(t, err)
+	l, err := NewLayout(2, 2, params.NSlots)
+	require.NoError(t, err)
+	// Spread the database over several vectors of a single key each
+	keys, values, want := testEntries(l)
+	var keyVecs, valueVecs []interface{}
+	for i := range keys {
+		keyVecs = append(keyVecs, poly(params, l.Pack(keys[i : i+1])[0]))
+		valueVecs = append(valueVecs, poly(params, l.Pack(values[i : i+1])[0]))
+	}
+	c := l.KeyValueLookup(len(keys))
+	require.True(t, c.NeedsRelinearizationKey())
+	require.True(t, c.NeedsRotateRowsKey())
+
+	eval := vche.NewGenericEvaluatorPlaintext(params)
+	for query, value := range want {
+		inputs := LookupInputs(poly(params, l.Ones()), poly(params, l.BlockStarts()), poly(params, l.Replicate(l.EncodeKey(query[:]))), keyVecs, valueVecs)
+		res := c.Eval(eval, inputs...)[0].(*ring.Poly).Coeffs[0]
+		for i := 0; i < l.NSlots; i += l.Width() {
+			require.Equal(t, value, res[i], query)
+		}
+	}
+	require.Panics(t, func() { LookupInputs(nil, nil, nil, keyVecs, nil) })
+}
+
+func TestKeyValueLookup(t *testing.T) {
+	t.Run("REP", func(t *testing.T) {
+		params, err := vche_1.NewParametersFromLiteral(vche_1.DefaultParams[2])
+		require.NoError(t, err)
+		l, err := NewLayout(2, 2, params.NSlots)
+		require.NoError(t, err)
+		c := l.KeyValueLookup(1)
+
+		kgen := vche_1.NewKeyGenerator(params)
+		sk := kgen.GenSecretKey()
+		evk := &vche_1.EvaluationKey{EvaluationKey: rlwe.EvaluationKey{
+			Rlk:  kgen.GenRelinearizationKey(sk, 2).RelinearizationKey,
+			Rtks: kgen.GenRotationKeysForRotations(c.Rotations(), true, sk).RotationKeySet,
+		}, H: sk.H}
+		encoder := vche_1.NewEncoder(params, sk.K, sk.S, false)
+		encoderPlaintext := vche_1.NewEncoderPlaintext(params, sk.K)
+		encryptor := vche_1.NewEncryptor(params, sk)
+		decryptor := vche_1.NewDecryptor(params, sk)
+
+		// encrypt returns the ciphertext of vec and its verification state, or its plaintext if the input is public
+		encrypt := func(vec []uint64, public bool) (interface{}, interface{}) {
+			tags := vche.GetRandomTags(params.NSlots)
+			pt := encoder.EncodeUintNew(vec, tags)
+			if public {
+				return pt, encoderPlaintext.EncodeNew(tags)
+			}
+			return encryptor.EncryptNew(pt), encoderPlaintext.EncodeNew(tags)
+		}
+		testKeyValueLookup(t, l, c, encrypt,
+			vche_1.NewGenericEvaluator(params, evk), vche_1.NewGenericEvaluatorPlaintext(params, sk.H),
+			func(ct, verif interface{}) []uint64 {
+				return encoder.DecodeUintNew(decryptor.DecryptNew(ct.(*vche_1.Ciphertext)), verif.(*vche_1.TaggedPoly))
+			})
+	})
+
+	t.Run("PE", func(t *testing.T) {
+		params, err := vche_2.NewParametersFromLiteral(vche_2.DefaultParams[2])
+		require.NoError(t, err)
+		l, err := NewLayout(2, 2, params.NSlots)
+		require.NoError(t, err)
+		c := l.KeyValueLookup(1)
+
+		kgen := vche_2.NewKeyGenerator(params)
+		sk := kgen.GenSecretKey()
+		evk := &vche_2.EvaluationKey{
+			Rlk:  kgen.GenRelinearizationKey(sk, 2),
+			Rtks: kgen.GenRotationKeysForRotations(c.Rotations(), true, sk),
+		}
+		encoder := vche_2.NewEncoder(params, sk.K, sk.Alpha, false)
+		encoderPlaintext := vche_2.NewEncoderPlaintext(params, sk.K)
+		encryptor := vche_2.NewEncryptor(params, sk)
+		decryptor := vche_2.NewDecryptor(params, sk)
+
+		encrypt := func(vec []uint64, public bool) (interface{}, interface{}) {
+			tags := vche.GetRandomTags(params.NSlots)
+			pt := encoder.EncodeUintNew(vec, tags)
+			if public {
+				return pt, encoderPlaintext.EncodeNew(tags)
+			}
+			return encryptor.EncryptNew(pt), encoderPlaintext.EncodeNew(tags)
+		}
+		testKeyValueLookup(t, l, c, encrypt,
+			vche_2.NewGenericEvaluator(params, evk), vche_2.NewGenericEvaluatorPlaintext(params),
+			func(ct, verif interface{}) []uint64 {
+				return encoder.DecodeUintNew(decryptor.DecryptNew(ct.(*vche_2.Ciphertext)), verif.(*vche_2.Poly))
+			})
+	})
+}
+
+// testKeyValueLookup looks up a key and a missing key of testDatabase, packed in a single vector, with the server and
+// client evaluators of an encoding.
+func testKeyValueLookup(t *testing.T, l Layout, c *vche.Circuit, encrypt func(vec []uint64, public bool) (interface{}, interface{}),
+	eval, evalPlaintext vche.GenericEvaluator, decode func(ct, verif interface{}) []uint64) {
+	keys, values, want := testEntries(l)
+	keyCt, keyVerif := encrypt(l.Pack(keys)[0], false)
+	valueCt, valueVerif := encrypt(l.Pack(values)[0], false)
+	oneCt, oneVerif := encrypt(l.Ones(), false)
+	firstPt, firstVerif := encrypt(l.BlockStarts(), true)
+
+	for _, query := range [][2]uint64{{3, 0}, {1, 1}} {
+		queryCt, queryVerif := encrypt(l.Replicate(l.EncodeKey(query[:])), false)
+		res := c.Eval(eval, LookupInputs(oneCt, firstPt, queryCt, []interface{}{keyCt}, []interface{}{valueCt})...)[0]
+		verif := c.Eval(evalPlaintext, LookupInputs(oneVerif, firstVerif, queryVerif, []interface{}{keyVerif}, []interface{}{valueVerif})...)[0]
+		got := decode(res, verif)
+		for i := 0; i < l.NSlots; i += l.Width() {
+			require.Equal(t, want[query], got[i], query)
+		}
+	}
+}
+
+func poly(params vche.Parameters, vec []uint64) *ring.Poly {
+	p := params.RingT().NewPoly()
+	copy(p.Coeffs[0], vec)
+	return p
+}
+
+func sum(vec []uint64) (s uint64) {
+	for _, x := range vec {
+		s += x
+	}
+	return s
+}
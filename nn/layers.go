@@ -0,0 +1,490 @@
+package nn
+
+import (
+	"fmt"
+
+	"veritas/vche/vche"
+)
+
+// rotateAndAdd adds to op its rotations by each of the ks in turn, e.g., ks = 1, 2, ..., n/2 sums n consecutive slots
+// into the first one.
+func rotateAndAdd(eval vche.GenericEvaluator, op interface{}, ks []int) interface{} {
+	res := eval.CopyNew(op)
+	for _, k := range ks {
+		eval.Add(res, eval.RotateColumnsNew(res, k), res)
+	}
+	return res
+}
+
+// powersOfTwo returns start, 2*start, ... up to and excluding end.
+func powersOfTwo(start, end int) []int {
+	var ks []int
+	for k := start; k < end; k <<= 1 {
+		ks = append(ks, k)
+	}
+	return ks
+}
+
+// encryptAll encrypts each of the vectors with the tags of the given name and index.
+func (b Backend) encryptAll(vecs [][]uint64, name string) (cts, verifs []interface{}) {
+	cts, verifs = make([]interface{}, len(vecs)), make([]interface{}, len(vecs))
+	for i := range vecs {
+		cts[i], verifs[i] = b.encrypt(vecs[i], fmt.Sprintf("%s/%d", name, i))
+	}
+	return cts, verifs
+}
+
+func (b Backend) checkFits(n int, what string) error {
+	if n > b.Parameters.NSlots/2 {
+		return fmt.Errorf("%s of %d slots exceeds a row of %d slots", what, n, b.Parameters.NSlots/2)
+	}
+	return nil
+}
+
+// checkWeights checks that there is a bias per output, and that the weights and biases multiplied by their scale fit
+// in the plaintext modulus.
+func (b Backend) checkWeights(inScale, weightsScale uint64, weights, bias Tensor, outSize int) error {
+	if Size(bias.Shape) != outSize {
+		return fmt.Errorf("weights of shape %v need %d biases, got shape %v", weights.Shape, outSize, bias.Shape)
+	}
+	if err := checkRescale(b.Parameters, weights.Data, weightsScale); err != nil {
+		return fmt.Errorf("weights: %v", err)
+	}
+	if err := checkRescale(b.Parameters, bias.Data, inScale*weightsScale); err != nil {
+		return fmt.Errorf("biases: %v", err)
+	}
+	return nil
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Convolution is a convolution with several kernel maps on the output of Im2Col. Its output holds a vector per map,
+// with the outputs of the map in its first slots.
+type Convolution struct {
+	Backend
+	W            [][]uint64
+	b, bVerif    []interface{}
+	inScale      uint64
+	weightsScale uint64
+}
+
+func checkConvolution(b Backend, inScale, weightsScale uint64, input Im2Col, weights, bias Tensor) error {
+	numMaps := weights.Shape[0]
+	if Size(weights.Shape) != numMaps*input.KernelSize*input.KernelSize {
+		return fmt.Errorf("kernels of shape %v do not match the kernel size %d", weights.Shape, input.KernelSize)
+	}
+	return b.checkWeights(inScale, weightsScale, weights, bias, numMaps)
+}
+
+func NewConvolution(b Backend, name string, inScale, weightsScale uint64, input Im2Col, weights, bias Tensor) *Convolution {
+	must(checkConvolution(b, inScale, weightsScale, input, weights, bias))
+	numMaps := weights.Shape[0]
+
+	W := Reshape2D(Rescale(b.Parameters, weights.Data, weightsScale), numMaps, input.KernelSize*input.KernelSize)
+	bias0 := Rescale(b.Parameters, bias.Data, inScale*weightsScale)
+	biases := make([][]uint64, numMaps)
+	for i := range biases {
+		biases[i] = make([]uint64, input.NumOutputs())
+		for j := range biases[i] {
+			biases[i][j] = bias0[i]
+		}
+	}
+	bCtxt, bVerif := b.encryptAll(biases, name+"/bias")
+	return &Convolution{b, W, bCtxt, bVerif, inScale, weightsScale}
+}
+
+func (l *Convolution) eval(eval vche.GenericEvaluator, in, b []interface{}) []interface{} {
+	res := make([]interface{}, len(l.W))
+	for m := range l.W {
+		res[m] = eval.CopyNew(b[m])
+		for i := range l.W[m] {
+			eval.Add(res[m], eval.MulScalarNew(in[i], l.W[m][i]), res[m])
+		}
+	}
+	return res
+}
+
+func (l *Convolution) Eval(in interface{}) interface{} {
+	return l.eval(l.Evaluator, in.([]interface{}), l.b)
+}
+
+func (l *Convolution) Verif(in interface{}) interface{} {
+	return l.eval(l.EvaluatorPlaintext, in.([]interface{}), l.bVerif)
+}
+
+func (l *Convolution) OutputScale() uint64 {
+	return l.inScale * l.weightsScale
+}
+
+// CombineDense combines the vectors of the maps of a convolution into a single vector, in which the outputs of the i-th
+// map start at slot i*stride.
+type CombineDense struct {
+	Backend
+	inScale uint64
+	numMaps int
+	stride  int
+}
+
+func NewCombineDense(b Backend, inScale uint64, numMaps, stride int) *CombineDense {
+	must(b.checkFits(numMaps*stride, "the combined maps"))
+	return &CombineDense{b, inScale, numMaps, stride}
+}
+
+func (l *CombineDense) eval(eval vche.GenericEvaluator, in []interface{}) interface{} {
+	if len(in) != l.numMaps {
+		panic(fmt.Errorf("expected %d maps, got %d", l.numMaps, len(in)))
+	}
+	res := eval.CopyNew(in[0])
+	for i := 1; i < len(in); i++ {
+		eval.Add(res, eval.RotateColumnsNew(in[i], -i*l.stride), res)
+	}
+	return res
+}
+
+func (l *CombineDense) Eval(in interface{}) interface{} {
+	return l.eval(l.Evaluator, in.([]interface{}))
+}
+
+func (l *CombineDense) Verif(in interface{}) interface{} {
+	return l.eval(l.EvaluatorPlaintext, in.([]interface{}))
+}
+
+func (l *CombineDense) OutputScale() uint64 {
+	return l.inScale
+}
+
+func (l *CombineDense) Rotations() []int {
+	var rots []int
+	for i := 1; i < l.numMaps; i++ {
+		rots = append(rots, -i*l.stride)
+	}
+	return rots
+}
+
+// Square squares each slot of a vector, or of each vector of a list.
+type Square struct {
+	Backend
+	inScale uint64
+}
+
+func NewSquare(b Backend, inScale uint64) *Square {
+	return &Square{b, inScale}
+}
+
+func (l *Square) eval(eval vche.GenericEvaluator, in interface{}) interface{} {
+	if ins, ok := in.([]interface{}); ok {
+		res := make([]interface{}, len(ins))
+		for i := range ins {
+			res[i] = l.eval(eval, ins[i])
+		}
+		return res
+	}
+	return eval.RelinearizeNew(eval.MulNew(in, in))
+}
+
+func (l *Square) Eval(in interface{}) interface{} {
+	return l.eval(l.Evaluator, in)
+}
+
+func (l *Square) Verif(in interface{}) interface{} {
+	return l.eval(l.EvaluatorPlaintext, in)
+}
+
+func (l *Square) OutputScale() uint64 {
+	return l.inScale * l.inScale
+}
+
+// Stack copies a vector of inSize values, the other slots being zero, in every block of nextPow2(inSize) slots.
+type Stack struct {
+	Backend
+	inScale   uint64
+	blockSize int
+}
+
+func NewStack(b Backend, inScale uint64, inSize int) *Stack {
+	must(b.checkFits(nextPow2(inSize), "the block"))
+	return &Stack{b, inScale, nextPow2(inSize)}
+}
+
+func (l *Stack) eval(eval vche.GenericEvaluator, in interface{}) interface{} {
+	res := rotateAndAdd(eval, in, l.Rotations())
+	eval.Add(res, eval.RotateRowsNew(res), res)
+	return res
+}
+
+func (l *Stack) Eval(in interface{}) interface{} {
+	return l.eval(l.Evaluator, in)
+}
+
+func (l *Stack) Verif(in interface{}) interface{} {
+	return l.eval(l.EvaluatorPlaintext, in)
+}
+
+func (l *Stack) OutputScale() uint64 {
+	return l.inScale
+}
+
+func (l *Stack) Rotations() []int {
+	var rots []int
+	for _, k := range powersOfTwo(l.blockSize, l.Parameters.NSlots/2) {
+		rots = append(rots, -k)
+	}
+	return rots
+}
+
+// MulStacked is a dense layer on the output of Stack, which computes a neuron per block: the n-th neuron is in the
+// first slot of the (n mod blocksPerVector)-th block of the (n / blocksPerVector)-th vector of the output. The other
+// slots are zero.
+type MulStacked struct {
+	Backend
+	inScale         uint64
+	weightsScale    uint64
+	blockSize       int
+	blocksPerVector int
+	numVectors      int
+	W, WVerif       []interface{}
+	b, bVerif       []interface{}
+	mask, maskVerif interface{}
+}
+
+func checkMulStacked(b Backend, inScale, weightsScale uint64, weights, bias Tensor) error {
+	if err := b.checkFits(nextPow2(weights.Shape[1]), "the block"); err != nil {
+		return err
+	}
+	return b.checkWeights(inScale, weightsScale, weights, bias, weights.Shape[0])
+}
+
+func NewMulStacked(b Backend, name string, inScale, weightsScale uint64, weights, bias Tensor) *MulStacked {
+	must(checkMulStacked(b, inScale, weightsScale, weights, bias))
+	outSize, inSize := weights.Shape[0], weights.Shape[1]
+	blockSize := nextPow2(inSize)
+	blocksPerVector := b.Parameters.NSlots / blockSize
+	numVectors := (outSize + blocksPerVector - 1) / blocksPerVector
+
+	W := Reshape2D(Rescale(b.Parameters, weights.Data, weightsScale), outSize, inSize)
+	bias0 := Rescale(b.Parameters, bias.Data, inScale*weightsScale)
+	stackedW, stackedB := make([][]uint64, numVectors), make([][]uint64, numVectors)
+	for v := range stackedW {
+		stackedW[v], stackedB[v] = make([]uint64, b.Parameters.NSlots), make([]uint64, b.Parameters.NSlots)
+		for j := 0; j < blocksPerVector && v*blocksPerVector+j < outSize; j++ {
+			copy(stackedW[v][j*blockSize:], W[v*blocksPerVector+j])
+			stackedB[v][j*blockSize] = bias0[v*blocksPerVector+j]
+		}
+	}
+	mask := make([]uint64, b.Parameters.NSlots)
+	for j := 0; j < blocksPerVector; j++ {
+		mask[j*blockSize] = 1
+	}
+
+	l := &MulStacked{Backend: b, inScale: inScale, weightsScale: weightsScale, blockSize: blockSize, blocksPerVector: blocksPerVector, numVectors: numVectors}
+	l.W, l.WVerif = b.encryptAll(stackedW, name+"/weight")
+	l.b, l.bVerif = b.encryptAll(stackedB, name+"/bias")
+	l.mask, l.maskVerif = b.encrypt(mask, name+"/mask")
+	return l
+}
+
+func (l *MulStacked) eval(eval vche.GenericEvaluator, in interface{}, W, b []interface{}, mask interface{}) interface{} {
+	res := make([]interface{}, l.numVectors)
+	for v := range W {
+		// Sum the products of each block into its first slot, and zero the other slots
+		dot := rotateAndAdd(eval, eval.RelinearizeNew(eval.MulNew(in, W[v])), l.Rotations())
+		res[v] = eval.RelinearizeNew(eval.MulNew(dot, mask))
+		eval.Add(res[v], b[v], res[v])
+	}
+	return res
+}
+
+func (l *MulStacked) Eval(in interface{}) interface{} {
+	return l.eval(l.Evaluator, in, l.W, l.b, l.mask)
+}
+
+func (l *MulStacked) Verif(in interface{}) interface{} {
+	return l.eval(l.EvaluatorPlaintext, in, l.WVerif, l.bVerif, l.maskVerif)
+}
+
+func (l *MulStacked) OutputScale() uint64 {
+	return l.inScale * l.weightsScale
+}
+
+func (l *MulStacked) Rotations() []int {
+	return powersOfTwo(1, l.blockSize)
+}
+
+// CombineInterleaved combines the vectors of MulStacked into a single vector, in which the n-th neuron is at offset
+// n / blocksPerVector of the (n mod blocksPerVector)-th block.
+type CombineInterleaved struct {
+	Backend
+	inScale    uint64
+	numVectors int
+}
+
+func NewCombineInterleaved(b Backend, inScale uint64, numVectors int) *CombineInterleaved {
+	return &CombineInterleaved{b, inScale, numVectors}
+}
+
+func (l *CombineInterleaved) eval(eval vche.GenericEvaluator, in []interface{}) interface{} {
+	if len(in) != l.numVectors {
+		panic(fmt.Errorf("expected %d vectors, got %d", l.numVectors, len(in)))
+	}
+	res := eval.CopyNew(in[0])
+	for i := 1; i < len(in); i++ {
+		eval.Add(res, eval.RotateColumnsNew(in[i], -i), res)
+	}
+	return res
+}
+
+func (l *CombineInterleaved) Eval(in interface{}) interface{} {
+	return l.eval(l.Evaluator, in.([]interface{}))
+}
+
+func (l *CombineInterleaved) Verif(in interface{}) interface{} {
+	return l.eval(l.EvaluatorPlaintext, in.([]interface{}))
+}
+
+func (l *CombineInterleaved) OutputScale() uint64 {
+	return l.inScale
+}
+
+func (l *CombineInterleaved) Rotations() []int {
+	var rots []int
+	for i := 1; i < l.numVectors; i++ {
+		rots = append(rots, -i)
+	}
+	return rots
+}
+
+// MulInterleaved is a dense layer on the output of CombineInterleaved. Its output holds a vector per neuron, with its
+// value in the first slot.
+type MulInterleaved struct {
+	Backend
+	inScale      uint64
+	weightsScale uint64
+	blockSize    int
+	numVectors   int
+	W, WVerif    []interface{}
+	b, bVerif    []interface{}
+}
+
+func checkMulInterleaved(b Backend, inScale, weightsScale uint64, blockSize int, weights, bias Tensor) error {
+	blocksPerVector := b.Parameters.NSlots / blockSize
+	numVectors := (weights.Shape[1] + blocksPerVector - 1) / blocksPerVector
+	if numVectors > blockSize {
+		return fmt.Errorf("%d interleaved vectors exceed the block size %d", numVectors, blockSize)
+	}
+	return b.checkWeights(inScale, weightsScale, weights, bias, weights.Shape[0])
+}
+
+func NewMulInterleaved(b Backend, name string, inScale, weightsScale uint64, blockSize int, weights, bias Tensor) *MulInterleaved {
+	must(checkMulInterleaved(b, inScale, weightsScale, blockSize, weights, bias))
+	outSize, inSize := weights.Shape[0], weights.Shape[1]
+	blocksPerVector := b.Parameters.NSlots / blockSize
+	numVectors := (inSize + blocksPerVector - 1) / blocksPerVector
+
+	W := Reshape2D(Rescale(b.Parameters, weights.Data, weightsScale), outSize, inSize)
+	bias0 := Rescale(b.Parameters, bias.Data, inScale*weightsScale)
+	rows, biases := make([][]uint64, outSize), make([][]uint64, outSize)
+	for r := range rows {
+		rows[r] = make([]uint64, b.Parameters.NSlots)
+		for n := range W[r] {
+			rows[r][(n%blocksPerVector)*blockSize+n/blocksPerVector] = W[r][n]
+		}
+		biases[r] = []uint64{bias0[r]}
+	}
+
+	l := &MulInterleaved{Backend: b, inScale: inScale, weightsScale: weightsScale, blockSize: blockSize, numVectors: numVectors}
+	l.W, l.WVerif = b.encryptAll(rows, name+"/weight")
+	l.b, l.bVerif = b.encryptAll(biases, name+"/bias")
+	return l
+}
+
+func (l *MulInterleaved) eval(eval vche.GenericEvaluator, in interface{}, W, b []interface{}) []interface{} {
+	res := make([]interface{}, len(W))
+	for r := range W {
+		// Sum the blocks of both rows into the first one, then its first slots
+		mult := rotateAndAdd(eval, eval.RelinearizeNew(eval.MulNew(in, W[r])), powersOfTwo(l.blockSize, l.Parameters.NSlots/2))
+		eval.Add(mult, eval.RotateRowsNew(mult), mult)
+		res[r] = rotateAndAdd(eval, mult, powersOfTwo(1, l.numVectors))
+		eval.Add(res[r], b[r], res[r])
+	}
+	return res
+}
+
+func (l *MulInterleaved) Eval(in interface{}) interface{} {
+	return l.eval(l.Evaluator, in, l.W, l.b)
+}
+
+func (l *MulInterleaved) Verif(in interface{}) interface{} {
+	return l.eval(l.EvaluatorPlaintext, in, l.WVerif, l.bVerif)
+}
+
+func (l *MulInterleaved) OutputScale() uint64 {
+	return l.inScale * l.weightsScale
+}
+
+func (l *MulInterleaved) Rotations() []int {
+	return append(powersOfTwo(l.blockSize, l.Parameters.NSlots/2), powersOfTwo(1, l.numVectors)...)
+}
+
+// Dense is a dense layer on a vector of inSize values in its first slots. Its output holds a vector per neuron, with
+// its value in the first slot.
+type Dense struct {
+	Backend
+	inScale      uint64
+	weightsScale uint64
+	inSize       int
+	W, WVerif    []interface{}
+	b, bVerif    []interface{}
+}
+
+func checkDense(b Backend, inScale, weightsScale uint64, weights, bias Tensor) error {
+	if err := b.checkFits(nextPow2(weights.Shape[1]), "the input"); err != nil {
+		return err
+	}
+	return b.checkWeights(inScale, weightsScale, weights, bias, weights.Shape[0])
+}
+
+func NewDense(b Backend, name string, inScale, weightsScale uint64, weights, bias Tensor) *Dense {
+	must(checkDense(b, inScale, weightsScale, weights, bias))
+	outSize, inSize := weights.Shape[0], weights.Shape[1]
+
+	W := Reshape2D(Rescale(b.Parameters, weights.Data, weightsScale), outSize, inSize)
+	bias0 := Rescale(b.Parameters, bias.Data, inScale*weightsScale)
+	biases := make([][]uint64, outSize)
+	for r := range biases {
+		biases[r] = []uint64{bias0[r]}
+	}
+
+	l := &Dense{Backend: b, inScale: inScale, weightsScale: weightsScale, inSize: inSize}
+	l.W, l.WVerif = b.encryptAll(W, name+"/weight")
+	l.b, l.bVerif = b.encryptAll(biases, name+"/bias")
+	return l
+}
+
+func (l *Dense) eval(eval vche.GenericEvaluator, in interface{}, W, b []interface{}) []interface{} {
+	res := make([]interface{}, len(W))
+	for r := range W {
+		res[r] = rotateAndAdd(eval, eval.RelinearizeNew(eval.MulNew(in, W[r])), l.Rotations())
+		eval.Add(res[r], b[r], res[r])
+	}
+	return res
+}
+
+func (l *Dense) Eval(in interface{}) interface{} {
+	return l.eval(l.Evaluator, in, l.W, l.b)
+}
+
+func (l *Dense) Verif(in interface{}) interface{} {
+	return l.eval(l.EvaluatorPlaintext, in, l.WVerif, l.bVerif)
+}
+
+func (l *Dense) OutputScale() uint64 {
+	return l.inScale * l.weightsScale
+}
+
+func (l *Dense) Rotations() []int {
+	return powersOfTwo(1, nextPow2(l.inSize))
+}
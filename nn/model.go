@@ -0,0 +1,309 @@
+// Package nn provides verified neural-network inference on encrypted inputs with the LoLa layers, e.g., for the
+// networks of the NeuralNetworkInference example. The server evaluates each Layer on ciphertexts, and the client
+// evaluates it on the verification state of the same inputs, so that the decoder of the encoding verifies the
+// predictions.
+//
+// The first layer of a model is a convolution whose kernel positions are packed in separate vectors by Im2Col. The
+// following layers transform between the layouts of the LoLa network:
+//
+//	Convolution: one vector per kernel map, with the outputs of the map in its first slots
+//	CombineDense: a single vector with the outputs of the maps one after the other, as flattened by PyTorch
+//	Stack: copies of the vector in every block of nextPow2(inSize) slots
+//	MulStacked: the neurons of a dense layer, in the first slot of the blocks of several vectors
+//	CombineInterleaved: a single vector with the neurons of the i-th vector at offset i of the blocks
+//	Dense and MulInterleaved: one vector per neuron, with its value in the first slot
+//
+// Square is the activation function and keeps the layout.
+//
+// Values are fixed-point integers: each layer tracks the scale of its output, e.g., the product of the scale of its
+// input and of its weights, which the Model uses to scale the weights of the next layer and to decode the predictions.
+package nn
+
+import (
+	"fmt"
+
+	"veritas/vche/scheme"
+	"veritas/vche/vche"
+)
+
+// Backend holds the components of an encoding, e.g., REP (vche_1) or PE (vche_2), used by the layers. The client and
+// server share the encoder and encryptor, as the weights of the model are encrypted. EvaluatorPlaintext and
+// EncoderPlaintext may be nil on the server. Finalize, if not nil, turns the verification state of the predictions
+// into the one expected by the decoder, see scheme.Scheme.Finalize.
+type Backend struct {
+	Parameters         vche.Parameters
+	Encoder            vche.GenericEncoder
+	EncoderPlaintext   vche.GenericEncoderPlaintext
+	Encryptor          vche.GenericEncryptor
+	Decryptor          vche.GenericDecryptor
+	Evaluator          vche.GenericEvaluator
+	EvaluatorPlaintext vche.GenericEvaluator
+	Finalize           func(verif interface{}) interface{}
+}
+
+// NewBackend returns the backend of a scheme. The closed-form PRF variants do not support the layers that multiply
+// rotated vectors.
+func NewBackend(s *scheme.Scheme) Backend {
+	return Backend{
+		Parameters:         s.Parameters,
+		Encoder:            s.Encoder,
+		EncoderPlaintext:   s.EncoderPlaintext,
+		Encryptor:          s.Encryptor,
+		Decryptor:          s.Decryptor,
+		Evaluator:          s.Evaluator,
+		EvaluatorPlaintext: s.EvaluatorPlaintext,
+		Finalize:           s.Finalize,
+	}
+}
+
+// encrypt encodes and encrypts the given slots with the index tags of the given name, and computes their verification
+// state if the backend can.
+func (b Backend) encrypt(coeffs []uint64, name string) (ct, verif interface{}) {
+	tags := vche.GetIndexTags([]byte(name), len(coeffs))
+	ct = b.Encryptor.EncryptNew(b.Encoder.EncodeUintNew(coeffs, tags))
+	if b.EncoderPlaintext != nil {
+		verif = b.EncoderPlaintext.EncodeNew(tags)
+	}
+	return ct, verif
+}
+
+// Layer is a layer of a model.
+type Layer interface {
+	Eval(in interface{}) interface{}
+	Verif(in interface{}) interface{}
+	OutputScale() uint64
+}
+
+// Im2Col packs a single-channel image for a convolution: the i-th vector holds the pixels of the image that are
+// multiplied by the i-th weight of the kernel, in row-major order of the outputs of the convolution. The image is
+// padded with PaddingUp rows and columns of zeros at its top and left.
+type Im2Col struct {
+	Height     int `json:"height"`
+	Width      int `json:"width"`
+	KernelSize int `json:"kernel_size"`
+	Stride     int `json:"stride"`
+	PaddingUp  int `json:"padding_up"`
+}
+
+// OutputShape returns the height and width of the output of the convolution.
+func (e Im2Col) OutputShape() (int, int) {
+	return (e.Height-e.KernelSize+e.PaddingUp)/e.Stride + 1, (e.Width-e.KernelSize+e.PaddingUp)/e.Stride + 1
+}
+
+// NumOutputs returns the number of outputs of the convolution, per kernel map.
+func (e Im2Col) NumOutputs() int {
+	h, w := e.OutputShape()
+	return h * w
+}
+
+func (e Im2Col) check(params vche.Parameters) error {
+	if e.KernelSize <= 0 || e.Stride <= 0 || e.PaddingUp < 0 || e.Height < e.KernelSize || e.Width < e.KernelSize {
+		return fmt.Errorf("invalid input %+v", e)
+	}
+	if e.NumOutputs() > params.NSlots {
+		return fmt.Errorf("the %d outputs of the convolution exceed the %d slots", e.NumOutputs(), params.NSlots)
+	}
+	return nil
+}
+
+// Pack returns the KernelSize^2 vectors of the image, whose pixels are multiplied by scale and mapped to [0, T) as by
+// Rescale.
+func (e Im2Col) Pack(params vche.Parameters, img [][]float64, scale uint64) [][]uint64 {
+	if len(img) != e.Height {
+		panic(fmt.Errorf("image should have %d rows, got %d", e.Height, len(img)))
+	}
+	padded := make([][]uint64, e.Height+e.PaddingUp)
+	for i := range padded {
+		padded[i] = make([]uint64, e.Width+e.PaddingUp)
+		if i >= e.PaddingUp {
+			row := img[i-e.PaddingUp]
+			if len(row) != e.Width {
+				panic(fmt.Errorf("image should have %d columns, got %d in row %d", e.Width, len(row), i-e.PaddingUp))
+			}
+			copy(padded[i][e.PaddingUp:], Rescale(params, row, scale))
+		}
+	}
+
+	hOut, wOut := e.OutputShape()
+	vecs := make([][]uint64, e.KernelSize*e.KernelSize)
+	for ki := 0; ki < e.KernelSize; ki++ {
+		for kj := 0; kj < e.KernelSize; kj++ {
+			vec := make([]uint64, hOut*wOut)
+			for i := 0; i < hOut; i++ {
+				for j := 0; j < wOut; j++ {
+					vec[i*wOut+j] = padded[i*e.Stride+ki][j*e.Stride+kj]
+				}
+			}
+			vecs[ki*e.KernelSize+kj] = vec
+		}
+	}
+	return vecs
+}
+
+// Tags returns the tags of the vectors of an image with the given dataset tag.
+func (e Im2Col) Tags(datasetTag []byte) [][]vche.Tag {
+	tags := make([][]vche.Tag, e.KernelSize*e.KernelSize)
+	for i := range tags {
+		tags[i] = vche.GetIndexTags([]byte(fmt.Sprintf("%s/kernel%d", datasetTag, i)), e.NumOutputs())
+	}
+	return tags
+}
+
+// Model is a sequence of layers evaluated on an image packed by Im2Col. Its methods that add a layer compute the scale
+// of the input of the layer from the previous one.
+type Model struct {
+	Backend
+	Input      Im2Col
+	InputScale uint64
+	Layers     []Layer
+}
+
+// NewModel returns a model without layers, whose input pixels are multiplied by inputScale.
+func NewModel(backend Backend, input Im2Col, inputScale uint64) *Model {
+	must(input.check(backend.Parameters))
+	return &Model{Backend: backend, Input: input, InputScale: inputScale}
+}
+
+// Scale returns the scale of the output of the model.
+func (m *Model) Scale() uint64 {
+	if len(m.Layers) == 0 {
+		return m.InputScale
+	}
+	return m.Layers[len(m.Layers)-1].OutputScale()
+}
+
+// Add appends a layer to the model.
+func (m *Model) Add(l Layer) *Model {
+	m.Layers = append(m.Layers, l)
+	return m
+}
+
+// Rotations returns the rotations of the columns used by the layers of the model, for the generation of the rotation
+// keys. The layers that sum the two rows of a vector also need the key that swaps them.
+func (m *Model) Rotations() []int {
+	var rots []int
+	seen := map[int]bool{}
+	for _, l := range m.Layers {
+		if r, ok := l.(interface{ Rotations() []int }); ok {
+			for _, k := range r.Rotations() {
+				if !seen[k] {
+					seen[k] = true
+					rots = append(rots, k)
+				}
+			}
+		}
+	}
+	return rots
+}
+
+// EncryptImage packs, encodes and encrypts an image with the given dataset tag.
+func (m *Model) EncryptImage(img [][]float64, datasetTag []byte) []interface{} {
+	vecs, tags := m.Input.Pack(m.Parameters, img, m.InputScale), m.Input.Tags(datasetTag)
+	cts := make([]interface{}, len(vecs))
+	for i := range vecs {
+		cts[i] = m.Encryptor.EncryptNew(m.Encoder.EncodeUintNew(vecs[i], tags[i]))
+	}
+	return cts
+}
+
+// VerifImage returns the verification state of an image encrypted with the given dataset tag.
+func (m *Model) VerifImage(datasetTag []byte) []interface{} {
+	tags := m.Input.Tags(datasetTag)
+	verifs := make([]interface{}, len(tags))
+	for i := range tags {
+		verifs[i] = m.EncoderPlaintext.EncodeNew(tags[i])
+	}
+	return verifs
+}
+
+// Eval evaluates the layers of the model on the ciphertexts of an image, and returns the ciphertexts of the predictions.
+func (m *Model) Eval(x interface{}) []interface{} {
+	for _, l := range m.Layers {
+		x = l.Eval(x)
+	}
+	return x.([]interface{})
+}
+
+// Verif evaluates the layers of the model on the verification state of an image.
+func (m *Model) Verif(x interface{}) []interface{} {
+	for _, l := range m.Layers {
+		x = l.Verif(x)
+	}
+	return x.([]interface{})
+}
+
+// Decode verifies and decodes the predictions of the model, held in the first slot of their ciphertext.
+func (m *Model) Decode(cts, verifs []interface{}) []float64 {
+	if len(cts) != len(verifs) {
+		panic(fmt.Errorf("got %d predictions but %d verification states", len(cts), len(verifs)))
+	}
+	preds := make([]float64, len(cts))
+	for i := range cts {
+		verif := verifs[i]
+		if m.Finalize != nil {
+			verif = m.Finalize(verif)
+		}
+		vals := m.Encoder.DecodeIntNew(m.Decryptor.DecryptNew(cts[i]), verif)
+		preds[i] = float64(vals[0]) / float64(m.Scale())
+	}
+	return preds
+}
+
+// Convolution adds a convolution with the given kernels, of shape (maps, KernelSize, KernelSize) or (maps, 1,
+// KernelSize, KernelSize), and biases. It must be the first layer.
+func (m *Model) Convolution(name string, weights, bias Tensor, weightsScale uint64) *Model {
+	if len(m.Layers) != 0 {
+		panic(fmt.Errorf("the convolution should be the first layer"))
+	}
+	return m.Add(NewConvolution(m.Backend, name, m.Scale(), weightsScale, m.Input, weights, bias))
+}
+
+// CombineDense adds the layer that combines the kernel maps of the convolution.
+func (m *Model) CombineDense() *Model {
+	conv, ok := m.Layers[0].(*Convolution)
+	if !ok {
+		panic(fmt.Errorf("the model has no convolution"))
+	}
+	return m.Add(NewCombineDense(m.Backend, m.Scale(), len(conv.W), m.Input.NumOutputs()))
+}
+
+// Square adds the square activation function.
+func (m *Model) Square() *Model {
+	return m.Add(NewSquare(m.Backend, m.Scale()))
+}
+
+// Stack adds the layer that copies a vector of inSize values in all the blocks of the vector.
+func (m *Model) Stack(inSize int) *Model {
+	return m.Add(NewStack(m.Backend, m.Scale(), inSize))
+}
+
+// MulStacked adds a dense layer on the output of Stack, with weights of shape (outSize, inSize).
+func (m *Model) MulStacked(name string, weights, bias Tensor, weightsScale uint64) *Model {
+	return m.Add(NewMulStacked(m.Backend, name, m.Scale(), weightsScale, weights, bias))
+}
+
+// CombineInterleaved adds the layer that combines the vectors of the previous MulStacked.
+func (m *Model) CombineInterleaved() *Model {
+	stacked := m.lastMulStacked()
+	return m.Add(NewCombineInterleaved(m.Backend, m.Scale(), stacked.numVectors))
+}
+
+// MulInterleaved adds a dense layer on the output of CombineInterleaved, with weights of shape (outSize, inSize).
+func (m *Model) MulInterleaved(name string, weights, bias Tensor, weightsScale uint64) *Model {
+	stacked := m.lastMulStacked()
+	return m.Add(NewMulInterleaved(m.Backend, name, m.Scale(), weightsScale, stacked.blockSize, weights, bias))
+}
+
+// Dense adds a dense layer on a single vector, with weights of shape (outSize, inSize).
+func (m *Model) Dense(name string, weights, bias Tensor, weightsScale uint64) *Model {
+	return m.Add(NewDense(m.Backend, name, m.Scale(), weightsScale, weights, bias))
+}
+
+func (m *Model) lastMulStacked() *MulStacked {
+	for i := len(m.Layers) - 1; i >= 0; i-- {
+		if l, ok := m.Layers[i].(*MulStacked); ok {
+			return l
+		}
+	}
+	panic(fmt.Errorf("the model has no MulStacked layer"))
+}
@@ -0,0 +1,214 @@
+package nn
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"veritas/vche/scheme"
+	"veritas/vche/vche"
+	"veritas/vche/vche_1"
+	"veritas/vche/vche_2"
+)
+
+var testInput = Im2Col{Height: 6, Width: 6, KernelSize: 2, Stride: 2}
+
+// testParams are the parameters of the tests that need no keys.
+var testParams = func() vche.Parameters {
+	params, err := vche_1.NewParametersFromLiteral(vche_1.DefaultParams[1])
+	if err != nil {
+		panic(err)
+	}
+	return params
+}()
+
+// testTensor returns a tensor of the given shape with values in {-step, 0, step}.
+func testTensor(seed int, step float64, shape ...int) Tensor {
+	t := Tensor{shape, make([]float64, Size(shape))}
+	for i := range t.Data {
+		t.Data[i] = float64((i*7+seed*3)%5%3-1) * step
+	}
+	return t
+}
+
+func testImage() [][]float64 {
+	img := make([][]float64, testInput.Height)
+	for i := range img {
+		img[i] = make([]float64, testInput.Width)
+		for j := range img[i] {
+			img[i][j] = float64((i + 2*j) % 2)
+		}
+	}
+	return img
+}
+
+// dense returns W x + b, for W of shape (outSize, inSize).
+func dense(w, b Tensor, x []float64) []float64 {
+	y := make([]float64, w.Shape[0])
+	for r := range y {
+		y[r] = b.Data[r]
+		for n := range x {
+			y[r] += w.Data[r*len(x)+n] * x[n]
+		}
+	}
+	return y
+}
+
+func square(x []float64) []float64 {
+	y := make([]float64, len(x))
+	for i := range x {
+		y[i] = x[i] * x[i]
+	}
+	return y
+}
+
+// convolve returns the outputs of the convolution of the image, flattened by map.
+func convolve(w, b Tensor, img [][]float64) []float64 {
+	vecs := testInput.Pack(testParams, img, 1)
+	numMaps, k2 := w.Shape[0], len(vecs)
+	var y []float64
+	for m := 0; m < numMaps; m++ {
+		for o := 0; o < testInput.NumOutputs(); o++ {
+			v := b.Data[m]
+			for i := 0; i < k2; i++ {
+				v += w.Data[m*k2+i] * float64(vecs[i][o])
+			}
+			y = append(y, v)
+		}
+	}
+	return y
+}
+
+// newBackend returns the backend of a new scheme, with the rotation keys of the model built by build, whose weights
+// are encrypted by a throwaway model.
+func newBackend(t *testing.T, cfg scheme.Config, build func(b Backend) *Model) Backend {
+	s, err := scheme.New(cfg)
+	require.NoError(t, err)
+	b := NewBackend(s)
+	b.Evaluator = s.NewEvaluator(build(b).Rotations(), true, false)
+	return b
+}
+
+func TestDenseModel(t *testing.T) {
+	convW, convB := testTensor(0, 0.5, 2, 1, 2, 2), testTensor(1, 0.5, 2)
+	linW, linB := testTensor(2, 0.5, 3, 2*testInput.NumOutputs()), testTensor(3, 0.5, 3)
+	build := func(b Backend) *Model {
+		return NewModel(b, testInput, 1).Convolution("conv1", convW, convB, 2).CombineDense().Square().Dense("lin1", linW, linB, 2)
+	}
+	want := dense(linW, linB, square(convolve(convW, convB, testImage())))
+
+	for _, cfg := range []scheme.Config{
+		{Encoding: scheme.REP, Params: vche_1.DefaultParams[1]},
+		{Encoding: scheme.PE, Params: vche_2.DefaultParams[1]},
+	} {
+		t.Run(string(cfg.Encoding), func(t *testing.T) {
+			m := build(newBackend(t, cfg, build))
+			// (input * conv)^2 * dense
+			require.Equal(t, uint64((1*2)*(1*2)*2), m.Scale())
+
+			res := m.Eval(m.EncryptImage(testImage(), []byte("img0")))
+			verifs := m.Verif(m.VerifImage([]byte("img0")))
+			require.InDeltaSlice(t, want, m.Decode(res, verifs), 1e-9)
+
+			// The verification of another image does not match
+			require.True(t, vche.Rejects(func() { m.Decode(res, m.Verif(m.VerifImage([]byte("img1")))) }))
+		})
+	}
+}
+
+func TestLoLaModel(t *testing.T) {
+	inSize := 2 * testInput.NumOutputs()
+	weights := Weights{
+		"conv1.weight": testTensor(0, 1, 2, 2, 2),
+		"conv1.bias":   testTensor(1, 1, 2),
+		"fc1.weight":   testTensor(2, 1, 6, inSize),
+		"fc1.bias":     testTensor(3, 1, 6),
+		"fc2.weight":   testTensor(4, 1, 3, 6),
+		"fc2.bias":     testTensor(5, 1, 3),
+	}
+	var buf bytes.Buffer
+	require.NoError(t, json.NewEncoder(&buf).Encode(weights))
+	weights, err := LoadWeights(&buf)
+	require.NoError(t, err)
+
+	spec, err := LoadSpec(strings.NewReader(`{
+		"input": {"height": 6, "width": 6, "kernel_size": 2, "stride": 2},
+		"input_scale": 1, "weights_scale": 1,
+		"layers": [
+			{"type": "Convolution", "weight": "conv1.weight", "bias": "conv1.bias"}, {"type": "CombineDense"},
+			{"type": "Square"}, {"type": "Stack", "in_size": 18},
+			{"type": "MulStacked", "weight": "fc1.weight", "bias": "fc1.bias"}, {"type": "CombineInterleaved"},
+			{"type": "Square"}, {"type": "MulInterleaved", "weight": "fc2.weight", "bias": "fc2.bias"}
+		]}`))
+	require.NoError(t, err)
+
+	want := dense(weights["fc2.weight"], weights["fc2.bias"], square(dense(weights["fc1.weight"], weights["fc1.bias"],
+		square(convolve(weights["conv1.weight"], weights["conv1.bias"], testImage())))))
+
+	// A larger plaintext modulus holds the intermediate values of the deeper network
+	literal := vche_1.DefaultParams[2]
+	literal.T = 786433
+	for _, y := range want {
+		require.Less(t, math.Abs(y), float64(literal.T/2))
+	}
+	build := func(b Backend) *Model {
+		m, err := NewModelFromSpec(b, spec, weights)
+		require.NoError(t, err)
+		return m
+	}
+	m := build(newBackend(t, scheme.Config{Encoding: scheme.REP, Params: literal}, build))
+	res := m.Eval(m.EncryptImage(testImage(), []byte("img0")))
+	verifs := m.Verif(m.VerifImage([]byte("img0")))
+	require.InDeltaSlice(t, want, m.Decode(res, verifs), 1e-9)
+}
+
+func TestSpecErrors(t *testing.T) {
+	weights := Weights{
+		"w": testTensor(0, 1, 2, 2, 2), "b": testTensor(0, 1, 2), "b3": testTensor(0, 1, 3),
+		"big":     testTensor(0, float64(testParams.T()), 2, 2, 2),
+		"wide":    testTensor(0, 1, 1, testParams.NSlots),
+		"fc.w":    testTensor(0, 1, 6, 2*testInput.NumOutputs()),
+		"fc.b":    testTensor(0, 1, 6),
+		"b1":      testTensor(0, 1, 1),
+		"fc.wide": testTensor(0, 1, 1, testParams.NSlots+1),
+	}
+	conv := LayerSpec{Type: "Convolution", Weight: "w", Bias: "b"}
+	stacked := []LayerSpec{conv, {Type: "CombineDense"}, {Type: "Stack", InSize: 18}, {Type: "MulStacked", Weight: "fc.w", Bias: "fc.b"}}
+	for name, spec := range map[string]Spec{
+		"scale":            {Input: testInput, InputScale: 1, WeightsScale: 0},
+		"input":            {Input: Im2Col{Height: 1, Width: 1, KernelSize: 2, Stride: 1}, InputScale: 1, WeightsScale: 1},
+		"input slots":      {Input: Im2Col{Height: 1 << 10, Width: 1 << 10, KernelSize: 1, Stride: 1}, InputScale: 1, WeightsScale: 1},
+		"first layer":      {Input: testInput, InputScale: 1, WeightsScale: 1, Layers: []LayerSpec{{Type: "Square"}}},
+		"missing weights":  {Input: testInput, InputScale: 1, WeightsScale: 1, Layers: []LayerSpec{{Type: "Convolution", Weight: "missing", Bias: "b"}}},
+		"unknown type":     {Input: testInput, InputScale: 1, WeightsScale: 1, Layers: []LayerSpec{conv, {Type: "Pool"}}},
+		"two convolutions": {Input: testInput, InputScale: 1, WeightsScale: 1, Layers: []LayerSpec{conv, conv}},
+		"biases":           {Input: testInput, InputScale: 1, WeightsScale: 1, Layers: []LayerSpec{{Type: "Convolution", Weight: "w", Bias: "b3"}}},
+		"large weights":    {Input: testInput, InputScale: 1, WeightsScale: 1, Layers: []LayerSpec{{Type: "Convolution", Weight: "big", Bias: "b"}}},
+		"large scale":      {Input: testInput, InputScale: 1 << 20, WeightsScale: 1 << 20, Layers: []LayerSpec{conv}},
+		"squares":          {Input: testInput, InputScale: 4, WeightsScale: 4, Layers: []LayerSpec{conv, {Type: "Square"}, {Type: "Square"}, {Type: "Square"}}},
+		"stack":            {Input: testInput, InputScale: 1, WeightsScale: 1, Layers: []LayerSpec{conv, {Type: "Stack", InSize: testParams.NSlots}}},
+		"dense":            {Input: testInput, InputScale: 1, WeightsScale: 1, Layers: []LayerSpec{conv, {Type: "Dense", Weight: "wide", Bias: "b1"}}},
+		"no MulStacked":    {Input: testInput, InputScale: 1, WeightsScale: 1, Layers: []LayerSpec{conv, {Type: "CombineInterleaved"}}},
+		"interleaved":      {Input: testInput, InputScale: 1, WeightsScale: 1, Layers: append(stacked, LayerSpec{Type: "MulInterleaved", Weight: "fc.wide", Bias: "b1"})},
+	} {
+		_, err := NewModelFromSpec(Backend{Parameters: testParams}, spec, weights)
+		require.Error(t, err, name)
+	}
+
+	_, err := LoadWeights(strings.NewReader(`{"w": {"shape": [2, 2], "data": [1, 2, 3]}}`))
+	require.Error(t, err)
+	_, err = LoadWeights(strings.NewReader(`{"w": {"shape": [0, 2], "data": []}}`))
+	require.Error(t, err)
+}
+
+func TestPackNegative(t *testing.T) {
+	img := testImage()
+	img[0][0], img[0][1] = -1, -2.4
+	vecs := testInput.Pack(testParams, img, 2)
+	require.Equal(t, testParams.T()-2, vecs[0][0])
+	require.Equal(t, testParams.T()-5, vecs[1][0])
+	require.Panics(t, func() { testInput.Pack(testParams, img, testParams.T()) })
+}
@@ -0,0 +1,162 @@
+package nn
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/bits"
+
+	"veritas/vche/vche"
+)
+
+// LayerSpec describes a layer of a model. Weight and Bias name the tensors of the layers that have weights, and InSize
+// is the size of the input of Stack.
+type LayerSpec struct {
+	Type   string `json:"type"`
+	Weight string `json:"weight,omitempty"`
+	Bias   string `json:"bias,omitempty"`
+	InSize int    `json:"in_size,omitempty"`
+}
+
+// Spec describes a model, e.g., the small LoLa network for MNIST:
+//
+//	{"input": {"height": 28, "width": 28, "kernel_size": 5, "stride": 4, "padding_up": 1},
+//	 "input_scale": 4, "weights_scale": 128,
+//	 "layers": [{"type": "Convolution", "weight": "conv1.weight", "bias": "conv1.bias"}, {"type": "CombineDense"},
+//	            {"type": "Square"}, {"type": "Dense", "weight": "lin1.weight", "bias": "lin1.bias"}]}
+//
+// The weights are read separately, see LoadWeights, or inline in the field weights.
+type Spec struct {
+	Input        Im2Col      `json:"input"`
+	InputScale   uint64      `json:"input_scale"`
+	WeightsScale uint64      `json:"weights_scale"`
+	Layers       []LayerSpec `json:"layers"`
+	Weights      Weights     `json:"weights,omitempty"`
+}
+
+// LoadSpec reads the JSON description of a model.
+func LoadSpec(r io.Reader) (Spec, error) {
+	var spec Spec
+	if err := json.NewDecoder(r).Decode(&spec); err != nil {
+		return Spec{}, fmt.Errorf("could not read model: %v", err)
+	}
+	for name, t := range spec.Weights {
+		if err := t.check(); err != nil {
+			return Spec{}, fmt.Errorf("invalid weights %s: %v", name, err)
+		}
+	}
+	return spec, nil
+}
+
+// NewModelFromSpec builds the model described by spec with the given weights, or the weights of spec if nil. The
+// names of the layers, which derive the tags of their weights, are those of their weight tensors. The whole spec is
+// checked against the parameters of the backend before encrypting any weight, so that the layers do not panic.
+func NewModelFromSpec(backend Backend, spec Spec, weights Weights) (*Model, error) {
+	if weights == nil {
+		weights = spec.Weights
+	}
+	if spec.InputScale == 0 || spec.WeightsScale == 0 {
+		return nil, fmt.Errorf("the scales should be positive, got %d and %d", spec.InputScale, spec.WeightsScale)
+	}
+	if err := spec.Input.check(backend.Parameters); err != nil {
+		return nil, err
+	}
+	ws, bs := make([]Tensor, len(spec.Layers)), make([]Tensor, len(spec.Layers))
+	// Follow the scale and layout of the outputs as the Model does
+	scale, numMaps, blockSize := spec.InputScale, 0, 0
+	for i, l := range spec.Layers {
+		if i == 0 && l.Type != "Convolution" {
+			return nil, fmt.Errorf("the first layer should be a Convolution, got %s", l.Type)
+		}
+		inScale := scale
+		var err error
+		switch l.Type {
+		case "Convolution", "MulStacked", "MulInterleaved", "Dense":
+			// Kernels have shape (maps, k, k), or (maps, 1, k, k) as in PyTorch
+			dims := 2
+			if l.Type == "Convolution" {
+				dims = 3
+				if len(weights[l.Weight].Shape) == 4 {
+					dims = 4
+				}
+			}
+			if ws[i], err = weights.Get(l.Weight, dims); err == nil {
+				bs[i], err = weights.Get(l.Bias, 1)
+			}
+			if err == nil {
+				err = mulScale(backend.Parameters, &scale, spec.WeightsScale)
+			}
+		case "Square":
+			err = mulScale(backend.Parameters, &scale, scale)
+		case "Stack":
+			if l.InSize <= 0 {
+				err = fmt.Errorf("in_size should be positive, got %d", l.InSize)
+			}
+		case "CombineDense", "CombineInterleaved":
+		default:
+			err = fmt.Errorf("unknown type")
+		}
+		if err == nil {
+			switch l.Type {
+			case "Convolution":
+				if i != 0 {
+					err = fmt.Errorf("the convolution should be the first layer")
+				} else {
+					err = checkConvolution(backend, inScale, spec.WeightsScale, spec.Input, ws[i], bs[i])
+					numMaps = ws[i].Shape[0]
+				}
+			case "CombineDense":
+				err = backend.checkFits(numMaps*spec.Input.NumOutputs(), "the combined maps")
+			case "Stack":
+				err = backend.checkFits(nextPow2(l.InSize), "the block")
+			case "MulStacked":
+				err = checkMulStacked(backend, inScale, spec.WeightsScale, ws[i], bs[i])
+				blockSize = nextPow2(ws[i].Shape[1])
+			case "CombineInterleaved", "MulInterleaved":
+				if blockSize == 0 {
+					err = fmt.Errorf("the model has no MulStacked layer")
+				} else if l.Type == "MulInterleaved" {
+					err = checkMulInterleaved(backend, inScale, spec.WeightsScale, blockSize, ws[i], bs[i])
+				}
+			case "Dense":
+				err = checkDense(backend, inScale, spec.WeightsScale, ws[i], bs[i])
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("layer %d (%s): %v", i, l.Type, err)
+		}
+	}
+
+	m := NewModel(backend, spec.Input, spec.InputScale)
+	for i, l := range spec.Layers {
+		switch l.Type {
+		case "Convolution":
+			m.Convolution(l.Weight, ws[i], bs[i], spec.WeightsScale)
+		case "CombineDense":
+			m.CombineDense()
+		case "Square":
+			m.Square()
+		case "Stack":
+			m.Stack(l.InSize)
+		case "MulStacked":
+			m.MulStacked(l.Weight, ws[i], bs[i], spec.WeightsScale)
+		case "CombineInterleaved":
+			m.CombineInterleaved()
+		case "MulInterleaved":
+			m.MulInterleaved(l.Weight, ws[i], bs[i], spec.WeightsScale)
+		case "Dense":
+			m.Dense(l.Weight, ws[i], bs[i], spec.WeightsScale)
+		}
+	}
+	return m, nil
+}
+
+// mulScale multiplies scale by factor, and checks that a unit at the new scale fits in the plaintext modulus.
+func mulScale(params vche.Parameters, scale *uint64, factor uint64) error {
+	hi, lo := bits.Mul64(*scale, factor)
+	if hi != 0 || lo > params.T()>>1 {
+		return fmt.Errorf("the scale %d x %d exceeds the plaintext modulus %d", *scale, factor, params.T())
+	}
+	*scale = lo
+	return nil
+}
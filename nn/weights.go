@@ -0,0 +1,137 @@
+package nn
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"veritas/vche/vche"
+)
+
+// Tensor is an array of weights in row-major order, e.g., the weights of a dense layer of shape (outSize, inSize).
+type Tensor struct {
+	Shape []int     `json:"shape"`
+	Data  []float64 `json:"data"`
+}
+
+// Size returns the number of weights of a tensor of the given shape.
+func Size(shape []int) int {
+	size := 1
+	for _, d := range shape {
+		size *= d
+	}
+	return size
+}
+
+func (t Tensor) check() error {
+	for _, d := range t.Shape {
+		if d <= 0 {
+			return fmt.Errorf("tensor of shape %v should have positive dimensions", t.Shape)
+		}
+	}
+	if len(t.Shape) == 0 || Size(t.Shape) != len(t.Data) {
+		return fmt.Errorf("tensor of shape %v should have %d values, got %d", t.Shape, Size(t.Shape), len(t.Data))
+	}
+	return nil
+}
+
+// Weights are named tensors, as stored in the initializers of an ONNX graph or the state dictionary of a PyTorch model.
+type Weights map[string]Tensor
+
+// LoadWeights reads weights from a JSON object that maps the name of each tensor to its shape and data:
+//
+//	{"conv1.weight": {"shape": [5, 5, 5], "data": [...]}, "conv1.bias": {"shape": [5], "data": [...]}}
+func LoadWeights(r io.Reader) (Weights, error) {
+	var w Weights
+	if err := json.NewDecoder(r).Decode(&w); err != nil {
+		return nil, fmt.Errorf("could not read weights: %v", err)
+	}
+	for name, t := range w {
+		if err := t.check(); err != nil {
+			return nil, fmt.Errorf("invalid weights %s: %v", name, err)
+		}
+	}
+	return w, nil
+}
+
+// LoadWeightsFile reads weights from a JSON file, see LoadWeights.
+func LoadWeightsFile(path string) (Weights, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadWeights(f)
+}
+
+// Get returns the tensor of the given name, after checking that it has the given number of dimensions.
+func (w Weights) Get(name string, dims int) (Tensor, error) {
+	t, ok := w[name]
+	if !ok {
+		return Tensor{}, fmt.Errorf("missing weights %s", name)
+	}
+	if len(t.Shape) != dims {
+		return Tensor{}, fmt.Errorf("weights %s should have %d dimensions, got shape %v", name, dims, t.Shape)
+	}
+	return t, nil
+}
+
+// checkRescale checks that the values of v multiplied by scale are in (-T/2, T/2].
+func checkRescale(params vche.Parameters, v []float64, scale uint64) error {
+	modulusHalf := float64(params.T() >> 1)
+	for i := range v {
+		if scaled := math.Round(float64(scale) * v[i]); math.IsNaN(scaled) || math.Abs(scaled) > modulusHalf {
+			return fmt.Errorf("value %f at scale %d exceeds the plaintext modulus %d", v[i], scale, params.T())
+		}
+	}
+	return nil
+}
+
+// Rescale multiplies the values of v by scale, rounds them, and maps them to [0, T), the negative values to T-|v|.
+func Rescale(params vche.Parameters, v []float64, scale uint64) []uint64 {
+	must(checkRescale(params, v, scale))
+	res := make([]uint64, len(v))
+	for i := range v {
+		scaled := math.Round(float64(scale) * v[i])
+		if scaled < 0 {
+			res[i] = uint64(int64(params.T()) + int64(scaled))
+		} else {
+			res[i] = uint64(scaled)
+		}
+	}
+	return res
+}
+
+// Reshape2D reshapes v into an m x n matrix.
+func Reshape2D(v []uint64, m, n int) [][]uint64 {
+	if len(v) != m*n {
+		panic(fmt.Errorf("cannot reshape %d values into %d x %d", len(v), m, n))
+	}
+	res := make([][]uint64, m)
+	for i := range res {
+		res[i] = append([]uint64{}, v[i*n:(i+1)*n]...)
+	}
+	return res
+}
+
+// Reshape3D reshapes v into an l x m x n array.
+func Reshape3D(v []uint64, l, m, n int) [][][]uint64 {
+	if len(v) != l*m*n {
+		panic(fmt.Errorf("cannot reshape %d values into %d x %d x %d", len(v), l, m, n))
+	}
+	res := make([][][]uint64, l)
+	for i := range res {
+		res[i] = Reshape2D(v[i*m*n:(i+1)*m*n], m, n)
+	}
+	return res
+}
+
+func nextPow2(x int) int {
+	p := 1
+	for p < x {
+		p <<= 1
+	}
+	return p
+}
@@ -0,0 +1,214 @@
+package planner
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/ldsec/lattigo/v2/bfv"
+	"github.com/ldsec/lattigo/v2/ring"
+	"github.com/ldsec/lattigo/v2/rlwe"
+	"github.com/ldsec/lattigo/v2/utils"
+	"veritas/vche/vche"
+	"veritas/vche/vche_1"
+)
+
+// Encoding is the VERITAS encoding the parameters are planned for.
+type Encoding int
+
+const (
+	REP Encoding = iota // replication encoding (vche_1)
+	PE                  // polynomial encoding (vche_2)
+)
+
+func (e Encoding) String() string {
+	switch e {
+	case REP:
+		return "REP"
+	case PE:
+		return "PE"
+	default:
+		return fmt.Sprintf("Encoding(%d)", int(e))
+	}
+}
+
+// MaxLogQP128 gives, for each log2 of the ring degree, the largest log2(QP) that ensures 128-bit classical security with
+// ternary secrets, following the homomorphic encryption standard.
+var MaxLogQP128 = map[int]int{
+	12: 109,
+	13: 218,
+	14: 438,
+	15: 881,
+	16: 1761,
+}
+
+// noiseMarginBits is the number of bits of noise budget that the planner keeps on top of the noise estimate.
+const noiseMarginBits = 10
+
+// Requirements describes the computation that the parameters must support.
+type Requirements struct {
+	Encoding      Encoding
+	Depth         int     // multiplicative depth of the circuit
+	PlaintextBits int     // the values (and all intermediate results) must fit in PlaintextBits bits
+	SoundnessBits float64 // a cheating server must pass the verification with probability at most 2^-SoundnessBits
+	MinSlots      int     // minimum number of values packed per ciphertext, after replication
+	Contributions bool    // the inputs are contributions encoded by an Issuer, whose noise is vche.NoiseModel.Issued
+}
+
+// Plan is a set of parameters proposed for some Requirements, along with the reasoning behind it.
+type Plan struct {
+	Requirements
+	Literal       vche.ParametersLiteral
+	Slots         int     // number of values per ciphertext after replication
+	LogT          float64 // log2 of the plaintext modulus
+	LogQP         float64 // log2 of the modulus of the key-switching keys
+	MaxLogQP      int     // largest log2(QP) for 128-bit security at this ring degree
+	Noise         float64 // estimated noise after Depth multiplications, in bits
+	Capacity      float64 // noise capacity of the parameters, in bits
+	Soundness     float64 // achieved soundness, in bits
+	rejectedLogNs []string
+}
+
+// NewPlan proposes parameters for req: an NTT-friendly plaintext modulus T large enough for the values (and, for PE,
+// for the soundness), the number of replications and dummies for REP, and the smallest ring degree whose Q chain holds
+// the estimated noise of req.Depth multiplications while ensuring 128-bit security.
+// It returns a non-nil error if no ring degree supported by Lattigo fits the requirements.
+func NewPlan(req Requirements) (*Plan, error) {
+	if req.Depth < 0 || req.PlaintextBits <= 0 {
+		return nil, fmt.Errorf("depth should be non-negative and plaintext bits positive, were %d and %d", req.Depth, req.PlaintextBits)
+	}
+
+	plan := &Plan{Requirements: req}
+	numReplications, numDummies := 1, 0
+	logT := req.PlaintextBits + 1
+	switch req.Encoding {
+	case REP:
+		numReplications, numDummies = vche_1.ReplicationsForSoundness(req.SoundnessBits)
+	case PE:
+		// A deviation of the server changes the outer polynomial of degree at most 2^depth, which goes unnoticed only if
+		// the secret evaluation point is one of its roots (Schwartz-Zippel).
+		logT = utils.MaxInt(logT, int(math.Ceil(req.SoundnessBits))+req.Depth+1)
+	default:
+		return nil, fmt.Errorf("unknown encoding %v", req.Encoding)
+	}
+	if logT > rlwe.MaxModuliSize-1 {
+		return nil, fmt.Errorf("a plaintext modulus of %d bits is needed, at most %d are supported", logT, rlwe.MaxModuliSize-1)
+	}
+
+	minSlots := utils.MaxInt(req.MinSlots, 1)
+	for logN := 12; logN <= rlwe.MaxLogN; logN++ {
+		if (1<<logN)/numReplications < minSlots {
+			plan.rejectedLogNs = append(plan.rejectedLogNs, fmt.Sprintf("LogN=%d: %d slots after %d replications, %d needed", logN, (1<<logN)/numReplications, numReplications, minSlots))
+			continue
+		}
+		t := ring.GenerateNTTPrimesP(logT, 2<<logN, 1)[0]
+		noise, err := estimateNoise(logN, t, req)
+		if err != nil {
+			return nil, err
+		}
+
+		logQ := int(math.Ceil(noise+math.Log2(float64(t)))) + 1 + noiseMarginBits
+		logQi := qChain(logQ, logT+1)
+		logP := logQi[0]
+		if logQP := logQi[0]*len(logQi) + logP; logQP > MaxLogQP128[logN] {
+			plan.rejectedLogNs = append(plan.rejectedLogNs, fmt.Sprintf("LogN=%d: log2(QP) = %d exceeds the 128-bit security bound of %d", logN, logQP, MaxLogQP128[logN]))
+			continue
+		}
+
+		plan.Literal = vche.ParametersLiteral{
+			ParametersLiteral:  bfv.ParametersLiteral{LogN: logN, T: t, LogQ: logQi, LogP: []int{logP}, Sigma: rlwe.DefaultSigma},
+			NumReplications:    numReplications,
+			NumDummies:         numDummies,
+			NumDistinctPRFKeys: 1,
+		}
+		params, err := plan.Parameters()
+		if err != nil {
+			return nil, err
+		}
+		model := vche.NewNoiseModel(params)
+		plan.Slots = params.NSlots
+		plan.LogT = math.Log2(float64(t))
+		plan.LogQP = float64(params.LogQP())
+		plan.MaxLogQP = MaxLogQP128[logN]
+		plan.Noise = noise
+		plan.Capacity = model.Capacity()
+		if req.Encoding == REP {
+			plan.Soundness = vche_1.SoundnessBits(numReplications, numDummies)
+		} else {
+			plan.Soundness = plan.LogT - float64(req.Depth)
+		}
+		return plan, nil
+	}
+	return nil, fmt.Errorf("no ring degree fits the requirements:\n%s", strings.Join(plan.rejectedLogNs, "\n"))
+}
+
+// estimateNoise returns the noise after req.Depth squarings followed by relinearizations of a fresh or, if
+// req.Contributions, issued input, for the ring degree 2^logN and the plaintext modulus t. The noise model does not depend on Q, so it is evaluated on a placeholder Q chain.
+func estimateNoise(logN int, t uint64, req Requirements) (float64, error) {
+	params, err := vche.NewParametersFromLiteral(vche.ParametersLiteral{
+		ParametersLiteral:  bfv.ParametersLiteral{LogN: logN, T: t, LogQ: []int{60}, LogP: []int{60}, Sigma: rlwe.DefaultSigma},
+		NumReplications:    1,
+		NumDistinctPRFKeys: 1,
+	})
+	if err != nil {
+		return 0, err
+	}
+	model := vche.NewNoiseModel(params)
+	noise := model.Fresh()
+	if req.Contributions {
+		noise = model.Issued()
+	}
+	components := 2 // a fresh PE ciphertext has two components
+	for i := 0; i < req.Depth; i++ {
+		terms := 1
+		if req.Encoding == PE {
+			terms = components
+			components = 2*components - 1
+		}
+		noise = model.KeySwitch(model.Mul(noise, noise, terms))
+	}
+	return noise, nil
+}
+
+// qChain splits logQ bits into moduli of at most rlwe.MaxModuliSize bits, each of at least minLogQi bits, so that the
+// plaintext modulus is smaller than the first modulus.
+func qChain(logQ, minLogQi int) []int {
+	k := (logQ + rlwe.MaxModuliSize - 1) / rlwe.MaxModuliSize
+	logQi := utils.MaxInt((logQ+k-1)/k, minLogQi)
+	chain := make([]int, k)
+	for i := range chain {
+		chain[i] = logQi
+	}
+	return chain
+}
+
+// Parameters instantiates the planned parameters.
+func (plan *Plan) Parameters() (vche.Parameters, error) {
+	return vche.NewParametersFromLiteral(plan.Literal)
+}
+
+// String explains the plan: the choice of each parameter, and the resulting slot capacity.
+func (plan *Plan) String() string {
+	var b strings.Builder
+	lit := plan.Literal
+	fmt.Fprintf(&b, "encoding: %v, depth %d, %d-bit values, %.0f-bit soundness, %d slots\n", plan.Encoding, plan.Depth, plan.PlaintextBits, plan.SoundnessBits, plan.MinSlots)
+	for _, r := range plan.rejectedLogNs {
+		fmt.Fprintf(&b, "  rejected %s\n", r)
+	}
+	fmt.Fprintf(&b, "LogN = %d (N = %d)\n", lit.LogN, 1<<lit.LogN)
+	fmt.Fprintf(&b, "T = %d (%.1f bits, T = 1 mod 2N for batching)\n", lit.T, plan.LogT)
+	fmt.Fprintf(&b, "LogQ = %v, LogP = %v: log2(QP) = %.0f <= %d for 128-bit security\n", lit.LogQ, lit.LogP, plan.LogQP, plan.MaxLogQP)
+	if plan.Contributions {
+		fmt.Fprintf(&b, "inputs issued from contributions: plaintext multiplication by the verification secrets\n")
+	}
+	fmt.Fprintf(&b, "noise after %d multiplications: %.1f bits, capacity %.1f bits\n", plan.Depth, plan.Noise, plan.Capacity)
+	switch plan.Encoding {
+	case REP:
+		fmt.Fprintf(&b, "replications: %d, dummies: %d, soundness %.1f bits\n", lit.NumReplications, lit.NumDummies, plan.Soundness)
+		fmt.Fprintf(&b, "slots: N / %d replications = %d values per ciphertext\n", lit.NumReplications, plan.Slots)
+	case PE:
+		fmt.Fprintf(&b, "outer degree after %d multiplications: %d, soundness log2(T) - %d = %.1f bits\n", plan.Depth, 1<<plan.Depth, plan.Depth, plan.Soundness)
+		fmt.Fprintf(&b, "slots: %d values per ciphertext, each encrypted in %d BFV ciphertexts after %d multiplications\n", plan.Slots, 1<<plan.Depth+1, plan.Depth)
+	}
+	return b.String()
+}
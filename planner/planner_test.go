@@ -0,0 +1,85 @@
+package planner
+
+import (
+	"github.com/ldsec/lattigo/v2/ring"
+	"github.com/ldsec/lattigo/v2/rlwe"
+	"github.com/stretchr/testify/require"
+	"veritas/vche/vche"
+	"veritas/vche/vche_1"
+	"testing"
+)
+
+func TestPlanner(t *testing.T) {
+	t.Run("Invariants", func(t *testing.T) {
+		for _, req := range []Requirements{
+			{Encoding: REP, Depth: 0, PlaintextBits: 16, SoundnessBits: 40},
+			{Encoding: REP, Depth: 2, PlaintextBits: 20, SoundnessBits: 40, MinSlots: 1024},
+			{Encoding: PE, Depth: 1, PlaintextBits: 20, SoundnessBits: 40},
+			{Encoding: PE, Depth: 3, PlaintextBits: 16, SoundnessBits: 30},
+			{Encoding: REP, Depth: 2, PlaintextBits: 20, SoundnessBits: 40, Contributions: true},
+			{Encoding: PE, Depth: 1, PlaintextBits: 20, SoundnessBits: 40, Contributions: true},
+		} {
+			plan, err := NewPlan(req)
+			require.NoError(t, err)
+			params, err := plan.Parameters()
+			require.NoError(t, err)
+
+			require.True(t, ring.IsPrime(params.T()))
+			require.Equal(t, uint64(1), params.T()%uint64(2*params.N()))
+			require.Greater(t, params.T(), uint64(1)<<req.PlaintextBits)
+			require.LessOrEqual(t, params.LogQP(), MaxLogQP128[params.LogN()])
+			require.GreaterOrEqual(t, params.NSlots, req.MinSlots)
+			require.Less(t, plan.Noise, plan.Capacity)
+			require.GreaterOrEqual(t, plan.Soundness, req.SoundnessBits)
+			require.NotEmpty(t, plan.String())
+		}
+	})
+
+	t.Run("Contributions", func(t *testing.T) {
+		req := Requirements{Encoding: PE, Depth: 2, PlaintextBits: 16, SoundnessBits: 30}
+		plan, err := NewPlan(req)
+		require.NoError(t, err)
+		req.Contributions = true
+		issued, err := NewPlan(req)
+		require.NoError(t, err)
+		require.Greater(t, issued.Noise, plan.Noise)
+	})
+
+	t.Run("Infeasible", func(t *testing.T) {
+		_, err := NewPlan(Requirements{Encoding: PE, Depth: 2, PlaintextBits: 16, SoundnessBits: 80})
+		require.Error(t, err)
+		_, err = NewPlan(Requirements{Encoding: REP, Depth: 1, PlaintextBits: 16, SoundnessBits: 40, MinSlots: 1 << 16})
+		require.Error(t, err)
+	})
+
+	t.Run("REP/Depth=2", func(t *testing.T) {
+		plan, err := NewPlan(Requirements{Encoding: REP, Depth: 2, PlaintextBits: 16, SoundnessBits: 20})
+		require.NoError(t, err)
+		params, err := plan.Parameters()
+		require.NoError(t, err)
+
+		kgen := vche_1.NewKeyGenerator(params)
+		sk := kgen.GenSecretKey()
+		rlk := kgen.GenRelinearizationKey(sk, 1)
+		evk := &vche_1.EvaluationKey{EvaluationKey: rlwe.EvaluationKey{Rlk: rlk.RelinearizationKey}, H: sk.H}
+		encoder := vche_1.NewEncoder(params, sk.K, sk.S, false)
+		eval := vche_1.NewEvaluator(params, evk)
+		evalPt := vche_1.NewEvaluatorPlaintext(params, sk.H)
+
+		tags := vche.GetIndexTags([]byte("x"), params.NSlots)
+		values := vche.GetRandomCoeffs(params.NSlots, 1<<8)
+		ct := vche_1.NewEncryptor(params, sk).EncryptNew(encoder.EncodeUintNew(values, tags))
+		verif := vche_1.NewEncoderPlaintext(params, sk.K).EncodeNew(tags)
+		for i := 0; i < plan.Depth; i++ {
+			ct = eval.RelinearizeNew(eval.MulNew(ct, ct))
+			verif = evalPt.RelinearizeNew(evalPt.MulNew(verif, verif))
+		}
+		require.Greater(t, vche_1.NoiseBudget(params, sk, ct), 0.0)
+
+		want := make([]uint64, len(values))
+		for i, v := range values {
+			want[i] = ring.ModExp(v, 4, params.T())
+		}
+		require.Equal(t, want, encoder.DecodeUintNew(vche_1.NewDecryptor(params, sk).DecryptNew(ct), verif))
+	})
+}
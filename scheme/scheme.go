@@ -0,0 +1,304 @@
+// Package scheme bundles the parameters, keys and generic components of an encoding behind a single facade, so that
+// applications switch between REP, PE, their closed-form PRF variants and the plain BFV baseline by changing the
+// encoding of their Config.
+package scheme
+
+import (
+	"fmt"
+
+	"github.com/ldsec/lattigo/v2/bfv"
+	"github.com/ldsec/lattigo/v2/rlwe"
+	"veritas/vche/bfv_generic"
+	"veritas/vche/vche"
+	"veritas/vche/vche_1"
+	"veritas/vche/vche_2"
+)
+
+// Encoding identifies an encoding.
+type Encoding string
+
+const (
+	// REP is the replication encoding (vche_1).
+	REP Encoding = "rep"
+	// REPCFPRF is REP with closed-form PRFs for the verification.
+	REPCFPRF Encoding = "rep-cfprf"
+	// PE is the polynomial encoding (vche_2).
+	PE Encoding = "pe"
+	// PECFPRF is PE with closed-form PRFs for the verification.
+	PECFPRF Encoding = "pe-cfprf"
+	// BFV is the baseline without verification.
+	BFV Encoding = "bfv"
+)
+
+// Encodings lists the supported encodings.
+var Encodings = []Encoding{REP, REPCFPRF, PE, PECFPRF, BFV}
+
+// Config describes a scheme: its encoding and parameters, and the operations that its evaluator supports besides the
+// arithmetic ones, i.e., the rotations of the columns, the rotation of the rows and the inner sum.
+type Config struct {
+	Encoding   Encoding               `json:"encoding"`
+	Params     vche.ParametersLiteral `json:"params"`
+	Rotations  []int                  `json:"rotations,omitempty"`
+	RotateRows bool                   `json:"rotate_rows,omitempty"`
+	InnerSum   bool                   `json:"inner_sum,omitempty"`
+}
+
+// Scheme holds the generic components of an encoding, with a fresh secret key. EncoderPlaintext and
+// EvaluatorPlaintext compute the verification state of the client, and are nil for BFV, which has none.
+type Scheme struct {
+	Config             Config
+	Parameters         vche.Parameters
+	Encoder            vche.GenericEncoder
+	EncoderPlaintext   vche.GenericEncoderPlaintext
+	Encryptor          vche.GenericEncryptor
+	Decryptor          vche.GenericDecryptor
+	Evaluator          vche.GenericEvaluator
+	EvaluatorPlaintext vche.GenericEvaluator
+
+	newEvaluator func(galEls []uint64) (eval vche.GenericEvaluator, evk interface{})
+	finalize     func(verif interface{}) interface{}
+	evk          interface{} // the keys of Evaluator
+	session      *vche.Session
+}
+
+// New generates the keys of the scheme described by cfg, and returns its components.
+func New(cfg Config) (*Scheme, error) {
+	var s *Scheme
+	var err error
+	switch cfg.Encoding {
+	case REP, REPCFPRF:
+		s, err = newREP(cfg)
+	case PE, PECFPRF:
+		s, err = newPE(cfg)
+	case BFV:
+		s, err = newBFV(cfg)
+	default:
+		return nil, fmt.Errorf("unknown encoding %q, expected one of %v", cfg.Encoding, Encodings)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.Evaluator, s.evk = s.newEvaluator(GaloisElements(s.Parameters, cfg.Rotations, cfg.RotateRows, cfg.InnerSum))
+	return s, nil
+}
+
+// WithSession returns a copy of the scheme that records in session the messages between the client and the server:
+// the keys of Evaluator, which the client sends when the session starts, the keys of the evaluators created with
+// NewEvaluator, the inputs encrypted with EncryptUintNew and EncryptIntNew, and the results decrypted with
+// DecryptUintNew and DecryptIntNew. The messages of the interactive protocols of PE are recorded by their verifier.
+func (s *Scheme) WithSession(session *vche.Session) *Scheme {
+	c := *s
+	c.session = session
+	session.Send(vche.ClientToServer, vche.EvaluationKeyMessage, s.evk)
+	return &c
+}
+
+// Session returns the session in which the messages of the scheme are recorded, or nil.
+func (s *Scheme) Session() *vche.Session {
+	return s.session
+}
+
+// GaloisElements returns the Galois elements of the rotation keys for the given operations. The replications of a
+// value are in consecutive slots, so that REP rotates the columns by k*NumReplications slots.
+func GaloisElements(params vche.Parameters, rotations []int, rotateRows, innerSum bool) []uint64 {
+	var galEls []uint64
+	seen := make(map[uint64]bool)
+	add := func(galEl uint64) {
+		if !seen[galEl] {
+			seen[galEl] = true
+			galEls = append(galEls, galEl)
+		}
+	}
+	for _, k := range rotations {
+		add(params.GaloisElementForColumnRotationBy(k * params.NumReplications))
+	}
+	if rotateRows {
+		add(params.GaloisElementForRowRotation())
+	}
+	if innerSum {
+		for _, galEl := range params.GaloisElementsForRowInnerSum() {
+			add(galEl)
+		}
+	}
+	return galEls
+}
+
+// NewEvaluator returns an evaluator with the keys for the given operations, e.g., for the rotations of a computation
+// that are only known after the scheme is created. Its keys are recorded as sent to the server.
+func (s *Scheme) NewEvaluator(rotations []int, rotateRows, innerSum bool) vche.GenericEvaluator {
+	eval, evk := s.newEvaluator(GaloisElements(s.Parameters, rotations, rotateRows, innerSum))
+	s.session.Send(vche.ClientToServer, vche.EvaluationKeyMessage, evk)
+	return eval
+}
+
+// NewEvaluatorForCircuit returns an evaluator with the keys needed by the circuit.
+func (s *Scheme) NewEvaluatorForCircuit(c *vche.Circuit) vche.GenericEvaluator {
+	return s.NewEvaluator(c.Rotations(), c.NeedsRotateRowsKey(), c.NeedsInnerSumKeys())
+}
+
+// Verifies tells whether the scheme verifies the results, i.e., is not BFV.
+func (s *Scheme) Verifies() bool {
+	return s.EncoderPlaintext != nil
+}
+
+// EncryptUintNew encodes and encrypts the given values with their tags.
+func (s *Scheme) EncryptUintNew(coeffs []uint64, tags []vche.Tag) interface{} {
+	ct := s.Encryptor.EncryptNew(s.Encoder.EncodeUintNew(coeffs, tags))
+	s.session.Send(vche.ClientToServer, vche.InputMessage, ct)
+	return ct
+}
+
+// EncryptIntNew encodes and encrypts the given signed values with their tags.
+func (s *Scheme) EncryptIntNew(coeffs []int64, tags []vche.Tag) interface{} {
+	ct := s.Encryptor.EncryptNew(s.Encoder.EncodeIntNew(coeffs, tags))
+	s.session.Send(vche.ClientToServer, vche.InputMessage, ct)
+	return ct
+}
+
+// VerifNew returns the verification state of the values with the given tags, or nil for BFV.
+func (s *Scheme) VerifNew(tags []vche.Tag) interface{} {
+	if !s.Verifies() {
+		return nil
+	}
+	return s.EncoderPlaintext.EncodeNew(tags)
+}
+
+// Finalize turns the verification state computed by EvaluatorPlaintext into the one expected by the decoder, which
+// differs for the closed-form PRFs.
+func (s *Scheme) Finalize(verif interface{}) interface{} {
+	if s.finalize == nil {
+		return verif
+	}
+	return s.finalize(verif)
+}
+
+// DecryptUintNew decrypts a ciphertext, and verifies and decodes it with the verification state computed by
+// EvaluatorPlaintext.
+func (s *Scheme) DecryptUintNew(ct, verif interface{}) []uint64 {
+	s.session.Send(vche.ServerToClient, vche.ResultMessage, ct)
+	return s.Encoder.DecodeUintNew(s.Decryptor.DecryptNew(ct), s.Finalize(verif))
+}
+
+// DecryptIntNew decrypts a ciphertext, and verifies and decodes its signed values with the verification state
+// computed by EvaluatorPlaintext.
+func (s *Scheme) DecryptIntNew(ct, verif interface{}) []int64 {
+	s.session.Send(vche.ServerToClient, vche.ResultMessage, ct)
+	return s.Encoder.DecodeIntNew(s.Decryptor.DecryptNew(ct), s.Finalize(verif))
+}
+
+func newREP(cfg Config) (*Scheme, error) {
+	params, err := vche_1.NewParametersFromLiteral(cfg.Params)
+	if err != nil {
+		return nil, err
+	}
+	kgen := vche_1.NewKeyGenerator(params)
+	sk := kgen.GenSecretKey()
+	cfprf := cfg.Encoding == REPCFPRF
+	s := &Scheme{
+		Config:     cfg,
+		Parameters: params,
+		Encoder:    vche_1.NewGenericEncoder(params, sk.K, sk.S, cfprf),
+		Encryptor:  vche_1.NewGenericEncryptor(params, sk),
+		Decryptor:  vche_1.NewGenericDecryptor(params, sk),
+	}
+	if cfprf {
+		s.EncoderPlaintext = vche_1.NewGenericEncoderPlaintextCFPRF(params, sk.K)
+		s.EvaluatorPlaintext = vche_1.NewGenericEvaluatorPlaintextCFPRF(params, sk.H)
+		eval := vche_1.NewEvaluatorPlaintextCFPRF(params, sk.H)
+		s.finalize = func(verif interface{}) interface{} {
+			v, ok := verif.(*vche_1.VerifPlaintext)
+			if !ok {
+				panic(fmt.Errorf("expected *VerifPlaintext, got %T", verif))
+			}
+			eval.ComputeMemo(v)
+			return eval.Eval(v)
+		}
+	} else {
+		s.EncoderPlaintext = vche_1.NewGenericEncoderPlaintext(params, sk.K)
+		s.EvaluatorPlaintext = vche_1.NewGenericEvaluatorPlaintext(params, sk.H)
+	}
+
+	rlk := kgen.GenRelinearizationKey(sk, 1)
+	s.newEvaluator = func(galEls []uint64) (vche.GenericEvaluator, interface{}) {
+		evk := &vche_1.EvaluationKey{EvaluationKey: rlwe.EvaluationKey{Rlk: rlk.RelinearizationKey}, H: sk.H}
+		if len(galEls) > 0 {
+			evk.Rtks = kgen.GenRotationKeys(galEls, sk).RotationKeySet
+		}
+		return vche_1.NewGenericEvaluator(params, evk), evk
+	}
+	return s, nil
+}
+
+func newPE(cfg Config) (*Scheme, error) {
+	params, err := vche_2.NewParametersFromLiteral(cfg.Params)
+	if err != nil {
+		return nil, err
+	}
+	kgen := vche_2.NewKeyGenerator(params)
+	sk := kgen.GenSecretKey()
+	cfprf := cfg.Encoding == PECFPRF
+	s := &Scheme{
+		Config:     cfg,
+		Parameters: params,
+		Encoder:    vche_2.NewGenericEncoder(params, sk.K, sk.Alpha, cfprf),
+		Encryptor:  vche_2.NewGenericEncryptor(params, sk),
+		Decryptor:  vche_2.NewGenericDecryptor(params, sk),
+	}
+	if cfprf {
+		s.EncoderPlaintext = vche_2.NewGenericEncoderPlaintextCFPRF(params, sk.K)
+		s.EvaluatorPlaintext = vche_2.NewGenericEvaluatorPlaintextCFPRF(params)
+		eval := vche_2.NewEvaluatorPlaintextCFPRF(params)
+		s.finalize = func(verif interface{}) interface{} {
+			v, ok := verif.(*vche_2.VerifPlaintext)
+			if !ok {
+				panic(fmt.Errorf("expected *VerifPlaintext, got %T", verif))
+			}
+			eval.ComputeMemo(v)
+			return eval.Eval(v)
+		}
+	} else {
+		s.EncoderPlaintext = vche_2.NewGenericEncoderPlaintext(params, sk.K)
+		s.EvaluatorPlaintext = vche_2.NewGenericEvaluatorPlaintext(params)
+	}
+
+	rlk := kgen.GenRelinearizationKey(sk, 1)
+	s.newEvaluator = func(galEls []uint64) (vche.GenericEvaluator, interface{}) {
+		evk := &vche_2.EvaluationKey{Rlk: rlk}
+		if len(galEls) > 0 {
+			evk.Rtks = kgen.GenRotationKeys(galEls, sk)
+		}
+		return vche_2.NewGenericEvaluator(params, evk), evk
+	}
+	return s, nil
+}
+
+func newBFV(cfg Config) (*Scheme, error) {
+	bfvParams, err := bfv.NewParametersFromLiteral(cfg.Params.ParametersLiteral)
+	if err != nil {
+		return nil, err
+	}
+	// The values are not replicated
+	params, err := vche.NewParameters(bfvParams, 1, 1)
+	if err != nil {
+		return nil, err
+	}
+	kgen := bfv.NewKeyGenerator(bfvParams)
+	sk := kgen.GenSecretKey()
+	s := &Scheme{
+		Config:     cfg,
+		Parameters: params,
+		Encoder:    bfv_generic.NewGenericEncoder(bfvParams),
+		Encryptor:  bfv_generic.NewGenericEncryptor(bfvParams, sk),
+		Decryptor:  bfv_generic.NewGenericDecryptor(bfvParams, sk),
+	}
+
+	rlk := kgen.GenRelinearizationKey(sk, 1)
+	s.newEvaluator = func(galEls []uint64) (vche.GenericEvaluator, interface{}) {
+		evk := rlwe.EvaluationKey{Rlk: rlk}
+		if len(galEls) > 0 {
+			evk.Rtks = kgen.GenRotationKeys(galEls, sk)
+		}
+		return bfv_generic.NewGenericEvaluator(bfvParams, evk), evk
+	}
+	return s, nil
+}
@@ -0,0 +1,65 @@
+package scheme
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"veritas/vche/vche"
+	"veritas/vche/vche_1"
+	"veritas/vche/vche_2"
+)
+
+func TestScheme(t *testing.T) {
+	c := vche.NewCircuit()
+	in := c.Inputs(2)
+	c.Output(c.Add(c.Relinearize(c.Mul(in[0], in[1])), c.RotateColumns(in[0], 1)))
+
+	for _, cfg := range []Config{
+		{Encoding: REP, Params: vche_1.DefaultParams[0]},
+		{Encoding: REPCFPRF, Params: vche_1.DefaultParams[0]},
+		{Encoding: PE, Params: vche_2.DefaultParams[0]},
+		{Encoding: PECFPRF, Params: vche_2.DefaultParams[0]},
+		{Encoding: BFV, Params: vche_2.DefaultParams[0]},
+	} {
+		t.Run(string(cfg.Encoding), func(t *testing.T) {
+			s, err := New(cfg)
+			require.NoError(t, err)
+			require.Equal(t, cfg.Encoding != BFV, s.Verifies())
+			session := vche.NewSession()
+			s = s.WithSession(session)
+
+			n, T := s.Parameters.NSlots, s.Parameters.T()
+			x, y := vche.GetRandomCoeffs(n, 1<<8), vche.GetRandomCoeffs(n, 1<<8)
+			tags := [][]vche.Tag{vche.GetIndexTags([]byte("x"), n), vche.GetIndexTags([]byte("y"), n)}
+			want := make([]uint64, n)
+			half := n / 2
+			for i := range want {
+				want[i] = (x[i]*y[i] + x[i/half*half+(i%half+1)%half]) % T
+			}
+
+			ctX, ctY := s.EncryptUintNew(x, tags[0]), s.EncryptUintNew(y, tags[1])
+			res := c.Eval(s.NewEvaluatorForCircuit(c), ctX, ctY)[0]
+			var verif interface{}
+			if s.Verifies() {
+				verif = c.Eval(s.EvaluatorPlaintext, s.VerifNew(tags[0]), s.VerifNew(tags[1]))[0]
+			}
+			require.Equal(t, want, s.DecryptUintNew(res, verif))
+
+			// The session records the keys of the default evaluator and of the evaluator of the circuit, the inputs
+			// and the result, in both encodings and the baseline
+			report := session.Report()
+			require.Equal(t, 2, report.Get(vche.ClientToServer, vche.EvaluationKeyMessage).Messages)
+			require.Equal(t, vche.Traffic{Messages: 2, Bytes: vche.Size(ctX) + vche.Size(ctY)}, report.Get(vche.ClientToServer, vche.InputMessage))
+			require.Equal(t, vche.Traffic{Messages: 1, Bytes: vche.Size(res)}, report.Get(vche.ServerToClient, vche.ResultMessage))
+
+			if s.Verifies() {
+				// The verification state of other inputs does not match
+				forged := c.Eval(s.EvaluatorPlaintext, s.VerifNew(tags[1]), s.VerifNew(tags[0]))[0]
+				require.True(t, vche.Rejects(func() { s.DecryptUintNew(res, forged) }))
+			}
+		})
+	}
+
+	_, err := New(Config{Encoding: "ckks", Params: vche_1.DefaultParams[0]})
+	require.Error(t, err)
+}
@@ -0,0 +1,358 @@
+package vche
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Wire identifies a value of a Circuit: one of its inputs, or the output of one of its gates.
+type Wire int
+
+type gateType int
+
+const (
+	gateAdd gateType = iota
+	gateSub
+	gateNeg
+	gateMul
+	gateMulScalar
+	gateRelinearize
+	gateRotateColumns
+	gateRotateRows
+	gateInnerSum
+)
+
+var gateNames = map[gateType]string{
+	gateAdd:           "Add",
+	gateSub:           "Sub",
+	gateNeg:           "Neg",
+	gateMul:           "Mul",
+	gateMulScalar:     "MulScalar",
+	gateRelinearize:   "Relinearize",
+	gateRotateColumns: "RotateColumns",
+	gateRotateRows:    "RotateRows",
+	gateInnerSum:      "InnerSum",
+}
+
+func (g gateType) String() string {
+	return gateNames[g]
+}
+
+type gate struct {
+	gateType gateType
+	in       []Wire
+	scalar   uint64
+	k        int
+}
+
+// Circuit is a computation that is written once and evaluated by any GenericEvaluator: by the server's evaluator on
+// ciphertexts, and by the client's plaintext (or closed-form PRF) evaluator on the verification state. Since both sides
+// run the same gates in the same order, they cannot diverge.
+// A Circuit is built by declaring its inputs and chaining gates on the returned wires, then marking its outputs.
+type Circuit struct {
+	numInputs int
+	gates     []gate
+	outputs   []Wire
+}
+
+func NewCircuit() *Circuit {
+	return &Circuit{}
+}
+
+// Input declares a new input of the circuit. Inputs must be declared before any gate.
+func (c *Circuit) Input() Wire {
+	if len(c.gates) > 0 {
+		panic(fmt.Errorf("inputs must be declared before the gates of the circuit"))
+	}
+	c.numInputs++
+	return Wire(c.numInputs - 1)
+}
+
+// Inputs declares n new inputs of the circuit.
+func (c *Circuit) Inputs(n int) []Wire {
+	ws := make([]Wire, n)
+	for i := range ws {
+		ws[i] = c.Input()
+	}
+	return ws
+}
+
+func (c *Circuit) numWires() int {
+	return c.numInputs + len(c.gates)
+}
+
+func (c *Circuit) addGate(g gate) Wire {
+	for _, w := range g.in {
+		if w < 0 || int(w) >= c.numWires() {
+			panic(fmt.Errorf("wire %d does not exist in the circuit", w))
+		}
+	}
+	c.gates = append(c.gates, g)
+	return Wire(c.numWires() - 1)
+}
+
+func (c *Circuit) Add(op0, op1 Wire) Wire {
+	return c.addGate(gate{gateType: gateAdd, in: []Wire{op0, op1}})
+}
+
+func (c *Circuit) Sub(op0, op1 Wire) Wire {
+	return c.addGate(gate{gateType: gateSub, in: []Wire{op0, op1}})
+}
+
+func (c *Circuit) Neg(op Wire) Wire {
+	return c.addGate(gate{gateType: gateNeg, in: []Wire{op}})
+}
+
+// Mul multiplies op0 and op1. As with the evaluators, op0 must carry a ciphertext on the server side.
+func (c *Circuit) Mul(op0, op1 Wire) Wire {
+	return c.addGate(gate{gateType: gateMul, in: []Wire{op0, op1}})
+}
+
+func (c *Circuit) MulScalar(op Wire, scalar uint64) Wire {
+	return c.addGate(gate{gateType: gateMulScalar, in: []Wire{op}, scalar: scalar})
+}
+
+func (c *Circuit) Relinearize(op Wire) Wire {
+	return c.addGate(gate{gateType: gateRelinearize, in: []Wire{op}})
+}
+
+func (c *Circuit) RotateColumns(op Wire, k int) Wire {
+	return c.addGate(gate{gateType: gateRotateColumns, in: []Wire{op}, k: k})
+}
+
+func (c *Circuit) RotateRows(op Wire) Wire {
+	return c.addGate(gate{gateType: gateRotateRows, in: []Wire{op}})
+}
+
+func (c *Circuit) InnerSum(op Wire) Wire {
+	return c.addGate(gate{gateType: gateInnerSum, in: []Wire{op}})
+}
+
+// Output marks the given wires as outputs of the circuit, in order.
+func (c *Circuit) Output(ws ...Wire) {
+	for _, w := range ws {
+		if w < 0 || int(w) >= c.numWires() {
+			panic(fmt.Errorf("wire %d does not exist in the circuit", w))
+		}
+	}
+	c.outputs = append(c.outputs, ws...)
+}
+
+func (c *Circuit) NumInputs() int {
+	return c.numInputs
+}
+
+func (c *Circuit) NumOutputs() int {
+	return len(c.outputs)
+}
+
+// Rotations returns the column rotations used by the circuit, so that the matching rotation keys can be generated.
+func (c *Circuit) Rotations() []int {
+	seen := make(map[int]bool)
+	var rots []int
+	for _, g := range c.gates {
+		if g.gateType == gateRotateColumns && !seen[g.k] {
+			seen[g.k] = true
+			rots = append(rots, g.k)
+		}
+	}
+	return rots
+}
+
+// NeedsRelinearizationKey returns whether the circuit relinearizes.
+func (c *Circuit) NeedsRelinearizationKey() bool {
+	return c.uses(gateRelinearize)
+}
+
+// NeedsInnerSumKeys returns whether the circuit computes inner sums.
+func (c *Circuit) NeedsInnerSumKeys() bool {
+	return c.uses(gateInnerSum)
+}
+
+// NeedsRotateRowsKey returns whether the circuit swaps rows.
+func (c *Circuit) NeedsRotateRowsKey() bool {
+	return c.uses(gateRotateRows)
+}
+
+func (c *Circuit) uses(gateType gateType) bool {
+	for _, g := range c.gates {
+		if g.gateType == gateType {
+			return true
+		}
+	}
+	return false
+}
+
+// Eval evaluates the circuit with eval on the given inputs, and returns its outputs. The inputs are not modified.
+// The server calls it with its evaluator on the ciphertexts, and the client with its plaintext evaluator on the
+// verification state of the same inputs, so that the outputs of both can be decoded together.
+func (c *Circuit) Eval(eval GenericEvaluator, inputs ...interface{}) []interface{} {
+	if len(inputs) != c.numInputs {
+		panic(fmt.Errorf("circuit expects %d inputs, got %d", c.numInputs, len(inputs)))
+	}
+
+	values := make([]interface{}, c.numWires())
+	copy(values, inputs)
+	for i, g := range c.gates {
+		var out interface{}
+		switch g.gateType {
+		case gateAdd:
+			out = eval.AddNew(values[g.in[0]], values[g.in[1]])
+		case gateSub:
+			out = eval.SubNew(values[g.in[0]], values[g.in[1]])
+		case gateNeg:
+			out = eval.NegNew(values[g.in[0]])
+		case gateMul:
+			out = eval.MulNew(values[g.in[0]], values[g.in[1]])
+		case gateMulScalar:
+			out = eval.MulScalarNew(values[g.in[0]], g.scalar)
+		case gateRelinearize:
+			out = eval.RelinearizeNew(values[g.in[0]])
+		case gateRotateColumns:
+			out = eval.RotateColumnsNew(values[g.in[0]], g.k)
+		case gateRotateRows:
+			out = eval.RotateRowsNew(values[g.in[0]])
+		case gateInnerSum:
+			out = eval.CopyNew(values[g.in[0]])
+			eval.InnerSum(out, out)
+		default:
+			panic(fmt.Errorf("unsupported gate %v", g.gateType))
+		}
+		values[c.numInputs+i] = out
+	}
+
+	outputs := make([]interface{}, len(c.outputs))
+	for i, w := range c.outputs {
+		outputs[i] = values[w]
+	}
+	return outputs
+}
+
+// String returns a textual description of the circuit, one gate per line.
+func (c *Circuit) String() string {
+	s := fmt.Sprintf("inputs: %d\n", c.numInputs)
+	for i, g := range c.gates {
+		s += fmt.Sprintf("w%d = %v%v", c.numInputs+i, g.gateType, g.in)
+		switch g.gateType {
+		case gateMulScalar:
+			s += fmt.Sprintf(" scalar=%d", g.scalar)
+		case gateRotateColumns:
+			s += fmt.Sprintf(" k=%d", g.k)
+		}
+		s += "\n"
+	}
+	return s + fmt.Sprintf("outputs: %v\n", c.outputs)
+}
+
+// ParseCircuit parses the textual description of a circuit, in the format returned by Circuit.String. Blank lines and
+// lines starting with # are ignored, so that circuits can be written by hand.
+func ParseCircuit(s string) (*Circuit, error) {
+	gateTypes := make(map[string]gateType, len(gateNames))
+	for g, name := range gateNames {
+		gateTypes[name] = g
+	}
+	parseWires := func(s string) ([]Wire, error) {
+		s = strings.TrimSpace(s)
+		if !strings.HasPrefix(s, "[") || !strings.HasSuffix(s, "]") {
+			return nil, fmt.Errorf("expected a list of wires, got %q", s)
+		}
+		var ws []Wire
+		for _, f := range strings.Fields(s[1 : len(s)-1]) {
+			w, err := strconv.Atoi(f)
+			if err != nil {
+				return nil, err
+			}
+			ws = append(ws, Wire(w))
+		}
+		return ws, nil
+	}
+
+	c := NewCircuit()
+	scanner := bufio.NewScanner(strings.NewReader(s))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		err := func() (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = fmt.Errorf("%v", r)
+				}
+			}()
+			switch {
+			case line == "" || strings.HasPrefix(line, "#"):
+			case strings.HasPrefix(line, "inputs:"):
+				n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "inputs:")))
+				if err != nil {
+					return err
+				}
+				c.Inputs(n)
+			case strings.HasPrefix(line, "outputs:"):
+				ws, err := parseWires(strings.TrimPrefix(line, "outputs:"))
+				if err != nil {
+					return err
+				}
+				c.Output(ws...)
+			default:
+				parts := strings.SplitN(line, "=", 2)
+				if len(parts) != 2 {
+					return fmt.Errorf("expected a gate, got %q", line)
+				}
+				if lhs := strings.TrimSpace(parts[0]); lhs != fmt.Sprintf("w%d", c.numWires()) {
+					return fmt.Errorf("expected gate w%d, got %s", c.numWires(), lhs)
+				}
+				rhs := strings.TrimSpace(parts[1])
+				i := strings.Index(rhs, "[")
+				j := strings.Index(rhs, "]")
+				if i < 0 || j < i {
+					return fmt.Errorf("expected the input wires of the gate, got %q", rhs)
+				}
+				g, ok := gateTypes[rhs[:i]]
+				if !ok {
+					return fmt.Errorf("unknown gate %q", rhs[:i])
+				}
+				ws, err := parseWires(rhs[i : j+1])
+				if err != nil {
+					return err
+				}
+				gt := gate{gateType: g, in: ws}
+				for _, opt := range strings.Fields(rhs[j+1:]) {
+					kv := strings.SplitN(opt, "=", 2)
+					if len(kv) != 2 {
+						return fmt.Errorf("expected an option key=value, got %q", opt)
+					}
+					switch kv[0] {
+					case "scalar":
+						if gt.scalar, err = strconv.ParseUint(kv[1], 10, 64); err != nil {
+							return err
+						}
+					case "k":
+						if gt.k, err = strconv.Atoi(kv[1]); err != nil {
+							return err
+						}
+					default:
+						return fmt.Errorf("unknown option %q", kv[0])
+					}
+				}
+				if arity := gateArity(g); len(ws) != arity {
+					return fmt.Errorf("gate %v expects %d inputs, got %d", g, arity, len(ws))
+				}
+				c.addGate(gt)
+			}
+			return nil
+		}()
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNum, err)
+		}
+	}
+	return c, scanner.Err()
+}
+
+func gateArity(g gateType) int {
+	switch g {
+	case gateAdd, gateSub, gateMul:
+		return 2
+	default:
+		return 1
+	}
+}
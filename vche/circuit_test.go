@@ -0,0 +1,60 @@
+package vche
+
+import (
+	"github.com/ldsec/lattigo/v2/bfv"
+	"github.com/ldsec/lattigo/v2/ring"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCircuit(t *testing.T) {
+	params, err := NewParametersFromLiteral(ParametersLiteral{ParametersLiteral: bfv.PN12QP109, NumReplications: 1, NumDistinctPRFKeys: 1})
+	require.NoError(t, err)
+
+	c := NewCircuit()
+	x, y := c.Input(), c.Input()
+	z := c.Relinearize(c.Mul(x, y))
+	w := c.Sub(c.Add(c.MulScalar(z, 3), c.RotateColumns(x, 2)), c.Neg(y))
+	s := c.InnerSum(c.RotateColumns(w, -2))
+	c.Output(w, s)
+
+	require.Equal(t, 2, c.NumInputs())
+	require.Equal(t, 2, c.NumOutputs())
+	require.Equal(t, []int{2, -2}, c.Rotations())
+	require.True(t, c.NeedsRelinearizationKey())
+	require.True(t, c.NeedsInnerSumKeys())
+	require.False(t, c.NeedsRotateRowsKey())
+
+	ringT := params.RingT()
+	px, py := ringT.NewPoly(), ringT.NewPoly()
+	ringT.SetCoefficientsUint64(GetRandomCoeffs(params.N(), params.T()), px)
+	ringT.SetCoefficientsUint64(GetRandomCoeffs(params.N(), params.T()), py)
+	pxCopy, pyCopy := px.CopyNew(), py.CopyNew()
+
+	outs := c.Eval(NewGenericEvaluatorPlaintext(params), px, py)
+	require.True(t, px.Equals(pxCopy) && py.Equals(pyCopy), "inputs should not be modified")
+
+	// Same computation, written directly against the evaluator
+	eval := NewEvaluatorPlaintext(params)
+	wantW := eval.AddNew(eval.MulScalarNew(eval.MulNew(px, py), 3), eval.RotateColumnsNew(px, 2))
+	eval.Sub(wantW, eval.NegNew(py), wantW)
+	wantS := eval.RotateColumnsNew(wantW, -2)
+	eval.InnerSum(wantS, wantS)
+	require.True(t, wantW.Equals(outs[0].(*ring.Poly)))
+	require.True(t, wantS.Equals(outs[1].(*ring.Poly)))
+
+	parsed, err := ParseCircuit(c.String())
+	require.NoError(t, err)
+	require.Equal(t, c, parsed)
+	parsed, err = ParseCircuit("# x * y\ninputs: 2\n\nw2 = Mul[0 1]\nw3 = Relinearize[2]\noutputs: [3]\n")
+	require.NoError(t, err)
+	require.Equal(t, 1, parsed.NumOutputs())
+	for _, bad := range []string{"inputs: 1\nw2 = Neg[0]", "inputs: 1\nw1 = Foo[0]", "inputs: 1\nw1 = Add[0]", "inputs: 1\nw1 = Neg[3]", "inputs: 1\nw1 = MulScalar[0] scalar=x"} {
+		_, err = ParseCircuit(bad)
+		require.Error(t, err, bad)
+	}
+
+	require.Panics(t, func() { c.Input() })
+	require.Panics(t, func() { c.Add(x, Wire(42)) })
+	require.Panics(t, func() { c.Eval(NewGenericEvaluatorPlaintext(params), px) })
+}
@@ -0,0 +1,228 @@
+package vche
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ldsec/lattigo/v2/rlwe"
+)
+
+// Direction is the direction of a message between the client, who owns the data and verifies the results, and the
+// server, who evaluates the computation.
+type Direction int
+
+const (
+	ClientToServer Direction = iota
+	ServerToClient
+)
+
+func (d Direction) String() string {
+	switch d {
+	case ClientToServer:
+		return "client->server"
+	case ServerToClient:
+		return "server->client"
+	default:
+		return fmt.Sprintf("Direction(%d)", int(d))
+	}
+}
+
+// MessageKind is the role of a message in a session.
+type MessageKind int
+
+const (
+	InputMessage            MessageKind = iota // encrypted inputs
+	EvaluationKeyMessage                       // relinearization and rotation keys
+	ResultMessage                              // encrypted results
+	VerificationMessage                        // challenges and answers of an interactive verification protocol
+	RequadratizationMessage                    // high-degree components and their corrections in the requadratization protocol
+)
+
+func (k MessageKind) String() string {
+	switch k {
+	case InputMessage:
+		return "inputs"
+	case EvaluationKeyMessage:
+		return "evaluation keys"
+	case ResultMessage:
+		return "results"
+	case VerificationMessage:
+		return "verification"
+	case RequadratizationMessage:
+		return "requadratization"
+	default:
+		return fmt.Sprintf("MessageKind(%d)", int(k))
+	}
+}
+
+// Sizer is implemented by the ciphertexts and keys whose serialized size is known without serializing them.
+type Sizer interface {
+	GetDataLen(WithMetaData bool) int
+}
+
+// Size returns the size in bytes of the serialization of a message: a ciphertext or a key (with their metadata, as
+// written by MarshalBinary), an evaluation key, a slice of bytes, or a uint64 scalar.
+func Size(msg interface{}) int {
+	switch m := msg.(type) {
+	case Sizer:
+		return m.GetDataLen(true)
+	case rlwe.EvaluationKey:
+		return Size(&m)
+	case *rlwe.EvaluationKey:
+		n := 0
+		if m.Rlk != nil {
+			n += m.Rlk.GetDataLen(true)
+		}
+		if m.Rtks != nil {
+			n += m.Rtks.GetDataLen(true)
+		}
+		return n
+	case []byte:
+		return len(m)
+	case uint64:
+		return 8
+	case encoding.BinaryMarshaler:
+		data, err := m.MarshalBinary()
+		if err != nil {
+			panic(err)
+		}
+		return len(data)
+	default:
+		panic(fmt.Errorf("cannot compute the size of %T", msg))
+	}
+}
+
+// Traffic counts the messages sent in one direction, and their total size in bytes.
+type Traffic struct {
+	Messages int
+	Bytes    int
+}
+
+func (t *Traffic) add(u Traffic) {
+	t.Messages += u.Messages
+	t.Bytes += u.Bytes
+}
+
+type trafficKey struct {
+	dir  Direction
+	kind MessageKind
+}
+
+// Session accounts for the messages exchanged between a client and a server. It is safe for concurrent use, and all
+// its methods are no-ops on a nil session, so that protocols can record their messages unconditionally.
+type Session struct {
+	mu      sync.Mutex
+	traffic map[trafficKey]Traffic
+}
+
+func NewSession() *Session {
+	return &Session{traffic: make(map[trafficKey]Traffic)}
+}
+
+// Send records that the messages msgs of the given kind were sent in the direction dir. Nil messages are skipped.
+func (s *Session) Send(dir Direction, kind MessageKind, msgs ...interface{}) {
+	if s == nil {
+		return
+	}
+	var t Traffic
+	for _, msg := range msgs {
+		if isNil(msg) {
+			continue
+		}
+		t.Messages++
+		t.Bytes += Size(msg)
+	}
+	s.record(dir, kind, t)
+}
+
+// SendBytes records that a message of n bytes was sent in the direction dir.
+func (s *Session) SendBytes(dir Direction, kind MessageKind, n int) {
+	if s == nil {
+		return
+	}
+	s.record(dir, kind, Traffic{1, n})
+}
+
+func (s *Session) record(dir Direction, kind MessageKind, t Traffic) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u := s.traffic[trafficKey{dir, kind}]
+	u.add(t)
+	s.traffic[trafficKey{dir, kind}] = u
+}
+
+// Reset forgets all the recorded messages.
+func (s *Session) Reset() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traffic = make(map[trafficKey]Traffic)
+}
+
+// Report returns the traffic recorded so far.
+func (s *Session) Report() CommunicationReport {
+	r := CommunicationReport{make(map[Direction]map[MessageKind]Traffic)}
+	if s == nil {
+		return r
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, t := range s.traffic {
+		if r.Traffic[k.dir] == nil {
+			r.Traffic[k.dir] = make(map[MessageKind]Traffic)
+		}
+		r.Traffic[k.dir][k.kind] = t
+	}
+	return r
+}
+
+// CommunicationReport is the traffic of a session, by direction and kind of message.
+type CommunicationReport struct {
+	Traffic map[Direction]map[MessageKind]Traffic
+}
+
+// Total returns the traffic in the direction dir.
+func (r CommunicationReport) Total(dir Direction) (t Traffic) {
+	for _, u := range r.Traffic[dir] {
+		t.add(u)
+	}
+	return t
+}
+
+// Get returns the traffic of the given kind in the direction dir.
+func (r CommunicationReport) Get(dir Direction, kind MessageKind) Traffic {
+	return r.Traffic[dir][kind]
+}
+
+func (r CommunicationReport) String() string {
+	var b strings.Builder
+	for _, dir := range []Direction{ClientToServer, ServerToClient} {
+		total := r.Total(dir)
+		fmt.Fprintf(&b, "%s: %d messages, %d bytes\n", dir, total.Messages, total.Bytes)
+		kinds := make([]int, 0, len(r.Traffic[dir]))
+		for kind := range r.Traffic[dir] {
+			kinds = append(kinds, int(kind))
+		}
+		sort.Ints(kinds)
+		for _, kind := range kinds {
+			t := r.Traffic[dir][MessageKind(kind)]
+			fmt.Fprintf(&b, "  %s: %d messages, %d bytes\n", MessageKind(kind), t.Messages, t.Bytes)
+		}
+	}
+	return b.String()
+}
+
+// isNil reports whether msg is nil, or a nil pointer.
+func isNil(msg interface{}) bool {
+	if msg == nil {
+		return true
+	}
+	v := reflect.ValueOf(msg)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
@@ -0,0 +1,61 @@
+package vche
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/ldsec/lattigo/v2/bfv"
+	"github.com/ldsec/lattigo/v2/rlwe"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommunication(t *testing.T) {
+	params, err := bfv.NewParametersFromLiteral(bfv.PN12QP109)
+	require.NoError(t, err)
+	kgen := bfv.NewKeyGenerator(params)
+	sk := kgen.GenSecretKey()
+	ct := bfv.NewEncryptor(params, sk).EncryptNew(bfv.NewPlaintext(params))
+	evk := &rlwe.EvaluationKey{Rlk: kgen.GenRelinearizationKey(sk, 1), Rtks: kgen.GenRotationKeysForRotations([]int{1}, false, sk)}
+
+	data, err := ct.MarshalBinary()
+	require.NoError(t, err)
+	require.Equal(t, len(data), Size(ct))
+	rlk, err := evk.Rlk.MarshalBinary()
+	require.NoError(t, err)
+	rtks, err := evk.Rtks.MarshalBinary()
+	require.NoError(t, err)
+	require.Equal(t, len(rlk)+len(rtks), Size(evk))
+	require.Equal(t, Size(evk), Size(*evk))
+	require.Panics(t, func() { Size(42) })
+
+	session := NewSession()
+	session.Send(ClientToServer, EvaluationKeyMessage, evk)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			session.Send(ClientToServer, InputMessage, ct)
+		}()
+	}
+	wg.Wait()
+	var nilCt *bfv.Ciphertext
+	session.Send(ServerToClient, ResultMessage, ct, nilCt, nil)
+	session.Send(ClientToServer, VerificationMessage, uint64(1), uint64(2))
+	session.SendBytes(ServerToClient, VerificationMessage, 100)
+
+	report := session.Report()
+	require.Equal(t, Traffic{4, 4 * len(data)}, report.Get(ClientToServer, InputMessage))
+	require.Equal(t, Traffic{1, Size(evk)}, report.Get(ClientToServer, EvaluationKeyMessage))
+	require.Equal(t, Traffic{2, 16}, report.Get(ClientToServer, VerificationMessage))
+	require.Equal(t, Traffic{7, 4*len(data) + Size(evk) + 16}, report.Total(ClientToServer))
+	require.Equal(t, Traffic{2, len(data) + 100}, report.Total(ServerToClient))
+	require.Contains(t, report.String(), "server->client: 2 messages")
+
+	session.Reset()
+	require.Equal(t, Traffic{}, session.Report().Total(ClientToServer))
+
+	var noSession *Session
+	noSession.Send(ClientToServer, InputMessage, ct)
+	require.Equal(t, Traffic{}, noSession.Report().Total(ClientToServer))
+}
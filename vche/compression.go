@@ -0,0 +1,140 @@
+package vche
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/ldsec/lattigo/v2/bfv"
+	"github.com/ldsec/lattigo/v2/ring"
+	"golang.org/x/crypto/blake2b"
+)
+
+// CompressedCiphertext is a BFV ciphertext switched from the modulus Q to its first prime q0, for transmission. Its
+// elements hold the coefficients modulo q0.
+type CompressedCiphertext struct {
+	Value [][]uint64
+}
+
+// CompressCiphertext switches the modulus of ct from Q to q0, i.e., rounds q0/Q * ct. The noise of ct, as estimated
+// by a NoiseModel or a NoiseEstimator, is scaled down by q0/Q, but the rounding adds a noise of its own: an error is
+// returned if q0 is too small relative to T to absorb it, or if the scaled noise of ct would exceed the capacity of q0,
+// since the compressed ciphertext would then no longer decrypt correctly.
+func CompressCiphertext(params Parameters, ct *bfv.Ciphertext, noise float64) (*CompressedCiphertext, error) {
+	model := NewNoiseModel(params)
+	level := ct.Level()
+	if model.Rounding() >= model.CapacityAtLevel(0) {
+		return nil, fmt.Errorf("cannot compress to q0 = %d: its capacity of %.1f bits does not absorb the rounding noise of %.1f bits",
+			params.Q()[0], model.CapacityAtLevel(0), model.Rounding())
+	}
+	if switched := model.ModSwitch(noise, level); switched >= model.CapacityAtLevel(0) {
+		return nil, fmt.Errorf("cannot compress a ciphertext with noise %.1f bits: its noise of %.1f bits once switched to q0 exceeds the capacity of %.1f bits",
+			noise, switched, model.CapacityAtLevel(0))
+	}
+	ringQ := params.RingQ()
+	cc := &CompressedCiphertext{make([][]uint64, len(ct.Value))}
+	pool, res := ringQ.NewPoly(), ringQ.NewPoly()
+	for i, el := range ct.Value {
+		// The division rounds in place
+		tmp := el.CopyNew()
+		ringQ.DivRoundByLastModulusManyLvl(level, level, tmp, pool, res)
+		cc.Value[i] = append([]uint64{}, res.Coeffs[0]...)
+	}
+	return cc, nil
+}
+
+// DecompressCiphertext lifts a compressed ciphertext back to the modulus Q, by multiplying it with Q/q0, so that it
+// decrypts as any other ciphertext. It returns an error if cc does not have the shape of a ciphertext of params.
+func DecompressCiphertext(params Parameters, cc *CompressedCiphertext) (*bfv.Ciphertext, error) {
+	if len(cc.Value) < 2 {
+		return nil, fmt.Errorf("a compressed ciphertext should have at least 2 elements, got %d", len(cc.Value))
+	}
+	for i, coeffs := range cc.Value {
+		if len(coeffs) != params.N() {
+			return nil, fmt.Errorf("compressed element %d should have %d coefficients, got %d", i, params.N(), len(coeffs))
+		}
+	}
+	ringQ := params.RingQ()
+	q0 := ringQ.Modulus[0]
+	// Q/q0 is divisible by all the other primes, so that only the residues modulo q0 are not zero
+	factorBig := new(big.Int).Div(ringQ.ModulusBigint, new(big.Int).SetUint64(q0))
+	factor := factorBig.Mod(factorBig, new(big.Int).SetUint64(q0)).Uint64()
+	bredParams := ring.BRedParams(q0)
+
+	ct := bfv.NewCiphertext(params.Parameters, len(cc.Value)-1)
+	for i, coeffs := range cc.Value {
+		for j, c := range coeffs {
+			ct.Value[i].Coeffs[0][j] = ring.BRed(c%q0, factor, q0, bredParams)
+		}
+	}
+	return ct, nil
+}
+
+// MarshalBinary encodes the compressed ciphertext in a slice of bytes.
+func (cc *CompressedCiphertext) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 0, cc.GetDataLen())
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint32(b, uint32(len(cc.Value)))
+	data = append(data, b[:4]...)
+	for _, coeffs := range cc.Value {
+		binary.BigEndian.PutUint32(b, uint32(len(coeffs)))
+		data = append(data, b[:4]...)
+		for _, c := range coeffs {
+			binary.BigEndian.PutUint64(b, c)
+			data = append(data, b...)
+		}
+	}
+	return data, nil
+}
+
+// UnmarshalBinary decodes a slice of bytes generated by MarshalBinary on the compressed ciphertext.
+func (cc *CompressedCiphertext) UnmarshalBinary(data []byte) error {
+	numElements, data, err := ReadCount(data, 4, "elements")
+	if err != nil {
+		return err
+	}
+	cc.Value = make([][]uint64, numElements)
+	for i := range cc.Value {
+		var n int
+		if n, data, err = ReadCount(data, 8, fmt.Sprintf("coefficients of element %d", i)); err != nil {
+			return err
+		}
+		cc.Value[i] = make([]uint64, n)
+		for j := range cc.Value[i] {
+			cc.Value[i][j] = binary.BigEndian.Uint64(data[8*j:])
+		}
+		data = data[8*n:]
+	}
+	if len(data) != 0 {
+		return fmt.Errorf("%d trailing bytes", len(data))
+	}
+	return nil
+}
+
+// GetDataLen returns the length in bytes of the compressed ciphertext as encoded by MarshalBinary.
+func (cc *CompressedCiphertext) GetDataLen() int {
+	dataLen := 4
+	for _, coeffs := range cc.Value {
+		dataLen += 4 + 8*len(coeffs)
+	}
+	return dataLen
+}
+
+// TagsDigest returns a digest of a list of tags, which commits to all of them, so that the digest of the tags of a
+// result can be sent instead of the tags, and compared with the digest of the tags recomputed by the verifier.
+func TagsDigest(tags [][]byte) []byte {
+	h, err := blake2b.New256(nil)
+	if err != nil {
+		panic(err)
+	}
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(len(tags)))
+	h.Write(b)
+	for _, tag := range tags {
+		// The tags are length-prefixed, so that distinct lists have distinct encodings
+		binary.BigEndian.PutUint64(b, uint64(len(tag)))
+		h.Write(b)
+		h.Write(tag)
+	}
+	return h.Sum(nil)
+}
@@ -1,6 +1,14 @@
 package vche
 
-import "github.com/ldsec/lattigo/v2/ring"
+import (
+	"errors"
+
+	"github.com/ldsec/lattigo/v2/ring"
+)
+
+// ErrVerification is wrapped by the errors with which the decoders panic when a result does not pass the verification,
+// so that callers that recover the panic can tell it apart from other failures with errors.Is.
+var ErrVerification = errors.New("verification failed")
 
 type EncoderPlaintext interface {
 	Encode(tags []Tag, p *ring.Poly)
@@ -0,0 +1,109 @@
+package vche
+
+import (
+	"encoding/binary"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func FuzzPRF(f *testing.F) {
+	f.Add([]byte("0123456789abcdef"), uint64(65537), uint64(0), []byte("dataset"), []byte("index"), byte(PRFBlake2b))
+	f.Add([]byte{}, uint64(1), uint64(42), []byte{}, []byte{}, byte(PRFAESCTR))
+	f.Add([]byte("key"), uint64(0), uint64(1), []byte("d"), []byte("i"), byte(PRFSHAKE128))
+	f.Add(make([]byte, 65), uint64(1)<<63+1, ^uint64(0), []byte("d"), []byte("i"), byte(PRFBlake2b))
+
+	f.Fuzz(func(t *testing.T, key []byte, T, index uint64, datasetTag, indexTag []byte, prfType byte) {
+		typ := PRFTypes[int(prfType)%len(PRFTypes)]
+		tag := Tag{datasetTag, indexTag}
+
+		var y uint64
+		if Rejects(func() { y = PRF(NewXOFWithType(typ, key), T, tag, index) }) {
+			// Invalid keys and moduli are rejected
+			return
+		}
+		require.Less(t, y, T)
+		require.Equal(t, y, PRF(NewXOFWithType(typ, key), T, tag, index))
+
+		if T < 2 {
+			require.True(t, Rejects(func() { PRFEfficient(NewXOFWithType(typ, key), NewXOFWithType(typ, key), T, tag, index) }))
+			return
+		}
+		a, b, u, v := CFPRF(NewXOFWithType(typ, key), NewXOFWithType(typ, key), T, tag, index)
+		for _, x := range []uint64{u, v} {
+			require.Less(t, x, T)
+		}
+		for _, x := range []uint64{a, b} {
+			require.NotZero(t, x)
+			require.Less(t, x, T)
+		}
+		require.Less(t, PRFEfficient(NewXOFWithType(typ, key), NewXOFWithType(typ, key), T, tag, index), T)
+	})
+}
+
+func FuzzBivariatePoly(f *testing.F) {
+	f.Add(uint64(65537), byte(1), byte(2), []byte("coefficients"), uint64(3), uint64(5), uint64(7))
+	f.Add(uint64(2), byte(0), byte(0), []byte{}, uint64(0), uint64(0), uint64(0))
+	f.Add(uint64(1)<<62-57, byte(3), byte(1), []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, ^uint64(0), uint64(1), ^uint64(0))
+
+	f.Fuzz(func(t *testing.T, T uint64, degree0, degree1 byte, data []byte, scalar, u, v uint64) {
+		// Plaintext moduli of BFV are well below 2^62, so that the sums of AddNoMod do not overflow
+		T = 2 + T%(1<<62-2)
+		u, v, scalar = u%T, v%T, scalar%T
+		p0 := []BivariatePoly{fuzzBivariatePoly(int(degree0%4), T, data)}
+		p1 := []BivariatePoly{fuzzBivariatePoly(int(degree1%4), T, append([]byte("p1"), data...))}
+
+		eval := func(p []BivariatePoly) uint64 { return evalBivariatePoly(p[0], u, v) }
+		x, y := big.NewInt(0).SetUint64(eval(p0)), big.NewInt(0).SetUint64(eval(p1))
+		bigT := big.NewInt(0).SetUint64(T)
+		mod := func(z *big.Int) uint64 { return z.Mod(z, bigT).Uint64() }
+
+		require.Equal(t, mod(big.NewInt(0).Add(x, y)), eval(BivariatePolyAdd(p0, p1)))
+		require.Equal(t, mod(big.NewInt(0).Add(x, y)), eval(BivariatePolyAddNoMod(p0, p1)))
+		require.Equal(t, mod(big.NewInt(0).Sub(x, y)), eval(BivariatePolySub(p0, p1)))
+		require.Equal(t, mod(big.NewInt(0).Sub(x, y)), eval(BivariatePolySubNoMod(p0, p1)))
+		require.Equal(t, mod(big.NewInt(0).Neg(x)), eval(BivariatePolyNeg(p0)))
+		require.Equal(t, mod(big.NewInt(0).Mul(x, big.NewInt(0).SetUint64(scalar))), eval(BivariatePolyMulScalar(p0, scalar)))
+		require.Equal(t, mod(big.NewInt(0).Mul(x, y)), eval(BivariatePolyMul(p0, p1)))
+	})
+}
+
+// fuzzBivariatePoly returns the polynomial of the given degree whose coefficients are read from data, repeated as needed.
+func fuzzBivariatePoly(degree int, T uint64, data []byte) BivariatePoly {
+	p := NewBivariatePoly(degree, T)
+	b := make([]byte, 8)
+	k := 0
+	for i := range p.Coeffs {
+		for j := range p.Coeffs[i] {
+			for l := range b {
+				if len(data) > 0 {
+					b[l] = data[k%len(data)]
+				}
+				k++
+			}
+			p.SetCoeff(i, j, binary.BigEndian.Uint64(b)%T)
+		}
+	}
+	return p
+}
+
+// evalBivariatePoly returns p(u, v) mod p.T, where the coefficients of p may exceed p.T.
+func evalBivariatePoly(p BivariatePoly, u, v uint64) uint64 {
+	bigT := big.NewInt(0).SetUint64(p.T)
+	res := big.NewInt(0)
+	uPow := big.NewInt(1)
+	for i := range p.Coeffs {
+		uvPow := big.NewInt(0).Set(uPow)
+		for j := range p.Coeffs[i] {
+			term := big.NewInt(0).SetUint64(p.GetCoeff(i, j))
+			term.Mul(term, uvPow)
+			res.Add(res, term)
+			uvPow.Mul(uvPow, big.NewInt(0).SetUint64(v))
+			uvPow.Mod(uvPow, bigT)
+		}
+		uPow.Mul(uPow, big.NewInt(0).SetUint64(u))
+		uPow.Mod(uPow, bigT)
+	}
+	return res.Mod(res, bigT).Uint64()
+}
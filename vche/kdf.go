@@ -0,0 +1,99 @@
+package vche
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"github.com/ldsec/lattigo/v2/ring"
+	"github.com/ldsec/lattigo/v2/rlwe"
+	"github.com/ldsec/lattigo/v2/utils"
+	"golang.org/x/crypto/hkdf"
+	"io"
+)
+
+// Labels used to separate the domains of the key material derived from a master seed.
+const (
+	LabelSecretKey = "rlwe-secret-key"
+	LabelPRFKey    = "prf-key"
+	LabelDummySet  = "dummy-set"
+	LabelAlpha     = "alpha"
+)
+
+const kdfSalt = "VERITAS-KDF-v1"
+
+// KDF deterministically derives key material (RLWE secret keys, PRF keys, dummy sets, alphas, ...) from a master seed.
+// Every output is bound to the context of the KDF and to a label, so that outputs for distinct contexts or labels are
+// independent. The same seed and context always yield the same keys, which allows reproducible tests and benchmarks,
+// and lets several devices of the same client re-derive identical keys.
+type KDF struct {
+	seed    []byte
+	context string
+}
+
+// NewKDF returns a KDF for the given master seed and context label.
+func NewKDF(seed []byte, context string) KDF {
+	if len(seed) < 16 {
+		panic(fmt.Errorf("master seed should be at least 16 bytes long, was %d", len(seed)))
+	}
+	return KDF{append([]byte{}, seed...), context}
+}
+
+// Context returns the context label of the KDF.
+func (kdf KDF) Context() string {
+	return kdf.context
+}
+
+// Sub returns a KDF for the sub-context label of the current context.
+func (kdf KDF) Sub(label string) KDF {
+	return KDF{kdf.seed, string(kdf.info(label))}
+}
+
+// info encodes the context and the label unambiguously (both are length-prefixed).
+func (kdf KDF) info(label string) []byte {
+	info := make([]byte, 0, 16+len(kdf.context)+len(label))
+	lenBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(lenBytes, uint64(len(kdf.context)))
+	info = append(info, lenBytes...)
+	info = append(info, kdf.context...)
+	binary.BigEndian.PutUint64(lenBytes, uint64(len(label)))
+	info = append(info, lenBytes...)
+	info = append(info, label...)
+	return info
+}
+
+// Derive returns n bytes of key material for the given label, using HKDF-SHA256.
+func (kdf KDF) Derive(label string, n int) []byte {
+	out := make([]byte, n)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, kdf.seed, []byte(kdfSalt), kdf.info(label)), out); err != nil {
+		panic(err)
+	}
+	return out
+}
+
+// PRNG returns a keyed PRNG whose key is derived for the given label.
+func (kdf KDF) PRNG(label string) utils.PRNG {
+	prng, err := utils.NewKeyedPRNG(kdf.Derive(label, 64))
+	if err != nil {
+		panic(err)
+	}
+	return prng
+}
+
+// PRFKey derives a PRF key with sub-keys of keyLen bytes for the given label.
+func (kdf KDF) PRFKey(label string, keyLen int) PRFKey {
+	return PRFKey{kdf.Derive(label+"/K1", keyLen), kdf.Derive(label+"/K2", keyLen)}
+}
+
+// GenSecretKeyFromPRNG samples an RLWE secret key with ternary coefficients (distribution [1/3, 1/3, 1/3], as in
+// rlwe.KeyGenerator.GenSecretKey), using the randomness of prng.
+func GenSecretKeyFromPRNG(params rlwe.Parameters, prng utils.PRNG) *rlwe.SecretKey {
+	ringQP := params.RingQP()
+	levelQ, levelP := params.QCount()-1, params.PCount()-1
+
+	sk := rlwe.NewSecretKey(params)
+	ring.NewTernarySampler(prng, params.RingQ(), 1.0/3, false).Read(sk.Value.Q)
+	ringQP.ExtendBasisSmallNormAndCenter(sk.Value.Q, levelP, nil, sk.Value.P)
+	ringQP.NTTLvl(levelQ, levelP, sk.Value, sk.Value)
+	ringQP.MFormLvl(levelQ, levelP, sk.Value, sk.Value)
+	return sk
+}
@@ -0,0 +1,33 @@
+package vche
+
+import (
+	"github.com/ldsec/lattigo/v2/bfv"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestKDF(t *testing.T) {
+	seed := []byte("veritas kdf test master seed")
+	kdf := NewKDF(seed, "client")
+
+	require.Equal(t, kdf.Derive(LabelPRFKey, 32), NewKDF(seed, "client").Derive(LabelPRFKey, 32))
+	require.Equal(t, kdf.PRFKey(LabelPRFKey, 8), NewKDF(seed, "client").PRFKey(LabelPRFKey, 8))
+
+	// domain separation between labels, contexts, seeds and sub-contexts
+	require.NotEqual(t, kdf.Derive(LabelPRFKey, 32), kdf.Derive(LabelDummySet, 32))
+	require.NotEqual(t, kdf.Derive(LabelPRFKey, 32), NewKDF(seed, "server").Derive(LabelPRFKey, 32))
+	require.NotEqual(t, kdf.Derive(LabelPRFKey, 32), NewKDF([]byte("another kdf test master seed"), "client").Derive(LabelPRFKey, 32))
+	require.NotEqual(t, kdf.Sub("a").Derive("b", 32), kdf.Sub("ab").Derive("", 32))
+	K := kdf.PRFKey(LabelPRFKey, 8)
+	require.NotEqual(t, K.K1, K.K2)
+
+	require.Panics(t, func() { NewKDF(seed[:8], "client") })
+
+	params, err := bfv.NewParametersFromLiteral(bfv.PN12QP109)
+	require.NoError(t, err)
+	sk1 := GenSecretKeyFromPRNG(params.Parameters, kdf.PRNG(LabelSecretKey))
+	sk2 := GenSecretKeyFromPRNG(params.Parameters, kdf.PRNG(LabelSecretKey))
+	sk3 := GenSecretKeyFromPRNG(params.Parameters, kdf.Sub("device").PRNG(LabelSecretKey))
+	require.True(t, sk1.Value.Q.Equals(sk2.Value.Q))
+	require.False(t, sk1.Value.Q.Equals(sk3.Value.Q))
+}
@@ -0,0 +1,83 @@
+package vche
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/ldsec/lattigo/v2/bfv"
+	"github.com/ldsec/lattigo/v2/ring"
+)
+
+// maxLogN bounds the ring degree accepted when decoding a ciphertext, far above the degrees of the parameters.
+const maxLogN = 20
+
+// ReadCount reads the number of elements in front of data, and checks that the rest of data is long enough to hold
+// them, given that each element takes at least minSize bytes, so that the caller can allocate them safely.
+func ReadCount(data []byte, minSize int, what string) (int, []byte, error) {
+	if len(data) < 4 {
+		return 0, nil, fmt.Errorf("missing number of %s", what)
+	}
+	n := uint64(binary.BigEndian.Uint32(data))
+	data = data[4:]
+	if n*uint64(minSize) > uint64(len(data)) {
+		return 0, nil, fmt.Errorf("%d %s do not fit in %d bytes", n, what, len(data))
+	}
+	return int(n), data, nil
+}
+
+// UnmarshalCiphertext decodes a BFV ciphertext generated by MarshalBinary. Unlike bfv.Ciphertext.UnmarshalBinary, it
+// checks the layout of data first, and returns an error instead of panicking when data is truncated or malformed.
+func UnmarshalCiphertext(data []byte) (*bfv.Ciphertext, error) {
+	if len(data) < 1 || data[0] == 0 {
+		return nil, fmt.Errorf("missing ciphertext elements")
+	}
+	rest := data[1:]
+	for i := 0; i < int(data[0]); i++ {
+		n, err := polyLen(rest)
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		rest = rest[n:]
+	}
+	if len(rest) != 0 {
+		return nil, fmt.Errorf("%d trailing bytes", len(rest))
+	}
+	ct := new(bfv.Ciphertext)
+	if err := ct.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return ct, nil
+}
+
+// UnmarshalPoly decodes a polynomial generated by ring.Poly.MarshalBinary, with the same checks as UnmarshalCiphertext.
+func UnmarshalPoly(data []byte) (*ring.Poly, error) {
+	n, err := polyLen(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != n {
+		return nil, fmt.Errorf("%d trailing bytes", len(data)-n)
+	}
+	pol := new(ring.Poly)
+	if err := pol.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return pol, nil
+}
+
+// polyLen returns the length of the encoding of the polynomial in front of data, as read from its header, and checks
+// that data is long enough to hold it.
+func polyLen(data []byte) (int, error) {
+	if len(data) < 4 {
+		return 0, fmt.Errorf("missing header")
+	}
+	logN, numModuli := int(data[0]), int(data[1])
+	if logN > maxLogN || numModuli == 0 {
+		return 0, fmt.Errorf("invalid header")
+	}
+	n := 4 + 8*(1<<logN)*numModuli
+	if len(data) < n {
+		return 0, fmt.Errorf("expected %d bytes, got %d", n, len(data))
+	}
+	return n, nil
+}
@@ -0,0 +1,284 @@
+package vche
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/ldsec/lattigo/v2/bfv"
+	"github.com/ldsec/lattigo/v2/dbfv"
+	"github.com/ldsec/lattigo/v2/drlwe"
+	"github.com/ldsec/lattigo/v2/ring"
+	"github.com/ldsec/lattigo/v2/rlwe"
+	"github.com/ldsec/lattigo/v2/utils"
+)
+
+// maxSigmaSmudging is the largest standard deviation of the smudging noise, so that its samples, bounded by
+// 6*sigma plus the smoothing term, fit an int64.
+const maxSigmaSmudging = 1 << 59
+
+// smoothingBits is the size of the uniform term that smooths the rounding of the float64 Gaussian samples, whose low
+// bits would otherwise be zero.
+const smoothingBits = 16
+
+// DefaultSigmaSmudging returns the standard deviation of the noise that each of numParties parties adds to its
+// decryption shares, so that the shares statistically hide the noise of the decrypted ciphertexts, which depends on the
+// secret keys of the parties. It leaves half of the capacity of the parameters (see NoiseModel) to the smudging noise
+// of the parties, each bounded by 6*sigma, and the other half to the noise of the ciphertexts. With the first default
+// parameters and three parties, it is about 2^55.8, i.e., 2^14 times the noise of a ciphertext after a multiplication
+// and 2^44 times that of a fresh one. The noise of deeper circuits, closer to sigma, is not hidden as well.
+func DefaultSigmaSmudging(params Parameters, numParties int) float64 {
+	sigma := math.Exp2(NewNoiseModel(params).Capacity() - 1 - math.Log2(6*float64(numParties)))
+	return math.Min(sigma, maxSigmaSmudging)
+}
+
+// Protocols holds the public parameters of the dbfv protocols among a set of parties: the common reference string from
+// which the parties sample the common random polynomials, and the standard deviation of the smudging noise of the
+// decryption shares. Each party generates its shares from its own share of the secret key with a Party, and sends them
+// as messages to an aggregator, which combines them with the Aggregate methods of the protocols; no struct holds the
+// shares of several parties. All the parties take part in every protocol, i.e., the secret key is shared N-out-of-N.
+//
+// Only the RLWE secret key is shared: the verification secrets of REP and PE (the PRF keys, and the dummy set or the
+// alphas) are held by a single auditor, see GenVerificationKey, which must not collude with the server.
+type Protocols struct {
+	params        Parameters
+	seed          []byte
+	sigmaSmudging float64
+	zero          *rlwe.SecretKey
+	dec           bfv.Decryptor
+}
+
+// NewProtocols returns the protocols with the common reference string seed, in which the parties smudge
+// their decryption shares with noise of standard deviation sigmaSmudging, see DefaultSigmaSmudging.
+func NewProtocols(params Parameters, seed []byte, sigmaSmudging float64) *Protocols {
+	if !(sigmaSmudging >= params.Sigma()) || sigmaSmudging > maxSigmaSmudging {
+		panic(fmt.Errorf("smudging sigma should be in [%v, 2^59], got %v", params.Sigma(), sigmaSmudging))
+	}
+	// Collaborative decryption switches to the zero key, under which the first element of the ciphertext decrypts
+	zero := rlwe.NewSecretKey(params.Parameters.Parameters)
+	return &Protocols{params, seed, sigmaSmudging, zero, bfv.NewDecryptor(params.Parameters, zero)}
+}
+
+// crs returns the common reference string of a protocol, derived from the seed of the protocols, so that the parties
+// and the aggregator sample the same common random polynomials whatever the order in which they run the protocols.
+func (pr *Protocols) crs(protocol string, galEl uint64) drlwe.CRS {
+	key := make([]byte, len(pr.seed)+len(protocol)+8)
+	binary.BigEndian.PutUint64(key[copy(key, pr.seed)+copy(key[len(pr.seed):], protocol):], galEl)
+	crs, err := utils.NewKeyedPRNG(key)
+	if err != nil {
+		panic(err)
+	}
+	return crs
+}
+
+func (pr *Protocols) publicKeyCRP() (*dbfv.CKGProtocol, drlwe.CKGCRP) {
+	ckg := dbfv.NewCKGProtocol(pr.params.Parameters)
+	return ckg, ckg.SampleCRP(pr.crs("ckg", 0))
+}
+
+func (pr *Protocols) relinearizationKeyCRP() (*dbfv.RKGProtocol, drlwe.RKGCRP) {
+	rkg := dbfv.NewRKGProtocol(pr.params.Parameters)
+	return rkg, rkg.SampleCRP(pr.crs("rkg", 0))
+}
+
+func (pr *Protocols) rotationKeyCRP(galEl uint64) (*dbfv.RTGProtocol, drlwe.RTGCRP) {
+	rtg := dbfv.NewRotKGProtocol(pr.params.Parameters)
+	return rtg, rtg.SampleCRP(pr.crs("rtg", galEl))
+}
+
+func checkShares(n int) {
+	if n == 0 {
+		panic(fmt.Errorf("cannot aggregate zero shares"))
+	}
+}
+
+// AggregatePublicKey returns the collective public key from the shares of all the parties.
+func (pr *Protocols) AggregatePublicKey(shares ...*drlwe.CKGShare) *rlwe.PublicKey {
+	checkShares(len(shares))
+	ckg, crp := pr.publicKeyCRP()
+	agg := ckg.AllocateShares()
+	for _, share := range shares {
+		ckg.AggregateShares(share, agg, agg)
+	}
+	pk := bfv.NewPublicKey(pr.params.Parameters)
+	ckg.GenPublicKey(agg, crp, pk)
+	return pk
+}
+
+// AggregateRelinearizationKeyRoundOne returns the aggregate of the first round shares of all the parties, which the
+// aggregator sends back to the parties for the second round.
+func (pr *Protocols) AggregateRelinearizationKeyRoundOne(shares ...*drlwe.RKGShare) *drlwe.RKGShare {
+	checkShares(len(shares))
+	rkg, _ := pr.relinearizationKeyCRP()
+	_, agg, _ := rkg.AllocateShares()
+	for _, share := range shares {
+		rkg.AggregateShares(share, agg, agg)
+	}
+	return agg
+}
+
+// AggregateRelinearizationKey returns the collective relinearization key from the aggregated first round and the
+// second round shares of all the parties.
+func (pr *Protocols) AggregateRelinearizationKey(round1 *drlwe.RKGShare, shares ...*drlwe.RKGShare) *rlwe.RelinearizationKey {
+	checkShares(len(shares))
+	rkg, _ := pr.relinearizationKeyCRP()
+	_, _, agg := rkg.AllocateShares()
+	for _, share := range shares {
+		rkg.AggregateShares(share, agg, agg)
+	}
+	rlk := bfv.NewRelinearizationKey(pr.params.Parameters, 1)
+	rkg.GenRelinearizationKey(round1, agg, rlk)
+	return rlk
+}
+
+// AggregateRotationKey returns the collective rotation key for the Galois element galEl from the shares of all the
+// parties.
+func (pr *Protocols) AggregateRotationKey(galEl uint64, shares ...*drlwe.RTGShare) *rlwe.SwitchingKey {
+	checkShares(len(shares))
+	rtg, crp := pr.rotationKeyCRP(galEl)
+	agg := rtg.AllocateShares()
+	for _, share := range shares {
+		rtg.Aggregate(share, agg, agg)
+	}
+	rtks := bfv.NewRotationKeySet(pr.params.Parameters, []uint64{galEl})
+	rtg.GenRotationKey(agg, crp, rtks.Keys[galEl])
+	return rtks.Keys[galEl]
+}
+
+// checkDecryption checks that a ciphertext of degree 1 still decrypts correctly once the smudging noise of the shares
+// of numParties parties is added to it.
+func (pr *Protocols) checkDecryption(ct *bfv.Ciphertext, numParties int) {
+	if ct.Degree() != 1 {
+		panic(fmt.Errorf("cannot decrypt collaboratively a ciphertext of degree %d, it should be relinearized", ct.Degree()))
+	}
+	if math.Log2(6*pr.sigmaSmudging*float64(numParties)) >= NewNoiseModel(pr.params).CapacityAtLevel(ct.Level()) {
+		panic(fmt.Errorf("the smudging noise of %d parties exceeds the capacity of a ciphertext at level %d", numParties, ct.Level()))
+	}
+}
+
+// Decrypt returns the plaintext of a ciphertext of degree 1 from the decryption shares of all the parties, which
+// switch it to the zero key.
+func (pr *Protocols) Decrypt(ct *bfv.Ciphertext, shares ...*drlwe.CKSShare) *bfv.Plaintext {
+	checkShares(len(shares))
+	pr.checkDecryption(ct, len(shares))
+	cks := dbfv.NewCKSProtocol(pr.params.Parameters, pr.sigmaSmudging)
+	agg := cks.AllocateShare(ct.Level())
+	for _, share := range shares {
+		if share.Value.Level() != ct.Level() {
+			panic(fmt.Errorf("decryption share at level %d, expected %d", share.Value.Level(), ct.Level()))
+		}
+		cks.AggregateShares(share, agg, agg)
+	}
+	switched := bfv.NewCiphertext(pr.params.Parameters, 1)
+	cks.KeySwitch(agg, ct.Ciphertext, switched.Ciphertext)
+	return pr.dec.DecryptNew(switched)
+}
+
+// Party holds a share of a secret key that no single party knows, i.e., the sum of the shares of all parties, and
+// generates its shares of the protocols.
+type Party struct {
+	protocols *Protocols
+	sk        *rlwe.SecretKey
+	ephSk     *rlwe.SecretKey
+	prng      utils.PRNG
+}
+
+// NewParty samples a fresh share of the secret key.
+func NewParty(protocols *Protocols) *Party {
+	prng, err := utils.NewPRNG()
+	if err != nil {
+		panic(err)
+	}
+	return &Party{protocols: protocols, sk: bfv.NewKeyGenerator(protocols.params.Parameters).GenSecretKey(), prng: prng}
+}
+
+// GenPublicKeyShare returns the share of the party of the collective public key.
+func (p *Party) GenPublicKeyShare() *drlwe.CKGShare {
+	ckg, crp := p.protocols.publicKeyCRP()
+	share := ckg.AllocateShares()
+	ckg.GenShare(p.sk, crp, share)
+	return share
+}
+
+// GenRelinearizationKeyShareRoundOne returns the first round share of the party of the collective relinearization key.
+// The party keeps the ephemeral secret of the round for GenRelinearizationKeyShareRoundTwo.
+func (p *Party) GenRelinearizationKeyShareRoundOne() *drlwe.RKGShare {
+	rkg, crp := p.protocols.relinearizationKeyCRP()
+	ephSk, share, _ := rkg.AllocateShares()
+	rkg.GenShareRoundOne(p.sk, crp, ephSk, share)
+	p.ephSk = ephSk
+	return share
+}
+
+// GenRelinearizationKeyShareRoundTwo returns the second round share of the party, given the aggregated first round.
+func (p *Party) GenRelinearizationKeyShareRoundTwo(round1 *drlwe.RKGShare) *drlwe.RKGShare {
+	if p.ephSk == nil {
+		panic(fmt.Errorf("the first round of the relinearization key generation has not been run"))
+	}
+	rkg, _ := p.protocols.relinearizationKeyCRP()
+	_, _, share := rkg.AllocateShares()
+	rkg.GenShareRoundTwo(p.ephSk, p.sk, round1, share)
+	p.ephSk = nil
+	return share
+}
+
+// GenRotationKeyShare returns the share of the party of the collective rotation key for the Galois element galEl.
+func (p *Party) GenRotationKeyShare(galEl uint64) *drlwe.RTGShare {
+	rtg, crp := p.protocols.rotationKeyCRP(galEl)
+	share := rtg.AllocateShares()
+	rtg.GenShare(p.sk, galEl, crp, share)
+	return share
+}
+
+// GenDecryptionShare returns the smudged decryption share of the party of a ciphertext of degree 1.
+func (p *Party) GenDecryptionShare(ct *bfv.Ciphertext) *drlwe.CKSShare {
+	if ct.Degree() != 1 {
+		panic(fmt.Errorf("cannot decrypt collaboratively a ciphertext of degree %d, it should be relinearized", ct.Degree()))
+	}
+	// The CKS protocol of Lattigo divides its noise by the special modulus P, and requires it to be smaller than the
+	// moduli of Q, so the party adds the smudging noise to the share itself
+	params := p.protocols.params
+	cks := dbfv.NewCKSProtocol(params.Parameters, params.Sigma())
+	share := cks.AllocateShare(ct.Level())
+	cks.GenShare(p.sk, p.protocols.zero, ct.Ciphertext, share)
+	e := params.RingQ().NewPolyLvl(ct.Level())
+	p.smudge(e)
+	if ct.Value[1].IsNTT {
+		params.RingQ().NTTLvl(ct.Level(), e, e)
+	}
+	params.RingQ().AddLvl(ct.Level(), share.Value, e, share.Value)
+	return share
+}
+
+// smudge samples the coefficients of e from the Gaussian distribution of the smudging noise of the protocols, smoothed
+// by a uniform term, and reduces them modulo each modulus of e.
+func (p *Party) smudge(e *ring.Poly) {
+	sigma := p.protocols.sigmaSmudging
+	buf := make([]byte, 24)
+	for i := range e.Coeffs[0] {
+		// Box-Muller transform of two uniform floats, rejected beyond 6 sigma
+		z := math.Inf(1)
+		for math.Abs(z) > 6 {
+			p.prng.Clock(buf)
+			u1 := float64(binary.BigEndian.Uint64(buf[:8])>>11+1) / (1 << 53)
+			u2 := float64(binary.BigEndian.Uint64(buf[8:16])>>11) / (1 << 53)
+			z = math.Sqrt(-2*math.Log(u1)) * math.Cos(2*math.Pi*u2)
+		}
+		smoothing := int64(binary.BigEndian.Uint64(buf[16:])>>(64-smoothingBits)) - 1<<(smoothingBits-1)
+		v := int64(math.Round(z*sigma)) + smoothing
+		abs := uint64(v)
+		if v < 0 {
+			abs = uint64(-v)
+		}
+		for j, qj := range p.protocols.params.RingQ().Modulus[:len(e.Coeffs)] {
+			e.Coeffs[j][i] = abs % qj
+			if v < 0 && e.Coeffs[j][i] != 0 {
+				e.Coeffs[j][i] = qj - e.Coeffs[j][i]
+			}
+		}
+	}
+}
+
+// DecryptionShares returns the decryption shares of all the parties of a ciphertext of degree 1, i.e., sends it to each
+// party and collects the result of its GenDecryptionShare.
+type DecryptionShares func(ct *bfv.Ciphertext) []*drlwe.CKSShare
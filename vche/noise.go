@@ -0,0 +1,534 @@
+package vche
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/big"
+	"reflect"
+	"sync"
+
+	"github.com/ldsec/lattigo/v2/bfv"
+	"github.com/ldsec/lattigo/v2/rlwe"
+	"github.com/ldsec/lattigo/v2/utils"
+)
+
+// NoiseModel estimates the growth of the BFV noise through homomorphic operations. Noise is expressed as the log2 of
+// the infinity norm of the error of a ciphertext, and a ciphertext decrypts correctly as long as its noise stays below
+// the Capacity of the parameters. The estimates are heuristic upper bounds: they are meant to predict decryption
+// failures, which the verification of REP and PE would otherwise report as a mismatch indistinguishable from cheating.
+type NoiseModel struct {
+	params Parameters
+}
+
+func NewNoiseModel(params Parameters) NoiseModel {
+	return NoiseModel{params}
+}
+
+func (m NoiseModel) String() string {
+	return fmt.Sprintf("fresh noise %.1f bits, capacity %.1f bits", m.Fresh(), m.Capacity())
+}
+
+// Capacity returns log2(Q/(2T)), the noise above which decryption fails.
+func (m NoiseModel) Capacity() float64 {
+	return m.CapacityAtLevel(m.params.MaxLevel())
+}
+
+// CapacityAtLevel returns the capacity of a ciphertext at the given level, i.e., whose modulus is the product of the
+// first level+1 primes of Q.
+func (m NoiseModel) CapacityAtLevel(level int) float64 {
+	logQ := 0.0
+	for _, qi := range m.params.Q()[:level+1] {
+		logQ += math.Log2(float64(qi))
+	}
+	return logQ - math.Log2(float64(m.params.T())) - 1
+}
+
+// Fresh returns the noise of a freshly encrypted ciphertext.
+func (m NoiseModel) Fresh() float64 {
+	return math.Log2(6*m.params.Sigma()) + float64(m.params.LogN())/2 + 1
+}
+
+func (m NoiseModel) Add(n0, n1 float64) float64 {
+	return log2Sum(n0, n1)
+}
+
+// MulScalar returns the noise after a multiplication by scalar, taken centered modulo T.
+func (m NoiseModel) MulScalar(n float64, scalar uint64) float64 {
+	t := m.params.T()
+	scalar %= t
+	if scalar > t/2 {
+		scalar = t - scalar
+	}
+	if scalar <= 1 {
+		return n
+	}
+	return n + math.Log2(float64(scalar))
+}
+
+// MulPlaintext returns the noise after a multiplication by a plaintext whose values are arbitrary modulo T, e.g., a
+// selector or a vector of scalars: the coefficients of the plaintext are up to T/2, and each coefficient of the product
+// sums N of their products with the error.
+func (m NoiseModel) MulPlaintext(n float64) float64 {
+	return n + float64(m.params.LogN()) + math.Log2(float64(m.params.T())) - 1
+}
+
+// Issued returns the noise of a fresh contribution encoded by the Issuer of REP or PE, i.e., multiplied by a plaintext
+// verification secret, and added to a fresh encryption of PRF values. For PE, it is the noise of the second
+// component, the first one being the fresh contribution.
+func (m NoiseModel) Issued() float64 {
+	return m.Add(m.MulPlaintext(m.Fresh()), m.Fresh())
+}
+
+// Mul returns the noise after a tensoring of two ciphertexts with noise n0 and n1, where each component of the result
+// is the sum of terms products (terms is 1 for single BFV ciphertexts). The extra bit accounts for the cross terms of the
+// tensoring, in which the errors are multiplied both by the messages and by the secret key.
+func (m NoiseModel) Mul(n0, n1 float64, terms int) float64 {
+	n := math.Log2(float64(m.params.T())) + float64(m.params.LogN()) + log2Sum(n0, n1) + 1
+	if terms > 1 {
+		n += math.Log2(float64(terms))
+	}
+	return n
+}
+
+// KeySwitch returns the noise after a relinearization, rotation or key switch.
+func (m NoiseModel) KeySwitch(n float64) float64 {
+	return log2Sum(n, m.Fresh())
+}
+
+// ModSwitch returns the noise after switching a ciphertext from the given level down to the first prime q0 of Q, which
+// scales the noise by q0/Q and adds the rounding error of each element, multiplied by the secret key of norm at most N.
+func (m NoiseModel) ModSwitch(n float64, level int) float64 {
+	return log2Sum(n-(m.CapacityAtLevel(level)-m.CapacityAtLevel(0)), m.Rounding())
+}
+
+// Rounding returns the noise added by the rounding of a modulus switch.
+func (m NoiseModel) Rounding() float64 {
+	return float64(m.params.LogN())
+}
+
+// InnerSum returns the noise after an inner sum, which adds up rotations of the ciphertext over all the slots.
+func (m NoiseModel) InnerSum(n float64) float64 {
+	return m.KeySwitch(n+float64(m.params.LogN())) + 1
+}
+
+// log2Sum returns log2(2^a + 2^b).
+func log2Sum(a, b float64) float64 {
+	if a < b {
+		a, b = b, a
+	}
+	return a + math.Log2(1+math.Exp2(b-a))
+}
+
+// MeasureNoise decrypts ct with sk and returns the log2 of the infinity norm of its noise.
+func MeasureNoise(params bfv.Parameters, sk *rlwe.SecretKey, ct *bfv.Ciphertext) float64 {
+	ringQ := params.RingQ()
+	pt := bfv.NewDecryptor(params, sk).DecryptNew(ct)
+
+	encoder := bfv.NewEncoder(params)
+	ptClean := bfv.NewPlaintext(params)
+	ptClean.Value.Copy(pt.Value)
+	encoder.EncodeUint(encoder.DecodeUintNew(ptClean), ptClean)
+
+	level := utils.MinInt(pt.Level(), ptClean.Level())
+	ringQ.SubLvl(level, pt.Value, ptClean.Value, pt.Value)
+	coeffs := make([]*big.Int, ringQ.N)
+	for i := range coeffs {
+		coeffs[i] = new(big.Int)
+	}
+	ringQ.PolyToBigintCenteredLvl(level, pt.Value, coeffs)
+
+	max := new(big.Int)
+	for _, c := range coeffs {
+		if c.CmpAbs(max) > 0 {
+			max.Abs(c)
+		}
+	}
+	if max.Sign() == 0 {
+		return 0
+	}
+	f, _ := new(big.Float).SetInt(max).Float64()
+	return math.Log2(f)
+}
+
+// NoiseBudget returns the number of bits of noise that ct can still absorb before decryption fails, as measured with
+// sk. It is negative if ct no longer decrypts correctly.
+func NoiseBudget(params Parameters, sk *rlwe.SecretKey, ct *bfv.Ciphertext) float64 {
+	return NewNoiseModel(params).Capacity() - MeasureNoise(params.Parameters, sk, ct)
+}
+
+// NoiseEstimator is a GenericEvaluator that forwards every call to an underlying evaluator while tracking the estimated
+// noise of each BFV component of the operands with a NoiseModel (a single one for REP, one per coefficient of the
+// tag polynomial for PE). Before each operation, it predicts the noise of the result and warns if a component exceeds
+// the capacity of the parameters. Operands that were not produced by the estimator are assumed fresh, unless their
+// noise was set with SetNoise, e.g. from a measurement. Operands are identified by reference, so they must be pointers
+// as returned by the constructors of the encodings. The estimator holds the estimates, and thereby keeps the tracked
+// operands alive, until they are released with Release or the estimator and its copies are dropped.
+type NoiseEstimator interface {
+	GenericEvaluator
+	// Noise returns the largest estimated noise of the components of op.
+	Noise(op interface{}) float64
+	// ComponentNoise returns the estimated noise of each component of op.
+	ComponentNoise(op interface{}) []float64
+	// SetNoise sets the noise of each component of op, or of all of them if a single value is given.
+	SetNoise(op interface{}, noise ...float64)
+	// Budget returns the estimated number of bits of noise that the noisiest component of op can still absorb.
+	Budget(op interface{}) float64
+	// Budgets returns the estimated number of bits of noise that each component of op can still absorb.
+	Budgets(op interface{}) []float64
+	// Release drops the estimates of ops, e.g. once the intermediate results of a circuit are no longer needed.
+	Release(ops ...interface{})
+}
+
+// NoiseWarning is called by a NoiseEstimator when an operation is predicted to exceed the noise capacity.
+type NoiseWarning func(op string, noise, capacity float64)
+
+// noiseState is the state shared by a NoiseEstimator and its copies. The estimates are keyed by the operands
+// themselves, which are pointers, so that an operand cannot be confused with another one allocated at the same address.
+type noiseState struct {
+	model NoiseModel
+	warn  NoiseWarning
+
+	mu        sync.Mutex
+	estimates map[interface{}][]float64
+}
+
+// isOperand reports whether op can key the estimates, i.e., whether it is a non-nil pointer.
+func isOperand(op interface{}) bool {
+	v := reflect.ValueOf(op)
+	return v.Kind() == reflect.Ptr && !v.IsNil()
+}
+
+func (state *noiseState) get(op interface{}) ([]float64, bool) {
+	if !isOperand(op) {
+		return nil, false
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	noise, ok := state.estimates[op]
+	return noise, ok
+}
+
+func (state *noiseState) set(op interface{}, noise []float64) {
+	if !isOperand(op) {
+		return
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	state.estimates[op] = noise
+}
+
+func (state *noiseState) release(ops []interface{}) {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	for _, op := range ops {
+		if isOperand(op) {
+			delete(state.estimates, op)
+		}
+	}
+}
+
+type noiseEstimator struct {
+	GenericEvaluator
+	state *noiseState
+}
+
+// NewNoiseEstimator wraps eval into a NoiseEstimator. If warn is nil, warnings are logged.
+func NewNoiseEstimator(params Parameters, eval GenericEvaluator, warn NoiseWarning) NoiseEstimator {
+	if warn == nil {
+		warn = func(op string, noise, capacity float64) {
+			log.Printf("%s: estimated noise of %.1f bits exceeds the capacity of %.1f bits, decryption will likely fail", op, noise, capacity)
+		}
+	}
+	return &noiseEstimator{eval, &noiseState{model: NewNoiseModel(params), warn: warn, estimates: make(map[interface{}][]float64)}}
+}
+
+// components returns the number of BFV components of op.
+func components(op interface{}) int {
+	if l, ok := op.(interface{ Len() int }); ok && l.Len() > 0 {
+		return l.Len()
+	}
+	return 1
+}
+
+func (eval *noiseEstimator) ComponentNoise(op interface{}) []float64 {
+	if noise, ok := eval.state.get(op); ok {
+		return append([]float64(nil), noise...)
+	}
+	noise := make([]float64, components(op))
+	for i := range noise {
+		noise[i] = eval.state.model.Fresh()
+	}
+	return noise
+}
+
+func (eval *noiseEstimator) Noise(op interface{}) float64 {
+	return maxNoise(eval.ComponentNoise(op))
+}
+
+func (eval *noiseEstimator) SetNoise(op interface{}, noise ...float64) {
+	if len(noise) == 1 {
+		n := noise[0]
+		noise = make([]float64, components(op))
+		for i := range noise {
+			noise[i] = n
+		}
+	}
+	eval.state.set(op, append([]float64(nil), noise...))
+}
+
+func (eval *noiseEstimator) Release(ops ...interface{}) {
+	eval.state.release(ops)
+}
+
+func (eval *noiseEstimator) Budget(op interface{}) float64 {
+	return eval.state.model.Capacity() - eval.Noise(op)
+}
+
+func (eval *noiseEstimator) Budgets(op interface{}) []float64 {
+	budgets := eval.ComponentNoise(op)
+	for i := range budgets {
+		budgets[i] = eval.state.model.Capacity() - budgets[i]
+	}
+	return budgets
+}
+
+func maxNoise(noise []float64) float64 {
+	max := math.Inf(-1)
+	for _, n := range noise {
+		max = math.Max(max, n)
+	}
+	return max
+}
+
+// predict warns if the predicted noise of a component of the result of op exceeds the capacity, and returns it.
+func (eval *noiseEstimator) predict(op string, noise []float64) []float64 {
+	if n, capacity := maxNoise(noise), eval.state.model.Capacity(); n > capacity {
+		eval.state.warn(op, n, capacity)
+	}
+	return noise
+}
+
+// unary returns the noise of op after applying f to each of its components.
+func (eval *noiseEstimator) unary(op string, ct0 interface{}, f func(float64) float64) []float64 {
+	noise := eval.ComponentNoise(ct0)
+	for i := range noise {
+		noise[i] = f(noise[i])
+	}
+	return eval.predict(op, noise)
+}
+
+// add returns the noise of the sum of op0 and op1, whose components are added index-wise.
+func (eval *noiseEstimator) add(op string, op0, op1 interface{}) []float64 {
+	n0, n1 := eval.ComponentNoise(op0), eval.ComponentNoise(op1)
+	if len(n0) < len(n1) {
+		n0, n1 = n1, n0
+	}
+	for i := range n1 {
+		n0[i] = eval.state.model.Add(n0[i], n1[i])
+	}
+	return eval.predict(op, n0)
+}
+
+// mul returns the noise of the product of op0 and op1, whose k-th component is the sum of the products of their i-th
+// and j-th components for i+j = k.
+func (eval *noiseEstimator) mul(op0, op1 interface{}) []float64 {
+	n0, n1 := eval.ComponentNoise(op0), eval.ComponentNoise(op1)
+	noise := make([]float64, len(n0)+len(n1)-1)
+	for k := range noise {
+		noise[k] = math.Inf(-1)
+	}
+	for i := range n0 {
+		for j := range n1 {
+			noise[i+j] = log2Sum(noise[i+j], eval.state.model.Mul(n0[i], n1[j], 1))
+		}
+	}
+	return eval.predict("Mul", noise)
+}
+
+func (eval *noiseEstimator) keySwitch(op string, ct0 interface{}) []float64 {
+	return eval.unary(op, ct0, eval.state.model.KeySwitch)
+}
+
+func (eval *noiseEstimator) same(ct0 interface{}) []float64 {
+	return eval.ComponentNoise(ct0)
+}
+
+func (eval *noiseEstimator) CopyNew(op interface{}) interface{} {
+	n := eval.same(op)
+	out := eval.GenericEvaluator.CopyNew(op)
+	eval.SetNoise(out, n...)
+	return out
+}
+
+func (eval *noiseEstimator) Add(op0, op1 interface{}, ctOut interface{}) {
+	n := eval.add("Add", op0, op1)
+	eval.GenericEvaluator.Add(op0, op1, ctOut)
+	eval.SetNoise(ctOut, n...)
+}
+
+func (eval *noiseEstimator) AddNew(op0, op1 interface{}) (ctOut interface{}) {
+	n := eval.add("Add", op0, op1)
+	ctOut = eval.GenericEvaluator.AddNew(op0, op1)
+	eval.SetNoise(ctOut, n...)
+	return ctOut
+}
+
+func (eval *noiseEstimator) AddNoMod(op0, op1 interface{}, ctOut interface{}) {
+	n := eval.add("AddNoMod", op0, op1)
+	eval.GenericEvaluator.AddNoMod(op0, op1, ctOut)
+	eval.SetNoise(ctOut, n...)
+}
+
+func (eval *noiseEstimator) AddNoModNew(op0, op1 interface{}) (ctOut interface{}) {
+	n := eval.add("AddNoMod", op0, op1)
+	ctOut = eval.GenericEvaluator.AddNoModNew(op0, op1)
+	eval.SetNoise(ctOut, n...)
+	return ctOut
+}
+
+func (eval *noiseEstimator) Sub(op0, op1 interface{}, ctOut interface{}) {
+	n := eval.add("Sub", op0, op1)
+	eval.GenericEvaluator.Sub(op0, op1, ctOut)
+	eval.SetNoise(ctOut, n...)
+}
+
+func (eval *noiseEstimator) SubNew(op0, op1 interface{}) (ctOut interface{}) {
+	n := eval.add("Sub", op0, op1)
+	ctOut = eval.GenericEvaluator.SubNew(op0, op1)
+	eval.SetNoise(ctOut, n...)
+	return ctOut
+}
+
+func (eval *noiseEstimator) SubNoMod(op0, op1 interface{}, ctOut interface{}) {
+	n := eval.add("SubNoMod", op0, op1)
+	eval.GenericEvaluator.SubNoMod(op0, op1, ctOut)
+	eval.SetNoise(ctOut, n...)
+}
+
+func (eval *noiseEstimator) SubNoModNew(op0, op1 interface{}) (ctOut interface{}) {
+	n := eval.add("SubNoMod", op0, op1)
+	ctOut = eval.GenericEvaluator.SubNoModNew(op0, op1)
+	eval.SetNoise(ctOut, n...)
+	return ctOut
+}
+
+func (eval *noiseEstimator) Neg(op interface{}, ctOut interface{}) {
+	n := eval.same(op)
+	eval.GenericEvaluator.Neg(op, ctOut)
+	eval.SetNoise(ctOut, n...)
+}
+
+func (eval *noiseEstimator) NegNew(op interface{}) (ctOut interface{}) {
+	n := eval.same(op)
+	ctOut = eval.GenericEvaluator.NegNew(op)
+	eval.SetNoise(ctOut, n...)
+	return ctOut
+}
+
+func (eval *noiseEstimator) Reduce(op interface{}, ctOut interface{}) {
+	n := eval.same(op)
+	eval.GenericEvaluator.Reduce(op, ctOut)
+	eval.SetNoise(ctOut, n...)
+}
+
+func (eval *noiseEstimator) ReduceNew(op interface{}) (ctOut interface{}) {
+	n := eval.same(op)
+	ctOut = eval.GenericEvaluator.ReduceNew(op)
+	eval.SetNoise(ctOut, n...)
+	return ctOut
+}
+
+func (eval *noiseEstimator) MulScalar(op interface{}, scalar uint64, ctOut interface{}) {
+	n := eval.unary("MulScalar", op, func(n float64) float64 { return eval.state.model.MulScalar(n, scalar) })
+	eval.GenericEvaluator.MulScalar(op, scalar, ctOut)
+	eval.SetNoise(ctOut, n...)
+}
+
+func (eval *noiseEstimator) MulScalarNew(op interface{}, scalar uint64) (ctOut interface{}) {
+	n := eval.unary("MulScalar", op, func(n float64) float64 { return eval.state.model.MulScalar(n, scalar) })
+	ctOut = eval.GenericEvaluator.MulScalarNew(op, scalar)
+	eval.SetNoise(ctOut, n...)
+	return ctOut
+}
+
+func (eval *noiseEstimator) Mul(op0 interface{}, op1 interface{}, ctOut interface{}) {
+	n := eval.mul(op0, op1)
+	eval.GenericEvaluator.Mul(op0, op1, ctOut)
+	eval.SetNoise(ctOut, n...)
+}
+
+func (eval *noiseEstimator) MulNew(op0 interface{}, op1 interface{}) (ctOut interface{}) {
+	n := eval.mul(op0, op1)
+	ctOut = eval.GenericEvaluator.MulNew(op0, op1)
+	eval.SetNoise(ctOut, n...)
+	return ctOut
+}
+
+func (eval *noiseEstimator) Relinearize(ct0 interface{}, ctOut interface{}) {
+	n := eval.keySwitch("Relinearize", ct0)
+	eval.GenericEvaluator.Relinearize(ct0, ctOut)
+	eval.SetNoise(ctOut, n...)
+}
+
+func (eval *noiseEstimator) RelinearizeNew(ct0 interface{}) (ctOut interface{}) {
+	n := eval.keySwitch("Relinearize", ct0)
+	ctOut = eval.GenericEvaluator.RelinearizeNew(ct0)
+	eval.SetNoise(ctOut, n...)
+	return ctOut
+}
+
+func (eval *noiseEstimator) SwitchKeys(ct0 interface{}, switchKey interface{}, ctOut interface{}) {
+	n := eval.keySwitch("SwitchKeys", ct0)
+	eval.GenericEvaluator.SwitchKeys(ct0, switchKey, ctOut)
+	eval.SetNoise(ctOut, n...)
+}
+
+func (eval *noiseEstimator) SwitchKeysNew(ct0 interface{}, switchKey interface{}) (ctOut interface{}) {
+	n := eval.keySwitch("SwitchKeys", ct0)
+	ctOut = eval.GenericEvaluator.SwitchKeysNew(ct0, switchKey)
+	eval.SetNoise(ctOut, n...)
+	return ctOut
+}
+
+func (eval *noiseEstimator) RotateColumns(ct0 interface{}, k int, ctOut interface{}) {
+	n := eval.keySwitch("RotateColumns", ct0)
+	eval.GenericEvaluator.RotateColumns(ct0, k, ctOut)
+	eval.SetNoise(ctOut, n...)
+}
+
+func (eval *noiseEstimator) RotateColumnsNew(ct0 interface{}, k int) (ctOut interface{}) {
+	n := eval.keySwitch("RotateColumns", ct0)
+	ctOut = eval.GenericEvaluator.RotateColumnsNew(ct0, k)
+	eval.SetNoise(ctOut, n...)
+	return ctOut
+}
+
+func (eval *noiseEstimator) RotateRows(ct0 interface{}, ctOut interface{}) {
+	n := eval.keySwitch("RotateRows", ct0)
+	eval.GenericEvaluator.RotateRows(ct0, ctOut)
+	eval.SetNoise(ctOut, n...)
+}
+
+func (eval *noiseEstimator) RotateRowsNew(ct0 interface{}) (ctOut interface{}) {
+	n := eval.keySwitch("RotateRows", ct0)
+	ctOut = eval.GenericEvaluator.RotateRowsNew(ct0)
+	eval.SetNoise(ctOut, n...)
+	return ctOut
+}
+
+func (eval *noiseEstimator) InnerSum(ct0 interface{}, ctOut interface{}) {
+	n := eval.unary("InnerSum", ct0, eval.state.model.InnerSum)
+	eval.GenericEvaluator.InnerSum(ct0, ctOut)
+	eval.SetNoise(ctOut, n...)
+}
+
+// ShallowCopy returns a copy of the evaluator that shares the noise estimates of eval.
+func (eval *noiseEstimator) ShallowCopy() GenericEvaluator {
+	return &noiseEstimator{eval.GenericEvaluator.ShallowCopy(), eval.state}
+}
+
+// WithKey returns an evaluator with the given key that shares the noise estimates of eval.
+func (eval *noiseEstimator) WithKey(evk interface{}) GenericEvaluator {
+	return &noiseEstimator{eval.GenericEvaluator.WithKey(evk), eval.state}
+}
@@ -0,0 +1,122 @@
+package vche
+
+import (
+	"fmt"
+
+	"github.com/ldsec/lattigo/v2/bfv"
+	"github.com/ldsec/lattigo/v2/rlwe"
+	"github.com/stretchr/testify/require"
+	"math"
+	"testing"
+)
+
+func TestNoise(t *testing.T) {
+	params, err := NewParametersFromLiteral(ParametersLiteral{ParametersLiteral: bfv.PN12QP109, NumReplications: 1, NumDistinctPRFKeys: 1})
+	require.NoError(t, err)
+	model := NewNoiseModel(params)
+
+	t.Run("Capacity", func(t *testing.T) {
+		require.InDelta(t, float64(params.LogQ())-math.Log2(float64(params.T()))-1, model.Capacity(), 1)
+	})
+
+	t.Run("ModSwitch", func(t *testing.T) {
+		require.InDelta(t, model.Capacity(), model.CapacityAtLevel(params.MaxLevel()), 1e-9)
+		require.Less(t, model.CapacityAtLevel(0), model.Capacity())
+		// Switching to q0 scales the noise down to the capacity of q0, and adds the rounding noise
+		require.InDelta(t, model.CapacityAtLevel(0), model.ModSwitch(model.Capacity(), params.MaxLevel()), 0.01)
+		require.InDelta(t, model.Rounding(), model.ModSwitch(0, params.MaxLevel()), 0.01)
+	})
+
+	t.Run("Add", func(t *testing.T) {
+		require.InDelta(t, 11, model.Add(10, 10), 1e-9)
+		require.InDelta(t, 20, model.Add(20, -40), 1e-9)
+	})
+
+	t.Run("MulScalar", func(t *testing.T) {
+		require.Equal(t, 10.0, model.MulScalar(10, 1))
+		require.Equal(t, 10.0, model.MulScalar(10, params.T()-1))
+		require.InDelta(t, 13, model.MulScalar(10, 8), 1e-9)
+		require.InDelta(t, 13, model.MulScalar(10, params.T()-8), 1e-9)
+	})
+
+	t.Run("MulPlaintext", func(t *testing.T) {
+		require.InDelta(t, 10+float64(params.LogN())+math.Log2(float64(params.T()))-1, model.MulPlaintext(10), 1e-9)
+		require.Greater(t, model.Issued(), model.MulPlaintext(model.Fresh()))
+		require.Less(t, model.Issued(), model.Capacity())
+	})
+
+	t.Run("Components", func(t *testing.T) {
+		eval := NewNoiseEstimator(params, NewGenericEvaluatorPlaintext(params), nil)
+		x := params.RingT().NewPoly()
+		eval.SetNoise(x, 10, 20)
+		require.Equal(t, []float64{10, 20}, eval.ComponentNoise(x))
+		require.Equal(t, 20.0, eval.Noise(x))
+		require.Equal(t, []float64{model.Capacity() - 10, model.Capacity() - 20}, eval.Budgets(x))
+	})
+
+	t.Run("Release", func(t *testing.T) {
+		eval := NewNoiseEstimator(params, NewGenericEvaluatorPlaintext(params), nil)
+		other := NewNoiseEstimator(params, NewGenericEvaluatorPlaintext(params), nil)
+		x := params.RingT().NewPoly()
+		eval.SetNoise(x, 10)
+		require.Equal(t, 10.0, eval.Noise(x))
+		require.Equal(t, 10.0, eval.ShallowCopy().(NoiseEstimator).Noise(x))
+
+		// Estimators do not share their estimates
+		require.Equal(t, model.Fresh(), other.Noise(x))
+
+		// Released operands are assumed fresh again
+		eval.Release(x, nil, 0)
+		require.Equal(t, model.Fresh(), eval.Noise(x))
+	})
+
+	t.Run("Estimator", func(t *testing.T) {
+		var warnings []string
+		eval := NewNoiseEstimator(params, NewGenericEvaluatorPlaintext(params), func(op string, _, _ float64) { warnings = append(warnings, op) })
+		x, y := params.RingT().NewPoly(), params.RingT().NewPoly()
+		require.Equal(t, model.Fresh(), eval.Noise(x))
+
+		eval.SetNoise(y, 30)
+		z := eval.AddNew(x, y)
+		require.Equal(t, model.Add(model.Fresh(), 30), eval.Noise(z))
+		eval.MulScalar(z, 4, z)
+		require.Equal(t, model.Add(model.Fresh(), 30)+2, eval.Noise(z))
+		require.Empty(t, warnings)
+
+		eval.SetNoise(y, model.Capacity())
+		eval.ShallowCopy().Mul(x, y, z)
+		require.Equal(t, []string{"Mul"}, warnings)
+		require.Less(t, eval.Budget(z), 0.0)
+	})
+}
+
+func TestNoiseMul(t *testing.T) {
+	for _, literal := range []bfv.ParametersLiteral{bfv.PN12QP109, bfv.PN13QP218} {
+		params, err := NewParametersFromLiteral(ParametersLiteral{ParametersLiteral: literal, NumReplications: 1, NumDistinctPRFKeys: 1})
+		require.NoError(t, err)
+		model := NewNoiseModel(params)
+
+		t.Run(fmt.Sprintf("LogN=%d", params.LogN()), func(t *testing.T) {
+			kgen := bfv.NewKeyGenerator(params.Parameters)
+			sk, pk := kgen.GenKeyPair()
+			encoder := bfv.NewEncoder(params.Parameters)
+			encryptor := bfv.NewEncryptor(params.Parameters, pk)
+			evaluator := bfv.NewEvaluator(params.Parameters, rlwe.EvaluationKey{Rlk: kgen.GenRelinearizationKey(sk, 1)})
+
+			// The estimate bounds the noise of the product of fresh ciphertexts, before and after relinearization
+			pt := bfv.NewPlaintext(params.Parameters)
+			for trial := 0; trial < 8; trial++ {
+				encoder.EncodeUint(GetRandomCoeffs(params.NSlots, params.T()), pt)
+				x := encryptor.EncryptNew(pt)
+				encoder.EncodeUint(GetRandomCoeffs(params.NSlots, params.T()), pt)
+				y := encryptor.EncryptNew(pt)
+				require.LessOrEqual(t, MeasureNoise(params.Parameters, sk, x), model.Fresh())
+
+				xy := evaluator.MulNew(x, y)
+				mul := model.Mul(model.Fresh(), model.Fresh(), 1)
+				require.LessOrEqual(t, MeasureNoise(params.Parameters, sk, xy), mul)
+				require.LessOrEqual(t, MeasureNoise(params.Parameters, sk, evaluator.RelinearizeNew(xy)), model.KeySwitch(mul))
+			}
+		})
+	}
+}
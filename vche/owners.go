@@ -0,0 +1,28 @@
+package vche
+
+// Datasets is the set of dataset labels, i.e., of first components Delta of the tags, of a data owner. In computations
+// over the inputs of several owners, each with its own PRF key, an owner encodes only the slots of its datasets and
+// leaves the others empty, so that the sum of the encodings of the owners is the joint input. The decryptor verifies
+// the joint result against the sum of the contributions of the owners to the verification state.
+//
+// The owners encrypt with the public key of the decryptor and keep their PRF keys to themselves, but the verification
+// secret of the decryptor, the dummy set of REP or the alphas of PE, cannot be derived per owner: the joint result is
+// verified against a single one, and every owner needs it to encode its inputs. The decryptor must thus trust each
+// owner with it, as an owner that shares it with the server lets the server forge results. Each owner also uploads a
+// full ciphertext, zero in the slots of the other owners, so that the upload of k owners costs k ciphertexts however
+// few slots each owns.
+type Datasets map[string]bool
+
+// NewDatasets returns the set of the given dataset labels.
+func NewDatasets(labels ...[]byte) Datasets {
+	d := make(Datasets, len(labels))
+	for _, label := range labels {
+		d[string(label)] = true
+	}
+	return d
+}
+
+// Owns tells whether the tag is labelled with one of the datasets. A nil set owns every tag.
+func (d Datasets) Owns(tag Tag) bool {
+	return d == nil || d[string(tag[0])]
+}
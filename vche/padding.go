@@ -0,0 +1,72 @@
+package vche
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// paddingIndexTagPrefix prefixes the index tags of padding slots. With the digest and the index that follow, padding
+// index tags are longer than the 8-byte index tags used throughout the library (see GetIndexTags), so that padding
+// tags do not collide with them.
+var paddingIndexTagPrefix = []byte("veritas/padding/")
+
+// PaddingTag returns the tag of the i-th slot of a padded vector whose values are tagged with the dataset tag
+// datasetTag, and whose tags have the digest digest (see PaddingDigest).
+func PaddingTag(datasetTag, digest []byte, i int) Tag {
+	indexTag := make([]byte, len(paddingIndexTagPrefix)+len(digest)+8)
+	copy(indexTag, paddingIndexTagPrefix)
+	copy(indexTag[len(paddingIndexTagPrefix):], digest)
+	binary.BigEndian.PutUint64(indexTag[len(paddingIndexTagPrefix)+len(digest):], uint64(i))
+	return Tag{datasetTag, indexTag}
+}
+
+// PaddingDigest returns the digest of the tags of a vector, from which its padding tags are derived. It commits to the
+// dataset and index tags of all the values, so that two vectors with distinct tags, e.g., two short vectors of the
+// same dataset, get distinct padding tags.
+func PaddingDigest(tags []Tag) []byte {
+	flat := make([][]byte, 0, 2*len(tags))
+	for _, tag := range tags {
+		flat = append(flat, tag[0], tag[1])
+	}
+	return TagsDigest(flat)
+}
+
+// PadTags extends tags to n tags with padding tags, generated with PaddingTag from the dataset tag of the last tag and
+// the digest of all the tags. Since the padding tags only depend on the given tags, the encoders and the verifier
+// derive the same padding, and the padded slots are authenticated as any other slot. Padding tags are unique as long
+// as the tags of the vectors are, as required by the scheme. It returns tags unchanged if they are already n or more.
+func PadTags(tags []Tag, n int) []Tag {
+	if len(tags) >= n {
+		return tags
+	}
+	if len(tags) == 0 {
+		panic(fmt.Errorf("cannot pad an empty list of tags"))
+	}
+	padded := make([]Tag, n)
+	copy(padded, tags)
+	datasetTag, digest := tags[len(tags)-1][0], PaddingDigest(tags)
+	for i := len(tags); i < n; i++ {
+		padded[i] = PaddingTag(datasetTag, digest, i)
+	}
+	return padded
+}
+
+// PadUint extends coeffs to n values with zeros. It returns coeffs unchanged if they are already n or more.
+func PadUint(coeffs []uint64, n int) []uint64 {
+	if len(coeffs) >= n {
+		return coeffs
+	}
+	padded := make([]uint64, n)
+	copy(padded, coeffs)
+	return padded
+}
+
+// PadInt extends coeffs to n values with zeros. It returns coeffs unchanged if they are already n or more.
+func PadInt(coeffs []int64, n int) []int64 {
+	if len(coeffs) >= n {
+		return coeffs
+	}
+	padded := make([]int64, n)
+	copy(padded, coeffs)
+	return padded
+}
@@ -0,0 +1,28 @@
+package vche
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPadTags(t *testing.T) {
+	tags := GetIndexTags([]byte("dataset"), 3)
+	padded := PadTags(tags, 8)
+	require.Len(t, padded, 8)
+	require.Equal(t, tags, padded[:3])
+	require.Equal(t, padded, PadTags(tags, 8), "the encoders and the verifier should derive the same padding")
+
+	// Two short vectors of the same dataset get distinct padding tags, including vectors of the same length
+	for _, other := range [][]Tag{tags[:2], GetTags([]byte("dataset"), [][]byte{{3}, {4}, {5}})} {
+		otherPadded := PadTags(other, 8)
+		for _, tag := range otherPadded[len(other):] {
+			for _, own := range padded {
+				require.NotEqual(t, own, tag)
+			}
+		}
+	}
+
+	require.Equal(t, tags, PadTags(tags, 3))
+	require.Panics(t, func() { PadTags(nil, 8) })
+}
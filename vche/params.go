@@ -44,14 +44,18 @@ var BfvPN16 = bfv.ParametersLiteral{
 type ParametersLiteral struct {
 	bfv.ParametersLiteral
 	NumReplications    int
+	NumDummies         int // number of dummy replications in REP, defaults to NumReplications/2 when zero
 	NumDistinctPRFKeys int
+	PRF                PRFType
 }
 
 type Parameters struct {
 	bfv.Parameters
 	NumReplications    int
+	NumDummies         int
 	NSlots             int
 	NumDistinctPRFKeys int
+	PRF                PRFType
 }
 
 func isPow2(x int) bool {
@@ -67,7 +71,7 @@ func NewParameters(bfvParams bfv.Parameters, numReplications int, numDistinctPRF
 	if numDistinctPRFKeys <= 0 {
 		return Parameters{}, fmt.Errorf("parameter numDistinctPRFKeys = %d should be positive", numDistinctPRFKeys)
 	}
-	return Parameters{Parameters: bfvParams, NumReplications: numReplications, NSlots: bfvParams.N() / numReplications, NumDistinctPRFKeys: numDistinctPRFKeys}, nil
+	return Parameters{Parameters: bfvParams, NumReplications: numReplications, NumDummies: numReplications / 2, NSlots: bfvParams.N() / numReplications, NumDistinctPRFKeys: numDistinctPRFKeys}, nil
 }
 
 // NewParametersFromLiteral instantiate a set of parameters from a ParametersLiteral specification.
@@ -80,13 +84,37 @@ func NewParametersFromLiteral(pl ParametersLiteral) (Parameters, error) {
 	if !(pl.NumReplications > 0 && (pl.NumReplications%2 == 0 || pl.NumReplications == 1)) {
 		return Parameters{}, fmt.Errorf("parameter numReplications = %d should be positive, and either even or 1", pl.NumReplications)
 	}
+	numDummies := pl.NumDummies
+	if numDummies == 0 {
+		numDummies = pl.NumReplications / 2
+	}
+	if numDummies < 0 || numDummies >= pl.NumReplications {
+		return Parameters{}, fmt.Errorf("parameter numDummies = %d should be non-negative and smaller than numReplications = %d", pl.NumDummies, pl.NumReplications)
+	}
 	if pl.NumDistinctPRFKeys <= 0 {
 		return Parameters{}, fmt.Errorf("parameter numDistinctPRFKeys = %d should be positive", pl.NumDistinctPRFKeys)
 	}
+	if !pl.PRF.valid() {
+		return Parameters{}, fmt.Errorf("parameter PRF = %v is not a supported PRF type", pl.PRF)
+	}
+
+	return Parameters{params, pl.NumReplications, numDummies, params.N() / pl.NumReplications, pl.NumDistinctPRFKeys, pl.PRF}, err
+}
+
+// WithPRF returns a copy of the parameters using the given PRF backend.
+func (p Parameters) WithPRF(prfType PRFType) Parameters {
+	if !prfType.valid() {
+		panic(fmt.Errorf("parameter PRF = %v is not a supported PRF type", prfType))
+	}
+	p.PRF = prfType
+	return p
+}
 
-	return Parameters{params, pl.NumReplications, params.N() / pl.NumReplications, pl.NumDistinctPRFKeys}, err
+// NewXOF returns an XOF keyed with K, of the type selected by the parameters.
+func (p Parameters) NewXOF(K []byte) XOF {
+	return NewXOFWithType(p.PRF, K)
 }
 
 func (p Parameters) Equals(other Parameters) bool {
-	return p.Parameters.Equals(other.Parameters) && p.NumReplications == other.NumReplications && p.NSlots == other.NSlots && p.NumDistinctPRFKeys == other.NumDistinctPRFKeys
+	return p.Parameters.Equals(other.Parameters) && p.NumReplications == other.NumReplications && p.NumDummies == other.NumDummies && p.NSlots == other.NSlots && p.NumDistinctPRFKeys == other.NumDistinctPRFKeys && p.PRF == other.PRF
 }
@@ -5,10 +5,47 @@ import (
 	"encoding/binary"
 	"fmt"
 	"golang.org/x/crypto/blake2b"
+	"io"
 	"math/big"
 	"math/bits"
 )
 
+// XOF is a keyed extendable-output function, from which the PRFs below draw their outputs. An XOF is reset to its keyed
+// state by Reset, absorbs its input through Write and is squeezed through Read.
+type XOF interface {
+	io.Writer
+	io.Reader
+	Reset()
+}
+
+// PRFType selects the XOF backing the PRFs of an encoder.
+type PRFType int
+
+const (
+	PRFBlake2b  PRFType = iota // BLAKE2b XOF (default)
+	PRFAESCTR                  // AES-CTR, hardware-accelerated on platforms with AES instructions
+	PRFSHAKE128                // SHAKE128
+)
+
+var PRFTypes = []PRFType{PRFBlake2b, PRFAESCTR, PRFSHAKE128}
+
+func (t PRFType) String() string {
+	switch t {
+	case PRFBlake2b:
+		return "BLAKE2b"
+	case PRFAESCTR:
+		return "AES-CTR"
+	case PRFSHAKE128:
+		return "SHAKE128"
+	default:
+		return fmt.Sprintf("PRFType(%d)", int(t))
+	}
+}
+
+func (t PRFType) valid() bool {
+	return t >= PRFBlake2b && t <= PRFSHAKE128
+}
+
 type Tag [2][]byte // A tag is a tuple (Delta, tau) in ({0,1}^*)^2
 
 type PRFKey struct {
@@ -32,7 +69,8 @@ func NewPRFKey(keyLen int) PRFKey {
 	return PRFKey{K1, K2}
 }
 
-func NewXOF(K []byte) blake2b.XOF {
+// NewXOF returns the default (BLAKE2b) XOF keyed with K.
+func NewXOF(K []byte) XOF {
 	xof, err := blake2b.NewXOF(8, K)
 	if err != nil {
 		panic(err)
@@ -40,7 +78,24 @@ func NewXOF(K []byte) blake2b.XOF {
 	return xof
 }
 
-func PRF(xof blake2b.XOF, T uint64, xs ...interface{}) uint64 {
+// NewXOFWithType returns an XOF of the given type keyed with K.
+func NewXOFWithType(prfType PRFType, K []byte) XOF {
+	switch prfType {
+	case PRFBlake2b:
+		return NewXOF(K)
+	case PRFAESCTR:
+		return newAESCTRXOF(K)
+	case PRFSHAKE128:
+		return newSHAKE128XOF(K)
+	default:
+		panic(fmt.Errorf("unsupported PRF type %v", prfType))
+	}
+}
+
+func PRF(xof XOF, T uint64, xs ...interface{}) uint64 {
+	if T == 0 {
+		panic(fmt.Errorf("PRF output modulus must be positive"))
+	}
 	maxValue := T
 	var err interface{}
 	mask := uint64(1<<uint64(bits.Len64(maxValue))) - 1
@@ -96,7 +151,7 @@ func PRF(xof blake2b.XOF, T uint64, xs ...interface{}) uint64 {
 	}
 }
 
-func PRFEfficient(xof1, xof2 blake2b.XOF, T uint64, xs ...interface{}) uint64 {
+func PRFEfficient(xof1, xof2 XOF, T uint64, xs ...interface{}) uint64 {
 	a, b, u, v := CFPRF(xof1, xof2, T, xs...)
 
 	bigT := big.NewInt(0).SetUint64(T)
@@ -115,7 +170,7 @@ func PRFEfficient(xof1, xof2 blake2b.XOF, T uint64, xs ...interface{}) uint64 {
 	return res.Uint64()
 }
 
-func CFPRF(xof1, xof2 blake2b.XOF, T uint64, xs ...interface{}) (uint64, uint64, uint64, uint64) {
+func CFPRF(xof1, xof2 XOF, T uint64, xs ...interface{}) (uint64, uint64, uint64, uint64) {
 	// Split tags up
 	v1 := make([]interface{}, len(xs)) // Input for PRF_1 (index tag)
 	v2 := make([]interface{}, len(xs)) // Input for PRF_2 (dataset tag)
@@ -0,0 +1,112 @@
+package vche
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"fmt"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/sha3"
+)
+
+// aesCTRXOF is an XOF built from AES: the absorbed input is compressed with a length-prefixed CBC-MAC into an IV, which
+// is then used to squeeze an AES-CTR keystream. The MAC and the keystream use distinct subkeys derived from K.
+type aesCTRXOF struct {
+	mac    cipher.Block
+	ctr    cipher.Block
+	buf    []byte
+	stream cipher.Stream
+}
+
+func newAESCTRXOF(K []byte) *aesCTRXOF {
+	key := K
+	if l := len(K); l != 16 && l != 24 && l != 32 {
+		// PRF keys are usually shorter than an AES key, expand them first
+		sum := blake2b.Sum256(K)
+		key = sum[:16]
+	}
+
+	master, err := aes.NewCipher(key)
+	if err != nil {
+		panic(err)
+	}
+
+	subkeyMac, subkeyCtr := make([]byte, aes.BlockSize), make([]byte, aes.BlockSize)
+	subkeyCtr[0] = 1
+	master.Encrypt(subkeyMac, subkeyMac)
+	master.Encrypt(subkeyCtr, subkeyCtr)
+
+	mac, err := aes.NewCipher(subkeyMac)
+	if err != nil {
+		panic(err)
+	}
+	ctr, err := aes.NewCipher(subkeyCtr)
+	if err != nil {
+		panic(err)
+	}
+	return &aesCTRXOF{mac: mac, ctr: ctr}
+}
+
+func (xof *aesCTRXOF) Write(p []byte) (int, error) {
+	if xof.stream != nil {
+		return 0, fmt.Errorf("cannot write to AES-CTR XOF after reading from it")
+	}
+	xof.buf = append(xof.buf, p...)
+	return len(p), nil
+}
+
+func (xof *aesCTRXOF) Read(p []byte) (int, error) {
+	if xof.stream == nil {
+		xof.stream = cipher.NewCTR(xof.ctr, xof.iv())
+	}
+	for i := range p {
+		p[i] = 0
+	}
+	xof.stream.XORKeyStream(p, p)
+	return len(p), nil
+}
+
+func (xof *aesCTRXOF) Reset() {
+	xof.buf = xof.buf[:0]
+	xof.stream = nil
+}
+
+// iv computes the CBC-MAC of the absorbed input. The input is prefixed with its length so that the set of encoded
+// messages is prefix-free, and zero-padded to a multiple of the block size.
+func (xof *aesCTRXOF) iv() []byte {
+	msg := make([]byte, 8, 8+len(xof.buf)+aes.BlockSize)
+	binary.BigEndian.PutUint64(msg, uint64(len(xof.buf)))
+	msg = append(msg, xof.buf...)
+	if r := len(msg) % aes.BlockSize; r != 0 {
+		msg = append(msg, make([]byte, aes.BlockSize-r)...)
+	}
+
+	state := make([]byte, aes.BlockSize)
+	for i := 0; i < len(msg); i += aes.BlockSize {
+		for j := 0; j < aes.BlockSize; j++ {
+			state[j] ^= msg[i+j]
+		}
+		xof.mac.Encrypt(state, state)
+	}
+	return state
+}
+
+// shake128XOF is SHAKE128 with the (length-prefixed) key absorbed before any input.
+type shake128XOF struct {
+	sha3.ShakeHash
+	key []byte
+}
+
+func newSHAKE128XOF(K []byte) *shake128XOF {
+	xof := &shake128XOF{sha3.NewShake128(), append([]byte{}, K...)}
+	xof.Reset()
+	return xof
+}
+
+func (xof *shake128XOF) Reset() {
+	xof.ShakeHash.Reset()
+	keyLen := make([]byte, 8)
+	binary.BigEndian.PutUint64(keyLen, uint64(len(xof.key)))
+	_, _ = xof.ShakeHash.Write(keyLen)
+	_, _ = xof.ShakeHash.Write(xof.key)
+}
@@ -13,25 +13,52 @@ func TestPRF(t *testing.T) {
 	N := bfvParams.N()
 	T := bfvParams.T()
 	K := NewPRFKey(8)
-	xof := NewXOF(K.K1)
 
-	tags := GetRandomTags(N)
+	for _, prfType := range PRFTypes {
+		t.Run(prfType.String(), func(t *testing.T) {
+			xof := NewXOFWithType(prfType, K.K1)
 
-	ys1 := make([]uint64, N)
-	for i := range ys1 {
-		ys1[i] = PRF(xof, T, tags[i])
-	}
+			tags := GetRandomTags(N)
+
+			ys1 := make([]uint64, N)
+			for i := range ys1 {
+				ys1[i] = PRF(xof, T, tags[i])
+				require.Less(t, ys1[i], T)
+			}
+
+			ys2 := make([]uint64, N)
+			for i := range ys2 {
+				ys2[i] = PRF(xof, T, tags[i])
+			}
+			require.Equal(t, ys1, ys2)
+
+			ys3 := make([]uint64, N)
+			for i := range ys3 {
+				tagNew := Tag{tags[i][0], tags[i][1][1:]}
+				ys3[i] = PRF(xof, T, tagNew)
+			}
+			require.NotEqual(t, ys1, ys3)
 
-	ys2 := make([]uint64, N)
-	for i := range ys2 {
-		ys2[i] = PRF(xof, T, tags[i])
+			xofOtherKey := NewXOFWithType(prfType, K.K2)
+			ys4 := make([]uint64, N)
+			for i := range ys4 {
+				ys4[i] = PRF(xofOtherKey, T, tags[i])
+			}
+			require.NotEqual(t, ys1, ys4)
+		})
 	}
-	require.Equal(t, ys1, ys2)
+}
+
+func TestPRFTypesDiffer(t *testing.T) {
+	K := NewPRFKey(8)
+	tag := GetRandomTags(1)[0]
+	T := uint64(0xffffffffffc0001)
 
-	ys3 := make([]uint64, N)
-	for i := range ys3 {
-		tagNew := Tag{tags[i][0], tags[i][1][1:]}
-		ys3[i] = PRF(xof, T, tagNew)
+	outs := map[uint64]PRFType{}
+	for _, prfType := range PRFTypes {
+		y := PRF(NewXOFWithType(prfType, K.K1), T, tag)
+		_, seen := outs[y]
+		require.False(t, seen, "PRF backends %v and %v collide", outs[y], prfType)
+		outs[y] = prfType
 	}
-	require.NotEqual(t, ys1, ys3)
 }
@@ -1,13 +1,18 @@
 package vche
 
 import (
+	"encoding"
 	"encoding/binary"
 	"fmt"
 	"github.com/ldsec/lattigo/v2/bfv"
+	"github.com/ldsec/lattigo/v2/drlwe"
 	"github.com/ldsec/lattigo/v2/ring"
+	"github.com/ldsec/lattigo/v2/rlwe"
 	"github.com/ldsec/lattigo/v2/utils"
 	"math"
 	"math/bits"
+	"reflect"
+	"runtime"
 )
 
 func GetRandomCoeffs(N int, maxValue uint64) []uint64 {
@@ -63,6 +68,21 @@ func GetRandomTagsSameIndex(N int) []Tag {
 	return tags
 }
 
+// GetLabelledTags returns a copy of the tags whose first half is labelled with dataset a and second half with dataset
+// b, as the inputs of two data owners.
+func GetLabelledTags(tags []Tag, a, b []byte) []Tag {
+	labelled := make([]Tag, len(tags))
+	copy(labelled, tags)
+	for i := range labelled {
+		if i < len(tags)/2 {
+			labelled[i][0] = a
+		} else {
+			labelled[i][0] = b
+		}
+	}
+	return labelled
+}
+
 func GetTags(datasetTag []byte, indexTags [][]byte) []Tag {
 	res := make([]Tag, len(indexTags))
 	for i := range indexTags {
@@ -118,3 +138,109 @@ func PrintCryptoParams(ps interface{}) {
 		panic(fmt.Errorf("unexpected parameters argument"))
 	}
 }
+
+// Mutation is a modification of a value, decoded from the input of a fuzz test. The fuzz tests interpret Kind, and reduce
+// Index modulo the size of the modified object.
+type Mutation struct {
+	Kind  byte
+	Index uint16
+	Delta uint64
+}
+
+// MutationSize is the number of bytes from which a Mutation is decoded.
+const MutationSize = 11
+
+// ParseMutations decodes the mutations of a fuzz input, ignoring its trailing bytes.
+func ParseMutations(data []byte) []Mutation {
+	ms := make([]Mutation, len(data)/MutationSize)
+	for i := range ms {
+		b := data[i*MutationSize : (i+1)*MutationSize]
+		ms[i] = Mutation{b[0], binary.BigEndian.Uint16(b[1:3]), binary.BigEndian.Uint64(b[3:])}
+	}
+	return ms
+}
+
+// Bytes encodes the mutation, e.g., to add it to the seed corpus of a fuzz test.
+func (m Mutation) Bytes() []byte {
+	b := make([]byte, MutationSize)
+	b[0] = m.Kind
+	binary.BigEndian.PutUint16(b[1:3], m.Index)
+	binary.BigEndian.PutUint64(b[3:], m.Delta)
+	return b
+}
+
+// Rejects runs verify and reports whether it rejected its input by panicking, as the verifications of the encodings do.
+// A panic due to a runtime error, such as an out-of-range index, is a crash rather than a rejection, and is propagated.
+func Rejects(verify func()) (rejected bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(runtime.Error); ok {
+				panic(r)
+			}
+			rejected = true
+		}
+	}()
+	verify()
+	return false
+}
+
+// CheckRoundTrip decodes data with decode, as the fuzz tests of the unmarshalers do. Malformed data should be rejected
+// with an error rather than a panic, and accepted data should encode back to as many bytes, which decode to the same
+// object. It returns an error if an accepted object does not round trip.
+func CheckRoundTrip[T encoding.BinaryMarshaler](data []byte, decode func([]byte) (T, error)) error {
+	x, err := decode(data)
+	if err != nil {
+		return nil
+	}
+	encoded, err := x.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("cannot encode a decoded %T: %w", x, err)
+	}
+	if len(encoded) != len(data) {
+		return fmt.Errorf("%T decoded from %d bytes encodes to %d bytes", x, len(data), len(encoded))
+	}
+	y, err := decode(encoded)
+	if err != nil {
+		return fmt.Errorf("cannot decode back a %T: %w", x, err)
+	}
+	if !reflect.DeepEqual(x, y) {
+		return fmt.Errorf("%T does not decode back to the same object", x)
+	}
+	return nil
+}
+
+// GenCollectiveKeys runs the key generation protocols among the parties, each of which sends its shares to the
+// aggregator, and returns the collective public, relinearization and rotation keys.
+func GenCollectiveKeys(params Parameters, protocols *Protocols, parties []*Party, galEls []uint64) (*rlwe.PublicKey, *rlwe.RelinearizationKey, *rlwe.RotationKeySet) {
+	pkShares := make([]*drlwe.CKGShare, len(parties))
+	rlkShares := make([]*drlwe.RKGShare, len(parties))
+	for i, p := range parties {
+		pkShares[i] = p.GenPublicKeyShare()
+		rlkShares[i] = p.GenRelinearizationKeyShareRoundOne()
+	}
+	round1 := protocols.AggregateRelinearizationKeyRoundOne(rlkShares...)
+	for i, p := range parties {
+		rlkShares[i] = p.GenRelinearizationKeyShareRoundTwo(round1)
+	}
+
+	rtks := bfv.NewRotationKeySet(params.Parameters, galEls)
+	for _, galEl := range galEls {
+		rtkShares := make([]*drlwe.RTGShare, len(parties))
+		for i, p := range parties {
+			rtkShares[i] = p.GenRotationKeyShare(galEl)
+		}
+		rtks.Keys[galEl] = protocols.AggregateRotationKey(galEl, rtkShares...)
+	}
+	return protocols.AggregatePublicKey(pkShares...), protocols.AggregateRelinearizationKey(round1, rlkShares...), rtks
+}
+
+// GetDecryptionShares returns the decryption shares that the parties send to the aggregator.
+func GetDecryptionShares(parties []*Party) DecryptionShares {
+	return func(ct *bfv.Ciphertext) []*drlwe.CKSShare {
+		shares := make([]*drlwe.CKSShare, len(parties))
+		for i, p := range parties {
+			shares[i] = p.GenDecryptionShare(ct)
+		}
+		return shares
+	}
+}
@@ -0,0 +1,377 @@
+package vche
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// TraceOp is a single call to a GenericEvaluator. Operands are referred to by their ID in the trace: the inputs of the
+// trace come first, in order of first use, and every result gets a fresh ID unless it is written in place into an
+// operand that already has one.
+type TraceOp struct {
+	Op     string `json:"op"`
+	In     []int  `json:"in"`
+	Out    int    `json:"out"`
+	Scalar uint64 `json:"scalar,omitempty"`
+	K      int    `json:"k,omitempty"`
+	Key    int    `json:"key,omitempty"` // index of the switching key, for SwitchKeys
+}
+
+func (op TraceOp) String() string {
+	s := fmt.Sprintf("%d = %s%v", op.Out, op.Op, op.In)
+	switch op.Op {
+	case "MulScalar":
+		s += fmt.Sprintf(" scalar=%d", op.Scalar)
+	case "RotateColumns":
+		s += fmt.Sprintf(" k=%d", op.K)
+	case "SwitchKeys":
+		s += fmt.Sprintf(" key=%d", op.Key)
+	}
+	return s
+}
+
+// Trace is a serializable log of the calls made to a GenericEvaluator.
+type Trace struct {
+	Inputs  []int     `json:"inputs"`
+	Ops     []TraceOp `json:"ops"`
+	Outputs []int     `json:"outputs"`
+}
+
+func (t *Trace) MarshalBinary() ([]byte, error) {
+	return json.Marshal(t)
+}
+
+func (t *Trace) UnmarshalBinary(data []byte) error {
+	return json.Unmarshal(data, t)
+}
+
+// Diff returns a description of the differences between t and other, op by op, or nil if they are the same.
+func (t *Trace) Diff(other *Trace) []string {
+	var diffs []string
+	if fmt.Sprint(t.Inputs) != fmt.Sprint(other.Inputs) {
+		diffs = append(diffs, fmt.Sprintf("inputs: %v != %v", t.Inputs, other.Inputs))
+	}
+	for i := 0; i < len(t.Ops) || i < len(other.Ops); i++ {
+		switch {
+		case i >= len(t.Ops):
+			diffs = append(diffs, fmt.Sprintf("op %d: missing != %v", i, other.Ops[i]))
+		case i >= len(other.Ops):
+			diffs = append(diffs, fmt.Sprintf("op %d: %v != missing", i, t.Ops[i]))
+		case t.Ops[i].String() != other.Ops[i].String():
+			diffs = append(diffs, fmt.Sprintf("op %d: %v != %v", i, t.Ops[i], other.Ops[i]))
+		}
+	}
+	if fmt.Sprint(t.Outputs) != fmt.Sprint(other.Outputs) {
+		diffs = append(diffs, fmt.Sprintf("outputs: %v != %v", t.Outputs, other.Outputs))
+	}
+	return diffs
+}
+
+// RecordingEvaluator is a GenericEvaluator that forwards every call to an underlying evaluator, and records it into a
+// Trace. Operands are identified by reference, so they must be pointers.
+type RecordingEvaluator interface {
+	GenericEvaluator
+	// Output marks the given operands as outputs of the trace, in order.
+	Output(ops ...interface{})
+	Trace() *Trace
+}
+
+// recorder is the state shared by a RecordingEvaluator and its copies.
+type recorder struct {
+	sync.Mutex
+	trace *Trace
+	ids   map[interface{}]int
+	keys  map[interface{}]int
+	next  int
+}
+
+type recordingEvaluator struct {
+	GenericEvaluator
+	rec *recorder
+}
+
+func NewRecordingEvaluator(eval GenericEvaluator) RecordingEvaluator {
+	return &recordingEvaluator{eval, &recorder{trace: &Trace{}, ids: make(map[interface{}]int), keys: make(map[interface{}]int)}}
+}
+
+// id returns the ID of op, registering it as an input of the trace if it was never seen.
+func (rec *recorder) id(op interface{}) int {
+	if id, ok := rec.ids[op]; ok {
+		return id
+	}
+	id := rec.next
+	rec.next++
+	rec.ids[op] = id
+	rec.trace.Inputs = append(rec.trace.Inputs, id)
+	return id
+}
+
+// outID returns the ID of the output op, which is fresh unless op already has an ID.
+func (rec *recorder) outID(op interface{}) int {
+	if id, ok := rec.ids[op]; ok {
+		return id
+	}
+	id := rec.next
+	rec.next++
+	rec.ids[op] = id
+	return id
+}
+
+func (rec *recorder) record(op TraceOp, ins []interface{}, out interface{}) {
+	rec.Lock()
+	defer rec.Unlock()
+	op.In = make([]int, len(ins))
+	for i := range ins {
+		op.In[i] = rec.id(ins[i])
+	}
+	op.Out = rec.outID(out)
+	rec.trace.Ops = append(rec.trace.Ops, op)
+}
+
+func (rec *recorder) keyID(key interface{}) int {
+	rec.Lock()
+	defer rec.Unlock()
+	if id, ok := rec.keys[key]; ok {
+		return id
+	}
+	rec.keys[key] = len(rec.keys)
+	return rec.keys[key]
+}
+
+func (eval *recordingEvaluator) Output(ops ...interface{}) {
+	eval.rec.Lock()
+	defer eval.rec.Unlock()
+	for _, op := range ops {
+		id, ok := eval.rec.ids[op]
+		if !ok {
+			panic(fmt.Errorf("operand %T was not produced by the recorded evaluation", op))
+		}
+		eval.rec.trace.Outputs = append(eval.rec.trace.Outputs, id)
+	}
+}
+
+func (eval *recordingEvaluator) Trace() *Trace {
+	return eval.rec.trace
+}
+
+func (eval *recordingEvaluator) CopyNew(op interface{}) interface{} {
+	out := eval.GenericEvaluator.CopyNew(op)
+	eval.rec.record(TraceOp{Op: "CopyNew"}, []interface{}{op}, out)
+	return out
+}
+
+func (eval *recordingEvaluator) Add(op0, op1 interface{}, ctOut interface{}) {
+	eval.GenericEvaluator.Add(op0, op1, ctOut)
+	eval.rec.record(TraceOp{Op: "Add"}, []interface{}{op0, op1}, ctOut)
+}
+
+func (eval *recordingEvaluator) AddNew(op0, op1 interface{}) (ctOut interface{}) {
+	ctOut = eval.GenericEvaluator.AddNew(op0, op1)
+	eval.rec.record(TraceOp{Op: "Add"}, []interface{}{op0, op1}, ctOut)
+	return ctOut
+}
+
+func (eval *recordingEvaluator) AddNoMod(op0, op1 interface{}, ctOut interface{}) {
+	eval.GenericEvaluator.AddNoMod(op0, op1, ctOut)
+	eval.rec.record(TraceOp{Op: "AddNoMod"}, []interface{}{op0, op1}, ctOut)
+}
+
+func (eval *recordingEvaluator) AddNoModNew(op0, op1 interface{}) (ctOut interface{}) {
+	ctOut = eval.GenericEvaluator.AddNoModNew(op0, op1)
+	eval.rec.record(TraceOp{Op: "AddNoMod"}, []interface{}{op0, op1}, ctOut)
+	return ctOut
+}
+
+func (eval *recordingEvaluator) Sub(op0, op1 interface{}, ctOut interface{}) {
+	eval.GenericEvaluator.Sub(op0, op1, ctOut)
+	eval.rec.record(TraceOp{Op: "Sub"}, []interface{}{op0, op1}, ctOut)
+}
+
+func (eval *recordingEvaluator) SubNew(op0, op1 interface{}) (ctOut interface{}) {
+	ctOut = eval.GenericEvaluator.SubNew(op0, op1)
+	eval.rec.record(TraceOp{Op: "Sub"}, []interface{}{op0, op1}, ctOut)
+	return ctOut
+}
+
+func (eval *recordingEvaluator) SubNoMod(op0, op1 interface{}, ctOut interface{}) {
+	eval.GenericEvaluator.SubNoMod(op0, op1, ctOut)
+	eval.rec.record(TraceOp{Op: "SubNoMod"}, []interface{}{op0, op1}, ctOut)
+}
+
+func (eval *recordingEvaluator) SubNoModNew(op0, op1 interface{}) (ctOut interface{}) {
+	ctOut = eval.GenericEvaluator.SubNoModNew(op0, op1)
+	eval.rec.record(TraceOp{Op: "SubNoMod"}, []interface{}{op0, op1}, ctOut)
+	return ctOut
+}
+
+func (eval *recordingEvaluator) Neg(op interface{}, ctOut interface{}) {
+	eval.GenericEvaluator.Neg(op, ctOut)
+	eval.rec.record(TraceOp{Op: "Neg"}, []interface{}{op}, ctOut)
+}
+
+func (eval *recordingEvaluator) NegNew(op interface{}) (ctOut interface{}) {
+	ctOut = eval.GenericEvaluator.NegNew(op)
+	eval.rec.record(TraceOp{Op: "Neg"}, []interface{}{op}, ctOut)
+	return ctOut
+}
+
+func (eval *recordingEvaluator) Reduce(op interface{}, ctOut interface{}) {
+	eval.GenericEvaluator.Reduce(op, ctOut)
+	eval.rec.record(TraceOp{Op: "Reduce"}, []interface{}{op}, ctOut)
+}
+
+func (eval *recordingEvaluator) ReduceNew(op interface{}) (ctOut interface{}) {
+	ctOut = eval.GenericEvaluator.ReduceNew(op)
+	eval.rec.record(TraceOp{Op: "Reduce"}, []interface{}{op}, ctOut)
+	return ctOut
+}
+
+func (eval *recordingEvaluator) MulScalar(op interface{}, scalar uint64, ctOut interface{}) {
+	eval.GenericEvaluator.MulScalar(op, scalar, ctOut)
+	eval.rec.record(TraceOp{Op: "MulScalar", Scalar: scalar}, []interface{}{op}, ctOut)
+}
+
+func (eval *recordingEvaluator) MulScalarNew(op interface{}, scalar uint64) (ctOut interface{}) {
+	ctOut = eval.GenericEvaluator.MulScalarNew(op, scalar)
+	eval.rec.record(TraceOp{Op: "MulScalar", Scalar: scalar}, []interface{}{op}, ctOut)
+	return ctOut
+}
+
+func (eval *recordingEvaluator) Mul(op0 interface{}, op1 interface{}, ctOut interface{}) {
+	eval.GenericEvaluator.Mul(op0, op1, ctOut)
+	eval.rec.record(TraceOp{Op: "Mul"}, []interface{}{op0, op1}, ctOut)
+}
+
+func (eval *recordingEvaluator) MulNew(op0 interface{}, op1 interface{}) (ctOut interface{}) {
+	ctOut = eval.GenericEvaluator.MulNew(op0, op1)
+	eval.rec.record(TraceOp{Op: "Mul"}, []interface{}{op0, op1}, ctOut)
+	return ctOut
+}
+
+func (eval *recordingEvaluator) Relinearize(ct0 interface{}, ctOut interface{}) {
+	eval.GenericEvaluator.Relinearize(ct0, ctOut)
+	eval.rec.record(TraceOp{Op: "Relinearize"}, []interface{}{ct0}, ctOut)
+}
+
+func (eval *recordingEvaluator) RelinearizeNew(ct0 interface{}) (ctOut interface{}) {
+	ctOut = eval.GenericEvaluator.RelinearizeNew(ct0)
+	eval.rec.record(TraceOp{Op: "Relinearize"}, []interface{}{ct0}, ctOut)
+	return ctOut
+}
+
+func (eval *recordingEvaluator) SwitchKeys(ct0 interface{}, switchKey interface{}, ctOut interface{}) {
+	eval.GenericEvaluator.SwitchKeys(ct0, switchKey, ctOut)
+	eval.rec.record(TraceOp{Op: "SwitchKeys", Key: eval.rec.keyID(switchKey)}, []interface{}{ct0}, ctOut)
+}
+
+func (eval *recordingEvaluator) SwitchKeysNew(ct0 interface{}, switchKey interface{}) (ctOut interface{}) {
+	ctOut = eval.GenericEvaluator.SwitchKeysNew(ct0, switchKey)
+	eval.rec.record(TraceOp{Op: "SwitchKeys", Key: eval.rec.keyID(switchKey)}, []interface{}{ct0}, ctOut)
+	return ctOut
+}
+
+func (eval *recordingEvaluator) RotateColumns(ct0 interface{}, k int, ctOut interface{}) {
+	eval.GenericEvaluator.RotateColumns(ct0, k, ctOut)
+	eval.rec.record(TraceOp{Op: "RotateColumns", K: k}, []interface{}{ct0}, ctOut)
+}
+
+func (eval *recordingEvaluator) RotateColumnsNew(ct0 interface{}, k int) (ctOut interface{}) {
+	ctOut = eval.GenericEvaluator.RotateColumnsNew(ct0, k)
+	eval.rec.record(TraceOp{Op: "RotateColumns", K: k}, []interface{}{ct0}, ctOut)
+	return ctOut
+}
+
+func (eval *recordingEvaluator) RotateRows(ct0 interface{}, ctOut interface{}) {
+	eval.GenericEvaluator.RotateRows(ct0, ctOut)
+	eval.rec.record(TraceOp{Op: "RotateRows"}, []interface{}{ct0}, ctOut)
+}
+
+func (eval *recordingEvaluator) RotateRowsNew(ct0 interface{}) (ctOut interface{}) {
+	ctOut = eval.GenericEvaluator.RotateRowsNew(ct0)
+	eval.rec.record(TraceOp{Op: "RotateRows"}, []interface{}{ct0}, ctOut)
+	return ctOut
+}
+
+func (eval *recordingEvaluator) InnerSum(ct0 interface{}, ctOut interface{}) {
+	eval.GenericEvaluator.InnerSum(ct0, ctOut)
+	eval.rec.record(TraceOp{Op: "InnerSum"}, []interface{}{ct0}, ctOut)
+}
+
+// ShallowCopy returns a copy of the evaluator that records into the same trace.
+func (eval *recordingEvaluator) ShallowCopy() GenericEvaluator {
+	return &recordingEvaluator{eval.GenericEvaluator.ShallowCopy(), eval.rec}
+}
+
+// WithKey returns an evaluator with the given key that records into the same trace.
+func (eval *recordingEvaluator) WithKey(evk interface{}) GenericEvaluator {
+	return &recordingEvaluator{eval.GenericEvaluator.WithKey(evk), eval.rec}
+}
+
+// Replay re-executes trace with eval on the given inputs, and returns the outputs of the trace. The inputs must be
+// given in the order of trace.Inputs, and switchingKeys in the order in which they were first used. Results are always
+// computed out of place, so the inputs are not modified.
+func Replay(trace *Trace, eval GenericEvaluator, inputs []interface{}, switchingKeys ...interface{}) []interface{} {
+	if len(inputs) != len(trace.Inputs) {
+		panic(fmt.Errorf("trace expects %d inputs, got %d", len(trace.Inputs), len(inputs)))
+	}
+
+	values := make(map[int]interface{})
+	for i, id := range trace.Inputs {
+		values[id] = inputs[i]
+	}
+	in := func(op TraceOp, i int) interface{} {
+		v, ok := values[op.In[i]]
+		if !ok {
+			panic(fmt.Errorf("operand %d of %v is undefined", op.In[i], op))
+		}
+		return v
+	}
+
+	for _, op := range trace.Ops {
+		var out interface{}
+		switch op.Op {
+		case "CopyNew":
+			out = eval.CopyNew(in(op, 0))
+		case "Add":
+			out = eval.AddNew(in(op, 0), in(op, 1))
+		case "AddNoMod":
+			out = eval.AddNoModNew(in(op, 0), in(op, 1))
+		case "Sub":
+			out = eval.SubNew(in(op, 0), in(op, 1))
+		case "SubNoMod":
+			out = eval.SubNoModNew(in(op, 0), in(op, 1))
+		case "Neg":
+			out = eval.NegNew(in(op, 0))
+		case "Reduce":
+			out = eval.ReduceNew(in(op, 0))
+		case "MulScalar":
+			out = eval.MulScalarNew(in(op, 0), op.Scalar)
+		case "Mul":
+			out = eval.MulNew(in(op, 0), in(op, 1))
+		case "Relinearize":
+			out = eval.RelinearizeNew(in(op, 0))
+		case "SwitchKeys":
+			if op.Key >= len(switchingKeys) {
+				panic(fmt.Errorf("missing switching key %d for %v", op.Key, op))
+			}
+			out = eval.SwitchKeysNew(in(op, 0), switchingKeys[op.Key])
+		case "RotateColumns":
+			out = eval.RotateColumnsNew(in(op, 0), op.K)
+		case "RotateRows":
+			out = eval.RotateRowsNew(in(op, 0))
+		case "InnerSum":
+			out = eval.CopyNew(in(op, 0))
+			eval.InnerSum(out, out)
+		default:
+			panic(fmt.Errorf("unsupported operation %s in trace", op.Op))
+		}
+		values[op.Out] = out
+	}
+
+	outputs := make([]interface{}, len(trace.Outputs))
+	for i, id := range trace.Outputs {
+		outputs[i] = values[id]
+	}
+	return outputs
+}
@@ -0,0 +1,60 @@
+package vche
+
+import (
+	"github.com/ldsec/lattigo/v2/bfv"
+	"github.com/ldsec/lattigo/v2/ring"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestTrace(t *testing.T) {
+	params, err := NewParametersFromLiteral(ParametersLiteral{ParametersLiteral: bfv.PN12QP109, NumReplications: 1, NumDistinctPRFKeys: 1})
+	require.NoError(t, err)
+	ringT := params.RingT()
+
+	newInput := func() *ring.Poly {
+		p := ringT.NewPoly()
+		ringT.SetCoefficientsUint64(GetRandomCoeffs(params.N(), params.T()), p)
+		return p
+	}
+	x, y := newInput(), newInput()
+
+	compute := func(eval GenericEvaluator, scalar uint64) []interface{} {
+		z := eval.MulNew(x, y)
+		eval.Relinearize(z, z)
+		w := ringT.NewPoly()
+		eval.MulScalar(z, scalar, w)
+		eval.Add(w, eval.RotateColumnsNew(x, 3), w)
+		s := eval.CopyNew(w)
+		eval.InnerSum(s, s)
+		return []interface{}{w, s}
+	}
+
+	eval := NewRecordingEvaluator(NewGenericEvaluatorPlaintext(params))
+	outs := compute(eval, 5)
+	eval.Output(outs...)
+	trace := eval.Trace()
+	require.Equal(t, []int{0, 1}, trace.Inputs)
+	require.Len(t, trace.Ops, 7)
+
+	data, err := trace.MarshalBinary()
+	require.NoError(t, err)
+	replayed := new(Trace)
+	require.NoError(t, replayed.UnmarshalBinary(data))
+	require.Empty(t, trace.Diff(replayed))
+
+	xCopy, yCopy := x.CopyNew(), y.CopyNew()
+	replayedOuts := Replay(replayed, NewGenericEvaluatorPlaintext(params), []interface{}{x, y})
+	require.True(t, x.Equals(xCopy) && y.Equals(yCopy), "inputs should not be modified")
+	for i := range outs {
+		require.True(t, outs[i].(*ring.Poly).Equals(replayedOuts[i].(*ring.Poly)))
+	}
+
+	// A run with a different scalar yields a trace that differs in exactly one operation
+	other := NewRecordingEvaluator(NewGenericEvaluatorPlaintext(params))
+	other.Output(compute(other, 6)...)
+	require.Len(t, trace.Diff(other.Trace()), 1)
+
+	require.Panics(t, func() { Replay(trace, NewGenericEvaluatorPlaintext(params), []interface{}{x}) })
+	require.Panics(t, func() { eval.Output(ringT.NewPoly()) })
+}
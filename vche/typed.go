@@ -0,0 +1,521 @@
+package vche
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/ldsec/lattigo/v2/ring"
+)
+
+// TypedEncoder is the type-safe counterpart of GenericEncoder, for an encoding with plaintexts of type Pt, plaintexts
+// for multiplications of type PtMul, and verification state of type V. The encoders of the encodings implement it,
+// e.g., vche_1.Encoder is a TypedEncoder[*vche_1.Plaintext, *vche_1.PlaintextMul, *vche_1.TaggedPoly].
+type TypedEncoder[Pt, PtMul, V any] interface {
+	EncodeUint(coeffs []uint64, tags []Tag, pt Pt)
+	EncodeUintNew(coeffs []uint64, tags []Tag) (pt Pt)
+	EncodeInt(coeffs []int64, tags []Tag, pt Pt)
+	EncodeIntNew(coeffs []int64, tags []Tag) (pt Pt)
+	EncodeUintMul(coeffs []uint64, tags []Tag, pt PtMul)
+	EncodeUintMulNew(coeffs []uint64, tags []Tag) (pt PtMul)
+	EncodeIntMul(coeffs []int64, tags []Tag, pt PtMul)
+	EncodeIntMulNew(coeffs []int64, tags []Tag) (pt PtMul)
+	DecodeUint(pt Pt, verif V, coeffs []uint64)
+	DecodeUintNew(pt Pt, verif V) (coeffs []uint64)
+	DecodeInt(pt Pt, verif V, coeffs []int64)
+	DecodeIntNew(pt Pt, verif V) (coeffs []int64)
+}
+
+// TypedEncoderPlaintext is the type-safe counterpart of GenericEncoderPlaintext, for verification state of type V.
+type TypedEncoderPlaintext[V any] interface {
+	Encode(tags []Tag, p V)
+	EncodeNew(tags []Tag) V
+}
+
+// TypedEncryptor is the type-safe counterpart of GenericEncryptor.
+type TypedEncryptor[Pt, Ct any] interface {
+	Encrypt(plaintext Pt, ciphertext Ct)
+	EncryptNew(plaintext Pt) Ct
+}
+
+// TypedDecryptor is the type-safe counterpart of GenericDecryptor.
+type TypedDecryptor[Ct, Pt any] interface {
+	Decrypt(ciphertext Ct, plaintext Pt)
+	DecryptNew(ciphertext Ct) (plaintext Pt)
+}
+
+// TypedEvaluator is the type-safe counterpart of GenericEvaluator on the values of type T of an encoding, i.e., its
+// ciphertexts on the server and their verification state on the client, so that the same generic function evaluates
+// a computation on both. Operations between ciphertexts and plaintexts remain on the evaluator of the encoding. The
+// plaintext evaluators of the encodings implement it, e.g., vche_1.EvaluatorPlaintext is a
+// TypedEvaluator[*vche_1.TaggedPoly], and NewTypedEvaluator adapts their ciphertext evaluators.
+type TypedEvaluator[T any] interface {
+	CopyNew(op T) T
+	Add(op0, op1 T, ctOut T)
+	AddNew(op0, op1 T) (ctOut T)
+	AddNoMod(op0, op1 T, ctOut T)
+	AddNoModNew(op0, op1 T) (ctOut T)
+	Sub(op0, op1 T, ctOut T)
+	SubNew(op0, op1 T) (ctOut T)
+	SubNoMod(op0, op1 T, ctOut T)
+	SubNoModNew(op0, op1 T) (ctOut T)
+	Neg(op T, ctOut T)
+	NegNew(op T) (ctOut T)
+	Reduce(op T, ctOut T)
+	ReduceNew(op T) (ctOut T)
+	MulScalar(op T, scalar uint64, ctOut T)
+	MulScalarNew(op T, scalar uint64) (ctOut T)
+	Mul(op0, op1 T, ctOut T)
+	MulNew(op0, op1 T) (ctOut T)
+	Relinearize(ct0 T, ctOut T)
+	RelinearizeNew(ct0 T) (ctOut T)
+	RotateColumns(ct0 T, k int, ctOut T)
+	RotateColumnsNew(ct0 T, k int) (ctOut T)
+	RotateRows(ct0 T, ctOut T)
+	RotateRowsNew(ct0 T) (ctOut T)
+	InnerSum(ct0 T, ctOut T)
+}
+
+// OperandEvaluator is an evaluator whose operands have type Op and whose results have type T, e.g., Evaluator with
+// Operand and *Ciphertext, or vche_1.Evaluator with vche_1.Operand and *vche_1.Ciphertext.
+type OperandEvaluator[Op, T any] interface {
+	CopyNew(op Op) Op
+	Add(op0, op1 Op, ctOut T)
+	AddNew(op0, op1 Op) (ctOut T)
+	AddNoMod(op0, op1 Op, ctOut T)
+	AddNoModNew(op0, op1 Op) (ctOut T)
+	Sub(op0, op1 Op, ctOut T)
+	SubNew(op0, op1 Op) (ctOut T)
+	SubNoMod(op0, op1 Op, ctOut T)
+	SubNoModNew(op0, op1 Op) (ctOut T)
+	Neg(op Op, ctOut T)
+	NegNew(op Op) (ctOut T)
+	Reduce(op Op, ctOut T)
+	ReduceNew(op Op) (ctOut T)
+	MulScalar(op Op, scalar uint64, ctOut T)
+	MulScalarNew(op Op, scalar uint64) (ctOut T)
+	Mul(op0 T, op1 Op, ctOut T)
+	MulNew(op0 T, op1 Op) (ctOut T)
+	Relinearize(ct0 T, ctOut T)
+	RelinearizeNew(ct0 T) (ctOut T)
+	RotateColumns(ct0 T, k int, ctOut T)
+	RotateColumnsNew(ct0 T, k int) (ctOut T)
+	RotateRows(ct0 T, ctOut T)
+	RotateRowsNew(ct0 T) (ctOut T)
+	InnerSum(ct0 T, ctOut T)
+}
+
+var _ OperandEvaluator[Operand, *Ciphertext] = Evaluator(nil)
+var _ TypedEvaluator[*Ciphertext] = typedEvaluator[Operand, *Ciphertext]{}
+var _ TypedEvaluator[*ring.Poly] = EvaluatorPlaintext(nil)
+var _ TypedEvaluator[*VerifPlaintext] = EvaluatorPlaintextCFPRF(nil)
+var _ TypedEncoderPlaintext[*ring.Poly] = EncoderPlaintext(nil)
+var _ TypedEncoderPlaintext[*VerifPlaintext] = EncoderPlaintextCFPRF(nil)
+
+type typedEvaluator[Op, T any] struct {
+	OperandEvaluator[Op, T]
+}
+
+// NewTypedEvaluator restricts the operands of eval to its results, of type T, which must implement Op.
+func NewTypedEvaluator[Op, T any](eval OperandEvaluator[Op, T]) TypedEvaluator[T] {
+	var zero T
+	if _, ok := interface{}(zero).(Op); !ok {
+		panic(fmt.Errorf("%T does not implement the operands of the evaluator", zero))
+	}
+	return typedEvaluator[Op, T]{eval}
+}
+
+func (eval typedEvaluator[Op, T]) op(x T) Op {
+	return interface{}(x).(Op)
+}
+
+func (eval typedEvaluator[Op, T]) CopyNew(op T) T {
+	return cast[T](eval.OperandEvaluator.CopyNew(eval.op(op)))
+}
+
+func (eval typedEvaluator[Op, T]) Add(op0, op1 T, ctOut T) {
+	eval.OperandEvaluator.Add(eval.op(op0), eval.op(op1), ctOut)
+}
+
+func (eval typedEvaluator[Op, T]) AddNew(op0, op1 T) (ctOut T) {
+	return eval.OperandEvaluator.AddNew(eval.op(op0), eval.op(op1))
+}
+
+func (eval typedEvaluator[Op, T]) AddNoMod(op0, op1 T, ctOut T) {
+	eval.OperandEvaluator.AddNoMod(eval.op(op0), eval.op(op1), ctOut)
+}
+
+func (eval typedEvaluator[Op, T]) AddNoModNew(op0, op1 T) (ctOut T) {
+	return eval.OperandEvaluator.AddNoModNew(eval.op(op0), eval.op(op1))
+}
+
+func (eval typedEvaluator[Op, T]) Sub(op0, op1 T, ctOut T) {
+	eval.OperandEvaluator.Sub(eval.op(op0), eval.op(op1), ctOut)
+}
+
+func (eval typedEvaluator[Op, T]) SubNew(op0, op1 T) (ctOut T) {
+	return eval.OperandEvaluator.SubNew(eval.op(op0), eval.op(op1))
+}
+
+func (eval typedEvaluator[Op, T]) SubNoMod(op0, op1 T, ctOut T) {
+	eval.OperandEvaluator.SubNoMod(eval.op(op0), eval.op(op1), ctOut)
+}
+
+func (eval typedEvaluator[Op, T]) SubNoModNew(op0, op1 T) (ctOut T) {
+	return eval.OperandEvaluator.SubNoModNew(eval.op(op0), eval.op(op1))
+}
+
+func (eval typedEvaluator[Op, T]) Neg(op T, ctOut T) {
+	eval.OperandEvaluator.Neg(eval.op(op), ctOut)
+}
+
+func (eval typedEvaluator[Op, T]) NegNew(op T) (ctOut T) {
+	return eval.OperandEvaluator.NegNew(eval.op(op))
+}
+
+func (eval typedEvaluator[Op, T]) Reduce(op T, ctOut T) {
+	eval.OperandEvaluator.Reduce(eval.op(op), ctOut)
+}
+
+func (eval typedEvaluator[Op, T]) ReduceNew(op T) (ctOut T) {
+	return eval.OperandEvaluator.ReduceNew(eval.op(op))
+}
+
+func (eval typedEvaluator[Op, T]) MulScalar(op T, scalar uint64, ctOut T) {
+	eval.OperandEvaluator.MulScalar(eval.op(op), scalar, ctOut)
+}
+
+func (eval typedEvaluator[Op, T]) MulScalarNew(op T, scalar uint64) (ctOut T) {
+	return eval.OperandEvaluator.MulScalarNew(eval.op(op), scalar)
+}
+
+func (eval typedEvaluator[Op, T]) Mul(op0, op1 T, ctOut T) {
+	eval.OperandEvaluator.Mul(op0, eval.op(op1), ctOut)
+}
+
+func (eval typedEvaluator[Op, T]) MulNew(op0, op1 T) (ctOut T) {
+	return eval.OperandEvaluator.MulNew(op0, eval.op(op1))
+}
+
+// cast returns x as a T, and panics if it is not one, which is how the Generic adapters check their arguments. A nil x
+// is the zero value of an interface type T.
+func cast[T any](x interface{}) T {
+	if y, ok := x.(T); ok {
+		return y
+	}
+	var zero T
+	if x == nil && interface{}(zero) == nil {
+		return zero
+	}
+	panic(fmt.Errorf("expected %v, got %T", reflect.TypeOf((*T)(nil)).Elem(), x))
+}
+
+type genericEncoderOf[Pt, PtMul, V any] struct {
+	enc TypedEncoder[Pt, PtMul, V]
+}
+
+// NewGenericEncoderOf adapts a TypedEncoder to the GenericEncoder interface.
+func NewGenericEncoderOf[Pt, PtMul, V any](enc TypedEncoder[Pt, PtMul, V]) GenericEncoder {
+	return genericEncoderOf[Pt, PtMul, V]{enc}
+}
+
+func (enc genericEncoderOf[Pt, PtMul, V]) EncodeUint(coeffs []uint64, tags []Tag, pt interface{}) {
+	enc.enc.EncodeUint(coeffs, tags, cast[Pt](pt))
+}
+
+func (enc genericEncoderOf[Pt, PtMul, V]) EncodeUintNew(coeffs []uint64, tags []Tag) (pt interface{}) {
+	return enc.enc.EncodeUintNew(coeffs, tags)
+}
+
+func (enc genericEncoderOf[Pt, PtMul, V]) EncodeInt(coeffs []int64, tags []Tag, pt interface{}) {
+	enc.enc.EncodeInt(coeffs, tags, cast[Pt](pt))
+}
+
+func (enc genericEncoderOf[Pt, PtMul, V]) EncodeIntNew(coeffs []int64, tags []Tag) (pt interface{}) {
+	return enc.enc.EncodeIntNew(coeffs, tags)
+}
+
+func (enc genericEncoderOf[Pt, PtMul, V]) EncodeUintMul(coeffs []uint64, tags []Tag, pt interface{}) {
+	enc.enc.EncodeUintMul(coeffs, tags, cast[PtMul](pt))
+}
+
+func (enc genericEncoderOf[Pt, PtMul, V]) EncodeUintMulNew(coeffs []uint64, tags []Tag) (pt interface{}) {
+	return enc.enc.EncodeUintMulNew(coeffs, tags)
+}
+
+func (enc genericEncoderOf[Pt, PtMul, V]) EncodeIntMul(coeffs []int64, tags []Tag, pt interface{}) {
+	enc.enc.EncodeIntMul(coeffs, tags, cast[PtMul](pt))
+}
+
+func (enc genericEncoderOf[Pt, PtMul, V]) EncodeIntMulNew(coeffs []int64, tags []Tag) (pt interface{}) {
+	return enc.enc.EncodeIntMulNew(coeffs, tags)
+}
+
+func (enc genericEncoderOf[Pt, PtMul, V]) DecodeUint(pt interface{}, verif interface{}, coeffs []uint64) {
+	enc.enc.DecodeUint(cast[Pt](pt), cast[V](verif), coeffs)
+}
+
+func (enc genericEncoderOf[Pt, PtMul, V]) DecodeUintNew(pt interface{}, verif interface{}) (coeffs []uint64) {
+	return enc.enc.DecodeUintNew(cast[Pt](pt), cast[V](verif))
+}
+
+func (enc genericEncoderOf[Pt, PtMul, V]) DecodeInt(pt interface{}, verif interface{}, coeffs []int64) {
+	enc.enc.DecodeInt(cast[Pt](pt), cast[V](verif), coeffs)
+}
+
+func (enc genericEncoderOf[Pt, PtMul, V]) DecodeIntNew(pt interface{}, verif interface{}) (coeffs []int64) {
+	return enc.enc.DecodeIntNew(cast[Pt](pt), cast[V](verif))
+}
+
+type genericEncoderPlaintextOf[V any] struct {
+	enc TypedEncoderPlaintext[V]
+}
+
+// NewGenericEncoderPlaintextOf adapts a TypedEncoderPlaintext to the GenericEncoderPlaintext interface.
+func NewGenericEncoderPlaintextOf[V any](enc TypedEncoderPlaintext[V]) GenericEncoderPlaintext {
+	return genericEncoderPlaintextOf[V]{enc}
+}
+
+func (enc genericEncoderPlaintextOf[V]) Encode(tags []Tag, p interface{}) {
+	enc.enc.Encode(tags, cast[V](p))
+}
+
+func (enc genericEncoderPlaintextOf[V]) EncodeNew(tags []Tag) interface{} {
+	return enc.enc.EncodeNew(tags)
+}
+
+type genericEncryptorOf[Pt, Ct any] struct {
+	enc TypedEncryptor[Pt, Ct]
+}
+
+// NewGenericEncryptorOf adapts a TypedEncryptor to the GenericEncryptor interface.
+func NewGenericEncryptorOf[Pt, Ct any](enc TypedEncryptor[Pt, Ct]) GenericEncryptor {
+	return genericEncryptorOf[Pt, Ct]{enc}
+}
+
+func (enc genericEncryptorOf[Pt, Ct]) Encrypt(plaintext interface{}, ciphertext interface{}) {
+	enc.enc.Encrypt(cast[Pt](plaintext), cast[Ct](ciphertext))
+}
+
+func (enc genericEncryptorOf[Pt, Ct]) EncryptNew(plaintext interface{}) interface{} {
+	return enc.enc.EncryptNew(cast[Pt](plaintext))
+}
+
+type genericDecryptorOf[Ct, Pt any] struct {
+	dec TypedDecryptor[Ct, Pt]
+}
+
+// NewGenericDecryptorOf adapts a TypedDecryptor to the GenericDecryptor interface.
+func NewGenericDecryptorOf[Ct, Pt any](dec TypedDecryptor[Ct, Pt]) GenericDecryptor {
+	return genericDecryptorOf[Ct, Pt]{dec}
+}
+
+func (dec genericDecryptorOf[Ct, Pt]) Decrypt(ciphertext interface{}, plaintext interface{}) {
+	dec.dec.Decrypt(cast[Ct](ciphertext), cast[Pt](plaintext))
+}
+
+func (dec genericDecryptorOf[Ct, Pt]) DecryptNew(ciphertext interface{}) (plaintext interface{}) {
+	return dec.dec.DecryptNew(cast[Ct](ciphertext))
+}
+
+// KeySwitcher switches the keys of values of type T with switching keys of type Swk.
+type KeySwitcher[T, Swk any] interface {
+	SwitchKeys(ct0 T, switchKey Swk, ctOut T)
+	SwitchKeysNew(ct0 T, switchKey Swk) (ctOut T)
+}
+
+// SwitchingEvaluator is an OperandEvaluator that also switches keys, e.g., the evaluators of the encodings, whose
+// verification counterparts are SwitchingEvaluator[T, T, Swk].
+type SwitchingEvaluator[Op, T, Swk any] interface {
+	OperandEvaluator[Op, T]
+	KeySwitcher[T, Swk]
+}
+
+// CopyableEvaluator is a SwitchingEvaluator of type E whose copies share its evaluation keys of type Evk, e.g.,
+// Evaluator or vche_1.Evaluator.
+type CopyableEvaluator[Op, T, Swk, Evk, E any] interface {
+	SwitchingEvaluator[Op, T, Swk]
+	ShallowCopy() E
+	WithKey(evk Evk) E
+}
+
+// genericEvaluatorOf adapts an evaluator with operands of type Op and results of type T to GenericEvaluator. The
+// evaluators without key switching or copies leave switcher, shallowCopy or withKey nil.
+type genericEvaluatorOf[Op, T, Swk any] struct {
+	eval        OperandEvaluator[Op, T]
+	switcher    KeySwitcher[T, Swk]
+	shallowCopy func() GenericEvaluator
+	withKey     func(evk interface{}) GenericEvaluator
+}
+
+// NewGenericEvaluatorOf adapts a TypedEvaluator to the GenericEvaluator interface. As a TypedEvaluator has no key
+// switching, and no copies sharing its keys, the adapter panics on SwitchKeys, ShallowCopy and WithKey.
+func NewGenericEvaluatorOf[T any](eval TypedEvaluator[T]) GenericEvaluator {
+	return genericEvaluatorOf[T, T, interface{}]{eval: eval}
+}
+
+// NewGenericOperandEvaluatorOf adapts an evaluator of ciphertexts, whose operands may also be plaintexts, to the
+// GenericEvaluator interface. The copies of the adapter wrap the copies of eval.
+func NewGenericOperandEvaluatorOf[Op, T, Swk, Evk any, E CopyableEvaluator[Op, T, Swk, Evk, E]](eval E) GenericEvaluator {
+	return genericEvaluatorOf[Op, T, Swk]{
+		eval:     eval,
+		switcher: eval,
+		shallowCopy: func() GenericEvaluator {
+			return NewGenericOperandEvaluatorOf[Op, T, Swk, Evk](eval.ShallowCopy())
+		},
+		withKey: func(evk interface{}) GenericEvaluator {
+			return NewGenericOperandEvaluatorOf[Op, T, Swk, Evk](eval.WithKey(cast[Evk](evk)))
+		},
+	}
+}
+
+// NewGenericEvaluatorPlaintextOf adapts an evaluator of verification state to the GenericEvaluator interface. As the
+// verification state needs no evaluation keys, ShallowCopy and WithKey return an adapter of the same evaluator.
+func NewGenericEvaluatorPlaintextOf[T, Swk any](eval SwitchingEvaluator[T, T, Swk]) GenericEvaluator {
+	var res genericEvaluatorOf[T, T, Swk]
+	res = genericEvaluatorOf[T, T, Swk]{
+		eval:        eval,
+		switcher:    eval,
+		shallowCopy: func() GenericEvaluator { return res },
+		withKey:     func(interface{}) GenericEvaluator { return res },
+	}
+	return res
+}
+
+func (eval genericEvaluatorOf[Op, T, Swk]) CopyNew(op interface{}) interface{} {
+	return eval.eval.CopyNew(cast[Op](op))
+}
+
+func (eval genericEvaluatorOf[Op, T, Swk]) Add(op0, op1 interface{}, ctOut interface{}) {
+	eval.eval.Add(cast[Op](op0), cast[Op](op1), cast[T](ctOut))
+}
+
+func (eval genericEvaluatorOf[Op, T, Swk]) AddNew(op0, op1 interface{}) (ctOut interface{}) {
+	return eval.eval.AddNew(cast[Op](op0), cast[Op](op1))
+}
+
+func (eval genericEvaluatorOf[Op, T, Swk]) AddNoMod(op0, op1 interface{}, ctOut interface{}) {
+	eval.eval.AddNoMod(cast[Op](op0), cast[Op](op1), cast[T](ctOut))
+}
+
+func (eval genericEvaluatorOf[Op, T, Swk]) AddNoModNew(op0, op1 interface{}) (ctOut interface{}) {
+	return eval.eval.AddNoModNew(cast[Op](op0), cast[Op](op1))
+}
+
+func (eval genericEvaluatorOf[Op, T, Swk]) Sub(op0, op1 interface{}, ctOut interface{}) {
+	eval.eval.Sub(cast[Op](op0), cast[Op](op1), cast[T](ctOut))
+}
+
+func (eval genericEvaluatorOf[Op, T, Swk]) SubNew(op0, op1 interface{}) (ctOut interface{}) {
+	return eval.eval.SubNew(cast[Op](op0), cast[Op](op1))
+}
+
+func (eval genericEvaluatorOf[Op, T, Swk]) SubNoMod(op0, op1 interface{}, ctOut interface{}) {
+	eval.eval.SubNoMod(cast[Op](op0), cast[Op](op1), cast[T](ctOut))
+}
+
+func (eval genericEvaluatorOf[Op, T, Swk]) SubNoModNew(op0, op1 interface{}) (ctOut interface{}) {
+	return eval.eval.SubNoModNew(cast[Op](op0), cast[Op](op1))
+}
+
+func (eval genericEvaluatorOf[Op, T, Swk]) Neg(op interface{}, ctOut interface{}) {
+	eval.eval.Neg(cast[Op](op), cast[T](ctOut))
+}
+
+func (eval genericEvaluatorOf[Op, T, Swk]) NegNew(op interface{}) (ctOut interface{}) {
+	return eval.eval.NegNew(cast[Op](op))
+}
+
+func (eval genericEvaluatorOf[Op, T, Swk]) Reduce(op interface{}, ctOut interface{}) {
+	eval.eval.Reduce(cast[Op](op), cast[T](ctOut))
+}
+
+func (eval genericEvaluatorOf[Op, T, Swk]) ReduceNew(op interface{}) (ctOut interface{}) {
+	return eval.eval.ReduceNew(cast[Op](op))
+}
+
+func (eval genericEvaluatorOf[Op, T, Swk]) MulScalar(op interface{}, scalar uint64, ctOut interface{}) {
+	eval.eval.MulScalar(cast[Op](op), scalar, cast[T](ctOut))
+}
+
+func (eval genericEvaluatorOf[Op, T, Swk]) MulScalarNew(op interface{}, scalar uint64) (ctOut interface{}) {
+	return eval.eval.MulScalarNew(cast[Op](op), scalar)
+}
+
+func (eval genericEvaluatorOf[Op, T, Swk]) Mul(op0 interface{}, op1 interface{}, ctOut interface{}) {
+	eval.eval.Mul(cast[T](op0), cast[Op](op1), cast[T](ctOut))
+}
+
+func (eval genericEvaluatorOf[Op, T, Swk]) MulNew(op0 interface{}, op1 interface{}) (ctOut interface{}) {
+	return eval.eval.MulNew(cast[T](op0), cast[Op](op1))
+}
+
+func (eval genericEvaluatorOf[Op, T, Swk]) Relinearize(ct0 interface{}, ctOut interface{}) {
+	eval.eval.Relinearize(cast[T](ct0), cast[T](ctOut))
+}
+
+func (eval genericEvaluatorOf[Op, T, Swk]) RelinearizeNew(ct0 interface{}) (ctOut interface{}) {
+	return eval.eval.RelinearizeNew(cast[T](ct0))
+}
+
+func (eval genericEvaluatorOf[Op, T, Swk]) SwitchKeys(ct0 interface{}, switchKey interface{}, ctOut interface{}) {
+	eval.keySwitcher().SwitchKeys(cast[T](ct0), cast[Swk](switchKey), cast[T](ctOut))
+}
+
+func (eval genericEvaluatorOf[Op, T, Swk]) SwitchKeysNew(ct0 interface{}, switchKey interface{}) (ctOut interface{}) {
+	return eval.keySwitcher().SwitchKeysNew(cast[T](ct0), cast[Swk](switchKey))
+}
+
+func (eval genericEvaluatorOf[Op, T, Swk]) RotateColumns(ct0 interface{}, k int, ctOut interface{}) {
+	eval.eval.RotateColumns(cast[T](ct0), k, cast[T](ctOut))
+}
+
+func (eval genericEvaluatorOf[Op, T, Swk]) RotateColumnsNew(ct0 interface{}, k int) (ctOut interface{}) {
+	return eval.eval.RotateColumnsNew(cast[T](ct0), k)
+}
+
+func (eval genericEvaluatorOf[Op, T, Swk]) RotateRows(ct0 interface{}, ctOut interface{}) {
+	eval.eval.RotateRows(cast[T](ct0), cast[T](ctOut))
+}
+
+func (eval genericEvaluatorOf[Op, T, Swk]) RotateRowsNew(ct0 interface{}) (ctOut interface{}) {
+	return eval.eval.RotateRowsNew(cast[T](ct0))
+}
+
+func (eval genericEvaluatorOf[Op, T, Swk]) InnerSum(ct0 interface{}, ctOut interface{}) {
+	eval.eval.InnerSum(cast[T](ct0), cast[T](ctOut))
+}
+
+func (eval genericEvaluatorOf[Op, T, Swk]) keySwitcher() KeySwitcher[T, Swk] {
+	if eval.switcher == nil {
+		panic(fmt.Errorf("SwitchKeys is not supported by a TypedEvaluator"))
+	}
+	return eval.switcher
+}
+
+func (eval genericEvaluatorOf[Op, T, Swk]) ShallowCopy() GenericEvaluator {
+	if eval.shallowCopy == nil {
+		panic(fmt.Errorf("ShallowCopy is not supported by a TypedEvaluator"))
+	}
+	return eval.shallowCopy()
+}
+
+func (eval genericEvaluatorOf[Op, T, Swk]) WithKey(evk interface{}) GenericEvaluator {
+	if eval.withKey == nil {
+		panic(fmt.Errorf("WithKey is not supported by a TypedEvaluator"))
+	}
+	return eval.withKey(evk)
+}
+
+// EvalTyped evaluates the circuit with a TypedEvaluator, see Circuit.Eval.
+func EvalTyped[T any](c *Circuit, eval TypedEvaluator[T], inputs ...T) []T {
+	ins := make([]interface{}, len(inputs))
+	for i := range inputs {
+		ins[i] = inputs[i]
+	}
+	outs := c.Eval(NewGenericEvaluatorOf(eval), ins...)
+	res := make([]T, len(outs))
+	for i := range outs {
+		res[i] = cast[T](outs[i])
+	}
+	return res
+}
@@ -0,0 +1,282 @@
+package vche
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// NumChunks returns the number of chunks of at most nSlots values needed to hold length values.
+func NumChunks(length, nSlots int) int {
+	if length <= 0 {
+		panic(fmt.Errorf("length should be positive, was %d", length))
+	}
+	return (length + nSlots - 1) / nSlots
+}
+
+// chunkBounds returns the start and end indices of the c-th chunk of a vector of the given length.
+func chunkBounds(c, length, nSlots int) (int, int) {
+	start, end := c*nSlots, (c+1)*nSlots
+	if end > length {
+		end = length
+	}
+	return start, end
+}
+
+// GetChunkedIndexTags returns the tags of a vector of the given length split into chunks of at most nSlots values: the
+// i-th value of the vector is tagged with datasetTag and its global index i (as in GetIndexTags), so that the tags of
+// distinct chunks do not collide. The last chunk is shorter if nSlots does not divide the length.
+func GetChunkedIndexTags(datasetTag []byte, length, nSlots int) [][]Tag {
+	tags := make([][]Tag, NumChunks(length, nSlots))
+	for c := range tags {
+		start, end := chunkBounds(c, length, nSlots)
+		tags[c] = make([]Tag, end-start)
+		for i := range tags[c] {
+			indexTag := make([]byte, 8)
+			binary.BigEndian.PutUint64(indexTag, uint64(start+i))
+			tags[c][i] = Tag{datasetTag, indexTag}
+		}
+	}
+	return tags
+}
+
+// ChunkUint splits values into chunks of at most nSlots values.
+func ChunkUint(values []uint64, nSlots int) [][]uint64 {
+	chunks := make([][]uint64, NumChunks(len(values), nSlots))
+	for c := range chunks {
+		start, end := chunkBounds(c, len(values), nSlots)
+		chunks[c] = values[start:end]
+	}
+	return chunks
+}
+
+// ChunkInt splits values into chunks of at most nSlots values.
+func ChunkInt(values []int64, nSlots int) [][]int64 {
+	chunks := make([][]int64, NumChunks(len(values), nSlots))
+	for c := range chunks {
+		start, end := chunkBounds(c, len(values), nSlots)
+		chunks[c] = values[start:end]
+	}
+	return chunks
+}
+
+// Vector is a vector of arbitrary length, held in chunks of at most NSlots values each. The chunks are the plaintexts,
+// ciphertexts or verification state of an encoding, e.g., a Vector[*vche_1.Ciphertext] is an encrypted vector.
+type Vector[T any] struct {
+	Chunks []T
+	Len    int
+}
+
+// NumChunks returns the number of chunks of v.
+func (v *Vector[T]) NumChunks() int {
+	return len(v.Chunks)
+}
+
+// checkVectors panics if v0 and v1 do not have the same length and number of chunks, naming v1 as what in the error.
+func checkVectors[T, U any](v0 *Vector[T], v1 *Vector[U], what string) {
+	if v0.Len != v1.Len || len(v0.Chunks) != len(v1.Chunks) {
+		panic(fmt.Errorf("%s should have the same length as the first operand, got %d and %d (%d and %d chunks)", what, v1.Len, v0.Len, len(v1.Chunks), len(v0.Chunks)))
+	}
+}
+
+// MapVector returns the vector of the images of the chunks of v by f, e.g., to evaluate closed-form verification state.
+func MapVector[T, U any](v *Vector[T], f func(T) U) *Vector[U] {
+	res := &Vector[U]{make([]U, len(v.Chunks)), v.Len}
+	for c := range v.Chunks {
+		res.Chunks[c] = f(v.Chunks[c])
+	}
+	return res
+}
+
+// VectorEncoder encodes vectors of arbitrary length with index tags derived from a dataset tag (see
+// GetChunkedIndexTags) into plaintexts of type Pt, and decodes them with verification state of type V.
+type VectorEncoder[Pt, V any] interface {
+	EncodeUintNew(values []uint64, datasetTag []byte) (pt *Vector[Pt])
+	EncodeIntNew(values []int64, datasetTag []byte) (pt *Vector[Pt])
+	DecodeUintNew(pt *Vector[Pt], verif *Vector[V]) (values []uint64)
+	DecodeIntNew(pt *Vector[Pt], verif *Vector[V]) (values []int64)
+}
+
+type vectorEncoder[Pt, PtMul, V any] struct {
+	nSlots  int
+	encoder TypedEncoder[Pt, PtMul, V]
+}
+
+// NewVectorEncoder returns a VectorEncoder that encodes chunks of at most nSlots values with encoder.
+func NewVectorEncoder[Pt, PtMul, V any](nSlots int, encoder TypedEncoder[Pt, PtMul, V]) VectorEncoder[Pt, V] {
+	return &vectorEncoder[Pt, PtMul, V]{nSlots, encoder}
+}
+
+func (enc *vectorEncoder[Pt, PtMul, V]) EncodeUintNew(values []uint64, datasetTag []byte) (pt *Vector[Pt]) {
+	tags := GetChunkedIndexTags(datasetTag, len(values), enc.nSlots)
+	pt = &Vector[Pt]{make([]Pt, len(tags)), len(values)}
+	for c, chunk := range ChunkUint(values, enc.nSlots) {
+		pt.Chunks[c] = enc.encoder.EncodeUintNew(chunk, tags[c])
+	}
+	return pt
+}
+
+func (enc *vectorEncoder[Pt, PtMul, V]) EncodeIntNew(values []int64, datasetTag []byte) (pt *Vector[Pt]) {
+	tags := GetChunkedIndexTags(datasetTag, len(values), enc.nSlots)
+	pt = &Vector[Pt]{make([]Pt, len(tags)), len(values)}
+	for c, chunk := range ChunkInt(values, enc.nSlots) {
+		pt.Chunks[c] = enc.encoder.EncodeIntNew(chunk, tags[c])
+	}
+	return pt
+}
+
+func (enc *vectorEncoder[Pt, PtMul, V]) DecodeUintNew(pt *Vector[Pt], verif *Vector[V]) (values []uint64) {
+	checkVectors(pt, verif, "verification vector")
+	values = make([]uint64, pt.Len)
+	for c, chunk := range ChunkUint(values, enc.nSlots) {
+		enc.encoder.DecodeUint(pt.Chunks[c], verif.Chunks[c], chunk)
+	}
+	return values
+}
+
+func (enc *vectorEncoder[Pt, PtMul, V]) DecodeIntNew(pt *Vector[Pt], verif *Vector[V]) (values []int64) {
+	checkVectors(pt, verif, "verification vector")
+	values = make([]int64, pt.Len)
+	for c, chunk := range ChunkInt(values, enc.nSlots) {
+		enc.encoder.DecodeInt(pt.Chunks[c], verif.Chunks[c], chunk)
+	}
+	return values
+}
+
+// VectorEncoderPlaintext computes the verification state, of type V, of the vectors encoded by a VectorEncoder.
+type VectorEncoderPlaintext[V any] interface {
+	EncodeNew(datasetTag []byte, length int) (verif *Vector[V])
+}
+
+type vectorEncoderPlaintext[V any] struct {
+	nSlots  int
+	encoder TypedEncoderPlaintext[V]
+}
+
+// NewVectorEncoderPlaintext returns a VectorEncoderPlaintext that encodes chunks of at most nSlots tags with encoder.
+func NewVectorEncoderPlaintext[V any](nSlots int, encoder TypedEncoderPlaintext[V]) VectorEncoderPlaintext[V] {
+	return &vectorEncoderPlaintext[V]{nSlots, encoder}
+}
+
+func (enc *vectorEncoderPlaintext[V]) EncodeNew(datasetTag []byte, length int) (verif *Vector[V]) {
+	tags := GetChunkedIndexTags(datasetTag, length, enc.nSlots)
+	verif = &Vector[V]{make([]V, len(tags)), length}
+	for c := range tags {
+		verif.Chunks[c] = enc.encoder.EncodeNew(tags[c])
+	}
+	return verif
+}
+
+// EncryptVectorNew encrypts each chunk of pt.
+func EncryptVectorNew[Pt, Ct any](encryptor TypedEncryptor[Pt, Ct], pt *Vector[Pt]) *Vector[Ct] {
+	return MapVector(pt, encryptor.EncryptNew)
+}
+
+// DecryptVectorNew decrypts each chunk of ct.
+func DecryptVectorNew[Ct, Pt any](decryptor TypedDecryptor[Ct, Pt], ct *Vector[Ct]) *Vector[Pt] {
+	return MapVector(ct, decryptor.DecryptNew)
+}
+
+// VectorEvaluator evaluates operations chunk-wise on vectors of ciphertexts, or of their verification state. InnerSum
+// sums all the values of a vector into every slot of a single chunk.
+type VectorEvaluator[T any] interface {
+	Add(op0, op1 *Vector[T], ctOut *Vector[T])
+	AddNew(op0, op1 *Vector[T]) (ctOut *Vector[T])
+	Sub(op0, op1 *Vector[T], ctOut *Vector[T])
+	SubNew(op0, op1 *Vector[T]) (ctOut *Vector[T])
+	MulScalar(op *Vector[T], scalar uint64, ctOut *Vector[T])
+	MulScalarNew(op *Vector[T], scalar uint64) (ctOut *Vector[T])
+	Mul(op0, op1 *Vector[T], ctOut *Vector[T])
+	MulNew(op0, op1 *Vector[T]) (ctOut *Vector[T])
+	Relinearize(op *Vector[T], ctOut *Vector[T])
+	RelinearizeNew(op *Vector[T]) (ctOut *Vector[T])
+	InnerSum(op *Vector[T], ctOut T)
+	InnerSumNew(op *Vector[T]) (ctOut T)
+}
+
+type vectorEvaluator[T any] struct {
+	eval TypedEvaluator[T]
+}
+
+// NewVectorEvaluator returns a VectorEvaluator that evaluates each chunk with eval.
+func NewVectorEvaluator[T any](eval TypedEvaluator[T]) VectorEvaluator[T] {
+	return &vectorEvaluator[T]{eval}
+}
+
+func (eval *vectorEvaluator[T]) binOp(op0, op1, ctOut *Vector[T], op func(ct0, ct1, ctOut T)) {
+	checkVectors(op0, op1, "second operand")
+	if len(ctOut.Chunks) != len(op0.Chunks) {
+		panic(fmt.Errorf("output should have as many chunks as the operands, got %d and %d", len(ctOut.Chunks), len(op0.Chunks)))
+	}
+	for c := range op0.Chunks {
+		op(op0.Chunks[c], op1.Chunks[c], ctOut.Chunks[c])
+	}
+	ctOut.Len = op0.Len
+}
+
+func (eval *vectorEvaluator[T]) binOpNew(op0, op1 *Vector[T], op func(ct0, ct1 T) T) (ctOut *Vector[T]) {
+	checkVectors(op0, op1, "second operand")
+	ctOut = &Vector[T]{make([]T, len(op0.Chunks)), op0.Len}
+	for c := range op0.Chunks {
+		ctOut.Chunks[c] = op(op0.Chunks[c], op1.Chunks[c])
+	}
+	return ctOut
+}
+
+func (eval *vectorEvaluator[T]) Add(op0, op1 *Vector[T], ctOut *Vector[T]) {
+	eval.binOp(op0, op1, ctOut, eval.eval.Add)
+}
+
+func (eval *vectorEvaluator[T]) AddNew(op0, op1 *Vector[T]) (ctOut *Vector[T]) {
+	return eval.binOpNew(op0, op1, eval.eval.AddNew)
+}
+
+func (eval *vectorEvaluator[T]) Sub(op0, op1 *Vector[T], ctOut *Vector[T]) {
+	eval.binOp(op0, op1, ctOut, eval.eval.Sub)
+}
+
+func (eval *vectorEvaluator[T]) SubNew(op0, op1 *Vector[T]) (ctOut *Vector[T]) {
+	return eval.binOpNew(op0, op1, eval.eval.SubNew)
+}
+
+func (eval *vectorEvaluator[T]) MulScalar(op *Vector[T], scalar uint64, ctOut *Vector[T]) {
+	eval.binOp(op, op, ctOut, func(ct0, _, ctOut T) { eval.eval.MulScalar(ct0, scalar, ctOut) })
+}
+
+func (eval *vectorEvaluator[T]) MulScalarNew(op *Vector[T], scalar uint64) (ctOut *Vector[T]) {
+	return eval.binOpNew(op, op, func(ct0, _ T) T { return eval.eval.MulScalarNew(ct0, scalar) })
+}
+
+func (eval *vectorEvaluator[T]) Mul(op0, op1 *Vector[T], ctOut *Vector[T]) {
+	eval.binOp(op0, op1, ctOut, eval.eval.Mul)
+}
+
+func (eval *vectorEvaluator[T]) MulNew(op0, op1 *Vector[T]) (ctOut *Vector[T]) {
+	return eval.binOpNew(op0, op1, eval.eval.MulNew)
+}
+
+func (eval *vectorEvaluator[T]) Relinearize(op *Vector[T], ctOut *Vector[T]) {
+	eval.binOp(op, op, ctOut, func(ct0, _, ctOut T) { eval.eval.Relinearize(ct0, ctOut) })
+}
+
+func (eval *vectorEvaluator[T]) RelinearizeNew(op *Vector[T]) (ctOut *Vector[T]) {
+	return eval.binOpNew(op, op, func(ct0, _ T) T { return eval.eval.RelinearizeNew(ct0) })
+}
+
+// sumChunks returns the sum of the chunks of op.
+func (eval *vectorEvaluator[T]) sumChunks(op *Vector[T]) (acc T) {
+	acc = eval.eval.CopyNew(op.Chunks[0])
+	for c := 1; c < len(op.Chunks); c++ {
+		eval.eval.Add(acc, op.Chunks[c], acc)
+	}
+	return acc
+}
+
+func (eval *vectorEvaluator[T]) InnerSum(op *Vector[T], ctOut T) {
+	eval.eval.InnerSum(eval.sumChunks(op), ctOut)
+}
+
+func (eval *vectorEvaluator[T]) InnerSumNew(op *Vector[T]) (ctOut T) {
+	ctOut = eval.sumChunks(op)
+	eval.eval.InnerSum(ctOut, ctOut)
+	return ctOut
+}
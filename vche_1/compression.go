@@ -0,0 +1,74 @@
+package vche_1
+
+import (
+	"bytes"
+	"fmt"
+
+	"veritas/vche/vche"
+)
+
+// CompressedCiphertext is a result compressed by the server for transmission: its ciphertext is switched to the first
+// prime of Q, and its tags, which the verifier recomputes, are replaced by their digest.
+type CompressedCiphertext struct {
+	*vche.CompressedCiphertext
+	TagsDigest []byte
+}
+
+// Compress returns the compressed ciphertext of ct, whose noise is estimated by noise (see vche.CompressCiphertext). It
+// returns an error if the compressed ciphertext would not decrypt correctly.
+func Compress(params Parameters, ct *Ciphertext, noise float64) (*CompressedCiphertext, error) {
+	cc, err := vche.CompressCiphertext(params, ct.Ciphertext, noise)
+	if err != nil {
+		return nil, err
+	}
+	return &CompressedCiphertext{cc, vche.TagsDigest(ct.tags)}, nil
+}
+
+// Decompress checks that the digest of the compressed ciphertext matches the tags of verif, i.e., of the result
+// expected by the verifier, and returns the ciphertext with these tags, to be decrypted and decoded with verif. It
+// returns an error if cc is malformed, and panics with vche.ErrVerification if the digest does not match.
+func Decompress(params Parameters, cc *CompressedCiphertext, verif *TaggedPoly) (*Ciphertext, error) {
+	if !bytes.Equal(cc.TagsDigest, vche.TagsDigest(verif.tags)) {
+		panic(fmt.Errorf("%w due to mismatched tags digest", vche.ErrVerification))
+	}
+	ct, err := vche.DecompressCiphertext(params, cc.CompressedCiphertext)
+	if err != nil {
+		return nil, err
+	}
+	tags := make([][]byte, len(verif.tags))
+	copy(tags, verif.tags)
+	return &Ciphertext{ct, tags}, nil
+}
+
+// MarshalBinary encodes the compressed ciphertext, along with the digest of its tags, in a slice of bytes.
+func (cc *CompressedCiphertext) MarshalBinary() ([]byte, error) {
+	ct, err := cc.CompressedCiphertext.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return appendBytes(appendBytes(nil, ct), cc.TagsDigest), nil
+}
+
+// UnmarshalBinary decodes a slice of bytes generated by MarshalBinary on the compressed ciphertext.
+func (cc *CompressedCiphertext) UnmarshalBinary(data []byte) error {
+	ct, data, err := readBytes(data)
+	if err != nil {
+		return err
+	}
+	cc.CompressedCiphertext = new(vche.CompressedCiphertext)
+	if err = cc.CompressedCiphertext.UnmarshalBinary(ct); err != nil {
+		return err
+	}
+	if cc.TagsDigest, data, err = readBytes(data); err != nil {
+		return err
+	}
+	if len(data) != 0 {
+		return fmt.Errorf("%d trailing bytes", len(data))
+	}
+	return nil
+}
+
+// GetDataLen returns the length in bytes of the compressed ciphertext as encoded by MarshalBinary.
+func (cc *CompressedCiphertext) GetDataLen() int {
+	return 4 + cc.CompressedCiphertext.GetDataLen() + 4 + len(cc.TagsDigest)
+}
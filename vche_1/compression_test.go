@@ -0,0 +1,62 @@
+package vche_1
+
+import (
+	"testing"
+
+	"github.com/ldsec/lattigo/v2/bfv"
+	"github.com/stretchr/testify/require"
+	"veritas/vche/vche"
+)
+
+func testCompression(testctx *testContext, t *testing.T) {
+	t.Run(testString("Compression/", testctx.params), func(t *testing.T) {
+		in := newTestInputs(testctx)
+		params := testctx.params
+		want := make([]uint64, params.NSlots)
+		for i := range want {
+			want[i] = in.x[i] * in.y[i] % params.T()
+		}
+		res := testctx.evaluator.RelinearizeNew(testctx.evaluator.MulNew(in.ctX, in.ctY))
+		model := vche.NewNoiseModel(params)
+		noise := model.KeySwitch(model.Mul(model.Fresh(), model.Fresh(), 1))
+
+		eval := testctx.evaluatorPlaintext
+		verif := eval.RelinearizeNew(eval.MulNew(in.verifX, in.verifY))
+
+		// The server compresses the result, which is smaller once serialized
+		cc, err := Compress(params, res, noise)
+		require.NoError(t, err)
+		data, err := cc.MarshalBinary()
+		require.NoError(t, err)
+		require.Less(t, len(data), res.GetDataLen(true))
+		cc = new(CompressedCiphertext)
+		require.NoError(t, cc.UnmarshalBinary(data))
+		require.Equal(t, len(data), cc.GetDataLen())
+
+		ct, err := Decompress(params, cc, verif)
+		require.NoError(t, err)
+		require.Equal(t, want, testctx.encoder.DecodeUintNew(testctx.decryptor.DecryptNew(ct), verif))
+
+		// A malformed compressed ciphertext is an error, not a failed verification
+		truncated := &CompressedCiphertext{&vche.CompressedCiphertext{Value: [][]uint64{cc.Value[0], cc.Value[1][1:]}}, cc.TagsDigest}
+		_, err = Decompress(params, truncated, verif)
+		require.Error(t, err)
+
+		// The digest does not match the tags of another result
+		require.True(t, vche.Rejects(func() { Decompress(params, cc, in.verifX) }))
+		cc.TagsDigest[0] ^= 1
+		require.True(t, vche.Rejects(func() { Decompress(params, cc, verif) }))
+
+		// A result too noisy to decrypt once switched to q0 is not compressed
+		_, err = Compress(params, res, model.Capacity())
+		require.Error(t, err)
+
+		// Nor is any result if q0 is too small relative to T
+		literal := DefaultParams[0]
+		literal.T = 268460033
+		paramsLargeT, err := NewParametersFromLiteral(literal)
+		require.NoError(t, err)
+		_, err = vche.CompressCiphertext(paramsLargeT, bfv.NewCiphertext(paramsLargeT.Parameters, 1), model.Fresh())
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,120 @@
+package vche_1
+
+import (
+	"fmt"
+
+	"github.com/ldsec/lattigo/v2/bfv"
+	"github.com/ldsec/lattigo/v2/rlwe"
+	"veritas/vche/vche"
+)
+
+// Contribution is an input of a contributor without the PRF key and the dummy set: its values, replicated in all the
+// slots, encrypted with the public key of the key holder. The contributor sends it to the key holder, whose Issuer
+// encodes it.
+type Contribution struct {
+	Value *bfv.Ciphertext
+}
+
+// Issuer encodes the contributions of the contributors, and is run by the holder of the verification secrets. It
+// zeroes the dummy slots of a contribution with a plaintext multiplication by the selector of the replicated slots,
+// and adds a fresh encryption of the PRF values of the dummy slots of the tags. Neither the selector nor the PRF values
+// are ever shared encrypted, so the server cannot shift the values of a result without shifting its dummies. The tags
+// of a contribution should only be issued once.
+//
+// Each contribution costs a round trip between its contributor and the key holder, which must be online to issue it.
+// The plaintext multiplication multiplies the noise of the contribution by up to N*T/2, see vche.NoiseModel.Issued,
+// which a vche.NoiseEstimator cannot tell from a fresh ciphertext: set it with SetNoise on the issued ciphertexts, and
+// plan the parameters with planner.Requirements.Contributions.
+type Issuer interface {
+	IssueNew(contribution *Contribution, tags []vche.Tag) *Ciphertext
+}
+
+type issuer struct {
+	params    Parameters
+	enc       *encoder
+	encryptor bfv.Encryptor
+	eval      bfv.Evaluator
+	selector  *bfv.PlaintextMul
+}
+
+// NewIssuer returns the issuer of the holder of sk, whose encodings use closed-form PRFs if useClosedFormPRF is set.
+func NewIssuer(params Parameters, sk *SecretKey, useClosedFormPRF bool) Issuer {
+	enc := NewEncoder(params, sk.K, sk.S, useClosedFormPRF).(*encoder)
+	sel := make([]uint64, params.N())
+	for i := 0; i < params.NSlots; i++ {
+		for j := 0; j < params.NumReplications; j++ {
+			if !sk.S[j] {
+				sel[i*params.NumReplications+j] = 1
+			}
+		}
+	}
+	selector := bfv.NewPlaintextMul(params.Parameters)
+	enc.Encoder.EncodeUintMul(sel, selector)
+	return &issuer{params, enc, bfv.NewEncryptor(params.Parameters, sk.SecretKey), bfv.NewEvaluator(params.Parameters, rlwe.EvaluationKey{}), selector}
+}
+
+func (iss *issuer) IssueNew(contribution *Contribution, tags []vche.Tag) *Ciphertext {
+	if contribution.Value.Degree() != 1 {
+		panic(fmt.Errorf("contribution should have degree 1, got %d", contribution.Value.Degree()))
+	}
+	// The encoding of zeros holds the PRF values in the dummy slots only
+	pt := iss.enc.EncodeUintNew(make([]uint64, len(tags)), tags)
+	ct := iss.eval.MulNew(contribution.Value, iss.selector)
+	iss.eval.Add(ct, iss.encryptor.EncryptNew(pt.Plaintext), ct)
+	return &Ciphertext{ct, pt.tags}
+}
+
+// Contributor encrypts the values of a contributor with the public key of the key holder.
+type Contributor interface {
+	EncryptUintNew(coeffs []uint64) *Contribution
+	EncryptIntNew(coeffs []int64) *Contribution
+}
+
+type contributor struct {
+	params Parameters
+	bfv.Encoder
+	encryptor bfv.Encryptor
+}
+
+// NewContributor returns the contributor that encrypts with the public key pk of the key holder.
+func NewContributor(params Parameters, pk *PublicKey) Contributor {
+	return &contributor{params, bfv.NewEncoder(params.Parameters), bfv.NewEncryptor(params.Parameters, pk.PublicKey)}
+}
+
+func (c *contributor) checkLength(lenCoeffs int) {
+	if lenCoeffs == 0 || lenCoeffs > c.params.NSlots {
+		panic(fmt.Errorf("coeffs should have between 1 and %d values, got %d", c.params.NSlots, lenCoeffs))
+	}
+}
+
+// encrypt returns the encryption of coeffs in all the replications of their slot.
+func (c *contributor) encrypt(coeffs []uint64) *Contribution {
+	replicated := make([]uint64, c.params.N())
+	for i := range coeffs {
+		for j := 0; j < c.params.NumReplications; j++ {
+			replicated[i*c.params.NumReplications+j] = coeffs[i]
+		}
+	}
+	pt := bfv.NewPlaintext(c.params.Parameters)
+	c.Encoder.EncodeUint(replicated, pt)
+	return &Contribution{c.encryptor.EncryptNew(pt)}
+}
+
+func (c *contributor) EncryptUintNew(coeffs []uint64) *Contribution {
+	c.checkLength(len(coeffs))
+	return c.encrypt(coeffs)
+}
+
+func (c *contributor) EncryptIntNew(coeffs []int64) *Contribution {
+	c.checkLength(len(coeffs))
+	T := c.params.T()
+	values := make([]uint64, len(coeffs))
+	for i, v := range coeffs {
+		if v < 0 {
+			values[i] = (T - uint64(-v)%T) % T
+		} else {
+			values[i] = uint64(v) % T
+		}
+	}
+	return c.encrypt(values)
+}
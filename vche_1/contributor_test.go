@@ -0,0 +1,52 @@
+package vche_1
+
+import (
+	"testing"
+
+	"github.com/ldsec/lattigo/v2/bfv"
+	"github.com/ldsec/lattigo/v2/rlwe"
+	"github.com/stretchr/testify/require"
+	"veritas/vche/vche"
+)
+
+func testContributor(testctx *testContext, t *testing.T) {
+	t.Run(testString("Contributor/", testctx.params), func(t *testing.T) {
+		in := newTestInputs(testctx)
+		params := testctx.params
+		issuer := NewIssuer(params, testctx.sk, false)
+		contributor := NewContributor(params, testctx.kgen.GenPublicKey(testctx.sk))
+		want := make([]uint64, params.NSlots)
+		for i := range want {
+			want[i] = in.x[i] * in.y[i] % params.T()
+		}
+
+		// The contributor encrypts x without the verification secrets, the key holder encodes it and encrypts y
+		contribution := contributor.EncryptUintNew(in.x)
+		ctX := issuer.IssueNew(contribution, in.tagsX)
+		res := testctx.evaluator.RelinearizeNew(testctx.evaluator.MulNew(ctX, in.ctY))
+		require.LessOrEqual(t, vche.MeasureNoise(params.Parameters, testctx.sk.SecretKey, ctX.Ciphertext), vche.NewNoiseModel(params).Issued())
+
+		eval := testctx.evaluatorPlaintext
+		verif := eval.RelinearizeNew(eval.MulNew(in.verifX, in.verifY))
+		require.Equal(t, want, testctx.encoder.DecodeUintNew(testctx.decryptor.DecryptNew(res), verif))
+
+		// Signed values are encoded as by the encoder
+		xs := []int64{-3, 0, 5}
+		ct := issuer.IssueNew(contributor.EncryptIntNew(xs), in.tagsX[:3])
+		require.Equal(t, xs, testctx.encoder.DecodeIntNew(testctx.decryptor.DecryptNew(ct), testctx.evaluatorPlaintextEncoder.EncodeNew(in.tagsX[:3]))[:3])
+
+		// A contribution issued for other tags does not verify
+		ct = issuer.IssueNew(contribution, in.tagsY)
+		require.True(t, vche.Rejects(func() { testctx.encoder.DecodeUintNew(testctx.decryptor.DecryptNew(ct), in.verifX) }))
+
+		// The server cannot shift the result with the ciphertexts of the contributor: the contribution shifts the dummy
+		// slots too, and its difference with its encoding shifts the dummy slots only
+		bfvEval := bfv.NewEvaluator(params.Parameters, rlwe.EvaluationKey{})
+		dummies := bfvEval.SubNew(ctX.Ciphertext, contribution.Value)
+		for _, shift := range []*bfv.Ciphertext{contribution.Value, dummies} {
+			shifted := res.CopyNew()
+			bfvEval.Add(shifted.Ciphertext, shift, shifted.Ciphertext)
+			require.True(t, vche.Rejects(func() { testctx.encoder.DecodeUintNew(testctx.decryptor.DecryptNew(shifted), verif) }))
+		}
+	})
+}
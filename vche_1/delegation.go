@@ -0,0 +1,125 @@
+package vche_1
+
+import (
+	"fmt"
+
+	"github.com/ldsec/lattigo/v2/bfv"
+	"github.com/ldsec/lattigo/v2/ring"
+	"github.com/ldsec/lattigo/v2/rlwe"
+	"github.com/ldsec/lattigo/v2/utils"
+)
+
+// VerificationToken lets a recipient verify a result delegated to it without the PRF key of the owner. It holds the
+// tags of the result, the dummy set, and the values expected in the dummy slots of the result, zero in the other slots.
+//
+// The delegator blinds the dummy slots of each delegated result with fresh random values, so the expected values of a
+// token are uniform and reveal nothing of the PRF values of the owner. The dummy set cannot be blinded: it is also
+// revealed by the decryption of the result, whose dummy slots differ from the replicated values. It is the long-term
+// verification secret of the owner, so the recipient must be trusted with it: a recipient that shares it with the
+// server lets the server forge the results of the owner.
+type VerificationToken struct {
+	Tags    [][]byte
+	S       DummySet
+	Dummies []uint64
+}
+
+// Delegator re-keys verified results to a recipient.
+type Delegator interface {
+	// Delegate switches the result to the key of the recipient, and returns it with the token that verifies it, given
+	// the verification state of the result computed with EvaluatorPlaintext.
+	Delegate(ct *Ciphertext, verif *TaggedPoly) (*Ciphertext, *VerificationToken)
+}
+
+type delegator struct {
+	params   Parameters
+	S        DummySet
+	swk      *SwitchingKey
+	eval     Evaluator
+	evalPtxt EvaluatorPlaintext
+	encoder  bfv.Encoder
+	bfvEval  bfv.Evaluator
+	sampler  *ring.UniformSampler
+}
+
+// NewDelegator returns the delegator of the owner of sk, with the switching key to the recipient.
+func NewDelegator(params Parameters, sk *SecretKey, swk *SwitchingKey) Delegator {
+	prng, err := utils.NewPRNG()
+	if err != nil {
+		panic(err)
+	}
+	return &delegator{
+		params:   params,
+		S:        sk.S,
+		swk:      swk,
+		eval:     NewEvaluator(params, &EvaluationKey{H: swk.H}),
+		evalPtxt: NewEvaluatorPlaintext(params, swk.H),
+		encoder:  bfv.NewEncoder(params.Parameters),
+		bfvEval:  bfv.NewEvaluator(params.Parameters, rlwe.EvaluationKey{}),
+		sampler:  ring.NewUniformSampler(prng, params.RingT()),
+	}
+}
+
+func (d *delegator) Delegate(ct *Ciphertext, verif *TaggedPoly) (*Ciphertext, *VerificationToken) {
+	res := d.eval.SwitchKeysNew(ct, d.swk)
+	resVerif := d.evalPtxt.SwitchKeysNew(verif, d.swk)
+
+	token := &VerificationToken{Tags: make([][]byte, len(resVerif.tags)), S: make(DummySet), Dummies: make([]uint64, d.params.N())}
+	copy(token.Tags, resVerif.tags)
+	for j := range d.S {
+		token.S[j] = d.S[j]
+	}
+	// Add a fresh mask to the dummy slots of the result, and expect the masked values
+	random := d.sampler.ReadNew()
+	mask := make([]uint64, d.params.N())
+	for i := 0; i < d.params.NSlots; i++ {
+		for j := 0; j < d.params.NumReplications; j++ {
+			if d.S[j] {
+				idx := i*d.params.NumReplications + j
+				mask[idx] = random.Coeffs[0][idx]
+				token.Dummies[idx] = (resVerif.Coeffs[0][idx] + mask[idx]) % d.params.T()
+			}
+		}
+	}
+	pt := bfv.NewPlaintext(d.params.Parameters)
+	d.encoder.EncodeUint(mask, pt)
+	d.bfvEval.Add(res.Ciphertext, pt, res.Ciphertext)
+	return res, token
+}
+
+// Recipient decrypts and verifies the results delegated to it.
+type Recipient interface {
+	DecodeUintNew(ct *Ciphertext, token *VerificationToken) []uint64
+	DecodeIntNew(ct *Ciphertext, token *VerificationToken) []int64
+}
+
+type recipient struct {
+	params Parameters
+	bfv.Decryptor
+	bfv.Encoder
+}
+
+// NewRecipient returns the recipient with the given secret key, to which the delegator switches the results.
+func NewRecipient(params Parameters, sk *rlwe.SecretKey) Recipient {
+	return &recipient{params, bfv.NewDecryptor(params.Parameters, sk), bfv.NewEncoder(params.Parameters)}
+}
+
+// open decrypts ct and returns the encoder and verification state that verify it against the token.
+func (r *recipient) open(ct *Ciphertext, token *VerificationToken) (*encoder, *Plaintext, *TaggedPoly) {
+	if len(token.Dummies) != r.params.N() {
+		panic(fmt.Errorf("token should have %d dummy values, got %d", r.params.N(), len(token.Dummies)))
+	}
+	pt := &Plaintext{r.Decryptor.DecryptNew(ct.Ciphertext), ct.tags}
+	verif := &TaggedPoly{r.params.RingT().NewPoly(), token.Tags}
+	copy(verif.Coeffs[0], token.Dummies)
+	return &encoder{Encoder: r.Encoder, params: r.params, S: token.S}, pt, verif
+}
+
+func (r *recipient) DecodeUintNew(ct *Ciphertext, token *VerificationToken) []uint64 {
+	enc, pt, verif := r.open(ct, token)
+	return enc.DecodeUintNew(pt, verif)
+}
+
+func (r *recipient) DecodeIntNew(ct *Ciphertext, token *VerificationToken) []int64 {
+	enc, pt, verif := r.open(ct, token)
+	return enc.DecodeIntNew(pt, verif)
+}
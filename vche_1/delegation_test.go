@@ -0,0 +1,62 @@
+package vche_1
+
+import (
+	"testing"
+
+	"github.com/ldsec/lattigo/v2/bfv"
+	"github.com/ldsec/lattigo/v2/rlwe"
+	"github.com/stretchr/testify/require"
+	"veritas/vche/vche"
+)
+
+func testDelegation(testctx *testContext, t *testing.T) {
+	t.Run(testString("Delegation/", testctx.params), func(t *testing.T) {
+		in := newTestInputs(testctx)
+		params := testctx.params
+		want := make([]uint64, params.NSlots)
+		for i := range want {
+			want[i] = (in.x[i]*in.y[i] + in.x[i]) % params.T()
+		}
+
+		// The server computes x*y + x, and the owner its verification state
+		res := testctx.evaluator.AddNew(testctx.evaluator.RelinearizeNew(testctx.evaluator.MulNew(in.ctX, in.ctY)), in.ctX)
+		eval := testctx.evaluatorPlaintext
+		verif := eval.AddNew(eval.RelinearizeNew(eval.MulNew(in.verifX, in.verifY)), in.verifX)
+
+		// The recipient has its own key, and does not share the dummy set of the owner
+		recipientSk := bfv.NewKeyGenerator(params.Parameters).GenSecretKey()
+		delegator := NewDelegator(params, testctx.sk, testctx.kgen.GenRecipientSwitchingKey(testctx.sk, recipientSk))
+		recipient := NewRecipient(params, recipientSk)
+
+		delegated, token := delegator.Delegate(res, verif)
+		require.Equal(t, want, recipient.DecodeUintNew(delegated, token))
+
+		// Each delegation is blinded with a fresh mask, so the tokens do not reveal the PRF values of the owner, and the
+		// token of one delegation does not verify another delegation of the same result
+		again, againToken := delegator.Delegate(res, verif)
+		require.Equal(t, want, recipient.DecodeUintNew(again, againToken))
+		require.NotEqual(t, token.Dummies, againToken.Dummies)
+		unblinded := make([]uint64, params.N())
+		for idx := range unblinded {
+			if token.Dummies[idx] != 0 {
+				unblinded[idx] = verif.Coeffs[0][idx]
+			}
+		}
+		require.NotEqual(t, unblinded, token.Dummies)
+		require.True(t, vche.Rejects(func() { recipient.DecodeUintNew(again, token) }))
+
+		// The token of another result does not verify it
+		_, otherToken := delegator.Delegate(in.ctX, in.verifX)
+		require.True(t, vche.Rejects(func() { recipient.DecodeUintNew(delegated, otherToken) }))
+
+		// Nor does the token verify a modified result
+		ones := make([]uint64, params.N())
+		for i := range ones {
+			ones[i] = 1
+		}
+		pt := bfv.NewPlaintext(params.Parameters)
+		bfv.NewEncoder(params.Parameters).EncodeUint(ones, pt)
+		bfv.NewEvaluator(params.Parameters, rlwe.EvaluationKey{}).Add(delegated.Ciphertext, pt, delegated.Ciphertext)
+		require.True(t, vche.Rejects(func() { recipient.DecodeUintNew(delegated, token) }))
+	})
+}
@@ -5,8 +5,8 @@ import (
 	"encoding/binary"
 	"fmt"
 	"github.com/ldsec/lattigo/v2/bfv"
+	"github.com/ldsec/lattigo/v2/utils"
 	"veritas/vche/vche"
-	"golang.org/x/crypto/blake2b"
 	"log"
 )
 
@@ -34,11 +34,21 @@ type encoder struct {
 	K                vche.PRFKey
 	S                DummySet
 	useClosedFormPRF bool
-	xof1, xof2       blake2b.XOF
+	xof1, xof2       vche.XOF
+	datasets         vche.Datasets
 }
 
 func NewEncoder(params Parameters, K vche.PRFKey, S DummySet, useClosedFormPRF bool) Encoder {
-	return &encoder{bfv.NewEncoder(params.Parameters), params, K, S, useClosedFormPRF, vche.NewXOF(K.K1), vche.NewXOF(K.K2)}
+	return &encoder{bfv.NewEncoder(params.Parameters), params, K, S, useClosedFormPRF, params.NewXOF(K.K1), params.NewXOF(K.K2), nil}
+}
+
+// NewOwnerEncoder returns the encoder of a data owner with PRF key K, in a computation over the inputs of several
+// owners that share the dummy set S of the decryptor and encrypt with its public key. It only encodes the values whose
+// tags are labelled with one of the datasets of the owner, and leaves the other slots empty, so that the server adds
+// the ciphertexts of the owners into a joint input. The decryptor verifies it with the contributions of
+// NewOwnerEncoderPlaintext. See vche.Datasets for the trust the decryptor puts in the owners.
+func NewOwnerEncoder(params Parameters, K vche.PRFKey, S DummySet, datasets vche.Datasets, useClosedFormPRF bool) Encoder {
+	return &encoder{bfv.NewEncoder(params.Parameters), params, K, S, useClosedFormPRF, params.NewXOF(K.K1), params.NewXOF(K.K2), datasets}
 }
 
 func (enc *encoder) checkLengths(cs interface{}, tags []vche.Tag) {
@@ -58,17 +68,21 @@ func (enc *encoder) checkLengths(cs interface{}, tags []vche.Tag) {
 	if lenCoeffs > NSlots {
 		panic(fmt.Errorf("coeffs cannot be longer than N / lambda = %d / %d = %d, was %d", N, lambda, NSlots, lenCoeffs))
 	}
-	if lenCoeffs < NSlots {
-		panic(fmt.Errorf("coeffs should have length %d, was %d", NSlots, lenCoeffs))
+	if lenCoeffs == 0 {
+		panic(fmt.Errorf("coeffs should not be empty"))
 	}
 }
 
 func (enc *encoder) encodeUintCoeffs(coeffs []uint64, tags []vche.Tag) []uint64 {
 	enc.checkLengths(coeffs, tags)
+	coeffs, tags = vche.PadUint(coeffs, enc.params.NSlots), vche.PadTags(tags, enc.params.NSlots)
 
 	// Set plaintext slots to duplicated coeffs or dummy values
 	internalCoeffs := make([]uint64, enc.params.N())
 	for i := 0; i < len(coeffs); i++ {
+		if !enc.datasets.Owns(tags[i]) {
+			continue
+		}
 		for j := 0; j < enc.params.NumReplications; j++ {
 			idx := i*enc.params.NumReplications + j
 			if enc.S[j] {
@@ -83,10 +97,14 @@ func (enc *encoder) encodeUintCoeffs(coeffs []uint64, tags []vche.Tag) []uint64
 
 func (enc *encoder) encodeIntCoeffs(coeffs []int64, tags []vche.Tag) []int64 {
 	enc.checkLengths(coeffs, tags)
+	coeffs, tags = vche.PadInt(coeffs, enc.params.NSlots), vche.PadTags(tags, enc.params.NSlots)
 
 	// Set plaintext slots to duplicated coeffs or dummy values
 	internalCoeffs := make([]int64, enc.params.N())
 	for i := 0; i < len(coeffs); i++ {
+		if !enc.datasets.Owns(tags[i]) {
+			continue
+		}
 		for j := 0; j < enc.params.NumReplications; j++ {
 			idx := i*enc.params.NumReplications + j
 			if enc.S[j] {
@@ -100,10 +118,15 @@ func (enc *encoder) encodeIntCoeffs(coeffs []int64, tags []vche.Tag) []int64 {
 }
 
 func (enc *encoder) encodeTags(tags []vche.Tag) [][]byte {
+	tags = vche.PadTags(tags, enc.params.NSlots)
+
 	// Set randomness used from the tags for each message
 	vs := make([][]byte, len(tags))
 	for i, tI := range tags {
 		vs[i] = make([]byte, 8)
+		if !enc.datasets.Owns(tI) {
+			continue
+		}
 		binary.BigEndian.PutUint64(vs[i], enc.PRF(tI))
 	}
 	return vs
@@ -156,11 +179,11 @@ func (enc *encoder) EncodeIntMulNew(coeffs []int64, tags []vche.Tag) (pt *Plaint
 func (enc *encoder) verifyUint(pt *Plaintext, verifPtxt *TaggedPoly) []uint64 {
 	// Check that recomputed tags match
 	if len(pt.tags) != len(verifPtxt.tags) {
-		panic(fmt.Errorf("verification failed due to mismatched tags (different lengths)"))
+		panic(fmt.Errorf("%w due to mismatched tags (different lengths)", vche.ErrVerification))
 	}
 	for i := range pt.tags {
 		if pt.tags[i] == nil || verifPtxt.tags[i] == nil || !bytes.Equal(pt.tags[i], verifPtxt.tags[i]) {
-			panic(fmt.Errorf("verification failed due to mismatched tags"))
+			panic(fmt.Errorf("%w due to mismatched tags", vche.ErrVerification))
 		}
 	}
 
@@ -176,14 +199,14 @@ func (enc *encoder) verifyUint(pt *Plaintext, verifPtxt *TaggedPoly) []uint64 {
 			if enc.S[j] {
 				idx := i*enc.params.NumReplications + j
 				if ms[idx] != dummies[idx] {
-					panic(fmt.Errorf("verification failed due to mismatch in %d (i=%d, j=%d)-th evaluated dummies: got %d, expected %d\n", idx, i, j, dummies[idx], ms[idx]))
+					panic(fmt.Errorf("%w due to mismatch in %d (i=%d, j=%d)-th evaluated dummies: got %d, expected %d\n", vche.ErrVerification, idx, i, j, dummies[idx], ms[idx]))
 				}
 			} else { // Verify that duplicated messages evaluate to the same value
 				idx := i*enc.params.NumReplications + j
 				if expected == nil {
 					expected = &ms[idx]
 				} else if ms[idx] != *expected {
-					panic(fmt.Errorf("verification failed due to mismatch between duplicated values"))
+					panic(fmt.Errorf("%w due to mismatch between duplicated values", vche.ErrVerification))
 				}
 			}
 		}
@@ -201,10 +224,12 @@ func (enc *encoder) verifyInt(pt *Plaintext, verifPtxt *TaggedPoly) []int64 {
 	return enc.Encoder.DecodeIntNew(cp)
 }
 
+// DecodeUint verifies pt against verifPtxt and decodes its values into coeffs. If coeffs is shorter than NSlots, only
+// the first len(coeffs) values are decoded, e.g., when a shorter vector was encoded; the verification covers all slots.
 func (enc *encoder) DecodeUint(pt *Plaintext, verifPtxt *TaggedPoly, coeffs []uint64) {
 	ms := enc.verifyUint(pt, verifPtxt)
 
-	for i := 0; i < enc.params.NSlots; i++ {
+	for i := 0; i < utils.MinInt(len(coeffs), enc.params.NSlots); i++ {
 		for j := 0; j < enc.params.NumReplications; j++ {
 			if !enc.S[j] {
 				idx := i*enc.params.NumReplications + j
@@ -224,7 +249,7 @@ func (enc *encoder) DecodeUintNew(pt *Plaintext, verifPtxt *TaggedPoly) []uint64
 func (enc *encoder) DecodeInt(pt *Plaintext, verifPtxt *TaggedPoly, coeffs []int64) {
 	ms := enc.verifyInt(pt, verifPtxt)
 
-	for i := 0; i < enc.params.NSlots; i++ {
+	for i := 0; i < utils.MinInt(len(coeffs), enc.params.NSlots); i++ {
 		for j := 0; j < enc.params.NumReplications; j++ {
 			if !enc.S[j] {
 				idx := i*enc.params.NumReplications + j
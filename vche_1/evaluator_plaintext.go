@@ -5,7 +5,6 @@ import (
 	"github.com/ldsec/lattigo/v2/ring"
 	"veritas/vche/vche"
 	"github.com/stretchr/testify/require"
-	"golang.org/x/crypto/blake2b"
 	"hash"
 	"math/big"
 )
@@ -254,16 +253,26 @@ type EncoderPlaintext interface {
 }
 
 type encoderPlaintext struct {
-	params Parameters
-	K      vche.PRFKey
-	xof1   blake2b.XOF
+	params   Parameters
+	K        vche.PRFKey
+	xof1     vche.XOF
+	datasets vche.Datasets
 }
 
 func NewEncoderPlaintext(parameters Parameters, K vche.PRFKey) EncoderPlaintext {
-	return encoderPlaintext{parameters, K, vche.NewXOF(K.K1)}
+	return encoderPlaintext{parameters, K, parameters.NewXOF(K.K1), nil}
+}
+
+// NewOwnerEncoderPlaintext returns the encoder of the contribution of a data owner with PRF key K to the verification
+// state of an input of several owners, which only covers the slots of its datasets. The decryptor adds the
+// contributions of the owners with EvaluatorPlaintext, as the server adds their ciphertexts.
+func NewOwnerEncoderPlaintext(parameters Parameters, K vche.PRFKey, datasets vche.Datasets) EncoderPlaintext {
+	return encoderPlaintext{parameters, K, parameters.NewXOF(K.K1), datasets}
 }
 
 func (enc encoderPlaintext) Encode(tags []vche.Tag, p *TaggedPoly) {
+	tags = vche.PadTags(tags, enc.params.NSlots)
+
 	N := enc.params.N()
 	lambda := enc.params.NumReplications
 	NSlots := enc.params.NSlots
@@ -271,6 +280,9 @@ func (enc encoderPlaintext) Encode(tags []vche.Tag, p *TaggedPoly) {
 	// Set plaintext slots to duplicated messages or dummy values
 	coeffs := make([]uint64, N)
 	for i := 0; i < NSlots; i++ {
+		if !enc.datasets.Owns(tags[i]) {
+			continue
+		}
 		for j := 0; j < lambda; j++ {
 			idx := i*lambda + j
 			coeffs[idx] = enc.PRF(tags[i], uint64(j))
@@ -282,6 +294,9 @@ func (enc encoderPlaintext) Encode(tags []vche.Tag, p *TaggedPoly) {
 	vs := make([][]byte, len(tags))
 	for i, tI := range tags {
 		vs[i] = make([]byte, 8)
+		if !enc.datasets.Owns(tI) {
+			continue
+		}
 		binary.BigEndian.PutUint64(vs[i], enc.PRF(tI))
 	}
 	p.tags = vs
@@ -6,7 +6,6 @@ import (
 	"github.com/ldsec/lattigo/v2/ring"
 	"veritas/vche/vche"
 	"github.com/stretchr/testify/require"
-	"golang.org/x/crypto/blake2b"
 	"hash"
 	"math/big"
 )
@@ -258,14 +257,16 @@ type encoderPlaintextCFPRF struct {
 	K          vche.PRFKey
 	u          *ring.Poly
 	v          *ring.Poly
-	xof1, xof2 blake2b.XOF
+	xof1, xof2 vche.XOF
 }
 
 func NewEncoderPlaintextCFPRF(parameters Parameters, K vche.PRFKey) EncoderPlaintextCFPRF {
-	return encoderPlaintextCFPRF{parameters, K, nil, nil, vche.NewXOF(K.K1), vche.NewXOF(K.K2)}
+	return encoderPlaintextCFPRF{parameters, K, nil, nil, parameters.NewXOF(K.K1), parameters.NewXOF(K.K2)}
 }
 
 func (enc encoderPlaintextCFPRF) Encode(tags []vche.Tag, p *VerifPlaintext) {
+	tags = vche.PadTags(tags, enc.params.NSlots)
+
 	var a uint64
 	var b uint64
 
@@ -0,0 +1,153 @@
+package vche_1
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/ldsec/lattigo/v2/bfv"
+	"github.com/stretchr/testify/require"
+	"veritas/vche/vche"
+)
+
+// Kinds of the mutations of FuzzDecodeUint
+const (
+	mutateSlot    = iota // adds Delta to a slot of the plaintext
+	mutateCoeff          // adds Delta to a coefficient of the plaintext in the RNS representation
+	mutateVerif          // adds Delta to a coefficient of the verification object
+	mutateTag            // flips bits of a tag of the plaintext
+	truncateTags         // drops the tags of the plaintext from Index on
+	clearVerifTag        // erases a tag of the verification object
+	numMutationKinds
+)
+
+func FuzzDecodeUint(f *testing.F) {
+	params, err := NewParametersFromLiteral(DefaultParams[0])
+	require.NoError(f, err)
+	sk := NewKeyGenerator(params).GenSecretKeyFromKDF(vche.NewKDF([]byte("FuzzDecodeUint/seed"), "vche_1"))
+	encoder := NewEncoder(params, sk.K, sk.S, false)
+	encoderPlaintext := NewEncoderPlaintext(params, sk.K)
+	bfvEncoder := bfv.NewEncoder(params.Parameters)
+	ringQ := params.RingQ()
+	tags := vche.GetIndexTags([]byte("FuzzDecodeUint"), params.NSlots)
+
+	f.Add([]byte("values"), []byte{})
+	for kind := 0; kind < numMutationKinds; kind++ {
+		f.Add([]byte{}, vche.Mutation{Kind: byte(kind), Index: 1, Delta: 1}.Bytes())
+	}
+	f.Add([]byte{1, 2, 3}, append(vche.Mutation{Kind: mutateSlot, Delta: params.T()}.Bytes(), vche.Mutation{Kind: mutateCoeff, Index: 7, Delta: 3}.Bytes()...))
+
+	f.Fuzz(func(t *testing.T, values, mutations []byte) {
+		coeffs := make([]uint64, params.NSlots)
+		for i := range coeffs {
+			if len(values) > 0 {
+				coeffs[i] = binary.BigEndian.Uint64(append(values[i%len(values):], make([]byte, 8)...)) % params.T()
+			}
+		}
+		pt := encoder.EncodeUintNew(coeffs, tags)
+		verif := encoderPlaintext.EncodeNew(tags)
+
+		for _, m := range vche.ParseMutations(mutations) {
+			switch int(m.Kind) % numMutationKinds {
+			case mutateSlot:
+				// Decode on a copy, as BFV changes the plaintext during decoding
+				cp := bfv.NewPlaintext(params.Parameters)
+				cp.Plaintext.Copy(pt.Plaintext.Plaintext)
+				slots := bfvEncoder.DecodeUintNew(cp)
+				slots[int(m.Index)%len(slots)] = (slots[int(m.Index)%len(slots)] + m.Delta%params.T()) % params.T()
+				bfvEncoder.EncodeUint(slots, pt.Plaintext)
+			case mutateCoeff:
+				level := int(m.Kind) / numMutationKinds % len(pt.Value.Coeffs)
+				q := ringQ.Modulus[level]
+				c := &pt.Value.Coeffs[level][int(m.Index)%ringQ.N]
+				*c = (*c + m.Delta%q) % q
+			case mutateVerif:
+				c := &verif.Coeffs[0][int(m.Index)%len(verif.Coeffs[0])]
+				*c = (*c + m.Delta%params.T()) % params.T()
+			case mutateTag:
+				if len(pt.tags) == 0 {
+					continue
+				}
+				tag := append([]byte{}, pt.tags[int(m.Index)%len(pt.tags)]...)
+				tag[int(m.Delta%uint64(len(tag)))] ^= byte(m.Delta>>8) | 1
+				pt.tags[int(m.Index)%len(pt.tags)] = tag
+			case truncateTags:
+				pt.tags = pt.tags[:int(m.Index)%(len(pt.tags)+1)]
+			case clearVerifTag:
+				verif.tags[int(m.Index)%len(verif.tags)] = nil
+			}
+		}
+
+		var res []uint64
+		if vche.Rejects(func() { res = encoder.DecodeUintNew(pt, verif) }) {
+			return
+		}
+		require.Equal(t, coeffs, res, "accepted an incorrect result")
+	})
+}
+
+func FuzzUnmarshalCiphertext(f *testing.F) {
+	ct, _, _ := fuzzEncodings(f)
+	addEncodingSeeds(f, ct)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		require.NoError(t, vche.CheckRoundTrip(data, func(b []byte) (*Ciphertext, error) {
+			ct := new(Ciphertext)
+			return ct, ct.UnmarshalBinary(b)
+		}))
+	})
+}
+
+func FuzzUnmarshalTaggedPoly(f *testing.F) {
+	_, verif, _ := fuzzEncodings(f)
+	addEncodingSeeds(f, verif)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		require.NoError(t, vche.CheckRoundTrip(data, func(b []byte) (*TaggedPoly, error) {
+			verif := new(TaggedPoly)
+			return verif, verif.UnmarshalBinary(b)
+		}))
+	})
+}
+
+func FuzzUnmarshalCompressedCiphertext(f *testing.F) {
+	_, _, cc := fuzzEncodings(f)
+	addEncodingSeeds(f, cc)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		require.NoError(t, vche.CheckRoundTrip(data, func(b []byte) (*CompressedCiphertext, error) {
+			cc := new(CompressedCiphertext)
+			return cc, cc.UnmarshalBinary(b)
+		}))
+	})
+}
+
+// fuzzEncodings returns the encodings of a ciphertext, of its verification object and of its compressed ciphertext, to
+// seed the fuzz tests of the unmarshalers.
+func fuzzEncodings(f *testing.F) (ct, verif, cc []byte) {
+	params, err := NewParametersFromLiteral(DefaultParams[0])
+	require.NoError(f, err)
+	sk := NewKeyGenerator(params).GenSecretKeyFromKDF(vche.NewKDF([]byte("FuzzUnmarshal/seed"), "vche_1"))
+	tags := vche.GetIndexTags([]byte("FuzzUnmarshal"), params.NSlots)
+	ctxt := NewEncryptor(params, sk).EncryptNew(NewEncoder(params, sk.K, sk.S, false).EncodeUintNew(vche.GetRandomCoeffs(params.NSlots, params.T()), tags))
+	compressed, err := Compress(params, ctxt, vche.NewNoiseModel(params).Fresh())
+	require.NoError(f, err)
+
+	ct, err = ctxt.MarshalBinary()
+	require.NoError(f, err)
+	verif, err = NewEncoderPlaintext(params, sk.K).EncodeNew(tags).MarshalBinary()
+	require.NoError(f, err)
+	cc, err = compressed.MarshalBinary()
+	require.NoError(f, err)
+	return ct, verif, cc
+}
+
+// addEncodingSeeds adds a valid encoding to the seed corpus, along with its truncations and a copy whose first length
+// prefix is the largest possible.
+func addEncodingSeeds(f *testing.F, data []byte) {
+	f.Add(data)
+	f.Add(data[:len(data)-1])
+	f.Add(data[:len(data)/2])
+	f.Add([]byte{})
+	huge := append([]byte{0xff, 0xff, 0xff, 0xff}, data[4:]...)
+	f.Add(huge)
+}
@@ -1,6 +1,7 @@
 package vche_1
 
 import (
+	"fmt"
 	"github.com/ldsec/lattigo/v2/bfv"
 	"github.com/ldsec/lattigo/v2/ring"
 	"github.com/ldsec/lattigo/v2/rlwe"
@@ -8,14 +9,18 @@ import (
 	"veritas/vche/vche"
 	"golang.org/x/crypto/blake2b"
 	"hash"
+	"math/bits"
 )
 
 type KeyGenerator interface {
 	GenSecretKey() (sk *SecretKey)
+	GenSecretKeyFromKDF(kdf vche.KDF) (sk *SecretKey)
 	GenPublicKey(sk *SecretKey) (pk *PublicKey)
 	GenKeyPair() (sk *SecretKey, pk *PublicKey)
 	GenRelinearizationKey(sk *SecretKey, maxDegree int) (evk *RelinearizationKey)
 	GenSwitchingKey(skInput, skOutput *SecretKey) (newevakey *SwitchingKey)
+	GenRecipientSwitchingKey(sk *SecretKey, recipient *rlwe.SecretKey) *SwitchingKey
+	GenVerificationKey() (sk *SecretKey)
 	GenRotationKeys(galEls []uint64, sk *SecretKey) (rks *RotationKeySet)
 	GenRotationKeysForRotations(ks []int, inclueSwapRows bool, sk *SecretKey) (rks *RotationKeySet)
 	GenRotationKeysForInnerSum(sk *SecretKey) (rks *RotationKeySet)
@@ -40,7 +45,26 @@ func (keygen *keyGenerator) GenSecretKey() (sk *SecretKey) {
 	return &SecretKey{SecretKey: keygen.KeyGenerator.GenSecretKey(),
 		H: keygen.H,
 		K: vche.NewPRFKey(8),
-		S: NewDummySet(keygen.params.NumReplications),
+		S: NewDummySetOfSize(keygen.params.NumReplications, keygen.params.NumDummies),
+	}
+}
+
+// GenSecretKeyFromKDF deterministically derives the RLWE secret key, the PRF key and the dummy set from kdf.
+func (keygen *keyGenerator) GenSecretKeyFromKDF(kdf vche.KDF) (sk *SecretKey) {
+	return &SecretKey{SecretKey: vche.GenSecretKeyFromPRNG(keygen.params.Parameters.Parameters, kdf.PRNG(vche.LabelSecretKey)),
+		H: keygen.H,
+		K: kdf.PRFKey(vche.LabelPRFKey, 8),
+		S: NewDummySetFromPRNG(keygen.params.NumReplications, keygen.params.NumDummies, kdf.PRNG(vche.LabelDummySet)),
+	}
+}
+
+// GenVerificationKey generates the verification secrets of an auditor, i.e., the PRF key and the dummy set, without
+// RLWE secret key, for the parties of vche.Protocols that share the RLWE secret key, see NewCollectiveKeys. The
+// verification secrets are not secret-shared: the auditor holds them alone.
+func (keygen *keyGenerator) GenVerificationKey() (sk *SecretKey) {
+	return &SecretKey{H: keygen.H,
+		K: vche.NewPRFKey(8),
+		S: NewDummySetOfSize(keygen.params.NumReplications, keygen.params.NumDummies),
 	}
 }
 
@@ -63,6 +87,12 @@ func (keygen *keyGenerator) GenSwitchingKey(skInput, skOutput *SecretKey) (newev
 	}
 }
 
+// GenRecipientSwitchingKey generates the key that switches the ciphertexts of sk to the key of a recipient, which does
+// not need the dummy set of sk, see NewDelegator.
+func (keygen *keyGenerator) GenRecipientSwitchingKey(sk *SecretKey, recipient *rlwe.SecretKey) *SwitchingKey {
+	return &SwitchingKey{keygen.KeyGenerator.GenSwitchingKey(sk.SecretKey, recipient), keygen.H}
+}
+
 func (keygen *keyGenerator) GenRotationKeys(galEls []uint64, sk *SecretKey) (rks *RotationKeySet) {
 	return &RotationKeySet{keygen.KeyGenerator.GenRotationKeys(galEls, sk.SecretKey), keygen.H}
 }
@@ -83,25 +113,38 @@ func (keygen *keyGenerator) GenRotationKeysForInnerSum(sk *SecretKey) (rks *Rota
 	return &RotationKeySet{keygen.KeyGenerator.GenRotationKeysForInnerSum(sk.SecretKey), sk.H}
 }
 
+// NewDummySet samples a dummy set of lambda/2 dummies among lambda replications.
 func NewDummySet(lambda int) DummySet {
+	return NewDummySetOfSize(lambda, lambda/2)
+}
+
+// NewDummySetOfSize samples a dummy set of numDummies dummies among lambda replications.
+func NewDummySetOfSize(lambda, numDummies int) DummySet {
 	prng, err := utils.NewPRNG()
 	if err != nil {
 		panic(err)
 	}
+	return NewDummySetFromPRNG(lambda, numDummies, prng)
+}
+
+// NewDummySetFromPRNG samples a dummy set of numDummies dummies among lambda replications, uniformly at random among
+// all such sets, using the randomness of prng.
+func NewDummySetFromPRNG(lambda, numDummies int, prng utils.PRNG) DummySet {
+	if numDummies < 0 || numDummies > lambda {
+		panic(fmt.Errorf("number of dummies should be between 0 and %d, was %d", lambda, numDummies))
+	}
 
-	for {
-		countDummies := 0
-		S := make(DummySet)
-
-		for i := 0; i < lambda; i++ {
-			b := ring.RandUniform(prng, 2, 1)
-			if b == 1 {
-				countDummies += 1
-				S[i] = true
-			}
-		}
-		if countDummies == lambda/2 {
-			return S
-		}
+	// Partial Fisher-Yates shuffle of the replication indices
+	perm := make([]int, lambda)
+	for i := range perm {
+		perm[i] = i
+	}
+	S := make(DummySet)
+	for i := 0; i < numDummies; i++ {
+		n := uint64(lambda - i)
+		j := i + int(ring.RandUniform(prng, n, uint64(1<<uint64(bits.Len64(n)))-1))
+		perm[i], perm[j] = perm[j], perm[i]
+		S[perm[i]] = true
 	}
+	return S
 }
@@ -0,0 +1,124 @@
+package vche_1
+
+import (
+	"encoding/binary"
+	"fmt"
+	"veritas/vche/vche"
+)
+
+// MarshalBinary encodes the ciphertext, along with its tags, in a slice of bytes.
+func (ciphertext *Ciphertext) MarshalBinary() ([]byte, error) {
+	ct, err := ciphertext.Ciphertext.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return appendTags(appendBytes(nil, ct), ciphertext.tags), nil
+}
+
+// UnmarshalBinary decodes a slice of bytes generated by MarshalBinary on the ciphertext.
+func (ciphertext *Ciphertext) UnmarshalBinary(data []byte) error {
+	ct, data, err := readBytes(data)
+	if err != nil {
+		return err
+	}
+	if ciphertext.Ciphertext, err = vche.UnmarshalCiphertext(ct); err != nil {
+		return err
+	}
+	ciphertext.tags, err = readTags(data)
+	return err
+}
+
+// GetDataLen returns the length in bytes of the ciphertext as encoded by MarshalBinary.
+func (ciphertext *Ciphertext) GetDataLen(WithMetaData bool) int {
+	return 4 + ciphertext.Ciphertext.GetDataLen(WithMetaData) + tagsLen(ciphertext.tags)
+}
+
+// MarshalBinary encodes the verification object, along with its tags, in a slice of bytes.
+func (poly *TaggedPoly) MarshalBinary() ([]byte, error) {
+	p, err := poly.Poly.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return appendTags(appendBytes(nil, p), poly.tags), nil
+}
+
+// UnmarshalBinary decodes a slice of bytes generated by MarshalBinary on the verification object.
+func (poly *TaggedPoly) UnmarshalBinary(data []byte) error {
+	p, data, err := readBytes(data)
+	if err != nil {
+		return err
+	}
+	if poly.Poly, err = vche.UnmarshalPoly(p); err != nil {
+		return err
+	}
+	poly.tags, err = readTags(data)
+	return err
+}
+
+// GetDataLen returns the length in bytes of the verification object as encoded by MarshalBinary.
+func (poly *TaggedPoly) GetDataLen(WithMetaData bool) int {
+	return 4 + poly.Poly.GetDataLen(WithMetaData) + tagsLen(poly.tags)
+}
+
+// GetDataLen returns the length in bytes of the relinearization and rotation keys of the evaluation key. The hash
+// function is public and not counted.
+func (evk *EvaluationKey) GetDataLen(WithMetaData bool) int {
+	return vche.Size(&evk.EvaluationKey)
+}
+
+func appendUint32(data []byte, x uint32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, x)
+	return append(data, b...)
+}
+
+func appendBytes(data, b []byte) []byte {
+	return append(appendUint32(data, uint32(len(b))), b...)
+}
+
+func readBytes(data []byte) ([]byte, []byte, error) {
+	if len(data) < 4 {
+		return nil, nil, fmt.Errorf("missing length prefix")
+	}
+	n := int(binary.BigEndian.Uint32(data))
+	if len(data)-4 < n {
+		return nil, nil, fmt.Errorf("expected %d bytes, got %d", n, len(data)-4)
+	}
+	return data[4 : 4+n], data[4+n:], nil
+}
+
+// appendTags appends the number of tags, then each length-prefixed tag.
+func appendTags(data []byte, tags [][]byte) []byte {
+	data = appendUint32(data, uint32(len(tags)))
+	for _, tag := range tags {
+		data = appendBytes(data, tag)
+	}
+	return data
+}
+
+// readTags decodes tags encoded by appendTags, which must span the rest of data.
+func readTags(data []byte) ([][]byte, error) {
+	// Each tag takes at least its length prefix
+	numTags, data, err := vche.ReadCount(data, 4, "tags")
+	if err != nil {
+		return nil, err
+	}
+	tags := make([][]byte, numTags)
+	for i := range tags {
+		if tags[i], data, err = readBytes(data); err != nil {
+			return nil, err
+		}
+	}
+	if len(data) != 0 {
+		return nil, fmt.Errorf("%d trailing bytes", len(data))
+	}
+	return tags, nil
+}
+
+func tagsLen(tags [][]byte) int {
+	dataLen := 4
+	for _, tag := range tags {
+		dataLen += 4 + len(tag)
+	}
+	return dataLen
+}
@@ -0,0 +1,39 @@
+package vche_1
+
+import (
+	"hash"
+
+	"github.com/ldsec/lattigo/v2/rlwe"
+	"veritas/vche/vche"
+)
+
+// NewCollectiveKeys returns the public key and the evaluation key made of the collective keys that the aggregator of
+// vche.Protocols combines from the shares of the parties, which share the RLWE secret key. The rotation keys may be
+// nil. H is the hash of the verification key of the auditor, see GenVerificationKey.
+func NewCollectiveKeys(H hash.Hash, pk *rlwe.PublicKey, rlk *rlwe.RelinearizationKey, rtks *rlwe.RotationKeySet) (*PublicKey, *EvaluationKey) {
+	return &PublicKey{pk}, &EvaluationKey{rlwe.EvaluationKey{Rlk: rlk, Rtks: rtks}, H}
+}
+
+type collectiveDecryptor struct {
+	params    Parameters
+	protocols *vche.Protocols
+	shares    vche.DecryptionShares
+}
+
+// NewCollectiveDecryptor returns the decryptor with which the aggregator of the protocols decrypts ciphertexts from the
+// decryption shares of the parties. The auditor then verifies the plaintexts with the Encoder of its verification key.
+func NewCollectiveDecryptor(params Parameters, protocols *vche.Protocols, shares vche.DecryptionShares) Decryptor {
+	return &collectiveDecryptor{params, protocols, shares}
+}
+
+func (dec *collectiveDecryptor) Decrypt(ciphertext *Ciphertext, plaintext *Plaintext) {
+	plaintext.Plaintext.Copy(dec.protocols.Decrypt(ciphertext.Ciphertext, dec.shares(ciphertext.Ciphertext)...).Plaintext)
+	plaintext.tags = make([][]byte, len(ciphertext.tags))
+	copy(plaintext.tags, ciphertext.tags)
+}
+
+func (dec *collectiveDecryptor) DecryptNew(ciphertext *Ciphertext) (plaintext *Plaintext) {
+	plaintext = NewPlaintext(dec.params)
+	dec.Decrypt(ciphertext, plaintext)
+	return plaintext
+}
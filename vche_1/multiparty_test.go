@@ -0,0 +1,63 @@
+package vche_1
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"veritas/vche/vche"
+)
+
+func testMultiparty(testctx *testContext, t *testing.T) {
+	t.Run(testString("Multiparty/", testctx.params), func(t *testing.T) {
+		in := newTestInputs(testctx)
+		params := testctx.params
+
+		// Three parties share the RLWE secret key and send their shares to an aggregator, and an auditor holds the
+		// verification key
+		protocols := vche.NewProtocols(params, []byte("multiparty test crs"), vche.DefaultSigmaSmudging(params, 3))
+		parties := []*vche.Party{vche.NewParty(protocols), vche.NewParty(protocols), vche.NewParty(protocols)}
+		vk := NewKeyGenerator(params).GenVerificationKey()
+		require.Nil(t, vk.SecretKey)
+		cpk, rlk, rtks := vche.GenCollectiveKeys(params, protocols, parties, []uint64{params.GaloisElementForColumnRotationBy(params.NumReplications)})
+		pk, evk := NewCollectiveKeys(vk.H, cpk, rlk, rtks)
+
+		encoder := NewEncoder(params, vk.K, vk.S, false)
+		encoderPtxt := NewEncoderPlaintext(params, vk.K)
+		encryptor := NewEncryptor(params, pk)
+		decryptor := NewCollectiveDecryptor(params, protocols, vche.GetDecryptionShares(parties))
+
+		x, y := in.x, in.y
+		half := params.NSlots / 2
+		want := make([]uint64, params.NSlots)
+		for i := range want {
+			// The columns are rotated by one slot within each row
+			r := i/half*half + (i%half+1)%half
+			want[i] = (x[r]*y[r] + x[r]) % params.T()
+		}
+
+		// The server computes rot(x*y + x, 1) with the collective keys
+		eval := NewEvaluator(params, evk)
+		ctX, ctY := encryptor.EncryptNew(encoder.EncodeUintNew(x, in.tagsX)), encryptor.EncryptNew(encoder.EncodeUintNew(y, in.tagsY))
+		res := eval.RotateColumnsNew(eval.AddNew(eval.RelinearizeNew(eval.MulNew(ctX, ctY)), ctX), 1)
+
+		evalPtxt := NewEvaluatorPlaintext(params, vk.H)
+		verifX, verifY := encoderPtxt.EncodeNew(in.tagsX), encoderPtxt.EncodeNew(in.tagsY)
+		verif := evalPtxt.RotateColumnsNew(evalPtxt.AddNew(evalPtxt.RelinearizeNew(evalPtxt.MulNew(verifX, verifY)), verifX), 1)
+
+		// The parties decrypt the result collaboratively, and the auditor verifies it
+		pt := decryptor.DecryptNew(res)
+		require.Equal(t, want, encoder.DecodeUintNew(pt, verif))
+		require.True(t, vche.Rejects(func() { encoder.DecodeUintNew(decryptor.DecryptNew(ctX), verif) }))
+
+		// The smudging noise must be larger than the fresh noise, fit an int64, and fit in the capacity of the decrypted
+		// ciphertexts
+		require.Panics(t, func() { vche.NewProtocols(params, nil, 1) })
+		require.Panics(t, func() { vche.NewProtocols(params, nil, 1<<60) })
+		// The smudging noise of the three parties, 6*sigma*3, reaches the capacity for the small parameters only
+		if loudSigma := math.Exp2(vche.NewNoiseModel(params).CapacityAtLevel(res.Level()) - math.Log2(18)); loudSigma <= 1<<59 {
+			loud := vche.NewProtocols(params, nil, loudSigma)
+			require.Panics(t, func() { NewCollectiveDecryptor(params, loud, vche.GetDecryptionShares(parties)).DecryptNew(res) })
+		}
+	})
+}
@@ -0,0 +1,11 @@
+package vche_1
+
+import (
+	"veritas/vche/vche"
+)
+
+// NoiseBudget returns the number of bits of noise that ct can still absorb before decryption fails, as measured with
+// sk. A negative budget means that decoding ct fails because of the noise, and not because the server cheated.
+func NoiseBudget(params Parameters, sk *SecretKey, ct *Ciphertext) float64 {
+	return vche.NoiseBudget(params, sk.SecretKey, ct.Ciphertext)
+}
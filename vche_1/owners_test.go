@@ -0,0 +1,56 @@
+package vche_1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"veritas/vche/vche"
+)
+
+func testMultiOwner(testctx *testContext, t *testing.T) {
+	t.Run(testString("MultiOwner/", testctx.params), func(t *testing.T) {
+		in := newTestInputs(testctx)
+		params := testctx.params
+
+		// Two owners with their own PRF keys, sharing the dummy set of the decryptor, and encrypting with its public key
+		labelA, labelB := []byte("hospital-a"), []byte("hospital-b")
+		KA, KB := testctx.sk.K, vche.NewPRFKey(len(testctx.sk.K.K1))
+		datasetsA, datasetsB := vche.NewDatasets(labelA), vche.NewDatasets(labelB)
+		encA, encB := NewOwnerEncoder(params, KA, testctx.sk.S, datasetsA, false), NewOwnerEncoder(params, KB, testctx.sk.S, datasetsB, false)
+		contribA, contribB := NewOwnerEncoderPlaintext(params, KA, datasetsA), NewOwnerEncoderPlaintext(params, KB, datasetsB)
+
+		encryptor := NewEncryptor(params, testctx.kgen.GenPublicKey(testctx.sk))
+
+		x := in.x
+		tags := vche.GetLabelledTags(in.tagsX, labelA, labelB)
+		want := make([]uint64, params.NSlots)
+		for i := range want {
+			want[i] = x[i] * x[i] % params.T()
+		}
+
+		// The server adds the inputs of the owners, and squares the joint input
+		eval := testctx.evaluator
+		ct := eval.AddNew(encryptor.EncryptNew(encA.EncodeUintNew(x, tags)), encryptor.EncryptNew(encB.EncodeUintNew(x, tags)))
+		res := eval.RelinearizeNew(eval.MulNew(ct, ct))
+
+		// The decryptor replays the computation on the contributions of the owners
+		evalPtxt := testctx.evaluatorPlaintext
+		verifFrom := func(contribA, contribB EncoderPlaintext) *TaggedPoly {
+			verif := evalPtxt.AddNew(contribA.EncodeNew(tags), contribB.EncodeNew(tags))
+			return evalPtxt.RelinearizeNew(evalPtxt.MulNew(verif, verif))
+		}
+		pt := testctx.decryptor.DecryptNew(res)
+		require.Equal(t, want, testctx.encoder.DecodeUintNew(pt, verifFrom(contribA, contribB)))
+
+		// A contribution for the datasets of another owner does not verify
+		require.True(t, vche.Rejects(func() {
+			testctx.encoder.DecodeUintNew(pt, verifFrom(contribA, NewOwnerEncoderPlaintext(params, KA, datasetsB)))
+		}))
+
+		// Nor does a result that omits the input of an owner
+		res = eval.RelinearizeNew(eval.MulNew(ct, encryptor.EncryptNew(encA.EncodeUintNew(x, tags))))
+		require.True(t, vche.Rejects(func() {
+			testctx.encoder.DecodeUintNew(testctx.decryptor.DecryptNew(res), verifFrom(contribA, contribB))
+		}))
+	})
+}
@@ -0,0 +1,61 @@
+package vche_1
+
+import (
+	"fmt"
+	"github.com/ldsec/lattigo/v2/bfv"
+	"math"
+)
+
+// log2Binomial returns log2(binom(n, k)).
+func log2Binomial(n, k int) float64 {
+	lgN, _ := math.Lgamma(float64(n + 1))
+	lgK, _ := math.Lgamma(float64(k + 1))
+	lgNK, _ := math.Lgamma(float64(n - k + 1))
+	return (lgN - lgK - lgNK) / math.Ln2
+}
+
+// SoundnessBits returns -log2 of the cheating probability for lambda replications, numDummies of which are dummies.
+func SoundnessBits(lambda, numDummies int) float64 {
+	if numDummies < 0 || numDummies > lambda {
+		panic(fmt.Errorf("number of dummies should be between 0 and %d, was %d", lambda, numDummies))
+	}
+	return log2Binomial(lambda, numDummies)
+}
+
+// CheatingProbability returns the probability that a server deviating from the computation passes the verification, for
+// lambda replications, numDummies of which are dummies. The dummies are checked against the re-computed PRF values and
+// all other replications must agree, so a server that alters some replications is caught unless it alters exactly the
+// non-dummy ones, i.e., unless it guesses the dummy set. This happens with probability 1 / binom(lambda, numDummies).
+// Forging the PRF outputs of the dummies is assumed to happen only with negligible probability.
+func CheatingProbability(lambda, numDummies int) float64 {
+	return math.Exp2(-SoundnessBits(lambda, numDummies))
+}
+
+// ReplicationsForSoundness returns the smallest power-of-two number of replications, and for it the smallest number of
+// dummies, such that the cheating probability is at most 2^-soundnessBits. Among the dummy sets of a given size, the
+// one with lambda/2 dummies is the hardest to guess, but fewer dummies are cheaper to encode and to verify.
+func ReplicationsForSoundness(soundnessBits float64) (numReplications, numDummies int) {
+	if soundnessBits <= 0 {
+		return 1, 0
+	}
+	numReplications = 2
+	for SoundnessBits(numReplications, numReplications/2) < soundnessBits {
+		numReplications <<= 1
+	}
+	numDummies = 1
+	for SoundnessBits(numReplications, numDummies) < soundnessBits {
+		numDummies++
+	}
+	return numReplications, numDummies
+}
+
+// ParametersLiteralForSoundness returns the parameters for the given BFV parameters, with the number of replications and
+// of dummies chosen by ReplicationsForSoundness. The resulting slot capacity is N / NumReplications.
+// It returns a non-nil error if the ring degree is too small to reach the soundness target.
+func ParametersLiteralForSoundness(bfvParams bfv.ParametersLiteral, soundnessBits float64) (ParametersLiteral, error) {
+	numReplications, numDummies := ReplicationsForSoundness(soundnessBits)
+	if numReplications > 1<<bfvParams.LogN {
+		return ParametersLiteral{}, fmt.Errorf("soundness of %.1f bits requires %d replications, more than N = %d", soundnessBits, numReplications, 1<<bfvParams.LogN)
+	}
+	return ParametersLiteral{ParametersLiteral: bfvParams, NumReplications: numReplications, NumDummies: numDummies, NumDistinctPRFKeys: 1}, nil
+}
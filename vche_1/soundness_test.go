@@ -0,0 +1,89 @@
+package vche_1
+
+import (
+	"github.com/ldsec/lattigo/v2/bfv"
+	"github.com/stretchr/testify/require"
+	"math"
+	"testing"
+	"veritas/vche/vche"
+)
+
+func TestSoundness(t *testing.T) {
+	t.Run("CheatingProbability", func(t *testing.T) {
+		require.InDelta(t, 1.0/28, CheatingProbability(8, 2), 1e-12)
+		require.InDelta(t, 1.0/601080390, CheatingProbability(32, 16), 1e-20)
+		require.Equal(t, 1.0, CheatingProbability(1, 0))
+		require.Panics(t, func() { CheatingProbability(8, 9) })
+	})
+
+	t.Run("ReplicationsForSoundness", func(t *testing.T) {
+		for _, bits := range []float64{10, 40, 80} {
+			lambda, numDummies := ReplicationsForSoundness(bits)
+			require.LessOrEqual(t, CheatingProbability(lambda, numDummies), math.Exp2(-bits))
+			require.Greater(t, CheatingProbability(lambda, numDummies-1), math.Exp2(-bits))
+			require.Greater(t, CheatingProbability(lambda/2, lambda/4), math.Exp2(-bits))
+		}
+		lambda, numDummies := ReplicationsForSoundness(40)
+		require.Equal(t, 64, lambda)
+		require.Equal(t, 12, numDummies)
+
+		pl, err := ParametersLiteralForSoundness(bfv.PN13QP218, 40)
+		require.NoError(t, err)
+		params, err := NewParametersFromLiteral(pl)
+		require.NoError(t, err)
+		require.Equal(t, 12, params.NumDummies)
+		require.Equal(t, 8192/64, params.NSlots)
+
+		_, err = ParametersLiteralForSoundness(bfv.PN12QP109, 5000)
+		require.Error(t, err)
+	})
+
+	t.Run("MonteCarlo", func(t *testing.T) {
+		if testing.Short() {
+			t.Skip("skipping Monte-Carlo estimation in short mode")
+		}
+
+		lambda, numDummies := 8, 2
+		params, err := NewParametersFromLiteral(ParametersLiteral{ParametersLiteral: bfv.PN12QP109, NumReplications: lambda, NumDummies: numDummies, NumDistinctPRFKeys: 1})
+		require.NoError(t, err)
+
+		K := vche.NewPRFKey(8)
+		bfvEncoder := bfv.NewEncoder(params.Parameters)
+		values := vche.GetRandomCoeffs(params.NSlots, params.T())
+		tags := vche.GetRandomTags(params.NSlots)
+		verif := NewEncoderPlaintext(params, K).EncodeNew(tags)
+
+		// The server alters the first value on the replications it guesses to be non-dummies
+		cheat := func(S DummySet, guess DummySet) (success bool) {
+			encoder := NewEncoder(params, K, S, false)
+			pt := encoder.EncodeUintNew(values, tags)
+			ms := bfvEncoder.DecodeUintNew(pt.Plaintext)
+			for j := 0; j < lambda; j++ {
+				if !guess[j] {
+					ms[j] = (ms[j] + 1) % params.T()
+				}
+			}
+			bfvEncoder.EncodeUint(ms, pt.Plaintext)
+
+			defer func() {
+				if recover() != nil {
+					success = false
+				}
+			}()
+			return encoder.DecodeUintNew(pt, verif)[0] != values[0]
+		}
+
+		S := NewDummySetOfSize(lambda, numDummies)
+		require.True(t, cheat(S, S))
+
+		trials, successes := 1500, 0
+		for i := 0; i < trials; i++ {
+			if cheat(NewDummySetOfSize(lambda, numDummies), NewDummySetOfSize(lambda, numDummies)) {
+				successes++
+			}
+		}
+
+		p := CheatingProbability(lambda, numDummies)
+		require.InDelta(t, p, float64(successes)/float64(trials), 5*math.Sqrt(p*(1-p)/float64(trials)))
+	})
+}
@@ -159,3 +159,27 @@ func decodingPanicsWithDecryptor(testctx *testContext, t *testing.T, decryptor D
 	plaintext := decryptor.DecryptNew(ciphertext)
 	require.Panics(t, func() { testctx.encoder.DecodeUintNew(plaintext, verif) })
 }
+
+// testInputs are two random vectors x and y with their tags, encrypted and encoded for verification with the keys of a
+// test context.
+type testInputs struct {
+	x, y           []uint64
+	tagsX, tagsY   []vche.Tag
+	ctX, ctY       *Ciphertext
+	verifX, verifY *TaggedPoly
+}
+
+func newTestInputs(testctx *testContext) *testInputs {
+	params := testctx.params
+	in := &testInputs{
+		x:     vche.GetRandomCoeffs(params.NSlots, 1<<8),
+		y:     vche.GetRandomCoeffs(params.NSlots, 1<<8),
+		tagsX: vche.GetRandomTags(params.NSlots),
+		tagsY: vche.GetRandomTags(params.NSlots),
+	}
+	in.ctX = testctx.encryptorSk.EncryptNew(testctx.encoder.EncodeUintNew(in.x, in.tagsX))
+	in.ctY = testctx.encryptorSk.EncryptNew(testctx.encoder.EncodeUintNew(in.y, in.tagsY))
+	in.verifX = testctx.evaluatorPlaintextEncoder.EncodeNew(in.tagsX)
+	in.verifY = testctx.evaluatorPlaintextEncoder.EncodeNew(in.tagsY)
+	return in
+}
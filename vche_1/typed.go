@@ -0,0 +1,30 @@
+package vche_1
+
+import (
+	"hash"
+
+	"veritas/vche/vche"
+)
+
+var _ vche.TypedEncoder[*Plaintext, *PlaintextMul, *TaggedPoly] = Encoder(nil)
+var _ vche.TypedEncoderPlaintext[*TaggedPoly] = EncoderPlaintext(nil)
+var _ vche.TypedEncoderPlaintext[*VerifPlaintext] = EncoderPlaintextCFPRF(nil)
+var _ vche.TypedEncryptor[*Plaintext, *Ciphertext] = Encryptor(nil)
+var _ vche.TypedDecryptor[*Ciphertext, *Plaintext] = Decryptor(nil)
+var _ vche.OperandEvaluator[Operand, *Ciphertext] = Evaluator(nil)
+
+// NewTypedEvaluator returns the evaluator of ciphertexts as a vche.TypedEvaluator.
+func NewTypedEvaluator(params Parameters, evaluationKey *EvaluationKey) vche.TypedEvaluator[*Ciphertext] {
+	return vche.NewTypedEvaluator[Operand, *Ciphertext](NewEvaluator(params, evaluationKey))
+}
+
+// NewTypedEvaluatorPlaintext returns the evaluator of the verification state as a vche.TypedEvaluator.
+func NewTypedEvaluatorPlaintext(params Parameters, H hash.Hash) vche.TypedEvaluator[*TaggedPoly] {
+	return NewEvaluatorPlaintext(params, H)
+}
+
+// NewTypedEvaluatorPlaintextCFPRF returns the evaluator of the verification state with closed-form PRFs as a
+// vche.TypedEvaluator.
+func NewTypedEvaluatorPlaintextCFPRF(params Parameters, H hash.Hash) vche.TypedEvaluator[*VerifPlaintext] {
+	return NewEvaluatorPlaintextCFPRF(params, H)
+}
@@ -0,0 +1,52 @@
+package vche_1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"veritas/vche/vche"
+)
+
+// sumOfSquares is evaluated by the same code on the ciphertexts and on their verification state.
+func sumOfSquares[T any](eval vche.TypedEvaluator[T], xs ...T) T {
+	res := eval.RelinearizeNew(eval.MulNew(xs[0], xs[0]))
+	for _, x := range xs[1:] {
+		eval.Add(res, eval.RelinearizeNew(eval.MulNew(x, x)), res)
+	}
+	return res
+}
+
+func TestTyped(t *testing.T) {
+	params, err := NewParametersFromLiteral(DefaultParams[0])
+	require.NoError(t, err)
+	testctx, err := genTestParams(params)
+	require.NoError(t, err)
+
+	cts, verifs := make([]*Ciphertext, 2), make([]*TaggedPoly, 2)
+	want := make([]uint64, params.NSlots)
+	for i := range cts {
+		coeffs, tags := vche.GetRandomCoeffs(params.NSlots, 1<<8), vche.GetRandomTags(params.NSlots)
+		cts[i] = testctx.encryptorSk.EncryptNew(testctx.encoder.EncodeUintNew(coeffs, tags))
+		verifs[i] = testctx.evaluatorPlaintextEncoder.EncodeNew(tags)
+		for j := range want {
+			want[j] = (want[j] + coeffs[j]*coeffs[j]) % params.T()
+		}
+	}
+
+	res := sumOfSquares(NewTypedEvaluator(params, testctx.evk), cts...)
+	verif := sumOfSquares(NewTypedEvaluatorPlaintext(params, testctx.sk.H), verifs...)
+	verifyTestVectors(testctx, testctx.decryptor, want, res, verif, t)
+
+	// The Generic adapters check the types at runtime
+	encoder := vche.NewGenericEncoderOf[*Plaintext, *PlaintextMul, *TaggedPoly](testctx.encoder)
+	decryptor := vche.NewGenericDecryptorOf[*Ciphertext, *Plaintext](testctx.decryptor)
+	require.Equal(t, want, encoder.DecodeUintNew(decryptor.DecryptNew(res), verif))
+	require.True(t, vche.Rejects(func() { encoder.DecodeUintNew(res, verif) }))
+
+	c := vche.NewCircuit()
+	in := c.Inputs(2)
+	c.Output(c.Add(c.Relinearize(c.Mul(in[0], in[0])), c.Relinearize(c.Mul(in[1], in[1]))))
+	res = vche.EvalTyped(c, NewTypedEvaluator(params, testctx.evk), cts...)[0]
+	verif = vche.EvalTyped(c, NewTypedEvaluatorPlaintext(params, testctx.sk.H), verifs...)[0]
+	verifyTestVectors(testctx, testctx.decryptor, want, res, verif, t)
+}
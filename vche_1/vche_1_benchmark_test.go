@@ -10,9 +10,9 @@ import (
 func BenchmarkVCHE1(b *testing.B) {
 	var err interface{}
 	paramsLiteral := ParametersLiteral{
-		bfv.PN14QP438,
-		64,
-		1,
+		ParametersLiteral:  bfv.PN14QP438,
+		NumReplications:    64,
+		NumDistinctPRFKeys: 1,
 	}
 	params, err := NewParametersFromLiteral(paramsLiteral)
 	testctx, err := genTestParams(params)
@@ -21,6 +21,7 @@ func BenchmarkVCHE1(b *testing.B) {
 	}
 
 	benchEncoder(testctx, b)
+	benchEncoderPRF(testctx, b)
 	benchEncrypt(testctx, b)
 	benchDecrypt(testctx, b)
 	benchEvaluator(testctx, b)
@@ -51,6 +52,30 @@ func benchEncoder(testctx *testContext, b *testing.B) {
 	})
 }
 
+func benchEncoderPRF(testctx *testContext, b *testing.B) {
+	coeffs := vche.GetRandomCoeffs(testctx.params.NSlots, testctx.params.T())
+	tags := vche.GetRandomTags(testctx.params.NSlots)
+
+	for _, prfType := range vche.PRFTypes {
+		params := testctx.params.WithPRF(prfType)
+		encoder := NewEncoder(params, testctx.sk.K, testctx.sk.S, false)
+		encoderPlaintext := NewEncoderPlaintext(params, testctx.sk.K)
+		plaintext := NewPlaintext(params)
+
+		b.Run(testStringNoSplit("Encoder/EncodeUint/PRF="+prfType.String()+"/", params), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				encoder.EncodeUint(coeffs, tags, plaintext)
+			}
+		})
+
+		b.Run(testStringNoSplit("EncoderPlaintext/Encode/PRF="+prfType.String()+"/", params), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				encoderPlaintext.EncodeNew(tags)
+			}
+		})
+	}
+}
+
 func benchEncrypt(testctx *testContext, b *testing.B) {
 
 	//encryptorPk := testctx.encryptorPk
@@ -3,9 +3,12 @@ package vche_1
 import (
 	"encoding/json"
 	"flag"
+	"github.com/ldsec/lattigo/v2/bfv"
 	"github.com/ldsec/lattigo/v2/utils"
+	"github.com/stretchr/testify/require"
 	"veritas/vche/vche"
 	"math"
+	"math/big"
 	"runtime"
 	"testing"
 )
@@ -13,6 +16,9 @@ import (
 var flagLongTest = flag.Bool("long", false, "run the long test suite (all parameters). Overrides -short and requires -timeout=0.")
 var flagParamString = flag.String("params", "", "specify the test cryptographic parameters as a JSON string. Overrides -short and -long.")
 
+// smallLogN is the largest ring degree on which the tests of composite computations run.
+const smallLogN = 13
+
 func TestVCHE1(t *testing.T) {
 	defaultParams := DefaultParams // the default test runs for ring degree N=2^12, 2^13, 2^14, 2^15
 	if testing.Short() {
@@ -41,13 +47,20 @@ func TestVCHE1(t *testing.T) {
 			panic(err)
 		}
 
-		for _, testSet := range []func(testctx *testContext, t *testing.T){
+		testSets := []func(testctx *testContext, t *testing.T){
 			testEncoder,
+			testKeyGenerator,
 			testEvaluator,
 			testType1Fails,
 			testType2Fails,
-			//testMarshaller,
-		} {
+			testMarshaller,
+		}
+		if params.LogN() <= smallLogN {
+			// These tests hold several ciphertexts and evaluators at once, so they only run on the small parameters
+			testSets = append(testSets, testVector, testCircuit, testTrace, testNoise, testCompression, testDelegation, testContributor, testMultiOwner, testMultiparty)
+		}
+
+		for _, testSet := range testSets {
 			testSet(testctx, t)
 			runtime.GC()
 		}
@@ -59,6 +72,240 @@ func testEncoder(testctx *testContext, t *testing.T) {
 		values, _, plaintext, _, verif := newTestVectors(testctx, nil, testctx.params.T())
 		verifyTestVectors(testctx, nil, values, plaintext, verif, t)
 	})
+
+	t.Run(testString("Encoder/Encode&Decode/Partial/", testctx.params), func(t *testing.T) {
+		n := testctx.params.NSlots/2 + 1
+		values := vche.GetRandomCoeffs(n, testctx.params.T())
+		tags := vche.GetRandomTags(n)
+		ctxt := testctx.encryptorSk.EncryptNew(testctx.encoder.EncodeUintNew(values, tags))
+		verif := testctx.evaluatorPlaintextEncoder.EncodeNew(tags)
+
+		valuesTest := make([]uint64, n)
+		testctx.encoder.DecodeUint(testctx.decryptor.DecryptNew(ctxt), verif, valuesTest)
+		require.Equal(t, values, valuesTest)
+
+		// The padded slots are authenticated: altering the last one is detected
+		if testctx.params.NumDummies > 0 {
+			pt := testctx.encoder.EncodeUintNew(values, tags)
+			bfvEncoder := bfv.NewEncoder(testctx.params.Parameters)
+			ms := bfvEncoder.DecodeUintNew(pt.Plaintext)
+			for j := 0; j < testctx.params.NumReplications; j++ {
+				idx := (testctx.params.NSlots-1)*testctx.params.NumReplications + j
+				ms[idx] = (ms[idx] + 1) % testctx.params.T()
+			}
+			bfvEncoder.EncodeUint(ms, pt.Plaintext)
+			require.Panics(t, func() { testctx.encoder.DecodeUintNew(pt, verif) })
+		}
+	})
+
+	for _, prfType := range vche.PRFTypes {
+		params := testctx.params.WithPRF(prfType)
+		t.Run(testString("Encoder/Encode&Decode/Uint/PRF="+prfType.String()+"/", params), func(t *testing.T) {
+			encoder := NewEncoder(params, testctx.sk.K, testctx.sk.S, false)
+			encoderPlaintext := NewEncoderPlaintext(params, testctx.sk.K)
+
+			values := vche.GetRandomCoeffs(params.NSlots, params.T())
+			tags := vche.GetRandomTags(params.NSlots)
+			plaintext := encoder.EncodeUintNew(values, tags)
+			verif := encoderPlaintext.EncodeNew(tags)
+
+			valuesTest := encoder.DecodeUintNew(plaintext, verif)
+			require.True(t, utils.EqualSliceUint64(values, valuesTest[:params.NSlots]))
+		})
+	}
+}
+
+func testKeyGenerator(testctx *testContext, t *testing.T) {
+	t.Run(testString("KeyGenerator/GenSecretKeyFromKDF/", testctx.params), func(t *testing.T) {
+		seed := []byte("veritas key generator test seed")
+		sk1 := testctx.kgen.GenSecretKeyFromKDF(vche.NewKDF(seed, "client"))
+		sk2 := testctx.kgen.GenSecretKeyFromKDF(vche.NewKDF(seed, "client"))
+		sk3 := testctx.kgen.GenSecretKeyFromKDF(vche.NewKDF(seed, "other client"))
+
+		require.True(t, sk1.SecretKey.Value.Q.Equals(sk2.SecretKey.Value.Q))
+		require.True(t, sk1.SecretKey.Value.P.Equals(sk2.SecretKey.Value.P))
+		require.Equal(t, sk1.K, sk2.K)
+		require.True(t, Eq(sk1.S, sk2.S))
+		require.False(t, sk1.SecretKey.Value.Q.Equals(sk3.SecretKey.Value.Q))
+		require.NotEqual(t, sk1.K, sk3.K)
+
+		// a ciphertext produced under the first key verifies and decrypts under the re-derived key
+		values := vche.GetRandomCoeffs(testctx.params.NSlots, testctx.params.T())
+		tags := vche.GetRandomTags(testctx.params.NSlots)
+		ctxt := NewEncryptor(testctx.params, sk1).EncryptNew(NewEncoder(testctx.params, sk1.K, sk1.S, false).EncodeUintNew(values, tags))
+		verif := NewEncoderPlaintext(testctx.params, sk2.K).EncodeNew(tags)
+		valuesTest := NewEncoder(testctx.params, sk2.K, sk2.S, false).DecodeUintNew(NewDecryptor(testctx.params, sk2).DecryptNew(ctxt), verif)
+		require.True(t, utils.EqualSliceUint64(values, valuesTest[:testctx.params.NSlots]))
+	})
+}
+
+func testVector(testctx *testContext, t *testing.T) {
+	t.Run(testString("Vector/", testctx.params), func(t *testing.T) {
+		T := testctx.params.T()
+		vectorEncoder := NewVectorEncoder(testctx.params, testctx.encoder)
+		vectorEncoderPlaintext := NewVectorEncoderPlaintext(testctx.params, testctx.evaluatorPlaintextEncoder)
+		evaluator := NewVectorEvaluator(testctx.evaluator.WithKey(testctx.innerSumEvk))
+		evaluatorPlaintext := NewVectorEvaluatorPlaintext(testctx.evaluatorPlaintext)
+
+		length := 2*testctx.params.NSlots + testctx.params.NSlots/2 + 1
+		a, b := vche.GetRandomCoeffs(length, T), vche.GetRandomCoeffs(length, T)
+		ctA := EncryptVectorNew(testctx.encryptorSk, vectorEncoder.EncodeUintNew(a, []byte("a")))
+		ctB := EncryptVectorNew(testctx.encryptorSk, vectorEncoder.EncodeUintNew(b, []byte("b")))
+		verifA := vectorEncoderPlaintext.EncodeNew([]byte("a"), length)
+		verifB := vectorEncoderPlaintext.EncodeNew([]byte("b"), length)
+		require.Equal(t, vche.NumChunks(length, testctx.params.NSlots), ctA.NumChunks())
+
+		// a * b + 3 * a - b
+		ct := evaluator.RelinearizeNew(evaluator.MulNew(ctA, ctB))
+		evaluator.Add(ct, evaluator.MulScalarNew(ctA, 3), ct)
+		evaluator.Sub(ct, ctB, ct)
+		verif := evaluatorPlaintext.RelinearizeNew(evaluatorPlaintext.MulNew(verifA, verifB))
+		evaluatorPlaintext.Add(verif, evaluatorPlaintext.MulScalarNew(verifA, 3), verif)
+		evaluatorPlaintext.Sub(verif, verifB, verif)
+
+		bigT := big.NewInt(0).SetUint64(T)
+		want := make([]uint64, length)
+		sum := big.NewInt(0)
+		for i := range want {
+			w := big.NewInt(0).Mul(big.NewInt(0).SetUint64(a[i]), big.NewInt(0).SetUint64(b[i]))
+			w.Add(w, big.NewInt(0).Mul(big.NewInt(0).SetUint64(a[i]), big.NewInt(3)))
+			w.Sub(w, big.NewInt(0).SetUint64(b[i]))
+			w.Mod(w, bigT)
+			want[i] = w.Uint64()
+			sum.Add(sum, w)
+		}
+		require.Equal(t, want, vectorEncoder.DecodeUintNew(DecryptVectorNew(testctx.decryptor, ct), verif))
+
+		// The sum of all the values of the vector lands in every slot
+		ctSum := evaluator.InnerSumNew(ct)
+		verifSum := evaluatorPlaintext.InnerSumNew(verif)
+		require.Equal(t, sum.Mod(sum, bigT).Uint64(), testctx.encoder.DecodeUintNew(testctx.decryptor.DecryptNew(ctSum), verifSum)[0])
+
+		// Tampering with a chunk is detected
+		evaluator.Add(ct, ctA, ct)
+		require.Panics(t, func() { vectorEncoder.DecodeUintNew(DecryptVectorNew(testctx.decryptor, ct), verif) })
+	})
+}
+
+func testNoise(testctx *testContext, t *testing.T) {
+	t.Run(testString("Noise/Estimate/", testctx.params), func(t *testing.T) {
+		c := vche.NewCircuit()
+		x, y := c.Input(), c.Input()
+		w := c.Sub(c.MulScalar(c.Relinearize(c.Mul(x, y)), 3), y)
+		c.Output(w, c.InnerSum(w))
+
+		_, _, _, ctX, _ := newTestVectors(testctx, testctx.encryptorSk, testctx.params.T())
+		_, _, _, ctY, _ := newTestVectors(testctx, testctx.encryptorSk, testctx.params.T())
+
+		warned := false
+		eval := vche.NewNoiseEstimator(testctx.params, NewGenericEvaluator(testctx.params, &testctx.innerSumEvk), func(string, float64, float64) { warned = true })
+		for _, out := range append(c.Eval(eval, ctX, ctY), ctX, ctY) {
+			require.GreaterOrEqual(t, NoiseBudget(testctx.params, testctx.sk, out.(*Ciphertext)), eval.Budget(out))
+		}
+		require.False(t, warned)
+	})
+}
+
+func testMarshaller(testctx *testContext, t *testing.T) {
+	t.Run(testString("Marshaller/Ciphertext/", testctx.params), func(t *testing.T) {
+		values, _, _, ct, verif := newTestVectors(testctx, testctx.encryptorSk, testctx.params.T())
+		data, err := ct.MarshalBinary()
+		require.NoError(t, err)
+		require.Equal(t, len(data), vche.Size(ct))
+
+		ctNew := new(Ciphertext)
+		require.NoError(t, ctNew.UnmarshalBinary(data))
+		require.Equal(t, ct, ctNew)
+		require.Equal(t, values, testctx.encoder.DecodeUintNew(testctx.decryptor.DecryptNew(ctNew), verif)[:len(values)])
+
+		require.Error(t, ctNew.UnmarshalBinary(data[:len(data)-1]))
+		// Oversized counts and malformed lattigo ciphertexts are rejected without allocating or panicking
+		for _, data := range [][]byte{{0x7f, 0xff, 0xff, 0xff}, {0, 0, 0, 5, 1, 0, 0, 0, 1, 0, 1}, append(data, 0)} {
+			require.NotPanics(t, func() { require.Error(t, new(Ciphertext).UnmarshalBinary(data)) })
+		}
+	})
+
+	t.Run(testString("Marshaller/TaggedPoly/", testctx.params), func(t *testing.T) {
+		values, _, _, ct, verif := newTestVectors(testctx, testctx.encryptorSk, testctx.params.T())
+		data, err := verif.MarshalBinary()
+		require.NoError(t, err)
+		require.Equal(t, len(data), vche.Size(verif))
+
+		verifNew := new(TaggedPoly)
+		require.NoError(t, verifNew.UnmarshalBinary(data))
+		require.Equal(t, verif, verifNew)
+		require.Equal(t, values, testctx.encoder.DecodeUintNew(testctx.decryptor.DecryptNew(ct), verifNew)[:len(values)])
+
+		require.Error(t, verifNew.UnmarshalBinary(data[:len(data)-1]))
+		// Oversized counts and malformed lattigo polynomials are rejected without allocating or panicking
+		for _, data := range [][]byte{{0x7f, 0xff, 0xff, 0xff}, {0, 0, 0, 4, 40, 1, 0, 0, 0, 0, 0, 0}, append(data, 0)} {
+			require.NotPanics(t, func() { require.Error(t, new(TaggedPoly).UnmarshalBinary(data)) })
+		}
+	})
+}
+
+func testCircuit(testctx *testContext, t *testing.T) {
+	t.Run(testString("Circuit/", testctx.params), func(t *testing.T) {
+		// 3 * x * y - y, and its inner sum
+		c := vche.NewCircuit()
+		x, y := c.Input(), c.Input()
+		w := c.Sub(c.MulScalar(c.Relinearize(c.Mul(x, y)), 3), y)
+		c.Output(w, c.InnerSum(w))
+
+		valuesX, _, _, ctX, verifX := newTestVectors(testctx, testctx.encryptorSk, testctx.params.T())
+		valuesY, _, _, ctY, verifY := newTestVectors(testctx, testctx.encryptorSk, testctx.params.T())
+
+		outs := c.Eval(NewGenericEvaluator(testctx.params, &testctx.innerSumEvk), ctX, ctY)
+		verifs := c.Eval(NewGenericEvaluatorPlaintext(testctx.params, testctx.sk.H), verifX, verifY)
+
+		bigT := big.NewInt(0).SetUint64(testctx.params.T())
+		want := make([]uint64, len(valuesX))
+		sum := big.NewInt(0)
+		for i := range want {
+			v := big.NewInt(0).Mul(big.NewInt(0).SetUint64(valuesX[i]), big.NewInt(0).SetUint64(valuesY[i]))
+			v.Mul(v, big.NewInt(3))
+			v.Sub(v, big.NewInt(0).SetUint64(valuesY[i]))
+			v.Mod(v, bigT)
+			want[i] = v.Uint64()
+			sum.Add(sum, v)
+		}
+		sum.Mod(sum, bigT)
+
+		got := testctx.encoder.DecodeUintNew(testctx.decryptor.DecryptNew(outs[0].(*Ciphertext)), verifs[0].(*TaggedPoly))
+		require.Equal(t, want, got[:len(want)])
+		got = testctx.encoder.DecodeUintNew(testctx.decryptor.DecryptNew(outs[1].(*Ciphertext)), verifs[1].(*TaggedPoly))
+		require.Equal(t, sum.Uint64(), got[0])
+	})
+}
+
+func testTrace(testctx *testContext, t *testing.T) {
+	t.Run(testString("Trace/", testctx.params), func(t *testing.T) {
+		_, _, _, ctX, verifX := newTestVectors(testctx, testctx.encryptorSk, testctx.params.T())
+		_, _, _, ctY, verifY := newTestVectors(testctx, testctx.encryptorSk, testctx.params.T())
+
+		// The server records its computation, and ships the trace with the result
+		server := vche.NewRecordingEvaluator(NewGenericEvaluator(testctx.params, &testctx.innerSumEvk))
+		ct := server.MulNew(ctX, ctY)
+		server.Relinearize(ct, ct)
+		server.Sub(ct, ctX, ct)
+		server.InnerSum(ct, ct)
+		server.Output(ct)
+		data, err := server.Trace().MarshalBinary()
+		require.NoError(t, err)
+
+		// The client replays it on the verification state
+		trace := new(vche.Trace)
+		require.NoError(t, trace.UnmarshalBinary(data))
+		verif := vche.Replay(trace, NewGenericEvaluatorPlaintext(testctx.params, testctx.sk.H), []interface{}{verifX, verifY})[0].(*TaggedPoly)
+		testctx.encoder.DecodeUintNew(testctx.decryptor.DecryptNew(ct.(*Ciphertext)), verif)
+
+		// A trace that deviates from the computation is caught at decoding (the dummies do not match)
+		trace.Ops[2].Op = "Add"
+		verif = vche.Replay(trace, NewGenericEvaluatorPlaintext(testctx.params, testctx.sk.H), []interface{}{verifX, verifY})[0].(*TaggedPoly)
+		if testctx.params.NumDummies > 0 {
+			require.Panics(t, func() { testctx.encoder.DecodeUintNew(testctx.decryptor.DecryptNew(ct.(*Ciphertext)), verif) })
+		}
+	})
 }
 
 func testEvaluator(testctx *testContext, t *testing.T) {
@@ -0,0 +1,67 @@
+package vche_1
+
+import (
+	"veritas/vche/vche"
+)
+
+// PlaintextVector is a vector of arbitrary length, encoded into several plaintexts of at most NSlots values each.
+type PlaintextVector = vche.Vector[*Plaintext]
+
+// CiphertextVector is a vector of arbitrary length, encrypted into several ciphertexts of at most NSlots values each.
+type CiphertextVector = vche.Vector[*Ciphertext]
+
+// TaggedPolyVector is the verification counterpart of a PlaintextVector or CiphertextVector.
+type TaggedPolyVector = vche.Vector[*TaggedPoly]
+
+// VerifPlaintextVector is the verification counterpart of a PlaintextVector or CiphertextVector with closed-form PRFs.
+type VerifPlaintextVector = vche.Vector[*VerifPlaintext]
+
+// NewVectorEncoder returns a vche.VectorEncoder that encodes vectors of arbitrary length with encoder.
+func NewVectorEncoder(params Parameters, encoder Encoder) vche.VectorEncoder[*Plaintext, *TaggedPoly] {
+	return vche.NewVectorEncoder[*Plaintext, *PlaintextMul, *TaggedPoly](params.NSlots, encoder)
+}
+
+// NewVectorEncoderPlaintext returns a vche.VectorEncoderPlaintext that computes the verification counterpart of the
+// vectors encoded by a vector encoder.
+func NewVectorEncoderPlaintext(params Parameters, encoder EncoderPlaintext) vche.VectorEncoderPlaintext[*TaggedPoly] {
+	return vche.NewVectorEncoderPlaintext[*TaggedPoly](params.NSlots, encoder)
+}
+
+// NewVectorEncoderPlaintextCFPRF is the counterpart of NewVectorEncoderPlaintext with closed-form PRFs, whose result
+// is evaluated with EvalVector before decoding.
+func NewVectorEncoderPlaintextCFPRF(params Parameters, encoder EncoderPlaintextCFPRF) vche.VectorEncoderPlaintext[*VerifPlaintext] {
+	return vche.NewVectorEncoderPlaintext[*VerifPlaintext](params.NSlots, encoder)
+}
+
+// EvalVector evaluates the closed-form verification state of each chunk of verif.
+func EvalVector(evaluator EvaluatorPlaintextCFPRF, verif *VerifPlaintextVector) *TaggedPolyVector {
+	return vche.MapVector(verif, func(op *VerifPlaintext) *TaggedPoly {
+		evaluator.ComputeMemo(op)
+		return evaluator.Eval(op)
+	})
+}
+
+// EncryptVectorNew encrypts each chunk of pt.
+func EncryptVectorNew(encryptor Encryptor, pt *PlaintextVector) *CiphertextVector {
+	return vche.EncryptVectorNew[*Plaintext, *Ciphertext](encryptor, pt)
+}
+
+// DecryptVectorNew decrypts each chunk of ct.
+func DecryptVectorNew(decryptor Decryptor, ct *CiphertextVector) *PlaintextVector {
+	return vche.DecryptVectorNew[*Ciphertext, *Plaintext](decryptor, ct)
+}
+
+// NewVectorEvaluator returns a vche.VectorEvaluator that evaluates vectors of ciphertexts chunk-wise with evaluator.
+func NewVectorEvaluator(evaluator Evaluator) vche.VectorEvaluator[*Ciphertext] {
+	return vche.NewVectorEvaluator(vche.NewTypedEvaluator[Operand, *Ciphertext](evaluator))
+}
+
+// NewVectorEvaluatorPlaintext returns the verification counterpart of NewVectorEvaluator.
+func NewVectorEvaluatorPlaintext(evaluator EvaluatorPlaintext) vche.VectorEvaluator[*TaggedPoly] {
+	return vche.NewVectorEvaluator[*TaggedPoly](evaluator)
+}
+
+// NewVectorEvaluatorPlaintextCFPRF returns the verification counterpart of NewVectorEvaluator with closed-form PRFs.
+func NewVectorEvaluatorPlaintextCFPRF(evaluator EvaluatorPlaintextCFPRF) vche.VectorEvaluator[*VerifPlaintext] {
+	return vche.NewVectorEvaluator[*VerifPlaintext](evaluator)
+}
@@ -0,0 +1,50 @@
+package vche_1_CFPRF
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"veritas/vche/vche"
+	"veritas/vche/vche_1"
+)
+
+// sumOfSquares is evaluated by the same code on the ciphertexts and on their closed-form verification state.
+func sumOfSquares[T any](eval vche.TypedEvaluator[T], xs ...T) T {
+	res := eval.RelinearizeNew(eval.MulNew(xs[0], xs[0]))
+	for _, x := range xs[1:] {
+		eval.Add(res, eval.RelinearizeNew(eval.MulNew(x, x)), res)
+	}
+	return res
+}
+
+func TestTyped(t *testing.T) {
+	params, err := vche_1.NewParametersFromLiteral(vche_1.DefaultParams[0])
+	require.NoError(t, err)
+	testctx, err := genTestParams(params)
+	require.NoError(t, err)
+
+	cts, verifs := make([]*vche_1.Ciphertext, 2), make([]*vche_1.VerifPlaintext, 2)
+	tags := make([][]vche.Tag, 2)
+	want := make([]uint64, params.NSlots)
+	for i := range cts {
+		var coeffs []uint64
+		coeffs, tags[i], _, cts[i], verifs[i] = newTestVectors(testctx, testctx.encryptorSk, 1<<8)
+		for j := range want {
+			want[j] = (want[j] + coeffs[j]*coeffs[j]) % params.T()
+		}
+	}
+
+	res := sumOfSquares(vche_1.NewTypedEvaluator(params, testctx.evk), cts...)
+	verif := sumOfSquares(vche_1.NewTypedEvaluatorPlaintextCFPRF(params, testctx.evk.H), verifs...)
+	verifyTestVectors(testctx, testctx.decryptor, want, res, verif, t)
+
+	// The Generic evaluator and encoder adapt the typed ones, and check the types at runtime
+	encoder := vche_1.NewGenericEncoderPlaintextCFPRF(params, testctx.sk.K)
+	eval := vche_1.NewGenericEvaluatorPlaintextCFPRF(params, testctx.evk.H).ShallowCopy()
+	c := vche.NewCircuit()
+	in := c.Inputs(2)
+	c.Output(c.Add(c.Relinearize(c.Mul(in[0], in[0])), c.Relinearize(c.Mul(in[1], in[1]))))
+	genericVerif := c.Eval(eval, encoder.EncodeNew(tags[0]), encoder.EncodeNew(tags[1]))[0]
+	verifyTestVectors(testctx, testctx.decryptor, want, res, genericVerif.(*vche_1.VerifPlaintext), t)
+	require.PanicsWithError(t, "expected *vche_1.VerifPlaintext, got *vche_1.Ciphertext", func() { eval.RelinearizeNew(cts[0]) })
+}
@@ -0,0 +1,95 @@
+package vche_2
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/ldsec/lattigo/v2/bfv"
+	"veritas/vche/vche"
+)
+
+// CompressedCiphertext is a result compressed by the server for transmission: each of its ciphertexts is switched to
+// the first prime of Q.
+type CompressedCiphertext struct {
+	Ciphertexts []*vche.CompressedCiphertext
+}
+
+// Compress returns the compressed ciphertext of ct, whose noise is estimated by noise, the largest noise of its
+// components (see vche.CompressCiphertext). It returns an error if the compressed ciphertext would not decrypt correctly.
+func Compress(params Parameters, ct *Ciphertext, noise float64) (*CompressedCiphertext, error) {
+	cc := &CompressedCiphertext{make([]*vche.CompressedCiphertext, len(ct.Ciphertexts))}
+	for i := range ct.Ciphertexts {
+		var err error
+		if cc.Ciphertexts[i], err = vche.CompressCiphertext(params, ct.Ciphertexts[i], noise); err != nil {
+			return nil, fmt.Errorf("component %d: %w", i, err)
+		}
+	}
+	return cc, nil
+}
+
+// Decompress returns the ciphertext of a compressed ciphertext, to be decrypted and decoded as any other ciphertext.
+// It returns an error if cc is malformed.
+func Decompress(params Parameters, cc *CompressedCiphertext) (*Ciphertext, error) {
+	if len(cc.Ciphertexts) == 0 {
+		return nil, fmt.Errorf("a compressed ciphertext should have at least 1 component")
+	}
+	ct := &Ciphertext{make([]*bfv.Ciphertext, len(cc.Ciphertexts))}
+	for i := range cc.Ciphertexts {
+		var err error
+		if ct.Ciphertexts[i], err = vche.DecompressCiphertext(params, cc.Ciphertexts[i]); err != nil {
+			return nil, fmt.Errorf("component %d: %w", i, err)
+		}
+	}
+	return ct, nil
+}
+
+// MarshalBinary encodes the compressed ciphertext, component by component, in a slice of bytes.
+func (cc *CompressedCiphertext) MarshalBinary() ([]byte, error) {
+	data := appendUint32(nil, uint32(len(cc.Ciphertexts)))
+	for _, ct := range cc.Ciphertexts {
+		b, err := ct.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		data = appendUint32(data, uint32(len(b)))
+		data = append(data, b...)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary decodes a slice of bytes generated by MarshalBinary on the compressed ciphertext.
+func (cc *CompressedCiphertext) UnmarshalBinary(data []byte) error {
+	// Each component takes at least its length and its number of elements
+	numComponents, data, err := vche.ReadCount(data, 8, "components")
+	if err != nil {
+		return err
+	}
+	cc.Ciphertexts = make([]*vche.CompressedCiphertext, numComponents)
+	for i := range cc.Ciphertexts {
+		if len(data) < 4 {
+			return fmt.Errorf("missing length of component %d", i)
+		}
+		n := int(binary.BigEndian.Uint32(data))
+		if len(data)-4 < n {
+			return fmt.Errorf("component %d: expected %d bytes, got %d", i, n, len(data)-4)
+		}
+		cc.Ciphertexts[i] = new(vche.CompressedCiphertext)
+		if err := cc.Ciphertexts[i].UnmarshalBinary(data[4 : 4+n]); err != nil {
+			return fmt.Errorf("component %d: %w", i, err)
+		}
+		data = data[4+n:]
+	}
+	if len(data) != 0 {
+		return fmt.Errorf("%d trailing bytes", len(data))
+	}
+	return nil
+}
+
+// GetDataLen returns the length in bytes of the compressed ciphertext as encoded by MarshalBinary.
+func (cc *CompressedCiphertext) GetDataLen() int {
+	dataLen := 4
+	for _, ct := range cc.Ciphertexts {
+		dataLen += 4 + ct.GetDataLen()
+	}
+	return dataLen
+}
@@ -0,0 +1,55 @@
+package vche_2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"veritas/vche/vche"
+)
+
+func testCompression(testctx *testContext, t *testing.T) {
+	t.Run(testString("Compression/", testctx.params), func(t *testing.T) {
+		in := newTestInputs(testctx)
+		params := testctx.params
+		want := make([]uint64, params.NSlots)
+		for i := range want {
+			want[i] = in.x[i] * in.y[i] % params.T()
+		}
+		res := testctx.evaluator.RelinearizeNew(testctx.evaluator.MulNew(in.ctX, in.ctY))
+		// The middle component of the product sums two tensorings
+		model := vche.NewNoiseModel(params)
+		noise := model.KeySwitch(model.Mul(model.Fresh(), model.Fresh(), 2))
+
+		verif := testctx.evaluatorPlaintext.MulNew(in.verifX, in.verifY)
+
+		// The server compresses the result, which is smaller once serialized
+		cc, err := Compress(params, res, noise)
+		require.NoError(t, err)
+		data, err := cc.MarshalBinary()
+		require.NoError(t, err)
+		require.Less(t, len(data), res.GetDataLen(true))
+		cc = new(CompressedCiphertext)
+		require.NoError(t, cc.UnmarshalBinary(data))
+		require.Equal(t, len(data), cc.GetDataLen())
+
+		ct, err := Decompress(params, cc)
+		require.NoError(t, err)
+		require.Equal(t, want, testctx.encoder.DecodeUintNew(testctx.decryptor.DecryptNew(ct), verif))
+
+		// Compression keeps the verification sound
+		tampered, err := Decompress(params, cc)
+		require.NoError(t, err)
+		tampered.Ciphertexts[0] = in.ctX.Ciphertexts[0]
+		require.True(t, vche.Rejects(func() { testctx.encoder.DecodeUintNew(testctx.decryptor.DecryptNew(tampered), verif) }))
+
+		// A malformed compressed ciphertext is an error
+		_, err = Decompress(params, &CompressedCiphertext{})
+		require.Error(t, err)
+		_, err = Decompress(params, &CompressedCiphertext{[]*vche.CompressedCiphertext{{Value: cc.Ciphertexts[0].Value[:1]}}})
+		require.Error(t, err)
+
+		// A result too noisy to decrypt once switched to q0 is not compressed
+		_, err = Compress(params, res, model.Capacity())
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,118 @@
+package vche_2
+
+import (
+	"fmt"
+
+	"github.com/ldsec/lattigo/v2/bfv"
+	"github.com/ldsec/lattigo/v2/rlwe"
+	"veritas/vche/vche"
+)
+
+// Contribution is an input of a contributor without the alphas: its values, replicated in all the slots, encrypted
+// with the public key of the key holder. The contributor sends it to the key holder, whose Issuer encodes it.
+type Contribution struct {
+	Value *bfv.Ciphertext
+}
+
+// Issuer encodes the contributions of the contributors, and is run by the holder of the verification secrets. The
+// first ciphertext of the encoding is the contribution, and the issuer computes the second one, (PRF(tags) - x) / alpha,
+// with a plaintext multiplication of the contribution by -1/alpha and a fresh encryption of PRF(tags) / alpha. Neither
+// 1/alpha nor the PRF values are ever shared encrypted, so the server cannot shift the values of a result without
+// shifting them consistently at alpha. The tags of a contribution should only be issued once.
+//
+// Each contribution costs a round trip between its contributor and the key holder, which must be online to issue it.
+// The plaintext multiplication multiplies the noise of the contribution by up to N*T/2, see vche.NoiseModel.Issued,
+// which a vche.NoiseEstimator cannot tell from a fresh ciphertext: set it with SetNoise on the second component of the
+// issued ciphertexts, and plan the parameters with planner.Requirements.Contributions.
+type Issuer interface {
+	IssueNew(contribution *Contribution, tags []vche.Tag) *Ciphertext
+}
+
+type issuer struct {
+	params    Parameters
+	enc       *encoder
+	encryptor bfv.Encryptor
+	eval      bfv.Evaluator
+	scale     *bfv.PlaintextMul
+}
+
+// NewIssuer returns the issuer of the holder of sk, whose encodings use closed-form PRFs if useClosedFormPRF is set.
+func NewIssuer(params Parameters, sk *SecretKey, useClosedFormPRF bool) Issuer {
+	enc := NewEncoder(params, sk.K, sk.Alpha, useClosedFormPRF).(*encoder)
+	values := make([]uint64, params.N())
+	for i := 0; i < params.NSlots; i++ {
+		for j := 0; j < params.NumReplications; j++ {
+			values[i*params.NumReplications+j] = (params.T() - enc.alphaInv[j]) % params.T()
+		}
+	}
+	scale := bfv.NewPlaintextMul(params.Parameters)
+	enc.Encoder.EncodeUintMul(values, scale)
+	return &issuer{params, enc, bfv.NewEncryptor(params.Parameters, sk.SecretKey), bfv.NewEvaluator(params.Parameters, rlwe.EvaluationKey{}), scale}
+}
+
+func (iss *issuer) IssueNew(contribution *Contribution, tags []vche.Tag) *Ciphertext {
+	if contribution.Value.Degree() != 1 {
+		panic(fmt.Errorf("contribution should have degree 1, got %d", contribution.Value.Degree()))
+	}
+	// The second element of the encoding of zeros is PRF(tags) / alpha
+	pt := bfv.NewPlaintext(iss.params.Parameters)
+	iss.enc.Encoder.EncodeUint(iss.enc.encodeUintTags(make([]uint64, len(tags)), tags), pt)
+	ys := iss.eval.MulNew(contribution.Value, iss.scale)
+	iss.eval.Add(ys, iss.encryptor.EncryptNew(pt), ys)
+	return &Ciphertext{[]*bfv.Ciphertext{contribution.Value.CopyNew(), ys}}
+}
+
+// Contributor encrypts the values of a contributor with the public key of the key holder.
+type Contributor interface {
+	EncryptUintNew(coeffs []uint64) *Contribution
+	EncryptIntNew(coeffs []int64) *Contribution
+}
+
+type contributor struct {
+	params Parameters
+	bfv.Encoder
+	encryptor bfv.Encryptor
+}
+
+// NewContributor returns the contributor that encrypts with the public key pk of the key holder.
+func NewContributor(params Parameters, pk *PublicKey) Contributor {
+	return &contributor{params, bfv.NewEncoder(params.Parameters), bfv.NewEncryptor(params.Parameters, pk)}
+}
+
+func (c *contributor) checkLength(lenCoeffs int) {
+	if lenCoeffs == 0 || lenCoeffs > c.params.NSlots {
+		panic(fmt.Errorf("coeffs should have between 1 and %d values, got %d", c.params.NSlots, lenCoeffs))
+	}
+}
+
+// encrypt returns the encryption of coeffs in all the replications of their slot.
+func (c *contributor) encrypt(coeffs []uint64) *Contribution {
+	replicated := make([]uint64, c.params.N())
+	for i := range coeffs {
+		for j := 0; j < c.params.NumReplications; j++ {
+			replicated[i*c.params.NumReplications+j] = coeffs[i]
+		}
+	}
+	pt := bfv.NewPlaintext(c.params.Parameters)
+	c.Encoder.EncodeUint(replicated, pt)
+	return &Contribution{c.encryptor.EncryptNew(pt)}
+}
+
+func (c *contributor) EncryptUintNew(coeffs []uint64) *Contribution {
+	c.checkLength(len(coeffs))
+	return c.encrypt(coeffs)
+}
+
+func (c *contributor) EncryptIntNew(coeffs []int64) *Contribution {
+	c.checkLength(len(coeffs))
+	T := c.params.T()
+	values := make([]uint64, len(coeffs))
+	for i, v := range coeffs {
+		if v < 0 {
+			values[i] = (T - uint64(-v)%T) % T
+		} else {
+			values[i] = uint64(v) % T
+		}
+	}
+	return c.encrypt(values)
+}
@@ -0,0 +1,154 @@
+package vche_2
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+
+	"github.com/ldsec/lattigo/v2/bfv"
+	"github.com/ldsec/lattigo/v2/rlwe"
+	"github.com/ldsec/lattigo/v2/utils"
+	"golang.org/x/crypto/blake2b"
+	"veritas/vche/vche"
+)
+
+// DelegatedCiphertext is a result switched to the key of a recipient. Value encrypts the values of the result, and
+// Check its ciphertexts folded at the secret alpha of the owner, so that the recipient verifies the result without
+// learning alpha. Both are computed by the owner from the result of the server, which cannot forge them without alpha.
+//
+// Since the ciphertexts are malleable, whoever relays them to the recipient could shift Value and Check by opposite
+// amounts, or replace Value alone, without alpha. The token therefore binds both ciphertexts with their digest.
+type DelegatedCiphertext struct {
+	Value *bfv.Ciphertext
+	Check *bfv.Ciphertext
+}
+
+// VerificationToken lets a recipient verify a result delegated to it without the secrets of the owner. It holds the
+// verification state of the result, which only depends on the PRF values of its inputs, and the digest of the
+// delegated ciphertexts. The token must reach the recipient authenticated by the owner.
+type VerificationToken struct {
+	Rhos   []uint64
+	Digest []byte
+}
+
+// Delegator re-keys verified results to a recipient.
+type Delegator interface {
+	// Delegate switches the result to the key of the recipient, and returns it with the token that verifies it, given
+	// the verification state of the result computed with EvaluatorPlaintext. The ciphertexts of the result must be
+	// relinearized.
+	Delegate(ct *Ciphertext, verif *Poly) (*DelegatedCiphertext, *VerificationToken)
+}
+
+type delegator struct {
+	params Parameters
+	alpha  uint64
+	swk    *SwitchingKey
+	eval   bfv.Evaluator
+}
+
+// NewDelegator returns the delegator of the owner of sk, with the switching key to the recipient. The results are folded
+// at the single alpha of sk, since the parameters of PE disallow replication, and hence distinct alphas.
+func NewDelegator(params Parameters, sk *SecretKey, swk *SwitchingKey) Delegator {
+	if params.NumReplications != 1 || len(sk.Alpha) != 1 {
+		panic(fmt.Errorf("delegation requires a single alpha, got %d replications and %d alphas", params.NumReplications, len(sk.Alpha)))
+	}
+	return &delegator{params, sk.Alpha[0], swk, bfv.NewEvaluator(params.Parameters, rlwe.EvaluationKey{})}
+}
+
+func (d *delegator) Delegate(ct *Ciphertext, verif *Poly) (*DelegatedCiphertext, *VerificationToken) {
+	if ct.Len() == 0 {
+		panic(fmt.Errorf("cannot delegate an empty ciphertext"))
+	}
+	// Fold the ciphertexts at alpha, as the decoder does with the plaintexts. Unlike Horner's rule, which multiplies the
+	// noise of the last ciphertext by alpha^(n-1), multiplying each ciphertext by the balanced representative of its
+	// power of alpha multiplies its noise by at most T/2
+	T := d.params.T()
+	check := ct.Ciphertexts[0].CopyNew()
+	tmp := bfv.NewCiphertext(d.params.Parameters, check.Degree())
+	power := uint64(1)
+	for i := 1; i < ct.Len(); i++ {
+		hi, lo := bits.Mul64(power, d.alpha)
+		power = bits.Rem64(hi, lo, T)
+		if power <= T/2 {
+			d.eval.MulScalar(ct.Ciphertexts[i], power, tmp)
+			d.eval.Add(check, tmp, check)
+		} else {
+			d.eval.MulScalar(ct.Ciphertexts[i], T-power, tmp)
+			d.eval.Sub(check, tmp, check)
+		}
+	}
+
+	res := &DelegatedCiphertext{
+		Value: d.eval.SwitchKeysNew(ct.Ciphertexts[0], d.swk),
+		Check: d.eval.SwitchKeysNew(check, d.swk),
+	}
+	token := &VerificationToken{make([]uint64, d.params.N()), delegationDigest(res)}
+	if verif.Shift == nil {
+		copy(token.Rhos, verif.Coeffs[0])
+	} else {
+		// Requadratized results are verified against their shifted state
+		rhos := d.params.RingT().NewPoly()
+		d.params.RingT().Add(verif.Poly, verif.Shift, rhos)
+		copy(token.Rhos, rhos.Coeffs[0])
+	}
+	return res, token
+}
+
+// delegationDigest returns the digest of the ciphertexts of a delegated result.
+func delegationDigest(ct *DelegatedCiphertext) []byte {
+	h, err := blake2b.New256(nil)
+	if err != nil {
+		panic(err)
+	}
+	for _, c := range []*bfv.Ciphertext{ct.Value, ct.Check} {
+		data, err := c.MarshalBinary()
+		if err != nil {
+			panic(err)
+		}
+		// The ciphertexts are length-prefixed, so that distinct pairs have distinct encodings
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint64(b, uint64(len(data)))
+		h.Write(b)
+		h.Write(data)
+	}
+	return h.Sum(nil)
+}
+
+// Recipient decrypts and verifies the results delegated to it.
+type Recipient interface {
+	DecodeUintNew(ct *DelegatedCiphertext, token *VerificationToken) []uint64
+	DecodeIntNew(ct *DelegatedCiphertext, token *VerificationToken) []int64
+}
+
+type recipient struct {
+	params Parameters
+	bfv.Decryptor
+	bfv.Encoder
+}
+
+// NewRecipient returns the recipient with the given secret key, to which the delegator switches the results.
+func NewRecipient(params Parameters, sk *rlwe.SecretKey) Recipient {
+	return &recipient{params, bfv.NewDecryptor(params.Parameters, sk), bfv.NewEncoder(params.Parameters)}
+}
+
+// verify checks that the ciphertexts of the result are the ones delegated by the owner, and that the folded ones decrypt
+// to the verification state of the token.
+func (r *recipient) verify(ct *DelegatedCiphertext, token *VerificationToken) {
+	if ct.Value == nil || ct.Check == nil || !bytes.Equal(delegationDigest(ct), token.Digest) {
+		panic(fmt.Errorf("%w due to mismatched digest", vche.ErrVerification))
+	}
+	if !utils.EqualSliceUint64(r.Encoder.DecodeUintNew(r.Decryptor.DecryptNew(ct.Check)), token.Rhos) {
+		panic(fmt.Errorf("%w due to mismatch", vche.ErrVerification))
+	}
+}
+
+func (r *recipient) DecodeUintNew(ct *DelegatedCiphertext, token *VerificationToken) []uint64 {
+	r.verify(ct, token)
+	return r.Encoder.DecodeUintNew(r.Decryptor.DecryptNew(ct.Value))
+}
+
+func (r *recipient) DecodeIntNew(ct *DelegatedCiphertext, token *VerificationToken) []int64 {
+	r.verify(ct, token)
+	return r.Encoder.DecodeIntNew(r.Decryptor.DecryptNew(ct.Value))
+}
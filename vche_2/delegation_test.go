@@ -0,0 +1,53 @@
+package vche_2
+
+import (
+	"testing"
+
+	"github.com/ldsec/lattigo/v2/bfv"
+	"github.com/ldsec/lattigo/v2/rlwe"
+	"github.com/stretchr/testify/require"
+	"veritas/vche/vche"
+)
+
+func testDelegation(testctx *testContext, t *testing.T) {
+	t.Run(testString("Delegation/", testctx.params), func(t *testing.T) {
+		in := newTestInputs(testctx)
+		params := testctx.params
+		want := make([]uint64, params.N())
+		for i := 0; i < params.NSlots; i++ {
+			want[i] = (in.x[i]*in.y[i] + in.x[i]) % params.T()
+		}
+
+		// The server computes x*y + x, and the owner its verification state
+		res := testctx.evaluator.AddNew(testctx.evaluator.RelinearizeNew(testctx.evaluator.MulNew(in.ctX, in.ctY)), in.ctX)
+		eval := testctx.evaluatorPlaintext
+		verif := eval.AddNew(eval.MulNew(in.verifX, in.verifY), in.verifX)
+
+		recipientSk := bfv.NewKeyGenerator(params.Parameters).GenSecretKey()
+		delegator := NewDelegator(params, testctx.sk, testctx.kgen.GenRecipientSwitchingKey(testctx.sk, recipientSk))
+		recipient := NewRecipient(params, recipientSk)
+
+		delegated, token := delegator.Delegate(res, verif)
+		require.Equal(t, want, recipient.DecodeUintNew(delegated, token))
+
+		// The token of another result does not verify it
+		_, otherToken := delegator.Delegate(in.ctX, in.verifX)
+		require.True(t, vche.Rejects(func() { recipient.DecodeUintNew(delegated, otherToken) }))
+
+		// Nor does it verify a result whose value was modified after the delegation, e.g., by the relay
+		ones := make([]uint64, params.N())
+		for i := range ones {
+			ones[i] = 1
+		}
+		pt := bfv.NewPlaintext(params.Parameters)
+		bfv.NewEncoder(params.Parameters).EncodeUint(ones, pt)
+		bfvEval := bfv.NewEvaluator(params.Parameters, rlwe.EvaluationKey{})
+		relayed := &DelegatedCiphertext{bfvEval.AddNew(delegated.Value, pt), delegated.Check}
+		require.True(t, vche.Rejects(func() { recipient.DecodeUintNew(relayed, token) }))
+
+		// Nor by the server
+		bfvEval.Add(res.Ciphertexts[0], pt, res.Ciphertexts[0])
+		delegated, token = delegator.Delegate(res, verif)
+		require.True(t, vche.Rejects(func() { recipient.DecodeUintNew(delegated, token) }))
+	})
+}
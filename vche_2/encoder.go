@@ -6,7 +6,6 @@ import (
 	"github.com/ldsec/lattigo/v2/ring"
 	"github.com/ldsec/lattigo/v2/utils"
 	"veritas/vche/vche"
-	"golang.org/x/crypto/blake2b"
 	"log"
 	"math/big"
 )
@@ -36,7 +35,8 @@ type encoder struct {
 	alpha            []uint64
 	alphaInv         []uint64
 	useClosedFormPRF bool
-	xofs1, xofs2     []blake2b.XOF
+	xofs1, xofs2     []vche.XOF
+	datasets         vche.Datasets
 }
 
 func checkAlpha(params Parameters, alpha uint64) {
@@ -49,6 +49,19 @@ func checkAlpha(params Parameters, alpha uint64) {
 }
 
 func NewEncoder(params Parameters, K []vche.PRFKey, alphas []uint64, useClosedFormPRF bool) Encoder {
+	return newEncoder(params, K, alphas, nil, useClosedFormPRF)
+}
+
+// NewOwnerEncoder returns the encoder of a data owner with PRF keys K, in a computation over the inputs of several
+// owners that share the alphas of the decryptor and encrypt with its public key. It only encodes the values whose tags
+// are labelled with one of the datasets of the owner, and leaves the other slots empty, so that the server adds the
+// ciphertexts of the owners into a joint input. The decryptor verifies it with the contributions of
+// NewOwnerEncoderPlaintext. See vche.Datasets for the trust the decryptor puts in the owners.
+func NewOwnerEncoder(params Parameters, K []vche.PRFKey, alphas []uint64, datasets vche.Datasets, useClosedFormPRF bool) Encoder {
+	return newEncoder(params, K, alphas, datasets, useClosedFormPRF)
+}
+
+func newEncoder(params Parameters, K []vche.PRFKey, alphas []uint64, datasets vche.Datasets, useClosedFormPRF bool) Encoder {
 	if len(K) != len(alphas) {
 		panic(fmt.Errorf("number of provided PRF keys and alphas must be the same, got %d and %d", len(K), len(alphas)))
 	}
@@ -59,12 +72,12 @@ func NewEncoder(params Parameters, K []vche.PRFKey, alphas []uint64, useClosedFo
 		alphasInv[i] = modInv(alpha, params.T())
 	}
 
-	xofs1, xofs2 := make([]blake2b.XOF, len(K)), make([]blake2b.XOF, len(K))
+	xofs1, xofs2 := make([]vche.XOF, len(K)), make([]vche.XOF, len(K))
 	for i := range xofs1 {
-		xofs1[i] = vche.NewXOF(K[i].K1)
-		xofs2[i] = vche.NewXOF(K[i].K2)
+		xofs1[i] = params.NewXOF(K[i].K1)
+		xofs2[i] = params.NewXOF(K[i].K2)
 	}
-	return &encoder{bfv.NewEncoder(params.Parameters), params, K, alphas, alphasInv, useClosedFormPRF, xofs1, xofs2}
+	return &encoder{bfv.NewEncoder(params.Parameters), params, K, alphas, alphasInv, useClosedFormPRF, xofs1, xofs2, datasets}
 }
 
 func (enc *encoder) checkLengths(cs interface{}, tags []vche.Tag) {
@@ -84,17 +97,21 @@ func (enc *encoder) checkLengths(cs interface{}, tags []vche.Tag) {
 	if lenCoeffs > NSlots {
 		panic(fmt.Errorf("coeffs cannot be longer than N / lambda = %d / %d = %d, was %d", N, lambda, NSlots, lenCoeffs))
 	}
-	if lenCoeffs < NSlots {
-		panic(fmt.Errorf("coeffs should have length %d, was %d", NSlots, lenCoeffs))
+	if lenCoeffs == 0 {
+		panic(fmt.Errorf("coeffs should not be empty"))
 	}
 }
 
 func (enc *encoder) encodeUintCoeffs(coeffs []uint64, tags []vche.Tag) []uint64 {
 	enc.checkLengths(coeffs, tags)
+	coeffs, tags = vche.PadUint(coeffs, enc.params.NSlots), vche.PadTags(tags, enc.params.NSlots)
 
 	// Build replicated message
 	internalCoeffs := make([]uint64, enc.params.N())
 	for i := range coeffs {
+		if !enc.datasets.Owns(tags[i]) {
+			continue
+		}
 		for j := 0; j < enc.params.NumReplications; j++ {
 			idx := i*enc.params.NumReplications + j
 			internalCoeffs[idx] = coeffs[i]
@@ -105,10 +122,14 @@ func (enc *encoder) encodeUintCoeffs(coeffs []uint64, tags []vche.Tag) []uint64
 
 func (enc *encoder) encodeIntCoeffs(coeffs []int64, tags []vche.Tag) []int64 {
 	enc.checkLengths(coeffs, tags)
+	coeffs, tags = vche.PadInt(coeffs, enc.params.NSlots), vche.PadTags(tags, enc.params.NSlots)
 
 	// Build replicated message
 	internalCoeffs := make([]int64, enc.params.N())
 	for i := range coeffs {
+		if !enc.datasets.Owns(tags[i]) {
+			continue
+		}
 		for j := 0; j < enc.params.NumReplications; j++ {
 			idx := i*enc.params.NumReplications + j
 			internalCoeffs[idx] = coeffs[i]
@@ -118,10 +139,15 @@ func (enc *encoder) encodeIntCoeffs(coeffs []int64, tags []vche.Tag) []int64 {
 }
 
 func (enc *encoder) encodeUintTags(coeffs []uint64, tags []vche.Tag) []uint64 {
+	coeffs, tags = vche.PadUint(coeffs, enc.params.NSlots), vche.PadTags(tags, enc.params.NSlots)
+
 	// Build replicated (PRF(tags) - message) / alpha
 	ys := make([]uint64, enc.params.N())
 	T := enc.params.T()
 	for i := range tags {
+		if !enc.datasets.Owns(tags[i]) {
+			continue
+		}
 		for j := 0; j < enc.params.NumReplications; j++ {
 			idx := i*enc.params.NumReplications + j
 			r := enc.PRF(j, tags[i])
@@ -139,10 +165,15 @@ func (enc *encoder) encodeUintTags(coeffs []uint64, tags []vche.Tag) []uint64 {
 }
 
 func (enc *encoder) encodeIntTags(coeffs []int64, tags []vche.Tag) []uint64 {
+	coeffs, tags = vche.PadInt(coeffs, enc.params.NSlots), vche.PadTags(tags, enc.params.NSlots)
+
 	// Build replicated (PRF(tags) - message) / alpha
 	ys := make([]uint64, enc.params.N())
 	T := enc.params.T()
 	for i := range tags {
+		if !enc.datasets.Owns(tags[i]) {
+			continue
+		}
 		for j := 0; j < enc.params.NumReplications; j++ {
 			idx := i*enc.params.NumReplications + j
 			r := enc.PRF(j, tags[i])
@@ -227,6 +258,9 @@ func (enc *encoder) EncodeIntMulNew(coeffs []int64, tags []vche.Tag) (pt *Plaint
 }
 
 func (enc *encoder) verifyUint(plaintext *Plaintext, verifPtxt *Poly) []uint64 {
+	if len(plaintext.Plaintexts) == 0 {
+		panic(fmt.Errorf("%w due to empty plaintext", vche.ErrVerification))
+	}
 	ys := make([][]uint64, len(plaintext.Plaintexts))
 	for i, p := range plaintext.Plaintexts {
 		p2 := bfv.NewPlaintext(enc.params.Parameters)
@@ -262,27 +296,32 @@ func (enc *encoder) verifyUint(plaintext *Plaintext, verifPtxt *Poly) []uint64 {
 	}
 
 	if !utils.EqualSliceUint64(rhos, rhosCheck) {
-		panic(fmt.Errorf("verification failed due to mismatch"))
+		panic(fmt.Errorf("%w due to mismatch", vche.ErrVerification))
 	}
 	return ys[0]
 }
 
 func (enc *encoder) verifyInt(pt *Plaintext, verifPtxt *Poly) []int64 {
+	enc.verifyUint(pt, verifPtxt)
 	cp := bfv.NewPlaintext(enc.params.Parameters)
 	cp.Plaintext.Copy(pt.Plaintexts[0].Plaintext)
-	enc.verifyUint(pt, verifPtxt)
 	return enc.Encoder.DecodeIntNew(cp)
 }
 
+// DecodeUint verifies pt against verif and decodes its values into coeffs. If coeffs is shorter than NSlots, only the
+// first len(coeffs) values are decoded, e.g., when a shorter vector was encoded; the verification covers all slots.
 func (enc *encoder) DecodeUint(pt *Plaintext, verif *Poly, coeffs []uint64) {
 	ms := enc.verifyUint(pt, verif)
 	for i := 0; i < enc.params.NSlots; i++ {
 		idx := i * enc.params.NumReplications
-		coeffs[i] = ms[idx]
+		c := ms[idx]
+		if i < len(coeffs) {
+			coeffs[i] = c
+		}
 		for j := 0; j < enc.params.NumReplications; j++ {
 			idx = i*enc.params.NumReplications + j
-			if coeffs[i] != ms[idx] {
-				panic(fmt.Errorf("verification failed on result #%d (replication slots %d and %d) this should have been caught during the decoding VC checks\n", i, i*enc.params.NumReplications, idx))
+			if c != ms[idx] {
+				panic(fmt.Errorf("%w on result #%d (replication slots %d and %d) this should have been caught during the decoding VC checks\n", vche.ErrVerification, i, i*enc.params.NumReplications, idx))
 			}
 		}
 	}
@@ -301,11 +340,14 @@ func (enc *encoder) DecodeInt(pt *Plaintext, verif *Poly, coeffs []int64) {
 	ms := enc.verifyInt(pt, verif)
 	for i := 0; i < enc.params.NSlots; i++ {
 		idx := i * enc.params.NumReplications
-		coeffs[i] = ms[idx]
+		c := ms[idx]
+		if i < len(coeffs) {
+			coeffs[i] = c
+		}
 		for j := 0; j < enc.params.NumReplications; j++ {
 			idx = i*enc.params.NumReplications + j
-			if coeffs[i] != ms[idx] {
-				panic(fmt.Errorf("verification failed on result #%d (replication slots %d and %d) (this should have been caught during the decoding VC checks)\n", i, i*enc.params.NumReplications, idx))
+			if c != ms[idx] {
+				panic(fmt.Errorf("%w on result #%d (replication slots %d and %d) (this should have been caught during the decoding VC checks)\n", vche.ErrVerification, i, i*enc.params.NumReplications, idx))
 			}
 		}
 	}
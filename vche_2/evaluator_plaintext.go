@@ -3,7 +3,6 @@ package vche_2
 import (
 	"github.com/ldsec/lattigo/v2/ring"
 	"veritas/vche/vche"
-	"golang.org/x/crypto/blake2b"
 )
 
 type EvaluatorPlaintext interface {
@@ -250,28 +249,45 @@ type encoderPlaintext struct {
 	Params    Parameters
 	K         []vche.PRFKey
 	useRequad bool
-	xofs1     []blake2b.XOF
+	xofs1     []vche.XOF
+	datasets  vche.Datasets
 }
 
 func NewEncoderPlaintext(parameters Parameters, K []vche.PRFKey) EncoderPlaintext {
-	xofs1 := make([]blake2b.XOF, len(K))
+	xofs1 := make([]vche.XOF, len(K))
 	for i := range xofs1 {
-		xofs1[i] = vche.NewXOF(K[i].K1)
+		xofs1[i] = parameters.NewXOF(K[i].K1)
 	}
-	return &encoderPlaintext{parameters, K, false, xofs1}
+	return &encoderPlaintext{parameters, K, false, xofs1, nil}
 }
 
 func NewEncoderPlaintextRequad(parameters Parameters, K []vche.PRFKey) EncoderPlaintext {
-	xofs1 := make([]blake2b.XOF, len(K))
+	xofs1 := make([]vche.XOF, len(K))
 	for i := range xofs1 {
-		xofs1[i] = vche.NewXOF(K[i].K1)
+		xofs1[i] = parameters.NewXOF(K[i].K1)
 	}
-	return &encoderPlaintext{parameters, K, true, xofs1}
+	return &encoderPlaintext{parameters, K, true, xofs1, nil}
+}
+
+// NewOwnerEncoderPlaintext returns the encoder of the contribution of a data owner with PRF keys K to the verification
+// state of an input of several owners, which only covers the slots of its datasets. The decryptor adds the
+// contributions of the owners with EvaluatorPlaintext, as the server adds their ciphertexts.
+func NewOwnerEncoderPlaintext(parameters Parameters, K []vche.PRFKey, datasets vche.Datasets) EncoderPlaintext {
+	xofs1 := make([]vche.XOF, len(K))
+	for i := range xofs1 {
+		xofs1[i] = parameters.NewXOF(K[i].K1)
+	}
+	return &encoderPlaintext{parameters, K, false, xofs1, datasets}
 }
 
 func (enc encoderPlaintext) Encode(tags []vche.Tag, p *Poly) {
+	tags = vche.PadTags(tags, enc.Params.NSlots)
+
 	rs := make([]uint64, enc.Params.N())
 	for i := range tags {
+		if !enc.datasets.Owns(tags[i]) {
+			continue
+		}
 		for j := 0; j < enc.Params.NumReplications; j++ {
 			idx := i*enc.Params.NumReplications + j
 			rs[idx] = enc.PRF(j, tags[i])
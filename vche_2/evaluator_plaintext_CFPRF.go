@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"github.com/ldsec/lattigo/v2/ring"
 	"veritas/vche/vche"
-	"golang.org/x/crypto/blake2b"
 )
 
 type VerifPlaintext struct {
@@ -290,28 +289,30 @@ type encoderPlaintextCFPRF struct {
 	u            *ring.Poly
 	v            *ring.Poly
 	useRequad    bool
-	xofs1, xofs2 []blake2b.XOF
+	xofs1, xofs2 []vche.XOF
 }
 
 func NewEncoderPlaintextCFPRF(parameters Parameters, K []vche.PRFKey) EncoderPlaintextCFPRF {
-	xofs1, xofs2 := make([]blake2b.XOF, len(K)), make([]blake2b.XOF, len(K))
+	xofs1, xofs2 := make([]vche.XOF, len(K)), make([]vche.XOF, len(K))
 	for i := range xofs1 {
-		xofs1[i] = vche.NewXOF(K[i].K1)
-		xofs2[i] = vche.NewXOF(K[i].K2)
+		xofs1[i] = parameters.NewXOF(K[i].K1)
+		xofs2[i] = parameters.NewXOF(K[i].K2)
 	}
 	return &encoderPlaintextCFPRF{parameters, K, nil, nil, false, xofs1, xofs2}
 }
 
 func NewEncoderPlaintextCFPRFRequad(parameters Parameters, K []vche.PRFKey) EncoderPlaintextCFPRF {
-	xofs1, xofs2 := make([]blake2b.XOF, len(K)), make([]blake2b.XOF, len(K))
+	xofs1, xofs2 := make([]vche.XOF, len(K)), make([]vche.XOF, len(K))
 	for i := range xofs1 {
-		xofs1[i] = vche.NewXOF(K[i].K1)
-		xofs2[i] = vche.NewXOF(K[i].K2)
+		xofs1[i] = parameters.NewXOF(K[i].K1)
+		xofs2[i] = parameters.NewXOF(K[i].K2)
 	}
 	return &encoderPlaintextCFPRF{parameters, K, nil, nil, true, xofs1, xofs2}
 }
 
 func (enc encoderPlaintextCFPRF) Encode(tags []vche.Tag, p *VerifPlaintext) {
+	tags = vche.PadTags(tags, enc.params.NSlots)
+
 	a := make([]uint64, enc.params.NumDistinctPRFKeys)
 	b := make([]uint64, enc.params.NumDistinctPRFKeys)
 
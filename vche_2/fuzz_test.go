@@ -0,0 +1,238 @@
+package vche_2
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/ldsec/lattigo/v2/bfv"
+	"github.com/ldsec/lattigo/v2/rlwe"
+	"github.com/stretchr/testify/require"
+	"veritas/vche/vche"
+)
+
+// Kinds of the mutations of FuzzDecodeUint
+const (
+	mutateSlot         = iota // adds Delta to a slot of a component of the plaintext
+	mutateCoeff               // adds Delta to a coefficient of a component of the plaintext in the RNS representation
+	mutateVerif               // adds Delta to a coefficient of the verification object
+	truncateComponents        // drops the components of the plaintext from Index on
+	appendComponent           // appends a copy of a component to the plaintext
+	numDecodeMutationKinds
+)
+
+// Kinds of the mutations of FuzzPolynomialProtocol, which add Delta to a slot of a message of the prover
+const (
+	mutateResult      = iota // y_0
+	mutateEvaluations        // a component of (w_0, ..., w_d)
+	mutateCombination        // HH
+	mutatePacked             // (HH, d, w_0, ..., w_d), where slot 1 holds the number of evaluations
+	numProtocolMutationKinds
+)
+
+func FuzzDecodeUint(f *testing.F) {
+	params, err := NewParametersFromLiteral(DefaultParams[0])
+	require.NoError(f, err)
+	sk := NewKeyGenerator(params).GenSecretKeyFromKDF(vche.NewKDF([]byte("FuzzDecodeUint/seed"), "vche_2"))
+	encoder := NewEncoder(params, sk.K, sk.Alpha, false)
+	encoderPlaintext := NewEncoderPlaintext(params, sk.K)
+	bfvEncoder := bfv.NewEncoder(params.Parameters)
+	ringQ := params.RingQ()
+	tags := vche.GetIndexTags([]byte("FuzzDecodeUint"), params.NSlots)
+
+	f.Add([]byte("values"), []byte{})
+	for kind := 0; kind < numDecodeMutationKinds; kind++ {
+		f.Add([]byte{}, vche.Mutation{Kind: byte(kind), Index: 1, Delta: 1}.Bytes())
+	}
+	f.Add([]byte{1, 2, 3}, vche.Mutation{Kind: truncateComponents}.Bytes())
+	f.Add([]byte{1, 2, 3}, append(vche.Mutation{Kind: mutateSlot, Delta: params.T()}.Bytes(), vche.Mutation{Kind: numDecodeMutationKinds + mutateCoeff, Index: 7, Delta: 3}.Bytes()...))
+
+	f.Fuzz(func(t *testing.T, values, mutations []byte) {
+		coeffs := make([]uint64, params.NSlots)
+		for i := range coeffs {
+			if len(values) > 0 {
+				coeffs[i] = binary.BigEndian.Uint64(append(values[i%len(values):], make([]byte, 8)...)) % params.T()
+			}
+		}
+		pt := encoder.EncodeUintNew(coeffs, tags)
+		verif := encoderPlaintext.EncodeNew(tags)
+
+		for _, m := range vche.ParseMutations(mutations) {
+			kind := int(m.Kind) % numDecodeMutationKinds
+			if len(pt.Plaintexts) == 0 && kind != mutateVerif {
+				continue
+			}
+			switch kind {
+			case mutateSlot:
+				c := pt.Plaintexts[int(m.Kind)/numDecodeMutationKinds%len(pt.Plaintexts)]
+				// Decode on a copy, as BFV changes the plaintext during decoding
+				cp := bfv.NewPlaintext(params.Parameters)
+				cp.Plaintext.Copy(c.Plaintext)
+				slots := bfvEncoder.DecodeUintNew(cp)
+				slots[int(m.Index)%len(slots)] = (slots[int(m.Index)%len(slots)] + m.Delta%params.T()) % params.T()
+				bfvEncoder.EncodeUint(slots, c)
+			case mutateCoeff:
+				c := pt.Plaintexts[int(m.Kind)/numDecodeMutationKinds%len(pt.Plaintexts)]
+				level := int(m.Delta>>56) % len(c.Value.Coeffs)
+				q := ringQ.Modulus[level]
+				coeff := &c.Value.Coeffs[level][int(m.Index)%ringQ.N]
+				*coeff = (*coeff + m.Delta%q) % q
+			case mutateVerif:
+				c := &verif.Coeffs[0][int(m.Index)%len(verif.Coeffs[0])]
+				*c = (*c + m.Delta%params.T()) % params.T()
+			case truncateComponents:
+				pt.Plaintexts = pt.Plaintexts[:int(m.Index)%(len(pt.Plaintexts)+1)]
+			case appendComponent:
+				c := bfv.NewPlaintext(params.Parameters)
+				c.Plaintext.Copy(pt.Plaintexts[int(m.Index)%len(pt.Plaintexts)].Plaintext)
+				pt.Plaintexts = append(pt.Plaintexts, c)
+			}
+		}
+
+		var res []uint64
+		if vche.Rejects(func() { res = encoder.DecodeUintNew(pt, verif) }) {
+			return
+		}
+		require.Equal(t, coeffs, res, "accepted an incorrect result")
+	})
+}
+
+func FuzzPolynomialProtocol(f *testing.F) {
+	params, err := NewParametersFromLiteral(DefaultParams[1])
+	require.NoError(f, err)
+	kgen := NewKeyGenerator(params)
+	sk := kgen.GenSecretKeyFromKDF(vche.NewKDF([]byte("FuzzPolynomialProtocol/seed"), "vche_2"))
+	p, v := NewProverVerifier(params, sk, kgen.GenRotationKeysForInnerSum(sk))
+	encoder := NewEncoder(params, sk.K, sk.Alpha, false)
+	tags := vche.GetIndexTags([]byte("FuzzPolynomialProtocol"), params.NSlots)
+	coeffs := vche.GetRandomCoeffs(params.NSlots, params.T())
+	ctxt := NewEncryptor(params, sk).EncryptNew(encoder.EncodeUintNew(coeffs, tags))
+	verif := NewEncoderPlaintext(params, sk.K).EncodeNew(tags)
+
+	f.Add([]byte{})
+	f.Add(vche.Mutation{Kind: mutateResult, Index: 3, Delta: 1}.Bytes())
+	f.Add(vche.Mutation{Kind: mutatePacked, Index: 1, Delta: params.T() - 1}.Bytes())
+	f.Add(vche.Mutation{Kind: mutatePacked, Index: 1, Delta: 1 << 20}.Bytes())
+	f.Add(append(vche.Mutation{Kind: mutateEvaluations, Index: 2, Delta: 5}.Bytes(), vche.Mutation{Kind: mutateCombination, Delta: 5}.Bytes()...))
+
+	f.Fuzz(func(t *testing.T, mutations []byte) {
+		prover := &mutatingProver{p, vche.ParseMutations(mutations), bfv.NewEncoder(params.Parameters), bfv.NewEvaluator(params.Parameters, rlwe.EvaluationKey{}), params}
+
+		var res []uint64
+		if vche.Rejects(func() { res = RunPolynomialProtocolUint(prover, v, ctxt, verif) }) {
+			return
+		}
+		require.Equal(t, coeffs, res, "accepted an incorrect result")
+	})
+}
+
+// mutatingProver is a prover that adds the deltas of its mutations to the slots of its messages.
+type mutatingProver struct {
+	Prover
+	mutations []vche.Mutation
+	encoder   bfv.Encoder
+	evaluator bfv.Evaluator
+	params    Parameters
+}
+
+// mutate adds to ct the deltas of the mutations of the given kind, and of the given component for mutateEvaluations.
+func (p *mutatingProver) mutate(ct *bfv.Ciphertext, kind, component int) *bfv.Ciphertext {
+	ct = ct.CopyNew()
+	for _, m := range p.mutations {
+		if int(m.Kind)%numProtocolMutationKinds != kind || (kind == mutateEvaluations && int(m.Kind)/numProtocolMutationKinds != component) {
+			continue
+		}
+		slots := make([]uint64, p.params.N())
+		slots[int(m.Index)%len(slots)] = m.Delta % p.params.T()
+		pt := bfv.NewPlaintext(p.params.Parameters)
+		p.encoder.EncodeUint(slots, pt)
+		p.evaluator.Add(ct, pt, ct)
+	}
+	return ct
+}
+
+func (p *mutatingProver) GetResult(ctxt *Ciphertext) *bfv.Ciphertext {
+	return p.mutate(p.Prover.GetResult(ctxt), mutateResult, 0)
+}
+
+func (p *mutatingProver) EvaluateAt(ctxt *Ciphertext, x uint64) *Ciphertext {
+	res := p.Prover.EvaluateAt(ctxt, x)
+	for i := range res.Ciphertexts {
+		res.Ciphertexts[i] = p.mutate(res.Ciphertexts[i], mutateEvaluations, i)
+	}
+	return res
+}
+
+func (p *mutatingProver) LinearlyCombine(ctxt *Ciphertext, x uint64) *bfv.Ciphertext {
+	return p.mutate(p.Prover.LinearlyCombine(ctxt, x), mutateCombination, 0)
+}
+
+func (p *mutatingProver) Pack(HH *bfv.Ciphertext, ws *Ciphertext) *ScalarCiphertext {
+	return p.mutate(p.Prover.Pack(HH, ws), mutatePacked, 0)
+}
+
+func FuzzUnmarshalCiphertext(f *testing.F) {
+	ct, _, _ := fuzzEncodings(f)
+	addEncodingSeeds(f, ct)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		require.NoError(t, vche.CheckRoundTrip(data, func(b []byte) (*Ciphertext, error) {
+			ct := new(Ciphertext)
+			return ct, ct.UnmarshalBinary(b)
+		}))
+	})
+}
+
+func FuzzUnmarshalPoly(f *testing.F) {
+	_, verif, _ := fuzzEncodings(f)
+	addEncodingSeeds(f, verif)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		require.NoError(t, vche.CheckRoundTrip(data, func(b []byte) (*Poly, error) {
+			verif := new(Poly)
+			return verif, verif.UnmarshalBinary(b)
+		}))
+	})
+}
+
+func FuzzUnmarshalCompressedCiphertext(f *testing.F) {
+	_, _, cc := fuzzEncodings(f)
+	addEncodingSeeds(f, cc)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		require.NoError(t, vche.CheckRoundTrip(data, func(b []byte) (*CompressedCiphertext, error) {
+			cc := new(CompressedCiphertext)
+			return cc, cc.UnmarshalBinary(b)
+		}))
+	})
+}
+
+// fuzzEncodings returns the encodings of a ciphertext, of its verification object and of its compressed ciphertext, to
+// seed the fuzz tests of the unmarshalers.
+func fuzzEncodings(f *testing.F) (ct, verif, cc []byte) {
+	params, err := NewParametersFromLiteral(DefaultParams[0])
+	require.NoError(f, err)
+	sk := NewKeyGenerator(params).GenSecretKeyFromKDF(vche.NewKDF([]byte("FuzzUnmarshal/seed"), "vche_2"))
+	tags := vche.GetIndexTags([]byte("FuzzUnmarshal"), params.NSlots)
+	ctxt := NewEncryptor(params, sk).EncryptNew(NewEncoder(params, sk.K, sk.Alpha, false).EncodeUintNew(vche.GetRandomCoeffs(params.NSlots, params.T()), tags))
+	compressed, err := Compress(params, ctxt, vche.NewNoiseModel(params).Fresh())
+	require.NoError(f, err)
+
+	ct, err = ctxt.MarshalBinary()
+	require.NoError(f, err)
+	verif, err = NewEncoderPlaintext(params, sk.K).EncodeNew(tags).MarshalBinary()
+	require.NoError(f, err)
+	cc, err = compressed.MarshalBinary()
+	require.NoError(f, err)
+	return ct, verif, cc
+}
+
+// addEncodingSeeds adds a valid encoding to the seed corpus, along with its truncations and a copy whose first count or
+// length is the largest possible.
+func addEncodingSeeds(f *testing.F, data []byte) {
+	f.Add(data)
+	f.Add(data[:len(data)-1])
+	f.Add(data[:len(data)/2])
+	f.Add([]byte{})
+	huge := append([]byte{0xff, 0xff, 0xff, 0xff}, data[4:]...)
+	f.Add(huge)
+}
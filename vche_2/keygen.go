@@ -13,10 +13,13 @@ import (
 
 type KeyGenerator interface {
 	GenSecretKey() (sk *SecretKey)
+	GenSecretKeyFromKDF(kdf vche.KDF) (sk *SecretKey)
 	GenPublicKey(sk *SecretKey) (pk *PublicKey)
 	GenKeyPair() (sk *SecretKey, pk *PublicKey)
 	GenRelinearizationKey(sk *SecretKey, maxDegree int) (evk *RelinearizationKey)
 	GenSwitchingKey(skInput, skOutput *SecretKey) (newevakey *SwitchingKey)
+	GenRecipientSwitchingKey(sk *SecretKey, recipient *rlwe.SecretKey) *SwitchingKey
+	GenVerificationKey() (sk *SecretKey)
 	GenRotationKeys(galEls []uint64, sk *SecretKey) (rks *RotationKeySet)
 	GenRotationKeysForRotations(ks []int, inclueSwapRows bool, sk *SecretKey) (rks *RotationKeySet)
 	GenRotationKeysForInnerSum(sk *SecretKey) (rks *RotationKeySet)
@@ -36,15 +39,38 @@ func (keygen *keyGenerator) GenSecretKey() (sk *SecretKey) {
 	if err != nil {
 		panic(err)
 	}
+	return keygen.genSecretKey(keygen.KeyGenerator.GenSecretKey(), func(i int) vche.PRFKey { return vche.NewPRFKey(8) }, prng)
+}
+
+// GenSecretKeyFromKDF deterministically derives the RLWE secret key, the PRF keys and the alphas from kdf.
+func (keygen *keyGenerator) GenSecretKeyFromKDF(kdf vche.KDF) (sk *SecretKey) {
+	return keygen.genSecretKey(
+		vche.GenSecretKeyFromPRNG(keygen.params.Parameters.Parameters, kdf.PRNG(vche.LabelSecretKey)),
+		func(i int) vche.PRFKey { return kdf.PRFKey(fmt.Sprintf("%s/%d", vche.LabelPRFKey, i), 8) },
+		kdf.PRNG(vche.LabelAlpha),
+	)
+}
 
+// GenVerificationKey generates the verification secrets of an auditor, i.e., the PRF keys and the alphas, without RLWE
+// secret key, for the parties of vche.Protocols that share the RLWE secret key, see NewCollectiveKeys. The verification
+// secrets are not secret-shared: the auditor holds them alone.
+func (keygen *keyGenerator) GenVerificationKey() (sk *SecretKey) {
+	prng, err := utils.NewPRNG()
+	if err != nil {
+		panic(err)
+	}
+	return keygen.genSecretKey(nil, func(i int) vche.PRFKey { return vche.NewPRFKey(8) }, prng)
+}
+
+func (keygen *keyGenerator) genSecretKey(rlweSk *rlwe.SecretKey, genPRFKey func(i int) vche.PRFKey, prng utils.PRNG) (sk *SecretKey) {
 	sk = &SecretKey{
-		SecretKey: keygen.KeyGenerator.GenSecretKey(),
+		SecretKey: rlweSk,
 		K:         make([]vche.PRFKey, keygen.params.NumDistinctPRFKeys),
 		Alpha:     make([]uint64, keygen.params.NumDistinctPRFKeys),
 		alphaInv:  make([]uint64, keygen.params.NumDistinctPRFKeys),
 	}
 	for i := 0; i < keygen.params.NumDistinctPRFKeys; i++ {
-		sk.K[i] = vche.NewPRFKey(8)
+		sk.K[i] = genPRFKey(i)
 
 		alpha := uint64(0)
 		for alpha == 0 {
@@ -78,6 +104,12 @@ func (keygen *keyGenerator) GenSwitchingKey(skInput, skOutput *SecretKey) (newev
 	return keygen.KeyGenerator.GenSwitchingKey(skInput.SecretKey, skOutput.SecretKey)
 }
 
+// GenRecipientSwitchingKey generates the key that switches the ciphertexts of sk to the key of a recipient, which does
+// not need the alphas of sk, see NewDelegator.
+func (keygen *keyGenerator) GenRecipientSwitchingKey(sk *SecretKey, recipient *rlwe.SecretKey) *SwitchingKey {
+	return keygen.KeyGenerator.GenSwitchingKey(sk.SecretKey, recipient)
+}
+
 func (keygen *keyGenerator) GenRotationKeys(galEls []uint64, sk *SecretKey) (rks *RotationKeySet) {
 	return keygen.KeyGenerator.GenRotationKeys(galEls, sk.SecretKey)
 }
@@ -0,0 +1,113 @@
+package vche_2
+
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/ldsec/lattigo/v2/bfv"
+	"veritas/vche/vche"
+)
+
+// MarshalBinary encodes the ciphertext, component by component, in a slice of bytes.
+func (c *Ciphertext) MarshalBinary() ([]byte, error) {
+	data := appendUint32(nil, uint32(len(c.Ciphertexts)))
+	for _, ct := range c.Ciphertexts {
+		b, err := ct.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		data = appendUint32(data, uint32(len(b)))
+		data = append(data, b...)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary decodes a slice of bytes generated by MarshalBinary on the ciphertext.
+func (c *Ciphertext) UnmarshalBinary(data []byte) error {
+	// Each component takes at least its length prefix
+	numComponents, data, err := vche.ReadCount(data, 4, "components")
+	if err != nil {
+		return err
+	}
+	c.Ciphertexts = make([]*bfv.Ciphertext, numComponents)
+	for i := range c.Ciphertexts {
+		if len(data) < 4 {
+			return fmt.Errorf("missing length of component %d", i)
+		}
+		n := int(binary.BigEndian.Uint32(data))
+		if len(data)-4 < n {
+			return fmt.Errorf("component %d: expected %d bytes, got %d", i, n, len(data)-4)
+		}
+		if c.Ciphertexts[i], err = vche.UnmarshalCiphertext(data[4 : 4+n]); err != nil {
+			return fmt.Errorf("component %d: %w", i, err)
+		}
+		data = data[4+n:]
+	}
+	if len(data) != 0 {
+		return fmt.Errorf("%d trailing bytes", len(data))
+	}
+	return nil
+}
+
+// GetDataLen returns the length in bytes of the ciphertext as encoded by MarshalBinary.
+func (c *Ciphertext) GetDataLen(WithMetaData bool) int {
+	dataLen := 4
+	for _, ct := range c.Ciphertexts {
+		dataLen += 4 + ct.GetDataLen(WithMetaData)
+	}
+	return dataLen
+}
+
+// MarshalBinary encodes the verification object, i.e., its polynomial and its shift, in a slice of bytes. A nil shift
+// is encoded as empty.
+func (poly *Poly) MarshalBinary() ([]byte, error) {
+	p, err := poly.Poly.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	var shift []byte
+	if poly.Shift != nil {
+		if shift, err = poly.Shift.MarshalBinary(); err != nil {
+			return nil, err
+		}
+	}
+	data := appendUint32(appendUint32(nil, uint32(len(p))), uint32(len(shift)))
+	return append(append(data, p...), shift...), nil
+}
+
+// UnmarshalBinary decodes a slice of bytes generated by MarshalBinary on the verification object.
+func (poly *Poly) UnmarshalBinary(data []byte) error {
+	if len(data) < 8 {
+		return fmt.Errorf("missing lengths of the polynomial and its shift")
+	}
+	n, m := uint64(binary.BigEndian.Uint32(data)), uint64(binary.BigEndian.Uint32(data[4:]))
+	data = data[8:]
+	if n+m != uint64(len(data)) {
+		return fmt.Errorf("expected %d bytes, got %d", n+m, len(data))
+	}
+	var err error
+	if poly.Poly, err = vche.UnmarshalPoly(data[:n]); err != nil {
+		return fmt.Errorf("polynomial: %w", err)
+	}
+	poly.Shift = nil
+	if m > 0 {
+		if poly.Shift, err = vche.UnmarshalPoly(data[n:]); err != nil {
+			return fmt.Errorf("shift: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetDataLen returns the length in bytes of the verification object as encoded by MarshalBinary.
+func (poly *Poly) GetDataLen(WithMetaData bool) int {
+	dataLen := 8 + poly.Poly.GetDataLen(WithMetaData)
+	if poly.Shift != nil {
+		dataLen += poly.Shift.GetDataLen(WithMetaData)
+	}
+	return dataLen
+}
+
+func appendUint32(data []byte, x uint32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, x)
+	return append(data, b...)
+}
@@ -0,0 +1,45 @@
+package vche_2
+
+import (
+	"github.com/ldsec/lattigo/v2/bfv"
+	"github.com/ldsec/lattigo/v2/rlwe"
+	"veritas/vche/vche"
+)
+
+// NewCollectiveKeys returns the public key and the evaluation key made of the collective keys that the aggregator of
+// vche.Protocols combines from the shares of the parties, which share the RLWE secret key. The rotation keys may be
+// nil.
+func NewCollectiveKeys(pk *rlwe.PublicKey, rlk *rlwe.RelinearizationKey, rtks *rlwe.RotationKeySet) (*PublicKey, *EvaluationKey) {
+	return pk, &EvaluationKey{Rlk: rlk, Rtks: rtks}
+}
+
+type collectiveDecryptor struct {
+	params    Parameters
+	protocols *vche.Protocols
+	shares    vche.DecryptionShares
+}
+
+// NewCollectiveDecryptor returns the decryptor with which the aggregator of the protocols decrypts ciphertexts from the
+// decryption shares of the parties. The auditor then verifies the plaintexts with the Encoder of its verification key.
+// The ciphertexts must be relinearized.
+func NewCollectiveDecryptor(params Parameters, protocols *vche.Protocols, shares vche.DecryptionShares) Decryptor {
+	return &collectiveDecryptor{params, protocols, shares}
+}
+
+// InternalDecryptor returns nil, as no party holds the secret key.
+func (dec *collectiveDecryptor) InternalDecryptor() bfv.Decryptor {
+	return nil
+}
+
+func (dec *collectiveDecryptor) Decrypt(ciphertext *Ciphertext, plaintext *Plaintext) {
+	plaintext.Plaintexts = make([]*bfv.Plaintext, len(ciphertext.Ciphertexts))
+	for i, ct := range ciphertext.Ciphertexts {
+		plaintext.Plaintexts[i] = dec.protocols.Decrypt(ct, dec.shares(ct)...)
+	}
+}
+
+func (dec *collectiveDecryptor) DecryptNew(ciphertext *Ciphertext) (plaintext *Plaintext) {
+	plaintext = NewPlaintext(dec.params)
+	dec.Decrypt(ciphertext, plaintext)
+	return plaintext
+}
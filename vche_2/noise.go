@@ -0,0 +1,16 @@
+package vche_2
+
+import (
+	"veritas/vche/vche"
+)
+
+// NoiseBudgets returns, for each BFV component of ct, the number of bits of noise that it can still absorb before
+// decryption fails, as measured with sk. A negative budget means that decoding ct fails because of the noise, and not
+// because the server cheated.
+func NoiseBudgets(params Parameters, sk *SecretKey, ct *Ciphertext) []float64 {
+	budgets := make([]float64, ct.Len())
+	for i, c := range ct.Ciphertexts {
+		budgets[i] = vche.NoiseBudget(params, sk.SecretKey, c)
+	}
+	return budgets
+}
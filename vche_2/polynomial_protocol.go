@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"testing"
+	"veritas/vche/vche"
 )
 
 func runPolynomialProtocol(p Prover, v Verifier, ctxt *Ciphertext, verif *Poly) []uint64 {
@@ -16,6 +17,7 @@ func runPolynomialProtocol(p Prover, v Verifier, ctxt *Ciphertext, verif *Poly)
 
 	// P -> V: y_0
 	y0Ctxt := p.GetResult(ctxt)
+	v.Session().Send(vche.ServerToClient, vche.ResultMessage, y0Ctxt)
 	y0 := v.Dec(y0Ctxt)
 
 	// P <- V: delta, beta
@@ -23,11 +25,13 @@ func runPolynomialProtocol(p Prover, v Verifier, ctxt *Ciphertext, verif *Poly)
 	// V: Check w_0 ?= y_0(delta)
 	// V: Check HH ?= w_0 + w_1 * beta + ... + w_d * (beta ^ d)
 	beta, delta := v.GetRandomPoint(), v.GetRandomPoint()
+	v.Session().Send(vche.ClientToServer, vche.VerificationMessage, beta, delta)
 
 	wsCtxt := p.EvaluateAt(ctxt, delta)
 	HHCtxt := p.LinearlyCombine(wsCtxt, beta)
 
 	m2Ctxt := p.Pack(HHCtxt, wsCtxt)
+	v.Session().Send(vche.ServerToClient, vche.VerificationMessage, m2Ctxt)
 
 	m2 := v.Dec(m2Ctxt)
 	HH, ws := v.Unpack(m2)
@@ -64,6 +68,7 @@ func BenchmarkPolynomialProtocolProver(p Prover, v Verifier, ctxt *Ciphertext, v
 	b.StartTimer()
 	y0Ctxt := p.GetResult(ctxt)
 	b.StopTimer()
+	v.Session().Send(vche.ServerToClient, vche.ResultMessage, y0Ctxt)
 	y0 := v.Dec(y0Ctxt)
 
 	// P <- V: delta, beta
@@ -71,6 +76,7 @@ func BenchmarkPolynomialProtocolProver(p Prover, v Verifier, ctxt *Ciphertext, v
 	// V: Check w_0 ?= y_0(delta)
 	// V: Check HH ?= w_0 + w_1 * beta + ... + w_d * (beta ^ d)
 	beta, delta := v.GetRandomPoint(), v.GetRandomPoint()
+	v.Session().Send(vche.ClientToServer, vche.VerificationMessage, beta, delta)
 
 	b.StartTimer()
 	wsCtxt := p.EvaluateAt(ctxt, delta)
@@ -78,6 +84,7 @@ func BenchmarkPolynomialProtocolProver(p Prover, v Verifier, ctxt *Ciphertext, v
 
 	m2Ctxt := p.Pack(HHCtxt, wsCtxt)
 	b.StopTimer()
+	v.Session().Send(vche.ServerToClient, vche.VerificationMessage, m2Ctxt)
 
 	m2 := v.Dec(m2Ctxt)
 	HH, ws := v.Unpack(m2)
@@ -112,6 +119,7 @@ func BenchmarkPolynomialProtocolVerifier(p Prover, v Verifier, ctxt *Ciphertext,
 
 	// P -> V: y_0
 	y0Ctxt := p.GetResult(ctxt)
+	v.Session().Send(vche.ServerToClient, vche.ResultMessage, y0Ctxt)
 	b.StartTimer()
 	y0 := v.Dec(y0Ctxt)
 	b.StopTimer()
@@ -123,11 +131,13 @@ func BenchmarkPolynomialProtocolVerifier(p Prover, v Verifier, ctxt *Ciphertext,
 	b.StartTimer()
 	beta, delta := v.GetRandomPoint(), v.GetRandomPoint()
 	b.StopTimer()
+	v.Session().Send(vche.ClientToServer, vche.VerificationMessage, beta, delta)
 
 	wsCtxt := p.EvaluateAt(ctxt, delta)
 	HHCtxt := p.LinearlyCombine(wsCtxt, beta)
 
 	m2Ctxt := p.Pack(HHCtxt, wsCtxt)
+	v.Session().Send(vche.ServerToClient, vche.VerificationMessage, m2Ctxt)
 
 	b.StartTimer()
 	m2 := v.Dec(m2Ctxt)
@@ -1,6 +1,7 @@
 package vche_2
 
 import (
+	"fmt"
 	"github.com/ldsec/lattigo/v2/bfv"
 	"github.com/ldsec/lattigo/v2/ring"
 	"github.com/ldsec/lattigo/v2/rlwe"
@@ -126,6 +127,8 @@ type Verifier interface {
 	ComputeRequadCFPRF(c3, c4 *bfv.Ciphertext, verif *VerifPlaintext) (c1Out, c2Out *bfv.Ciphertext, verifOut *VerifPlaintext)
 	Params() Parameters
 	SK() *SecretKey
+	WithSession(session *vche.Session) Verifier // Records the messages of the protocols run by the verifier in session
+	Session() *vche.Session
 }
 
 type verifier struct {
@@ -133,18 +136,27 @@ type verifier struct {
 	bfv.Encoder
 	params Parameters
 	*SecretKey
+	session *vche.Session
 }
 
 func NewVerifier(params Parameters, sk *SecretKey) Verifier {
-	return &verifier{bfv.NewDecryptor(params.Parameters, sk.SecretKey), bfv.NewEncoder(params.Parameters), params, sk}
+	return &verifier{bfv.NewDecryptor(params.Parameters, sk.SecretKey), bfv.NewEncoder(params.Parameters), params, sk, nil}
 }
 
 func (v *verifier) WithDecryptor(decryptor bfv.Decryptor) Verifier {
-	return &verifier{decryptor, v.Encoder, v.params, v.SecretKey}
+	return &verifier{decryptor, v.Encoder, v.params, v.SecretKey, v.session}
 }
 
 func (v *verifier) WithKey(_ rlwe.EvaluationKey) Verifier {
-	return &verifier{v.Decryptor, v.Encoder, v.params, v.SecretKey}
+	return &verifier{v.Decryptor, v.Encoder, v.params, v.SecretKey, v.session}
+}
+
+func (v *verifier) WithSession(session *vche.Session) Verifier {
+	return &verifier{v.Decryptor, v.Encoder, v.params, v.SecretKey, session}
+}
+
+func (v *verifier) Session() *vche.Session {
+	return v.session
 }
 
 func (v *verifier) Params() Parameters {
@@ -185,7 +197,7 @@ func (v *verifier) LinearlyCombine(evals []uint64, x uint64) uint64 {
 }
 func (v *verifier) CheckEqual(lhs, rhs uint64) {
 	if lhs != rhs {
-		panic("verification failed : equality check failed")
+		panic(fmt.Errorf("%w : equality check failed", vche.ErrVerification))
 	}
 }
 
@@ -213,6 +225,9 @@ func (v *verifier) Enc2(p *bfv.Plaintext) *bfv.Ciphertext {
 func (v *verifier) Unpack(m []uint64) (HH uint64, ws []uint64) {
 	HH = m[0]
 	l := m[1]
+	if l == 0 || l > uint64(len(m)-2) {
+		panic(fmt.Errorf("%w : invalid number of evaluations %d", vche.ErrVerification, l))
+	}
 	ws = m[2 : l+2]
 	return HH, ws
 }
@@ -25,10 +25,13 @@ func RunRequadratizationProtocol(p Prover, v Verifier, ctxt *Ciphertext, verif *
 		panic(fmt.Errorf("cannot requadratize the ciphertext %v with outer degree %d > 4", ctxt, ctxt.Len()-1))
 	}
 
+	v.Session().Send(vche.ServerToClient, vche.RequadratizationMessage, c3, c4)
+
 	// V: Compute c1_bar, c2_bar; Update state
 	c1Bar, c2Bar, resV := v.ComputeRequad(c3, c4, verif)
 
 	// P <- V: c1_bar, c2_bar
+	v.Session().Send(vche.ClientToServer, vche.RequadratizationMessage, c1Bar, c2Bar)
 
 	// P: Compute ctxt = (c0, c1 + c1_bar, c2 + c2_bar)
 	eval := bfv.NewEvaluator(v.Params().Parameters, rlwe.EvaluationKey{}) // TODO: get from prover
@@ -60,12 +63,15 @@ func RunRequadratizationProtocolCFPRF(p Prover, v Verifier, ctxt *Ciphertext, ve
 		panic(fmt.Errorf("cannot requadratize the ciphertext %v with outer degree %d > 4", ctxt, ctxt.Len()-1))
 	}
 
+	v.Session().Send(vche.ServerToClient, vche.RequadratizationMessage, c3, c4)
+
 	// V: Compute c1_bar, c2_bar; Update state
 	c1Bar, c2Bar, resV := v.ComputeRequadCFPRF(c3, c4, verif)
 
 	vche.NewVerifPlaintext(v.Params())
 
 	// P <- V: c1_bar, c2_bar
+	v.Session().Send(vche.ClientToServer, vche.RequadratizationMessage, c1Bar, c2Bar)
 
 	// P: Compute ctxt = (c0, c1 + c1_bar, c2 + c2_bar)
 	eval := bfv.NewEvaluator(v.Params().Parameters, rlwe.EvaluationKey{}) // TODO: get from prover
@@ -5,6 +5,7 @@ import (
 	"github.com/ldsec/lattigo/v2/bfv"
 	"github.com/ldsec/lattigo/v2/rlwe"
 	"log"
+	"veritas/vche/vche"
 	"testing"
 )
 
@@ -26,10 +27,13 @@ func BenchmarkRequadratizationProtocolProver(p Prover, v Verifier, ctxt *Ciphert
 		panic(fmt.Errorf("cannot requadratize the ciphertext %v with outer degree %d > 4", ctxt, ctxt.Len()-1))
 	}
 
+	v.Session().Send(vche.ServerToClient, vche.RequadratizationMessage, c3, c4)
+
 	// V: Compute c1_bar, c2_bar; Update state
 	c1Bar, c2Bar, resV := v.ComputeRequad(c3, c4, verif)
 
 	// P <- V: c1_bar, c2_bar
+	v.Session().Send(vche.ClientToServer, vche.RequadratizationMessage, c1Bar, c2Bar)
 
 	// P: Compute ctxt = (c0, c1 + c1_bar, c2 + c2_bar)
 	b.StartTimer()
@@ -64,12 +68,15 @@ func BenchmarkRequadratizationProtocolVerifier(p Prover, v Verifier, ctxt *Ciphe
 		panic(fmt.Errorf("cannot requadratize the ciphertext %v with outer degree %d > 4", ctxt, ctxt.Len()-1))
 	}
 
+	v.Session().Send(vche.ServerToClient, vche.RequadratizationMessage, c3, c4)
+
 	// V: Compute c1_bar, c2_bar; Update state
 	b.StartTimer()
 	c1Bar, c2Bar, resV := v.ComputeRequad(c3, c4, verif)
 	b.StopTimer()
 
 	// P <- V: c1_bar, c2_bar
+	v.Session().Send(vche.ClientToServer, vche.RequadratizationMessage, c1Bar, c2Bar)
 
 	// P: Compute ctxt = (c0, c1 + c1_bar, c2 + c2_bar)
 	eval := bfv.NewEvaluator(v.Params().Parameters, rlwe.EvaluationKey{}) // TODO: get from prover
@@ -0,0 +1,28 @@
+package vche_2
+
+import (
+	"veritas/vche/vche"
+)
+
+var _ vche.TypedEncoder[*Plaintext, *PlaintextMul, *Poly] = Encoder(nil)
+var _ vche.TypedEncoderPlaintext[*Poly] = EncoderPlaintext(nil)
+var _ vche.TypedEncoderPlaintext[*VerifPlaintext] = EncoderPlaintextCFPRF(nil)
+var _ vche.TypedEncryptor[*Plaintext, *Ciphertext] = Encryptor(nil)
+var _ vche.TypedDecryptor[*Ciphertext, *Plaintext] = Decryptor(nil)
+var _ vche.OperandEvaluator[Operand, *Ciphertext] = Evaluator(nil)
+
+// NewTypedEvaluator returns the evaluator of ciphertexts as a vche.TypedEvaluator.
+func NewTypedEvaluator(params Parameters, evaluationKey *EvaluationKey) vche.TypedEvaluator[*Ciphertext] {
+	return vche.NewTypedEvaluator[Operand, *Ciphertext](NewEvaluator(params, evaluationKey))
+}
+
+// NewTypedEvaluatorPlaintext returns the evaluator of the verification state as a vche.TypedEvaluator.
+func NewTypedEvaluatorPlaintext(params Parameters) vche.TypedEvaluator[*Poly] {
+	return NewEvaluatorPlaintext(params)
+}
+
+// NewTypedEvaluatorPlaintextCFPRF returns the evaluator of the verification state with closed-form PRFs as a
+// vche.TypedEvaluator.
+func NewTypedEvaluatorPlaintextCFPRF(params Parameters) vche.TypedEvaluator[*VerifPlaintext] {
+	return NewEvaluatorPlaintextCFPRF(params)
+}
@@ -0,0 +1,52 @@
+package vche_2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"veritas/vche/vche"
+)
+
+// sumOfSquares is evaluated by the same code on the ciphertexts and on their verification state.
+func sumOfSquares[T any](eval vche.TypedEvaluator[T], xs ...T) T {
+	res := eval.RelinearizeNew(eval.MulNew(xs[0], xs[0]))
+	for _, x := range xs[1:] {
+		eval.Add(res, eval.RelinearizeNew(eval.MulNew(x, x)), res)
+	}
+	return res
+}
+
+func TestTyped(t *testing.T) {
+	params, err := NewParametersFromLiteral(DefaultParams[0])
+	require.NoError(t, err)
+	testctx, err := genTestParams(params)
+	require.NoError(t, err)
+
+	cts, verifs := make([]*Ciphertext, 2), make([]*Poly, 2)
+	want := make([]uint64, params.NSlots)
+	for i := range cts {
+		var coeffs []uint64
+		coeffs, _, _, cts[i], verifs[i] = newTestVectors(testctx, testctx.encryptorSk, 1<<8)
+		for j := range want {
+			want[j] = (want[j] + coeffs[j]*coeffs[j]) % params.T()
+		}
+	}
+
+	res := sumOfSquares(NewTypedEvaluator(params, testctx.evk), cts...)
+	verif := sumOfSquares(NewTypedEvaluatorPlaintext(params), verifs...)
+	verifyTestVectors(testctx, testctx.decryptor, want, res, verif, false, t)
+
+	// The Generic evaluators adapt the typed ones, and check the types at runtime
+	c := vche.NewCircuit()
+	in := c.Inputs(2)
+	c.Output(c.Add(c.Relinearize(c.Mul(in[0], in[0])), c.Relinearize(c.Mul(in[1], in[1]))))
+	eval, evalPlaintext := NewGenericEvaluator(params, testctx.evk).ShallowCopy(), NewGenericEvaluatorPlaintext(params).ShallowCopy()
+	genericRes := c.Eval(eval, cts[0], cts[1])[0]
+	genericVerif := c.Eval(evalPlaintext, verifs[0], verifs[1])[0]
+	encoder := vche.NewGenericEncoderOf[*Plaintext, *PlaintextMul, *Poly](testctx.encoder)
+	decryptor := NewGenericDecryptor(params, testctx.sk)
+	require.Equal(t, want, encoder.DecodeUintNew(decryptor.DecryptNew(genericRes), genericVerif))
+	require.PanicsWithError(t, "expected *vche_2.Ciphertext, got *vche_2.Poly", func() { eval.RelinearizeNew(verifs[0]) })
+	require.PanicsWithError(t, "expected *vche_2.Plaintext, got *vche_2.Ciphertext", func() { encoder.DecodeUintNew(genericRes, genericVerif) })
+	require.True(t, vche.Rejects(func() { encoder.DecodeUintNew(decryptor.DecryptNew(genericRes), verifs[0]) }))
+}
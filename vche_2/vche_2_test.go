@@ -6,8 +6,10 @@ import (
 	"github.com/ldsec/lattigo/v2/bfv"
 	"github.com/ldsec/lattigo/v2/rlwe"
 	"github.com/ldsec/lattigo/v2/utils"
+	"github.com/stretchr/testify/require"
 	"veritas/vche/vche"
 	"math"
+	"math/big"
 	"runtime"
 	"testing"
 )
@@ -15,6 +17,9 @@ import (
 var flagLongTest = flag.Bool("long", false, "run the long test suite (all parameters). Overrides -short and requires -timeout=0.")
 var flagParamString = flag.String("params", "", "specify the test cryptographic parameters as a JSON string. Overrides -short and -long.")
 
+// smallLogN is the largest ring degree on which the tests of composite computations run.
+const smallLogN = 13
+
 func TestVCHE2(t *testing.T) {
 	defaultParams := DefaultParams // the default test runs for ring degree N=2^12, 2^13, 2^14, 2^15
 	if testing.Short() {
@@ -43,13 +48,20 @@ func TestVCHE2(t *testing.T) {
 			panic(err)
 		}
 
-		for _, testSet := range []func(testctx *testContext, t *testing.T){
+		testSets := []func(testctx *testContext, t *testing.T){
 			testEncoder,
+			testKeyGenerator,
 			testEvaluator,
 			testType1Fails,
 			testType2Fails,
-			//testMarshaller,
-		} {
+			testMarshaller,
+		}
+		if params.LogN() <= smallLogN {
+			// These tests hold several ciphertexts and evaluators at once, so they only run on the small parameters
+			testSets = append(testSets, testVector, testCircuit, testNoise, testCompression, testDelegation, testContributor, testMultiOwner, testMultiparty)
+		}
+
+		for _, testSet := range testSets {
 			testSet(testctx, t)
 			runtime.GC()
 		}
@@ -66,6 +78,247 @@ func testEncoder(testctx *testContext, t *testing.T) {
 		verifyTestVectors(testctx, nil, values, ptxt, verif, true, t)
 		verifyTestVectors(testctx, testctx.decryptor, values, ctxt, verif, true, t)
 	})
+
+	t.Run(testString("Encoder/Encode&Decode/Partial/", testctx.params), func(t *testing.T) {
+		n := testctx.params.NSlots/2 + 1
+		values := vche.GetRandomCoeffs(n, testctx.params.T())
+		tags := vche.GetRandomTags(n)
+		ctxt := testctx.encryptorSk.EncryptNew(testctx.encoder.EncodeUintNew(values, tags))
+		verif := testctx.evaluatorPlaintextEncoder.EncodeNew(tags)
+
+		valuesTest := make([]uint64, n)
+		testctx.encoder.DecodeUint(testctx.decryptor.DecryptNew(ctxt), verif, valuesTest)
+		require.Equal(t, values, valuesTest)
+
+		// The padded slots are authenticated: altering the last one is detected
+		pt := testctx.encoder.EncodeUintNew(values, tags)
+		bfvEncoder := bfv.NewEncoder(testctx.params.Parameters)
+		ms := bfvEncoder.DecodeUintNew(pt.Plaintexts[0])
+		for j := 0; j < testctx.params.NumReplications; j++ {
+			idx := (testctx.params.NSlots-1)*testctx.params.NumReplications + j
+			ms[idx] = (ms[idx] + 1) % testctx.params.T()
+		}
+		bfvEncoder.EncodeUint(ms, pt.Plaintexts[0])
+		require.Panics(t, func() { testctx.encoder.DecodeUintNew(pt, verif) })
+	})
+
+	for _, prfType := range vche.PRFTypes {
+		params := testctx.params.WithPRF(prfType)
+		t.Run(testString("Encoder/Encode&Decode/Uint/PRF="+prfType.String()+"/", params), func(t *testing.T) {
+			encoder := NewEncoder(params, testctx.sk.K, testctx.sk.Alpha, false)
+			encoderPlaintext := NewEncoderPlaintext(params, testctx.sk.K)
+
+			values := vche.GetRandomCoeffs(params.NSlots, params.T())
+			tags := vche.GetRandomTags(params.NSlots)
+			plaintext := encoder.EncodeUintNew(values, tags)
+			verif := encoderPlaintext.EncodeNew(tags)
+
+			valuesTest := encoder.DecodeUintNew(plaintext, verif)
+			require.True(t, utils.EqualSliceUint64(values, valuesTest[:params.NSlots]))
+		})
+	}
+}
+
+func testKeyGenerator(testctx *testContext, t *testing.T) {
+	t.Run(testString("KeyGenerator/GenSecretKeyFromKDF/", testctx.params), func(t *testing.T) {
+		seed := []byte("veritas key generator test seed")
+		sk1 := testctx.kgen.GenSecretKeyFromKDF(vche.NewKDF(seed, "client"))
+		sk2 := testctx.kgen.GenSecretKeyFromKDF(vche.NewKDF(seed, "client"))
+		sk3 := testctx.kgen.GenSecretKeyFromKDF(vche.NewKDF(seed, "other client"))
+
+		require.True(t, sk1.SecretKey.Value.Q.Equals(sk2.SecretKey.Value.Q))
+		require.True(t, sk1.SecretKey.Value.P.Equals(sk2.SecretKey.Value.P))
+		require.Equal(t, sk1.K, sk2.K)
+		require.Equal(t, sk1.Alpha, sk2.Alpha)
+		require.False(t, sk1.SecretKey.Value.Q.Equals(sk3.SecretKey.Value.Q))
+		require.NotEqual(t, sk1.K, sk3.K)
+		require.NotEqual(t, sk1.Alpha, sk3.Alpha)
+
+		// a ciphertext produced under the first key verifies and decrypts under the re-derived key
+		values := vche.GetRandomCoeffs(testctx.params.NSlots, testctx.params.T())
+		tags := vche.GetRandomTags(testctx.params.NSlots)
+		ctxt := NewEncryptor(testctx.params, sk1).EncryptNew(NewEncoder(testctx.params, sk1.K, sk1.Alpha, false).EncodeUintNew(values, tags))
+		verif := NewEncoderPlaintext(testctx.params, sk2.K).EncodeNew(tags)
+		valuesTest := NewEncoder(testctx.params, sk2.K, sk2.Alpha, false).DecodeUintNew(NewDecryptor(testctx.params, sk2).DecryptNew(ctxt), verif)
+		require.True(t, utils.EqualSliceUint64(values, valuesTest[:testctx.params.NSlots]))
+	})
+}
+
+func testVector(testctx *testContext, t *testing.T) {
+	t.Run(testString("Vector/", testctx.params), func(t *testing.T) {
+		T := testctx.params.T()
+		vectorEncoder := NewVectorEncoder(testctx.params, testctx.encoder)
+		vectorEncoderPlaintext := NewVectorEncoderPlaintext(testctx.params, testctx.evaluatorPlaintextEncoder)
+		evaluator := NewVectorEvaluator(testctx.evaluator.WithKey(testctx.innerSumEvk))
+		evaluatorPlaintext := NewVectorEvaluatorPlaintext(testctx.evaluatorPlaintext)
+
+		length := 2*testctx.params.NSlots + testctx.params.NSlots/2 + 1
+		a, b := vche.GetRandomCoeffs(length, T), vche.GetRandomCoeffs(length, T)
+		ctA := EncryptVectorNew(testctx.encryptorSk, vectorEncoder.EncodeUintNew(a, []byte("a")))
+		ctB := EncryptVectorNew(testctx.encryptorSk, vectorEncoder.EncodeUintNew(b, []byte("b")))
+		verifA := vectorEncoderPlaintext.EncodeNew([]byte("a"), length)
+		verifB := vectorEncoderPlaintext.EncodeNew([]byte("b"), length)
+		require.Equal(t, vche.NumChunks(length, testctx.params.NSlots), ctA.NumChunks())
+
+		// a * b + 3 * a - b
+		ct := evaluator.RelinearizeNew(evaluator.MulNew(ctA, ctB))
+		evaluator.Add(ct, evaluator.MulScalarNew(ctA, 3), ct)
+		evaluator.Sub(ct, ctB, ct)
+		verif := evaluatorPlaintext.RelinearizeNew(evaluatorPlaintext.MulNew(verifA, verifB))
+		evaluatorPlaintext.Add(verif, evaluatorPlaintext.MulScalarNew(verifA, 3), verif)
+		evaluatorPlaintext.Sub(verif, verifB, verif)
+
+		bigT := big.NewInt(0).SetUint64(T)
+		want := make([]uint64, length)
+		sum := big.NewInt(0)
+		for i := range want {
+			w := big.NewInt(0).Mul(big.NewInt(0).SetUint64(a[i]), big.NewInt(0).SetUint64(b[i]))
+			w.Add(w, big.NewInt(0).Mul(big.NewInt(0).SetUint64(a[i]), big.NewInt(3)))
+			w.Sub(w, big.NewInt(0).SetUint64(b[i]))
+			w.Mod(w, bigT)
+			want[i] = w.Uint64()
+			sum.Add(sum, w)
+		}
+		require.Equal(t, want, vectorEncoder.DecodeUintNew(DecryptVectorNew(testctx.decryptor, ct), verif))
+
+		// The sum of all the values of the vector lands in every slot
+		ctSum := evaluator.InnerSumNew(ct)
+		verifSum := evaluatorPlaintext.InnerSumNew(verif)
+		require.Equal(t, sum.Mod(sum, bigT).Uint64(), testctx.encoder.DecodeUintNew(testctx.decryptor.DecryptNew(ctSum), verifSum)[0])
+
+		// Tampering with a chunk is detected
+		evaluator.Add(ct, ctA, ct)
+		require.Panics(t, func() { vectorEncoder.DecodeUintNew(DecryptVectorNew(testctx.decryptor, ct), verif) })
+	})
+}
+
+func testMarshaller(testctx *testContext, t *testing.T) {
+	t.Run(testString("Marshaller/Ciphertext/", testctx.params), func(t *testing.T) {
+		values, _, _, ct, verif := newTestVectors(testctx, testctx.encryptorSk, testctx.params.T())
+		data, err := ct.MarshalBinary()
+		require.NoError(t, err)
+		require.Equal(t, len(data), vche.Size(ct))
+
+		ctNew := new(Ciphertext)
+		require.NoError(t, ctNew.UnmarshalBinary(data))
+		require.Equal(t, ct, ctNew)
+		require.Equal(t, values, testctx.encoder.DecodeUintNew(testctx.decryptor.DecryptNew(ctNew), verif)[:len(values)])
+
+		require.Error(t, ctNew.UnmarshalBinary(data[:len(data)-1]))
+		// Oversized counts and malformed lattigo ciphertexts are rejected without allocating or panicking
+		for _, data := range [][]byte{{0x7f, 0xff, 0xff, 0xff}, {0, 0, 0, 5, 1, 0, 0, 0, 1, 0, 1}, append(data, 0)} {
+			require.NotPanics(t, func() { require.Error(t, new(Ciphertext).UnmarshalBinary(data)) })
+		}
+	})
+
+	t.Run(testString("Marshaller/Poly/", testctx.params), func(t *testing.T) {
+		values, _, _, ct, verif := newTestVectors(testctx, testctx.encryptorSk, testctx.params.T())
+		data, err := verif.MarshalBinary()
+		require.NoError(t, err)
+		require.Equal(t, len(data), vche.Size(verif))
+
+		verifNew := new(Poly)
+		require.NoError(t, verifNew.UnmarshalBinary(data))
+		require.Equal(t, verif, verifNew)
+		require.Equal(t, values, testctx.encoder.DecodeUintNew(testctx.decryptor.DecryptNew(ct), verifNew)[:len(values)])
+
+		require.Error(t, verifNew.UnmarshalBinary(data[:len(data)-1]))
+		// Oversized lengths and malformed lattigo polynomials are rejected without allocating or panicking
+		for _, data := range [][]byte{{0x7f, 0xff, 0xff, 0xff, 0, 0, 0, 0}, {0, 0, 0, 4, 0, 0, 0, 0, 40, 1, 0, 0}, append(data, 0)} {
+			require.NotPanics(t, func() { require.Error(t, new(Poly).UnmarshalBinary(data)) })
+		}
+
+		// A verification object without shift round trips as well
+		verif.Shift = nil
+		data, err = verif.MarshalBinary()
+		require.NoError(t, err)
+		require.NoError(t, verifNew.UnmarshalBinary(data))
+		require.Equal(t, verif, verifNew)
+	})
+}
+
+func testCircuit(testctx *testContext, t *testing.T) {
+	t.Run(testString("Circuit/", testctx.params), func(t *testing.T) {
+		// 3 * x * y - y, and its inner sum
+		c := vche.NewCircuit()
+		x, y := c.Input(), c.Input()
+		w := c.Sub(c.MulScalar(c.Relinearize(c.Mul(x, y)), 3), y)
+		c.Output(w, c.InnerSum(w))
+
+		valuesX, _, _, ctX, verifX := newTestVectors(testctx, testctx.encryptorSk, testctx.params.T())
+		valuesY, _, _, ctY, verifY := newTestVectors(testctx, testctx.encryptorSk, testctx.params.T())
+
+		outs := c.Eval(NewGenericEvaluator(testctx.params, &testctx.innerSumEvk), ctX, ctY)
+		verifs := c.Eval(NewGenericEvaluatorPlaintext(testctx.params), verifX, verifY)
+
+		bigT := big.NewInt(0).SetUint64(testctx.params.T())
+		want := make([]uint64, len(valuesX))
+		sum := big.NewInt(0)
+		for i := range want {
+			v := big.NewInt(0).Mul(big.NewInt(0).SetUint64(valuesX[i]), big.NewInt(0).SetUint64(valuesY[i]))
+			v.Mul(v, big.NewInt(3))
+			v.Sub(v, big.NewInt(0).SetUint64(valuesY[i]))
+			v.Mod(v, bigT)
+			want[i] = v.Uint64()
+			sum.Add(sum, v)
+		}
+		sum.Mod(sum, bigT)
+
+		got := testctx.encoder.DecodeUintNew(testctx.decryptor.DecryptNew(outs[0].(*Ciphertext)), verifs[0].(*Poly))
+		require.Equal(t, want, got[:len(want)])
+		got = testctx.encoder.DecodeUintNew(testctx.decryptor.DecryptNew(outs[1].(*Ciphertext)), verifs[1].(*Poly))
+		require.Equal(t, sum.Uint64(), got[0])
+	})
+}
+
+func testNoise(testctx *testContext, t *testing.T) {
+	t.Run(testString("Noise/Estimate/", testctx.params), func(t *testing.T) {
+		c := vche.NewCircuit()
+		x, y := c.Input(), c.Input()
+		w := c.Sub(c.MulScalar(c.Relinearize(c.Mul(x, y)), 3), y)
+		c.Output(w, c.InnerSum(w))
+
+		_, _, _, ctX, _ := newTestVectors(testctx, testctx.encryptorPk, testctx.params.T())
+		_, _, _, ctY, _ := newTestVectors(testctx, testctx.encryptorPk, testctx.params.T())
+
+		warned := false
+		eval := vche.NewNoiseEstimator(testctx.params, NewGenericEvaluator(testctx.params, &testctx.innerSumEvk), func(string, float64, float64) { warned = true })
+		for _, out := range append(c.Eval(eval, ctX, ctY), ctX, ctY) {
+			estimates := eval.Budgets(out)
+			for i, budget := range NoiseBudgets(testctx.params, testctx.sk, out.(*Ciphertext)) {
+				require.GreaterOrEqual(t, budget, estimates[i])
+			}
+		}
+		require.False(t, warned)
+	})
+
+	t.Run(testString("Noise/Mul/", testctx.params), func(t *testing.T) {
+		eval := vche.NewNoiseEstimator(testctx.params, NewGenericEvaluator(testctx.params, testctx.evk), nil)
+		for trial := 0; trial < 4; trial++ {
+			_, _, _, ctX, _ := newTestVectors(testctx, testctx.encryptorPk, testctx.params.T())
+			_, _, _, ctY, _ := newTestVectors(testctx, testctx.encryptorPk, testctx.params.T())
+			ct := eval.MulNew(ctX, ctY).(*Ciphertext)
+			estimates := eval.Budgets(ct)
+			require.Len(t, estimates, ct.Len())
+			for i, budget := range NoiseBudgets(testctx.params, testctx.sk, ct) {
+				require.GreaterOrEqual(t, budget, estimates[i])
+			}
+		}
+	})
+
+	t.Run(testString("Noise/Warning/", testctx.params), func(t *testing.T) {
+		_, _, _, ct, _ := newTestVectors(testctx, testctx.encryptorPk, testctx.params.T())
+
+		var warnings []string
+		eval := vche.NewNoiseEstimator(testctx.params, NewGenericEvaluator(testctx.params, testctx.evk), func(op string, _, _ float64) { warnings = append(warnings, op) })
+		var op interface{} = ct
+		for len(warnings) == 0 {
+			require.Greater(t, eval.Budget(op), 0.0)
+			op = eval.RelinearizeNew(eval.MulNew(op, op))
+		}
+		require.Equal(t, "Mul", warnings[0])
+		require.Less(t, eval.Budget(op), 0.0)
+	})
 }
 
 func testEvaluator(testctx *testContext, t *testing.T) {
@@ -660,7 +913,12 @@ func testEvaluatorRequad(testctx *testContext, t *testing.T) {
 		testctx.evaluatorPlaintext.Relinearize(verifRes, verifRes)
 
 		// Interactive Requadratization
-		ctxtRes, verifRes = RunRequadratizationProtocol(testctx.prover, testctx.verifier, ctxtRes, verifRes)
+		c3Len, c4Len := ctxtRes.Ciphertexts[3].GetDataLen(true), ctxtRes.Ciphertexts[4].GetDataLen(true)
+		session := vche.NewSession()
+		ctxtRes, verifRes = RunRequadratizationProtocol(testctx.prover, testctx.verifier.WithSession(session), ctxtRes, verifRes)
+		report := session.Report()
+		require.Equal(t, vche.Traffic{Messages: 2, Bytes: c3Len + c4Len}, report.Get(vche.ServerToClient, vche.RequadratizationMessage))
+		require.Equal(t, 2, report.Get(vche.ClientToServer, vche.RequadratizationMessage).Messages)
 
 		valuesRes := vche.ApplyBinOp(mul, values, values)
 		valuesRes = vche.ApplyBinOp(mul, valuesRes, valuesRes)
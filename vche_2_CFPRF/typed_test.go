@@ -0,0 +1,51 @@
+package vche_2_CFPRF
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"veritas/vche/vche"
+	"veritas/vche/vche_2"
+)
+
+// sumOfSquares is evaluated by the same code on the ciphertexts and on their closed-form verification state.
+func sumOfSquares[T any](eval vche.TypedEvaluator[T], xs ...T) T {
+	res := eval.RelinearizeNew(eval.MulNew(xs[0], xs[0]))
+	for _, x := range xs[1:] {
+		eval.Add(res, eval.RelinearizeNew(eval.MulNew(x, x)), res)
+	}
+	return res
+}
+
+func TestTyped(t *testing.T) {
+	params, err := vche_2.NewParametersFromLiteral(vche_2.DefaultParams[0])
+	require.NoError(t, err)
+	testctx, err := genTestParams(params)
+	require.NoError(t, err)
+
+	cts, verifs := make([]*vche_2.Ciphertext, 2), make([]*vche_2.VerifPlaintext, 2)
+	tags := make([][]vche.Tag, 2)
+	want := make([]uint64, params.NSlots)
+	for i := range cts {
+		var coeffs []uint64
+		coeffs, tags[i], _, cts[i], verifs[i] = newTestVectors(testctx, testctx.encryptorSk, 1<<8)
+		for j := range want {
+			want[j] = (want[j] + coeffs[j]*coeffs[j]) % params.T()
+		}
+	}
+
+	res := sumOfSquares(vche_2.NewTypedEvaluator(params, testctx.evk), cts...)
+	// The test vectors have the shift of the requadratization protocol
+	verif := sumOfSquares[*vche_2.VerifPlaintext](testctx.evaluatorPlaintext, verifs...)
+	verifyTestVectors(testctx, testctx.decryptor, want, res, verif, false, t)
+
+	// The Generic evaluator and encoder adapt the typed ones, and check the types at runtime
+	encoder := vche.NewGenericEncoderPlaintextOf[*vche_2.VerifPlaintext](testctx.evaluatorPlaintextEncoder)
+	eval := vche.NewGenericEvaluatorPlaintextOf[*vche_2.VerifPlaintext, interface{}](testctx.evaluatorPlaintext).ShallowCopy()
+	c := vche.NewCircuit()
+	in := c.Inputs(2)
+	c.Output(c.Add(c.Relinearize(c.Mul(in[0], in[0])), c.Relinearize(c.Mul(in[1], in[1]))))
+	genericVerif := c.Eval(eval, encoder.EncodeNew(tags[0]), encoder.EncodeNew(tags[1]))[0]
+	verifyTestVectors(testctx, testctx.decryptor, want, res, genericVerif.(*vche_2.VerifPlaintext), false, t)
+	require.PanicsWithError(t, "expected *vche_2.VerifPlaintext, got *vche_2.Ciphertext", func() { eval.RelinearizeNew(cts[0]) })
+}
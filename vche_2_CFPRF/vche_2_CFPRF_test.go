@@ -9,8 +9,11 @@ import (
 	"veritas/vche/vche"
 	"veritas/vche/vche_2"
 	"math"
+	"math/big"
 	"runtime"
 	"testing"
+
+	"github.com/stretchr/testify/require"
 )
 
 var flagLongTest = flag.Bool("long", false, "run the long test suite (all parameters). Overrides -short and requires -timeout=0.")
@@ -47,6 +50,7 @@ func TestVCHE2CFPRF(t *testing.T) {
 		for _, testSet := range []func(testctx *testContext, t *testing.T){
 			testEncoder,
 			testEvaluator,
+			testVector,
 			testType1Fails,
 			testType2Fails,
 			//testMarshaller,
@@ -69,6 +73,44 @@ func testEncoder(testctx *testContext, t *testing.T) {
 	})
 }
 
+func testVector(testctx *testContext, t *testing.T) {
+	t.Run(testString("Vector/", testctx.params), func(t *testing.T) {
+		T := testctx.params.T()
+		vectorEncoder := vche_2.NewVectorEncoder(testctx.params, testctx.encoder)
+		vectorEncoderPlaintext := vche_2.NewVectorEncoderPlaintextCFPRF(testctx.params, testctx.evaluatorPlaintextEncoder)
+		evaluator := vche_2.NewVectorEvaluator(testctx.evaluator)
+		evaluatorPlaintext := vche_2.NewVectorEvaluatorPlaintextCFPRF(testctx.evaluatorPlaintext)
+
+		length := testctx.params.NSlots + testctx.params.NSlots/2 + 1
+		a, b := vche.GetRandomCoeffs(length, T), vche.GetRandomCoeffs(length, T)
+		ctA := vche_2.EncryptVectorNew(testctx.encryptorSk, vectorEncoder.EncodeUintNew(a, []byte("a")))
+		ctB := vche_2.EncryptVectorNew(testctx.encryptorSk, vectorEncoder.EncodeUintNew(b, []byte("b")))
+		verifA := vectorEncoderPlaintext.EncodeNew([]byte("a"), length)
+		verifB := vectorEncoderPlaintext.EncodeNew([]byte("b"), length)
+
+		// a * b - b
+		ct := evaluator.RelinearizeNew(evaluator.MulNew(ctA, ctB))
+		evaluator.Sub(ct, ctB, ct)
+		verif := evaluatorPlaintext.RelinearizeNew(evaluatorPlaintext.MulNew(verifA, verifB))
+		evaluatorPlaintext.Sub(verif, verifB, verif)
+
+		bigT := big.NewInt(0).SetUint64(T)
+		want := make([]uint64, length)
+		for i := range want {
+			w := big.NewInt(0).Mul(big.NewInt(0).SetUint64(a[i]), big.NewInt(0).SetUint64(b[i]))
+			w.Sub(w, big.NewInt(0).SetUint64(b[i]))
+			want[i] = w.Mod(w, bigT).Uint64()
+		}
+		require.Equal(t, want, vectorEncoder.DecodeUintNew(vche_2.DecryptVectorNew(testctx.decryptor, ct), vche_2.EvalVector(testctx.evaluatorPlaintext, verif)))
+
+		// Tampering with a chunk is detected
+		evaluator.Add(ct, ctA, ct)
+		require.Panics(t, func() {
+			vectorEncoder.DecodeUintNew(vche_2.DecryptVectorNew(testctx.decryptor, ct), vche_2.EvalVector(testctx.evaluatorPlaintext, verif))
+		})
+	})
+}
+
 func testEvaluator(testctx *testContext, t *testing.T) {
 	for _, testSet := range []func(testctx *testContext, t *testing.T){
 		testEvaluatorAdd,